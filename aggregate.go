@@ -0,0 +1,60 @@
+package rod
+
+import (
+	"encoding/json"
+)
+
+// Aggregation holds the results of Aggregate. Count is the number of records which had a numeric value for the field,
+// and the others are computed from just those records. If Count is 0 then the others are all 0 too.
+type Aggregation struct {
+	Count int
+	Sum   float64
+	Min   float64
+	Max   float64
+	Avg   float64
+}
+
+// Aggregate computes the count, sum, min, max and average of the numeric JSON field at path (as per GetJsonField)
+// over every record in the bucket at location. The bucket is streamed through a cursor and only the field itself is
+// decoded, so it works on buckets of any size. Records where the field is missing or isn't a number are skipped.
+//
+//	agg, err := rod.Aggregate(tx, "order", "Total")
+//	fmt.Printf("%d orders, worth %.2f in total\n", agg.Count, agg.Sum)
+func Aggregate(tx Tx, location, path string) (Aggregation, error) {
+	var agg Aggregation
+
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return agg, wrapErr("Aggregate", location, "", err)
+	}
+
+	err = Each(tx, location, func(key string, value []byte) error {
+		raw, err := jsonField(value, segments)
+		if err != nil || raw == nil {
+			return nil
+		}
+		var n float64
+		if json.Unmarshal(raw, &n) != nil {
+			return nil
+		}
+
+		if agg.Count == 0 || n < agg.Min {
+			agg.Min = n
+		}
+		if agg.Count == 0 || n > agg.Max {
+			agg.Max = n
+		}
+		agg.Sum += n
+		agg.Count++
+		return nil
+	})
+	if err != nil {
+		return Aggregation{}, wrapErr("Aggregate", location, "", err)
+	}
+
+	if agg.Count > 0 {
+		agg.Avg = agg.Sum / float64(agg.Count)
+	}
+
+	return agg, nil
+}
@@ -0,0 +1,51 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestAggregate(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 12}))
+		check(PutJson(tx, "user", "bob", User{"bob", 3}))
+		check(PutJson(tx, "user", "carol", User{"carol", 21}))
+		check(PutJson(tx, "user", "dave", map[string]interface{}{"Username": "dave", "Logins": "lots"}))
+		check(PutJson(tx, "user", "eve", map[string]interface{}{"Username": "eve"}))
+		check(Put(tx, "user", "broken", []byte("{")))
+		return nil
+	})
+	check(err)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		agg, err := Aggregate(tx, "user", "Logins")
+		check(err)
+		want := Aggregation{Count: 3, Sum: 36, Min: 3, Max: 21, Avg: 12}
+		if agg != want {
+			t.Fatalf("Expected %#v but got %#v", want, agg)
+		}
+
+		agg, err = Aggregate(tx, "user", "Missing")
+		check(err)
+		if agg != (Aggregation{}) {
+			t.Fatalf("Expected an empty aggregation but got %#v", agg)
+		}
+
+		agg, err = Aggregate(tx, "does-not-exist", "Logins")
+		check(err)
+		if agg.Count != 0 {
+			t.Fatalf("Expected nothing from a missing bucket but got %#v", agg)
+		}
+
+		if _, err := Aggregate(tx, "user", "a..b"); err == nil {
+			t.Fatal("An invalid path should return an error")
+		}
+
+		return nil
+	})
+	check(err)
+}
@@ -0,0 +1,237 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// GetOrPutJson decodes the existing record at location/key into v. If there isn't one then create is called for a new
+// record which is put there instead, just as PutJson would put it (and then decoded into v too, so v always ends up the
+// same as what is stored). Since this all happens in your transaction it is atomic. The returned bool tells you whether
+// the record was created.
+//
+//	var settings Settings
+//	created, err := rod.GetOrPutJson(tx, "settings", "default", &settings, func() interface{} {
+//	    return Settings{Theme: "light"}
+//	})
+func GetOrPutJson(tx Tx, location, key string, v interface{}, create func() interface{}) (bool, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil {
+		return false, wrapErr("GetOrPutJson", location, key, err)
+	}
+	if raw != nil {
+		return false, wrapErr("GetOrPutJson", location, key, loadJson(tx, raw, v))
+	}
+
+	value, done, err := encodeJson(tx, location, key, create(), nil)
+	if err != nil {
+		return false, wrapErr("GetOrPutJson", location, key, err)
+	}
+	if err := Put(tx, location, key, value); err != nil {
+		return false, wrapErr("GetOrPutJson", location, key, err)
+	}
+	done()
+
+	return true, wrapErr("GetOrPutJson", location, key, loadJson(tx, value, v))
+}
+
+// PutIfAbsent puts value at location/key only if the key doesn't already exist, telling you whether it was written.
+// This is useful for claiming or registering something (e.g. a username) exactly once.
+func PutIfAbsent(tx Tx, location, key string, value []byte) (bool, error) {
+	exists, err := Exists(tx, location, key)
+	if err != nil {
+		return false, wrapErr("PutIfAbsent", location, key, err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	return true, wrapErr("PutIfAbsent", location, key, Put(tx, location, key, value))
+}
+
+// CompareAndSwap puts new at location/key only if what is currently stored there is the same as old, telling you
+// whether it was written. An old of nil means the key must not exist yet. Since you can capture old in one transaction
+// and swap in another, this lets you build optimistic concurrency on top of rod.
+func CompareAndSwap(tx Tx, location, key string, old, new []byte) (bool, error) {
+	exists, err := Exists(tx, location, key)
+	if err != nil {
+		return false, wrapErr("CompareAndSwap", location, key, err)
+	}
+
+	if old == nil {
+		if exists {
+			return false, nil
+		}
+	} else {
+		if !exists {
+			return false, nil
+		}
+		current, err := Get(tx, location, key)
+		if err != nil {
+			return false, wrapErr("CompareAndSwap", location, key, err)
+		}
+		if !bytes.Equal(current, old) {
+			return false, nil
+		}
+	}
+
+	return true, wrapErr("CompareAndSwap", location, key, Put(tx, location, key, new))
+}
+
+// CompareAndSwapVersion is the JSON form of CompareAndSwap where, instead of comparing the whole document, the
+// top-level numeric field in the stored document is compared against version. A missing key has version 0. If they
+// match then v is put with field set to version+1 (and if v is a pointer, it is also updated with the new version),
+// the rest of v being encoded just as PutJson would. If they don't match then false is returned and nothing is put.
+//
+//	swapped, err := rod.CompareAndSwapVersion(tx, "page", "home", "Version", page.Version, &page)
+//	if !swapped {
+//	    // someone else got there first
+//	}
+func CompareAndSwapVersion(tx Tx, location, key, field string, version int64, v interface{}) (bool, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil {
+		return false, wrapErr("CompareAndSwapVersion", location, key, err)
+	}
+
+	current, err := jsonVersion(raw, field)
+	if err != nil {
+		return false, wrapErr("CompareAndSwapVersion", location, key, err)
+	}
+	if current != version {
+		return false, nil
+	}
+
+	// encode the new document as PutJson would, setting the new version into it
+	value, done, err := encodeJson(tx, location, key, v, func(value []byte) ([]byte, error) {
+		doc := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(value, &doc); err != nil {
+			return nil, err
+		}
+		doc[field] = json.RawMessage(strconv.FormatInt(version+1, 10))
+		return json.Marshal(doc)
+	})
+	if err != nil {
+		return false, wrapErr("CompareAndSwapVersion", location, key, err)
+	}
+
+	if err := Put(tx, location, key, value); err != nil {
+		return false, wrapErr("CompareAndSwapVersion", location, key, err)
+	}
+	done()
+
+	if reflect.ValueOf(v).Kind() == reflect.Ptr {
+		return true, wrapErr("CompareAndSwapVersion", location, key, loadJson(tx, value, v))
+	}
+	return true, nil
+}
+
+// jsonVersion reads the top-level numeric field from the JSON document in raw, with a nil document or a missing (or
+// null) field being version 0.
+func jsonVersion(raw []byte, field string) (int64, error) {
+	if raw == nil {
+		return 0, nil
+	}
+
+	doc := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return 0, err
+	}
+	f, ok := doc[field]
+	if !ok || string(f) == "null" {
+		return 0, nil
+	}
+
+	var version int64
+	if err := json.Unmarshal(f, &version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Pop gets the value at location/key and deletes it, all in the one call. Unlike Get, the value is a copy so it is
+// still valid after the delete. If the key (or any bucket) doesn't exist then nil is returned and nothing happens.
+func Pop(tx Tx, location, key string) ([]byte, error) {
+	if key == "" {
+		return nil, wrapErr("Pop", location, key, ErrKeyNotProvided)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return nil, wrapErr("Pop", location, "", err)
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	if !hasKey(b, []byte(key)) {
+		return nil, nil
+	}
+	v := b.Get([]byte(key))
+	value := make([]byte, len(v))
+	copy(value, v)
+
+	return value, delKey(tx, b, location, []byte(key))
+}
+
+// PopJson calls Pop and decodes the value into v using json.Unmarshal(). If the key doesn't exist then nothing is
+// placed into v.
+func PopJson(tx Tx, location, key string, v interface{}) error {
+	raw, err := Pop(tx, location, key)
+	if err != nil || raw == nil {
+		return wrapErr("PopJson", location, key, err)
+	}
+	return wrapErr("PopJson", location, key, loadJson(tx, raw, v))
+}
+
+// PopFirst gets the first key/value in the bucket at location (just like First) and deletes it, which together with
+// time-ordered or sequential keys lets you use a bucket as a queue. If the bucket is empty then an empty key and a nil
+// value are returned.
+func PopFirst(tx Tx, location string) (string, []byte, error) {
+	key, _, err := First(tx, location)
+	if err != nil || key == "" {
+		return "", nil, wrapErr("PopFirst", location, "", err)
+	}
+	value, err := Pop(tx, location, key)
+	return key, value, wrapErr("PopFirst", location, key, err)
+}
+
+// PopLast gets the last key/value in the bucket at location (just like Last) and deletes it, allowing a bucket to be
+// used as a stack. If the bucket is empty then an empty key and a nil value are returned.
+func PopLast(tx Tx, location string) (string, []byte, error) {
+	key, _, err := Last(tx, location)
+	if err != nil || key == "" {
+		return "", nil, wrapErr("PopLast", location, "", err)
+	}
+	value, err := Pop(tx, location, key)
+	return key, value, wrapErr("PopLast", location, key, err)
+}
+
+// UpdateJson does the read-modify-write dance for you. It gets the record at location/key into v, calls modify (which
+// should change v, usually via a closure), then puts v back. If the record doesn't exist then ErrKeyNotFound is
+// returned and modify isn't called. If modify returns an error then nothing is put and that error is returned.
+//
+//	var user User
+//	err := rod.UpdateJson(tx, "user", "chilts", &user, func() error {
+//	    user.Logins++
+//	    return nil
+//	})
+func UpdateJson(tx Tx, location, key string, v interface{}, modify func() error) error {
+	raw, err := Get(tx, location, key)
+	if err != nil {
+		return wrapErr("UpdateJson", location, key, err)
+	}
+	if raw == nil {
+		return wrapErr("UpdateJson", location, key, ErrKeyNotFound)
+	}
+
+	if err := loadJson(tx, raw, v); err != nil {
+		return wrapErr("UpdateJson", location, key, err)
+	}
+	if err := modify(); err != nil {
+		return err
+	}
+
+	return wrapErr("UpdateJson", location, key, PutJson(tx, location, key, v))
+}
@@ -0,0 +1,277 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestGetOrPutJson(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		calls := 0
+		create := func() interface{} {
+			calls++
+			return User{"default", 0}
+		}
+
+		var user User
+		created, err := GetOrPutJson(tx, "settings", "user", &user, create)
+		check(err)
+		if !created || user.Username != "default" {
+			t.Fatalf("The record should have been created: %v %#v", created, user)
+		}
+
+		// change what's stored, and make sure we get it back rather than a new one
+		check(PutJson(tx, "settings", "user", User{"chilts", 5}))
+		user = User{}
+		created, err = GetOrPutJson(tx, "settings", "user", &user, create)
+		check(err)
+		if created || user.Username != "chilts" || user.Logins != 5 {
+			t.Fatalf("The existing record should have been returned: %v %#v", created, user)
+		}
+		if calls != 1 {
+			t.Fatalf("create should only have been called once, but was called %d times", calls)
+		}
+
+		if _, err := GetOrPutJson(tx, "settings", "", &user, create); !errors.Is(err, ErrKeyNotProvided) {
+			t.Fatalf("Expected ErrKeyNotProvided but got %v", err)
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		written, err := PutIfAbsent(tx, "username", "chilts", []byte("user-1"))
+		check(err)
+		if !written {
+			t.Fatal("The first claim should have been written")
+		}
+
+		written, err = PutIfAbsent(tx, "username", "chilts", []byte("user-2"))
+		check(err)
+		if written {
+			t.Fatal("The second claim should not have been written")
+		}
+
+		owner, err := GetString(tx, "username", "chilts")
+		check(err)
+		if owner != "user-1" {
+			t.Fatalf("The original claim should still be there, but got %s", owner)
+		}
+
+		// an empty value still counts as being there
+		check(Put(tx, "username", "empty", []byte{}))
+		written, err = PutIfAbsent(tx, "username", "empty", []byte("user-3"))
+		check(err)
+		if written {
+			t.Fatal("A key with an empty value should not be overwritten")
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		// nil means it must not exist
+		swapped, err := CompareAndSwap(tx, "lock", "job", nil, []byte("worker-1"))
+		check(err)
+		if !swapped {
+			t.Fatal("Swapping into a missing key should have worked")
+		}
+		swapped, err = CompareAndSwap(tx, "lock", "job", nil, []byte("worker-2"))
+		check(err)
+		if swapped {
+			t.Fatal("Swapping with nil into an existing key should not have worked")
+		}
+
+		swapped, err = CompareAndSwap(tx, "lock", "job", []byte("worker-2"), []byte("worker-3"))
+		check(err)
+		if swapped {
+			t.Fatal("Swapping with the wrong old value should not have worked")
+		}
+
+		swapped, err = CompareAndSwap(tx, "lock", "job", []byte("worker-1"), []byte("worker-3"))
+		check(err)
+		if !swapped {
+			t.Fatal("Swapping with the right old value should have worked")
+		}
+
+		owner, err := GetString(tx, "lock", "job")
+		check(err)
+		if owner != "worker-3" {
+			t.Fatalf("Expected worker-3 but got %s", owner)
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+type Page struct {
+	Title   string
+	Version int64
+}
+
+func TestCompareAndSwapVersion(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		page := Page{Title: "Home"}
+		swapped, err := CompareAndSwapVersion(tx, "page", "home", "Version", page.Version, &page)
+		check(err)
+		if !swapped || page.Version != 1 {
+			t.Fatalf("The first write should have worked and bumped the version: %v %#v", swapped, page)
+		}
+
+		// someone else reads and writes
+		var theirs Page
+		check(GetJson(tx, "page", "home", &theirs))
+		theirs.Title = "Their Home"
+		swapped, err = CompareAndSwapVersion(tx, "page", "home", "Version", theirs.Version, &theirs)
+		check(err)
+		if !swapped || theirs.Version != 2 {
+			t.Fatalf("Their write should have worked: %v %#v", swapped, theirs)
+		}
+
+		// now our stale copy should fail
+		page.Title = "My Home"
+		swapped, err = CompareAndSwapVersion(tx, "page", "home", "Version", page.Version, &page)
+		check(err)
+		if swapped {
+			t.Fatal("A stale version should not have been written")
+		}
+
+		var stored Page
+		check(GetJson(tx, "page", "home", &stored))
+		if stored.Title != "Their Home" || stored.Version != 2 {
+			t.Fatalf("The stored page is not what was expected: %#v", stored)
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestPop(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "queue", "001", "first"))
+		check(PutString(tx, "queue", "002", "second"))
+		check(PutString(tx, "queue", "003", "third"))
+		check(PutJson(tx, "user", "chilts", User{"chilts", 3}))
+
+		value, err := Pop(tx, "queue", "002")
+		check(err)
+		if string(value) != "second" {
+			t.Fatalf("Expected 'second' but got '%s'", value)
+		}
+		exists, err := Exists(tx, "queue", "002")
+		check(err)
+		if exists {
+			t.Fatal("The popped key should have been deleted")
+		}
+
+		value, err = Pop(tx, "queue", "002")
+		check(err)
+		if value != nil {
+			t.Fatal("Popping a missing key should return nil")
+		}
+
+		key, value, err := PopFirst(tx, "queue")
+		check(err)
+		if key != "001" || string(value) != "first" {
+			t.Fatalf("PopFirst returned the wrong thing: %s=%s", key, value)
+		}
+
+		key, value, err = PopLast(tx, "queue")
+		check(err)
+		if key != "003" || string(value) != "third" {
+			t.Fatalf("PopLast returned the wrong thing: %s=%s", key, value)
+		}
+
+		key, value, err = PopFirst(tx, "queue")
+		check(err)
+		if key != "" || value != nil {
+			t.Fatal("PopFirst on an empty bucket should return nothing")
+		}
+
+		var user User
+		check(PopJson(tx, "user", "chilts", &user))
+		if user.Logins != 3 {
+			t.Fatalf("Expected 3 logins but got %d", user.Logins)
+		}
+		n, err := Count(tx, "user")
+		check(err)
+		if n != 0 {
+			t.Fatal("The popped user should have been deleted")
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestUpdateJson(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "chilts", User{"chilts", 1}))
+
+		var user User
+		err := UpdateJson(tx, "user", "chilts", &user, func() error {
+			user.Logins++
+			return nil
+		})
+		check(err)
+
+		var stored User
+		check(GetJson(tx, "user", "chilts", &stored))
+		if stored.Logins != 2 {
+			t.Fatalf("Expected 2 logins but got %d", stored.Logins)
+		}
+
+		// a modify error means nothing is written
+		errBoom := errors.New("boom")
+		err = UpdateJson(tx, "user", "chilts", &user, func() error {
+			user.Logins = 100
+			return errBoom
+		})
+		if err != errBoom {
+			t.Fatalf("Expected the modify error but got %v", err)
+		}
+		check(GetJson(tx, "user", "chilts", &stored))
+		if stored.Logins != 2 {
+			t.Fatalf("Nothing should have been written, but logins is now %d", stored.Logins)
+		}
+
+		err = UpdateJson(tx, "user", "nobody", &user, func() error {
+			t.Fatal("modify should not be called for a missing record")
+			return nil
+		})
+		if !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("Expected ErrKeyNotFound but got %v", err)
+		}
+
+		return nil
+	})
+	check(err)
+}
@@ -0,0 +1,111 @@
+package rod
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AuditBucket is the top-level bucket which the audit log is kept in.
+const AuditBucket = "__rod_audit"
+
+// AuditEntry records a single Put or Del made through a Store with auditing enabled.
+type AuditEntry struct {
+	// Seq orders the entries, starting at 1.
+	Seq      uint64
+	Op       string
+	Location string
+	Key      string
+	Actor    string
+	Time     time.Time
+	// Before and After are the hex SHA-256 of the value before and after the change, or empty if there wasn't one.
+	Before string `json:",omitempty"`
+	After  string `json:",omitempty"`
+}
+
+// AuditQuery chooses which entries QueryAudit returns. Empty fields match everything.
+type AuditQuery struct {
+	Location string
+	Key      string
+	Actor    string
+	Since    time.Time
+	Until    time.Time
+	// Limit is the most entries to return, with 0 for no limit.
+	Limit int
+}
+
+// EnableAudit turns on the audit log for this Store. From then on, every Put or Del made with rod inside the Store's
+// Update or UpdateAs is recorded in AuditBucket within the same transaction, so the log can't be missing a write which
+// was committed (nor hold one which was rolled back). Use UpdateAs to record who made the change. Call EnableAudit
+// before using the Store, since it isn't safe to call alongside transactions.
+func (s *Store) EnableAudit() {
+	s.hooks = append(s.hooks, audit)
+}
+
+// audit is the hook which appends each mutation to the audit log.
+func audit(tx Tx, st *txState, m *Mutation) error {
+	b, err := createBucket(tx, AuditBucket)
+	if err != nil {
+		return err
+	}
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	entry := AuditEntry{
+		Seq:      seq,
+		Op:       m.Op,
+		Location: m.Location,
+		Key:      m.Key,
+		Actor:    st.actor,
+		Time:     now().UTC(),
+		Before:   hash(m.Before),
+		After:    hash(m.After),
+	}
+	value, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+
+	// this isn't done with putKey, otherwise we'd audit the audit log
+	return b.Put([]byte(U64Key(seq)), value)
+}
+
+func hash(value []byte) string {
+	if value == nil {
+		return ""
+	}
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:])
+}
+
+// QueryAudit returns the entries in the audit log which match q, oldest first.
+func QueryAudit(tx Tx, q AuditQuery) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	err := EachJson(tx, AuditBucket, func(key string, entry AuditEntry) error {
+		switch {
+		case q.Location != "" && entry.Location != q.Location:
+			return nil
+		case q.Key != "" && entry.Key != q.Key:
+			return nil
+		case q.Actor != "" && entry.Actor != q.Actor:
+			return nil
+		case !q.Since.IsZero() && entry.Time.Before(q.Since):
+			return nil
+		case !q.Until.IsZero() && !entry.Time.Before(q.Until):
+			return nil
+		}
+
+		entries = append(entries, entry)
+		if q.Limit > 0 && len(entries) == q.Limit {
+			return Stop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, wrapErr("QueryAudit", AuditBucket, "", err)
+	}
+	return entries, nil
+}
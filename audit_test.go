@@ -0,0 +1,90 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestAudit(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	store := NewStore(db)
+	store.EnableAudit()
+
+	t.Run("Puts and deletes are recorded", func(t *testing.T) {
+		err := store.UpdateAs("chilts", func(tx *bolt.Tx) error {
+			check(PutJson(tx, "user", "bob", User{"bob", 1}))
+			check(PutJson(tx, "user", "bob", User{"bob", 2}))
+			check(Del(tx, "user", "bob"))
+			// deleting a missing key changes nothing, so isn't recorded
+			check(Del(tx, "user", "bob"))
+			return nil
+		})
+		check(err)
+
+		err = store.View(func(tx *bolt.Tx) error {
+			entries, err := QueryAudit(tx, AuditQuery{Key: "bob"})
+			check(err)
+			if len(entries) != 3 {
+				t.Fatalf("Expected three entries but got %d", len(entries))
+			}
+
+			put, update, del := entries[0], entries[1], entries[2]
+			if put.Op != "put" || put.Actor != "chilts" || put.Before != "" || put.After == "" {
+				t.Fatalf("Unexpected first entry: %#v", put)
+			}
+			if update.Before != put.After || update.After == put.After {
+				t.Fatalf("The second entry should follow on from the first: %#v", update)
+			}
+			if del.Op != "del" || del.Before != update.After || del.After != "" {
+				t.Fatalf("Unexpected delete entry: %#v", del)
+			}
+			if del.Seq != 3 {
+				t.Fatalf("Expected the delete to be entry 3 but got %d", del.Seq)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Rolled back writes are not recorded", func(t *testing.T) {
+		failed := errors.New("failed")
+		err := store.UpdateAs("mallory", func(tx *bolt.Tx) error {
+			check(PutString(tx, "config", "admin", "mallory"))
+			return failed
+		})
+		if err != failed {
+			t.Fatalf("Expected the transaction to fail but got %v", err)
+		}
+
+		err = store.View(func(tx *bolt.Tx) error {
+			entries, err := QueryAudit(tx, AuditQuery{Actor: "mallory"})
+			check(err)
+			if len(entries) != 0 {
+				t.Fatalf("Expected no entries but got %d", len(entries))
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Writes outside the Store are not recorded", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "name", "rod")
+		})
+		check(err)
+
+		err = store.View(func(tx *bolt.Tx) error {
+			entries, err := QueryAudit(tx, AuditQuery{Location: "config"})
+			check(err)
+			if len(entries) != 0 {
+				t.Fatalf("Expected no entries but got %d", len(entries))
+			}
+			return nil
+		})
+		check(err)
+	})
+}
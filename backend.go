@@ -0,0 +1,161 @@
+package rod
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrUnsupportedTx is returned if the transaction given to rod is neither a *bolt.Tx, a BackendTx, nor one which a
+// registered backend knows about.
+var ErrUnsupportedTx = errors.New("unsupported transaction type")
+
+// Tx is the transaction every rod function works within. It is either a *bolt.Tx (the default), or a BackendTx from
+// another backend such as rodtest. Since a *bolt.Tx is already a Tx, existing code using Bolt needs no changes.
+type Tx interface {
+	Writable() bool
+}
+
+// BackendTx is the transaction a backend must provide for rod to work with it. Buckets are nested as in Bolt, with
+// the top-level ones found from the transaction.
+type BackendTx interface {
+	Tx
+
+	// Bucket returns the top-level bucket called name, or nil if it doesn't exist.
+	Bucket(name []byte) Bucket
+
+	// CreateBucketIfNotExists returns the top-level bucket called name, creating it first if needed.
+	CreateBucketIfNotExists(name []byte) (Bucket, error)
+}
+
+// Bucket is a collection of keys and nested buckets, all in key order. It follows the semantics of a *bolt.Bucket, in
+// particular that Get returns nil for a missing key or a nested bucket, and that values are only valid for the life
+// of the transaction. Neither a Bucket nor a BackendTx may write to a name or key passed in, since rod may pass the
+// bytes of a string without copying them.
+type Bucket interface {
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+
+	// Bucket returns the nested bucket called name, or nil if it doesn't exist.
+	Bucket(name []byte) Bucket
+
+	// CreateBucketIfNotExists returns the nested bucket called name, creating it first if needed.
+	CreateBucketIfNotExists(name []byte) (Bucket, error)
+
+	// NextSequence returns an auto-incrementing integer for the bucket, starting at 1.
+	NextSequence() (uint64, error)
+
+	Cursor() Cursor
+}
+
+// Cursor iterates over a Bucket in key order, as a *bolt.Cursor does. Each method returns a nil key once the cursor
+// runs off either end, and a nil value for a nested bucket.
+type Cursor interface {
+	First() (key []byte, value []byte)
+	Last() (key []byte, value []byte)
+	Next() (key []byte, value []byte)
+	Prev() (key []byte, value []byte)
+	Seek(seek []byte) (key []byte, value []byte)
+}
+
+// backends are the adapters registered with RegisterBackend.
+var backends struct {
+	sync.RWMutex
+	adapters []func(tx Tx) (BackendTx, bool)
+}
+
+// RegisterBackend lets rod accept another kind of transaction as it is, without the caller having to wrap it. The
+// adapter is given every transaction which isn't a *bolt.Tx or a BackendTx, and should return the BackendTx for it
+// and true if it knows what it is. It is usually called from an init() function, as in rodbbolt.
+func RegisterBackend(adapter func(tx Tx) (BackendTx, bool)) {
+	backends.Lock()
+	defer backends.Unlock()
+	backends.adapters = append(backends.adapters, adapter)
+}
+
+// backend returns the BackendTx to work with for tx.
+func backend(tx Tx) (BackendTx, error) {
+	switch t := tx.(type) {
+	case *bolt.Tx:
+		return boltTx{t}, nil
+	case BackendTx:
+		return t, nil
+	}
+
+	backends.RLock()
+	defer backends.RUnlock()
+	for _, adapter := range backends.adapters {
+		if btx, ok := adapter(tx); ok {
+			return btx, nil
+		}
+	}
+	return nil, ErrUnsupportedTx
+}
+
+// boltTx adapts a *bolt.Tx to a BackendTx.
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Writable() bool {
+	return t.tx.Writable()
+}
+
+func (t boltTx) Bucket(name []byte) Bucket {
+	return wrapBoltBucket(t.tx.Bucket(name))
+}
+
+func (t boltTx) CreateBucketIfNotExists(name []byte) (Bucket, error) {
+	b, err := t.tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return boltBucket{b}, nil
+}
+
+// boltBucket adapts a *bolt.Bucket to a Bucket.
+type boltBucket struct {
+	b *bolt.Bucket
+}
+
+// wrapBoltBucket makes sure a missing bucket is a nil Bucket, rather than a Bucket wrapping nil.
+func wrapBoltBucket(b *bolt.Bucket) Bucket {
+	if b == nil {
+		return nil
+	}
+	return boltBucket{b}
+}
+
+func (b boltBucket) Get(key []byte) []byte {
+	return b.b.Get(key)
+}
+
+func (b boltBucket) Put(key, value []byte) error {
+	return b.b.Put(key, value)
+}
+
+func (b boltBucket) Delete(key []byte) error {
+	return b.b.Delete(key)
+}
+
+func (b boltBucket) Bucket(name []byte) Bucket {
+	return wrapBoltBucket(b.b.Bucket(name))
+}
+
+func (b boltBucket) CreateBucketIfNotExists(name []byte) (Bucket, error) {
+	nb, err := b.b.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return boltBucket{nb}, nil
+}
+
+func (b boltBucket) NextSequence() (uint64, error) {
+	return b.b.NextSequence()
+}
+
+func (b boltBucket) Cursor() Cursor {
+	return b.b.Cursor()
+}
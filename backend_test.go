@@ -0,0 +1,102 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+// notATx is a Tx which isn't a backend, so rod can't use it.
+type notATx struct{}
+
+func (notATx) Writable() bool {
+	return true
+}
+
+// wrappedTx is a Tx which rod only understands once its backend is registered.
+type wrappedTx struct {
+	*bolt.Tx
+}
+
+func TestBackend(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("A missing bucket is nil", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "backend", "key", "value"))
+
+			b, err := BucketAt(tx, "backend.missing")
+			check(err)
+			if b != nil {
+				t.Fatalf("A missing bucket should be nil but got %#v", b)
+			}
+
+			bb, err := GetBucket(tx, "backend.missing")
+			check(err)
+			if bb != nil {
+				t.Fatalf("A missing bucket should be nil but got %#v", bb)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("GetBucket still gives the *bolt.Bucket", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			b, err := GetBucket(tx, "backend")
+			check(err)
+			if b == nil || string(b.Get([]byte("key"))) != "value" || b.Tx() != tx {
+				t.Fatalf("Expected the bolt bucket but got %#v", b)
+			}
+			n := 0
+			check(b.ForEach(func(k, v []byte) error {
+				n++
+				return nil
+			}))
+			if n != 1 {
+				t.Fatalf("Expected one key but got %d", n)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Unsupported transactions", func(t *testing.T) {
+		err := Put(notATx{}, "backend", "key", []byte("value"))
+		if !errors.Is(err, ErrUnsupportedTx) {
+			t.Fatalf("Expected ErrUnsupportedTx but got %v", err)
+		}
+
+		_, err = Get(notATx{}, "backend", "key")
+		if !errors.Is(err, ErrUnsupportedTx) {
+			t.Fatalf("Expected ErrUnsupportedTx but got %v", err)
+		}
+	})
+
+	t.Run("RegisterBackend", func(t *testing.T) {
+		RegisterBackend(func(tx Tx) (BackendTx, bool) {
+			if w, ok := tx.(wrappedTx); ok {
+				return boltTx{w.Tx}, true
+			}
+			return nil, false
+		})
+
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutString(wrappedTx{tx}, "backend", "wrapped", "value"))
+
+			value, err := GetString(tx, "backend", "wrapped")
+			check(err)
+			if value != "value" {
+				t.Fatalf("Expected the value put through the wrapped tx but got '%s'", value)
+			}
+			return nil
+		})
+		check(err)
+
+		if err := Put(notATx{}, "backend", "key", nil); !errors.Is(err, ErrUnsupportedTx) {
+			t.Fatalf("Expected ErrUnsupportedTx but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package rod
+
+// BadRecord is a value which All or Find skipped because it couldn't be decoded, when given SkipBad.
+type BadRecord struct {
+	Key string
+	// Value is a copy of the raw value, so it can be looked at (or repaired) after the transaction.
+	Value []byte
+	Err   error
+}
+
+// SkipBad makes All and Find (and the other functions taking a QueryOption) skip any value which can't be decoded,
+// adding it to bad instead of failing, so that one corrupt record doesn't make the whole location unreadable. Nested
+// buckets are skipped too, without being added.
+//
+//	var users []User
+//	var bad []rod.BadRecord
+//	err := rod.All(tx, "user", &users, rod.SkipBad(&bad))
+//	for _, b := range bad {
+//	    log.Printf("user %s is corrupt: %v", b.Key, b.Err)
+//	}
+func SkipBad(bad *[]BadRecord) QueryOption {
+	return func(q *query) {
+		q.bad = bad
+	}
+}
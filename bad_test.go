@@ -0,0 +1,94 @@
+package rod
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSkipBad(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 3}))
+		check(Put(tx, "user", "broken", []byte(`{"Username":`)))
+		check(PutJson(tx, "user", "chilts", User{"chilts", 1}))
+		check(Put(tx, "user", "wrong", []byte(`{"Logins":"lots"}`)))
+		_, err := createBucket(tx, "user.nested")
+		return err
+	}))
+
+	t.Run("A bad record fails the scan without SkipBad", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			var users []User
+			if err := All(tx, "user", &users); err == nil {
+				t.Fatalf("Expected All to fail")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("All", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			var users []User
+			var bad []BadRecord
+			check(All(tx, "user", &users, SkipBad(&bad)))
+			if len(users) != 2 || users[0].Username != "alice" || users[1].Username != "chilts" {
+				t.Fatalf("Unexpected users %#v", users)
+			}
+			if len(bad) != 2 || bad[0].Key != "broken" || bad[1].Key != "wrong" || bad[0].Err == nil {
+				t.Fatalf("Unexpected bad records %#v", bad)
+			}
+			if string(bad[0].Value) != `{"Username":` {
+				t.Fatalf("The bad value should have been kept: %q", bad[0].Value)
+			}
+
+			// and sorted, where decoding happens later
+			users, bad = nil, nil
+			check(All(tx, "user", &users, SortByDesc("Logins"), SkipBad(&bad)))
+			if len(users) != 2 || users[0].Username != "alice" || len(bad) != 2 {
+				t.Fatalf("Unexpected users %#v and bad records %#v", users, bad)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			var users []User
+			var bad []BadRecord
+			check(Find(tx, "user", func(raw []byte) bool { return true }, &users, SkipBad(&bad)))
+			if len(users) != 2 || len(bad) != 2 {
+				t.Fatalf("Unexpected users %#v and bad records %#v", users, bad)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ShardedStore", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "rod-")
+		check(err)
+		defer os.RemoveAll(dir)
+
+		s, err := OpenSharded(dir, 3, 0666, nil)
+		check(err)
+		defer s.Close()
+
+		for _, key := range []string{"a", "b", "c", "d", "e", "f"} {
+			check(s.Put("user", key, []byte(`{"Username":"`+key+`"}`)))
+		}
+		for _, key := range []string{"x", "y", "z"} {
+			check(s.Put("user", key, []byte(`nope`)))
+		}
+
+		var users []User
+		var bad []BadRecord
+		check(s.All("user", &users, SkipBad(&bad)))
+		if len(users) != 6 || len(bad) != 3 || bad[0].Key != "x" || bad[2].Key != "z" {
+			t.Fatalf("Unexpected users %#v and bad records %#v", users, bad)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package rod
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BlobChunksBucket is the top-level bucket which the chunks of each blob are kept in, beneath a bucket for its
+// location.
+const BlobChunksBucket = "__rod_blob_chunks"
+
+// DefaultBlobChunkSize is the size of each chunk a blob is split into, unless told otherwise.
+const DefaultBlobChunkSize = 64 << 10
+
+var (
+	// ErrNotBlob is returned when reading a blob from a key which holds something else.
+	ErrNotBlob = errors.New("value is not a blob")
+
+	// ErrBlobCorrupt is returned when a blob's chunks are missing, or don't match its checksum once read.
+	ErrBlobCorrupt = errors.New("blob is corrupt")
+
+	// ErrInvalidBlobChunkSize is returned by PutBlob when BlobChunkSize is given a size which isn't positive.
+	ErrInvalidBlobChunkSize = errors.New("blob chunk size must be positive")
+)
+
+// BlobInfo is the manifest which PutBlob puts at the blob's key, describing its chunks.
+type BlobInfo struct {
+	// Blob marks the value as a manifest, and is always 1.
+	Blob      int `json:"rod:blob"`
+	Size      int64
+	Chunks    int
+	ChunkSize int
+	// SHA256 is the hex checksum of the whole blob.
+	SHA256 string
+}
+
+// BlobOption configures PutBlob.
+type BlobOption func(*blobConfig)
+
+// BlobChunkSize splits the blob into chunks of n bytes, rather than DefaultBlobChunkSize. n must be positive.
+func BlobChunkSize(n int) BlobOption {
+	return func(c *blobConfig) {
+		c.chunkSize = n
+	}
+}
+
+type blobConfig struct {
+	chunkSize int
+}
+
+// PutBlob reads everything from r and stores it at location/key as a blob, which is split into chunks so that a large
+// file doesn't need one huge value (and the memory to go with it). A manifest (see BlobInfo) is put at the key itself,
+// and the chunks in BlobChunksBucket. Any blob already at the key is replaced.
+//
+//	f, err := os.Open("video.mp4")
+//	err = rod.PutBlob(tx, "uploads", "video.mp4", f)
+func PutBlob(tx Tx, location, key string, r io.Reader, opts ...BlobOption) error {
+	c := blobConfig{chunkSize: DefaultBlobChunkSize}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.chunkSize <= 0 {
+		return wrapErr("PutBlob", location, key, ErrInvalidBlobChunkSize)
+	}
+
+	old, err := StatBlob(tx, location, key)
+	if err != nil && !errors.Is(err, ErrNotBlob) {
+		return wrapErr("PutBlob", location, key, err)
+	}
+
+	info := BlobInfo{Blob: 1, ChunkSize: c.chunkSize}
+	sum := sha256.New()
+	for {
+		// bolt keeps hold of the value until the commit, so each chunk needs its own buffer
+		chunk := make([]byte, c.chunkSize)
+		n, err := io.ReadFull(r, chunk)
+		if n > 0 {
+			if err := Put(tx, blobChunksLocation(location), blobChunkKey(key, info.Chunks), chunk[:n]); err != nil {
+				return wrapErr("PutBlob", location, key, err)
+			}
+			sum.Write(chunk[:n])
+			info.Size += int64(n)
+			info.Chunks++
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return wrapErr("PutBlob", location, key, err)
+		}
+	}
+	info.SHA256 = hex.EncodeToString(sum.Sum(nil))
+
+	// remove any chunks of the old blob beyond the end of the new one
+	if old != nil {
+		for i := info.Chunks; i < old.Chunks; i++ {
+			if err := Del(tx, blobChunksLocation(location), blobChunkKey(key, i)); err != nil {
+				return wrapErr("PutBlob", location, key, err)
+			}
+		}
+	}
+
+	return wrapErr("PutBlob", location, key, PutJson(tx, location, key, info))
+}
+
+// StatBlob returns the manifest of the blob at location/key, or nil if the key doesn't exist. ErrNotBlob is returned
+// if the key holds something else.
+func StatBlob(tx Tx, location, key string) (*BlobInfo, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+
+	info := parseBlob(raw)
+	if info == nil {
+		return nil, wrapErr("StatBlob", location, key, ErrNotBlob)
+	}
+	return info, nil
+}
+
+// parseBlob returns the manifest in raw, or nil if it isn't one. Since the marker is always the first field, anything
+// else can be told apart without decoding it.
+func parseBlob(raw []byte) *BlobInfo {
+	if !bytes.HasPrefix(raw, []byte(`{"rod:blob":`)) {
+		return nil
+	}
+	var info BlobInfo
+	if err := json.Unmarshal(raw, &info); err != nil || info.Blob != 1 {
+		return nil
+	}
+	return &info
+}
+
+// GetBlob returns a reader for the blob at location/key, or nil if the key doesn't exist. Chunks are read as they are
+// needed, so the blob is never all in memory at once, and the reader must be finished with before the transaction is.
+// Once the whole blob has been read it is checked against its checksum, and ErrBlobCorrupt returned instead of io.EOF
+// if it doesn't match.
+//
+//	r, err := rod.GetBlob(tx, "uploads", "video.mp4")
+//	_, err = io.Copy(w, r)
+func GetBlob(tx Tx, location, key string) (io.Reader, error) {
+	info, err := StatBlob(tx, location, key)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return &blobReader{tx: tx, location: location, key: key, info: info, sum: sha256.New()}, nil
+}
+
+// DelBlob deletes the blob at location/key, along with its chunks. As with Del, deleting one which doesn't exist is
+// not an error.
+func DelBlob(tx Tx, location, key string) error {
+	info, err := StatBlob(tx, location, key)
+	if err != nil || info == nil {
+		return err
+	}
+	for i := 0; i < info.Chunks; i++ {
+		if err := Del(tx, blobChunksLocation(location), blobChunkKey(key, i)); err != nil {
+			return err
+		}
+	}
+	return Del(tx, location, key)
+}
+
+func blobChunksLocation(location string) string {
+	return BlobChunksBucket + "." + location
+}
+
+// blobChunkKey is the key of chunk i of the blob at key, which sort in order.
+func blobChunkKey(key string, i int) string {
+	return fmt.Sprintf("%s\x00%08x", key, i)
+}
+
+// blobReader reads a blob a chunk at a time.
+type blobReader struct {
+	tx       Tx
+	location string
+	key      string
+	info     *BlobInfo
+	next     int
+	chunk    []byte
+	sum      interface {
+		io.Writer
+		Sum([]byte) []byte
+	}
+	off int64
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.next == r.info.Chunks {
+			if r.off != r.info.Size || hex.EncodeToString(r.sum.Sum(nil)) != r.info.SHA256 {
+				return 0, wrapErr("GetBlob", r.location, r.key, ErrBlobCorrupt)
+			}
+			return 0, io.EOF
+		}
+		chunk, err := Get(r.tx, blobChunksLocation(r.location), blobChunkKey(r.key, r.next))
+		if err != nil {
+			return 0, err
+		}
+		if chunk == nil {
+			return 0, wrapErr("GetBlob", r.location, r.key, ErrBlobCorrupt)
+		}
+		r.sum.Write(chunk)
+		r.chunk = chunk
+		r.next++
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	r.off += int64(n)
+	return n, nil
+}
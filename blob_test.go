@@ -0,0 +1,155 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func readBlob(tx Tx, location, key string) ([]byte, error) {
+	r, err := GetBlob(tx, location, key)
+	if err != nil || r == nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestBlob(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	data := make([]byte, 300<<10)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	t.Run("PutBlob and GetBlob", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			return PutBlob(tx, "files", "big.bin", bytes.NewReader(data))
+		}))
+
+		check(db.View(func(tx *bolt.Tx) error {
+			got, err := readBlob(tx, "files", "big.bin")
+			check(err)
+			if !bytes.Equal(got, data) {
+				t.Fatalf("The blob read back should be the same as the one put (%d bytes vs %d)", len(got), len(data))
+			}
+
+			info, err := StatBlob(tx, "files", "big.bin")
+			check(err)
+			if info.Size != int64(len(data)) || info.Chunks != 5 || info.ChunkSize != DefaultBlobChunkSize {
+				t.Fatalf("Unexpected manifest: %#v", info)
+			}
+
+			// no single value should be bigger than a chunk
+			each := 0
+			check(tx.Bucket([]byte(BlobChunksBucket)).Bucket([]byte("files")).ForEach(func(k, v []byte) error {
+				each++
+				if len(v) > DefaultBlobChunkSize {
+					t.Fatalf("Chunk %q is %d bytes", k, len(v))
+				}
+				return nil
+			}))
+			if each != 5 {
+				t.Fatalf("There should be 5 chunks, not %d", each)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Replacing a blob removes its old chunks", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			return PutBlob(tx, "files", "big.bin", strings.NewReader("small now"), BlobChunkSize(4))
+		}))
+
+		check(db.View(func(tx *bolt.Tx) error {
+			got, err := readBlob(tx, "files", "big.bin")
+			check(err)
+			if string(got) != "small now" {
+				t.Fatalf("Unexpected blob: %q", got)
+			}
+			if n := tx.Bucket([]byte(BlobChunksBucket)).Bucket([]byte("files")).Stats().KeyN; n != 3 {
+				t.Fatalf("There should be 3 chunks left, not %d", n)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Empty and missing blobs", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			check(PutBlob(tx, "files", "empty", strings.NewReader("")))
+
+			got, err := readBlob(tx, "files", "empty")
+			check(err)
+			if got == nil || len(got) != 0 {
+				t.Fatalf("An empty blob should read back as empty, not %q", got)
+			}
+
+			r, err := GetBlob(tx, "files", "missing")
+			check(err)
+			if r != nil {
+				t.Fatal("A missing blob should give a nil reader")
+			}
+
+			check(PutString(tx, "files", "plain", "not a blob"))
+			_, err = GetBlob(tx, "files", "plain")
+			if !errors.Is(err, ErrNotBlob) {
+				t.Fatalf("Expected ErrNotBlob, got %v", err)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Corrupt blobs", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			check(PutBlob(tx, "files", "corrupt", strings.NewReader("abcdefgh"), BlobChunkSize(4)))
+			check(Put(tx, blobChunksLocation("files"), blobChunkKey("corrupt", 1), []byte("EFGH")))
+			_, err := readBlob(tx, "files", "corrupt")
+			if !errors.Is(err, ErrBlobCorrupt) {
+				t.Fatalf("Expected ErrBlobCorrupt for a changed chunk, got %v", err)
+			}
+
+			check(Del(tx, blobChunksLocation("files"), blobChunkKey("corrupt", 1)))
+			_, err = readBlob(tx, "files", "corrupt")
+			if !errors.Is(err, ErrBlobCorrupt) {
+				t.Fatalf("Expected ErrBlobCorrupt for a missing chunk, got %v", err)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("DelBlob", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			check(DelBlob(tx, "files", "big.bin"))
+			check(DelBlob(tx, "files", "big.bin"))
+
+			info, err := StatBlob(tx, "files", "big.bin")
+			check(err)
+			if info != nil {
+				t.Fatal("The blob should have been deleted")
+			}
+			v, err := Get(tx, blobChunksLocation("files"), blobChunkKey("big.bin", 0))
+			check(err)
+			if v != nil {
+				t.Fatal("The blob's chunks should have been deleted")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Chunk sizes must be positive", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			for _, n := range []int{0, -1} {
+				err := PutBlob(tx, "files", "bad.bin", strings.NewReader("data"), BlobChunkSize(n))
+				var e *Error
+				if !errors.Is(err, ErrInvalidBlobChunkSize) || !errors.As(err, &e) || e.Op != "PutBlob" {
+					t.Fatalf("Expected ErrInvalidBlobChunkSize from PutBlob for %d but got %v", n, err)
+				}
+			}
+			return nil
+		}))
+	})
+}
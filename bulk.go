@@ -0,0 +1,201 @@
+package rod
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/boltdb/bolt"
+)
+
+// DefaultBulkLoadBatchSize is how many keys BulkLoad puts in each transaction, unless told otherwise.
+const DefaultBulkLoadBatchSize = 10000
+
+// ErrInvalidBulkLoadBatchSize is returned by BulkLoad when BulkLoadBatchSize is given a size which isn't positive.
+var ErrInvalidBulkLoadBatchSize = errors.New("bulk load batch size must be positive")
+
+// KV is a single key and value, as given to BulkLoad.
+type KV struct {
+	Key   string
+	Value []byte
+}
+
+// BulkLoadProgress tells you how far a bulk load has got.
+type BulkLoadProgress struct {
+	// Keys and Bytes are the number of keys, and the size of their values, committed so far.
+	Keys  int64
+	Bytes int64
+	// Txs is the number of transactions committed so far.
+	Txs int
+}
+
+// BulkLoadOption configures BulkLoad and BulkLoadJsonLines.
+type BulkLoadOption func(*bulkLoad)
+
+// BulkLoadBatchSize sets how many keys are put in each transaction, which is DefaultBulkLoadBatchSize by default. The
+// size must be positive.
+func BulkLoadBatchSize(n int) BulkLoadOption {
+	return func(l *bulkLoad) {
+		l.batchSize = n
+	}
+}
+
+// OnBulkLoadProgress calls fn after each transaction has committed.
+func OnBulkLoadProgress(fn func(p BulkLoadProgress)) BulkLoadOption {
+	return func(l *bulkLoad) {
+		l.progress = fn
+	}
+}
+
+// BulkLoadNoSync turns off Bolt's fsync after each commit for the duration of the load, and syncs once at the end
+// instead, which is much faster. If the process dies part way through then the load may be lost, and since it sets
+// db.NoSync, nothing else should be writing to db at the same time.
+func BulkLoadNoSync() BulkLoadOption {
+	return func(l *bulkLoad) {
+		l.noSync = true
+	}
+}
+
+type bulkLoad struct {
+	batchSize int
+	progress  func(p BulkLoadProgress)
+	noSync    bool
+}
+
+// BulkLoad puts every item received from items into the bucket at location, until items is closed. Rather than one
+// transaction for each item, items are put in batches of BulkLoadBatchSize, each in its own transaction, which makes
+// importing millions of records a matter of minutes rather than hours. The bucket's FillPercent is raised so that items
+// received in key order pack the pages tightly. Since Bolt keeps hold of each value until its transaction commits, a
+// value must not be changed once it has been sent.
+//
+//	items := make(chan rod.KV, 1000)
+//	go func() {
+//	    defer close(items)
+//	    for _, row := range rows {
+//	        items <- rod.KV{Key: row.ID, Value: row.Json()}
+//	    }
+//	}()
+//	p, err := rod.BulkLoad(db, "rows", items, rod.BulkLoadNoSync())
+//
+// If a transaction fails then BulkLoad returns its error (along with the progress committed before it) without
+// reading any more items, so the sender should also stop if nothing is receiving.
+func BulkLoad(db *bolt.DB, location string, items <-chan KV, opts ...BulkLoadOption) (BulkLoadProgress, error) {
+	l := &bulkLoad{batchSize: DefaultBulkLoadBatchSize}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.batchSize <= 0 {
+		return BulkLoadProgress{}, wrapErr("BulkLoad", location, "", ErrInvalidBulkLoadBatchSize)
+	}
+
+	if l.noSync {
+		noSync := db.NoSync
+		db.NoSync = true
+		defer func() { db.NoSync = noSync }()
+	}
+
+	var p BulkLoadProgress
+	batch := make([]KV, 0, l.batchSize)
+	for open := true; open; {
+		batch = batch[:0]
+		for len(batch) < l.batchSize {
+			item, ok := <-items
+			if !ok {
+				open = false
+				break
+			}
+			batch = append(batch, item)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		var bytes int64
+		err := db.Update(func(tx *bolt.Tx) error {
+			b, err := createBucket(tx, location)
+			if err != nil {
+				return err
+			}
+			if bb, ok := b.(boltBucket); ok {
+				bb.b.FillPercent = 0.9
+			}
+			for _, item := range batch {
+				if item.Key == "" {
+					return wrapErr("BulkLoad", location, item.Key, ErrKeyNotProvided)
+				}
+				if err := putKey(tx, b, location, []byte(item.Key), item.Value); err != nil {
+					return wrapErr("BulkLoad", location, item.Key, err)
+				}
+				bytes += int64(len(item.Value))
+			}
+			return nil
+		})
+		if err != nil {
+			return p, wrapErr("BulkLoad", location, "", err)
+		}
+
+		p.Keys += int64(len(batch))
+		p.Bytes += bytes
+		p.Txs++
+		if l.progress != nil {
+			l.progress(p)
+		}
+	}
+
+	if l.noSync {
+		if err := db.Sync(); err != nil {
+			return p, wrapErr("BulkLoad", location, "", err)
+		}
+	}
+	return p, nil
+}
+
+// BulkLoadJsonLines is BulkLoad for a reader of newline-delimited JSON documents, such as an export from another
+// database, where each document's key is in its field called keyField. Each line is put as it is, and blank lines are
+// skipped. A line which isn't a JSON object, or whose key isn't a string, stops the load with an error.
+//
+//	f, err := os.Open("users.jsonl")
+//	p, err := rod.BulkLoadJsonLines(db, "users", f, "id")
+func BulkLoadJsonLines(db *bolt.DB, location string, r io.Reader, keyField string, opts ...BulkLoadOption) (BulkLoadProgress, error) {
+	items := make(chan KV, 1000)
+	stop := make(chan struct{})
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(items)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 64<<20)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			var doc map[string]json.RawMessage
+			var key string
+			if err := json.Unmarshal(line, &doc); err != nil {
+				errc <- err
+				return
+			}
+			if err := json.Unmarshal(doc[keyField], &key); err != nil {
+				errc <- err
+				return
+			}
+
+			select {
+			case items <- KV{Key: key, Value: copyBytes(line)}:
+			case <-stop:
+				return
+			}
+		}
+		errc <- scanner.Err()
+	}()
+
+	p, err := BulkLoad(db, location, items, opts...)
+	close(stop)
+	if err != nil {
+		return p, err
+	}
+	return p, wrapErr("BulkLoadJsonLines", location, "", <-errc)
+}
@@ -0,0 +1,93 @@
+package rod
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestBulkLoad(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("BulkLoad", func(t *testing.T) {
+		items := make(chan KV)
+		go func() {
+			defer close(items)
+			for i := 0; i < 2500; i++ {
+				items <- KV{Key: fmt.Sprintf("row-%05d", i), Value: []byte(fmt.Sprintf(`{"n":%d}`, i))}
+			}
+		}()
+
+		var reports []BulkLoadProgress
+		p, err := BulkLoad(db, "rows", items, BulkLoadBatchSize(1000), BulkLoadNoSync(), OnBulkLoadProgress(func(p BulkLoadProgress) {
+			reports = append(reports, p)
+		}))
+		check(err)
+		if p.Keys != 2500 || p.Txs != 3 || len(reports) != 3 || reports[0].Keys != 1000 {
+			t.Fatalf("Unexpected progress: %#v %#v", p, reports)
+		}
+		if db.NoSync {
+			t.Fatal("NoSync should have been put back")
+		}
+
+		check(db.View(func(tx *bolt.Tx) error {
+			n, err := Count(tx, "rows")
+			check(err)
+			if n != 2500 {
+				t.Fatalf("Expected 2500 rows, not %d", n)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("A failed transaction stops the load", func(t *testing.T) {
+		items := make(chan KV, 3)
+		items <- KV{Key: "a", Value: []byte("1")}
+		items <- KV{Key: "", Value: []byte("2")}
+		close(items)
+
+		p, err := BulkLoad(db, "broken", items)
+		if err == nil || p.Keys != 0 {
+			t.Fatalf("An empty key should fail the load: %#v %v", p, err)
+		}
+	})
+
+	t.Run("The batch size must be positive", func(t *testing.T) {
+		for _, n := range []int{0, -1} {
+			items := make(chan KV, 1)
+			items <- KV{Key: "a", Value: []byte("1")}
+			close(items)
+
+			if _, err := BulkLoad(db, "sized", items, BulkLoadBatchSize(n)); !errors.Is(err, ErrInvalidBulkLoadBatchSize) {
+				t.Fatalf("Expected ErrInvalidBulkLoadBatchSize for %d but got %v", n, err)
+			}
+		}
+	})
+
+	t.Run("BulkLoadJsonLines", func(t *testing.T) {
+		r := strings.NewReader(`{"id":"chilts","Logins":3}` + "\n\n" + `{"id":"bob","Logins":1}` + "\n")
+		p, err := BulkLoadJsonLines(db, "users", r, "id")
+		check(err)
+		if p.Keys != 2 {
+			t.Fatalf("Two users should have been loaded, not %d", p.Keys)
+		}
+
+		check(db.View(func(tx *bolt.Tx) error {
+			var user User
+			check(GetJson(tx, "users", "chilts", &user))
+			if user.Logins != 3 {
+				t.Fatalf("Unexpected user: %#v", user)
+			}
+			return nil
+		}))
+
+		_, err = BulkLoadJsonLines(db, "users", strings.NewReader("not json\n"), "id")
+		if err == nil {
+			t.Fatal("A line which isn't JSON should be an error")
+		}
+	})
+}
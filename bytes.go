@@ -0,0 +1,94 @@
+package rod
+
+// PutB is the same as Put except the key is a []byte, for binary keys such as hashes or packed integers.
+func PutB(tx Tx, location string, key, value []byte) error {
+	if len(key) == 0 {
+		return wrapErr("PutB", location, "", ErrKeyNotProvided)
+	}
+
+	b, err := createBucket(tx, location)
+	if err != nil {
+		return wrapErr("PutB", location, string(key), err)
+	}
+
+	return wrapErr("PutB", location, string(key), putKey(tx, b, location, key, value))
+}
+
+// GetB is the same as Get except the key is a []byte. As with Get, the value is only valid for the life of the
+// transaction.
+func GetB(tx Tx, location string, key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, wrapErr("GetB", location, "", ErrKeyNotProvided)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return nil, wrapErr("GetB", location, string(key), err)
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	return b.Get(key), nil
+}
+
+// DelB is the same as Del except the key is a []byte.
+func DelB(tx Tx, location string, key []byte) error {
+	if len(key) == 0 {
+		return wrapErr("DelB", location, "", ErrKeyNotProvided)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("DelB", location, string(key), err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	return wrapErr("DelB", location, string(key), delKey(tx, b, location, key))
+}
+
+// ExistsB is the same as Exists except the key is a []byte.
+func ExistsB(tx Tx, location string, key []byte) (bool, error) {
+	if len(key) == 0 {
+		return false, wrapErr("ExistsB", location, "", ErrKeyNotProvided)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return false, wrapErr("ExistsB", location, "", err)
+	}
+	if b == nil {
+		return false, nil
+	}
+
+	return hasKey(b, key), nil
+}
+
+// EachB is the same as Each except the key is given to fn as a []byte. Both the key and the value are only valid for
+// the life of the transaction, so copy them if you need to keep them.
+func EachB(tx Tx, location string, fn func(key, value []byte) error) error {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("EachB", location, "", err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		if err := fn(k, v); err != nil {
+			if err == Stop {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
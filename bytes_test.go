@@ -0,0 +1,80 @@
+package rod
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestBytesKeys(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("PutB, GetB, ExistsB and DelB", func(t *testing.T) {
+		hash := sha256.Sum256([]byte("Hello, World!"))
+		key := hash[:]
+
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutB(tx, "blobs", key, []byte("Hello, World!")))
+
+			value, err := GetB(tx, "blobs", key)
+			check(err)
+			if string(value) != "Hello, World!" {
+				t.Fatalf("Expected the blob back but got '%s'", value)
+			}
+
+			exists, err := ExistsB(tx, "blobs", key)
+			check(err)
+			if !exists {
+				t.Fatal("The blob should exist")
+			}
+
+			check(DelB(tx, "blobs", key))
+			exists, err = ExistsB(tx, "blobs", key)
+			check(err)
+			if exists {
+				t.Fatal("The blob should have been deleted")
+			}
+
+			// a missing bucket is not an error
+			value, err = GetB(tx, "no-blobs", key)
+			check(err)
+			if value != nil {
+				t.Fatal("Expected nil from a missing bucket")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("EachB", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutB(tx, "packed", []byte{0x00, 0x01}, []byte("one")))
+			check(PutB(tx, "packed", []byte{0x00, 0x00}, []byte("zero")))
+
+			var keys [][]byte
+			err := EachB(tx, "packed", func(key, value []byte) error {
+				keys = append(keys, append([]byte(nil), key...))
+				return nil
+			})
+			check(err)
+			if len(keys) != 2 || !bytes.Equal(keys[0], []byte{0x00, 0x00}) {
+				t.Fatalf("Unexpected keys: %v", keys)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Empty keys", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return PutB(tx, "blobs", nil, []byte("x"))
+		})
+		if !errors.Is(err, ErrKeyNotProvided) {
+			t.Fatalf("Expected ErrKeyNotProvided but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package rod
+
+import (
+	"container/list"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+// CacheStats are the counters of the cache turned on with EnableCache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Size is how many records are in the cache.
+	Size int
+}
+
+// cacheKey is where a cached record is kept.
+type cacheKey struct {
+	location string
+	key      string
+}
+
+// cacheEntry is a record decoded into a value of its type, which is what GetJsonCached copies out.
+type cacheEntry struct {
+	key   cacheKey
+	value reflect.Value
+}
+
+// readCache is an LRU cache of decoded records. Since a read which started before a write committed may have the old
+// value, generation counts the invalidations so that such a read knows not to cache what it found.
+type readCache struct {
+	mu         sync.Mutex
+	size       int
+	entries    map[cacheKey]*list.Element
+	lru        *list.List
+	generation uint64
+	stats      CacheStats
+}
+
+// EnableCache turns on a read-through cache in front of GetJsonCached, holding up to size decoded records and
+// evicting the least recently used. A Put or Del made with rod inside the Store's Update or UpdateAs removes the
+// record from the cache once it has committed, but a write made some other way (directly with Bolt, or by another
+// process) isn't seen. As with AddView, call EnableCache before using the Store.
+func (s *Store) EnableCache(size int) {
+	s.cache = &readCache{size: size, entries: map[cacheKey]*list.Element{}, lru: list.New()}
+	s.hooks = append(s.hooks, uncache)
+}
+
+// uncache is the hook which notes each key written to, to remove from the cache after the commit.
+func uncache(tx Tx, st *txState, m *Mutation) error {
+	st.uncached = append(st.uncached, cacheKey{m.Location, m.Key})
+	return nil
+}
+
+// CacheStats returns the counters of the cache, which are all zero unless EnableCache has been called.
+func (s *Store) CacheStats() CacheStats {
+	if s.cache == nil {
+		return CacheStats{}
+	}
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	stats := s.cache.stats
+	stats.Size = s.cache.lru.Len()
+	return stats
+}
+
+// GetJsonCached is like GetJson in a transaction of its own, except that the decoded record is cached, so getting it
+// again just copies it into v without reading or decoding anything. Since the copy is shallow, any slices, maps or
+// pointers in v are shared with the cache and must not be changed. Without EnableCache it is the same as GetJson.
+//
+//	var user User
+//	err := store.GetJsonCached("user", "chilts", &user)
+func (s *Store) GetJsonCached(location, key string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return wrapErr("GetJsonCached", location, key, &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)})
+	}
+	get := func(v interface{}) (bool, error) {
+		var found bool
+		err := s.View(func(tx *bolt.Tx) error {
+			var err error
+			found, err = GetJsonFound(tx, location, key, v)
+			return err
+		})
+		return found, wrapErr("GetJsonCached", location, key, err)
+	}
+	if s.cache == nil {
+		_, err := get(v)
+		return err
+	}
+
+	ck := cacheKey{location, key}
+	generation, ok := s.cache.get(ck, rv.Elem())
+	if ok {
+		return nil
+	}
+
+	fresh := reflect.New(rv.Elem().Type())
+	found, err := get(fresh.Interface())
+	if err != nil || !found {
+		return err
+	}
+	rv.Elem().Set(fresh.Elem())
+	s.cache.add(ck, fresh.Elem(), generation)
+	return nil
+}
+
+// get copies the cached record into v if there is one of the same type. Otherwise it counts a miss and returns the
+// generation to give add.
+func (c *readCache) get(ck cacheKey, v reflect.Value) (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[ck]; ok {
+		entry := el.Value.(*cacheEntry)
+		if entry.value.Type() == v.Type() {
+			c.lru.MoveToFront(el)
+			c.stats.Hits++
+			v.Set(entry.value)
+			return 0, true
+		}
+	}
+	c.stats.Misses++
+	return c.generation, false
+}
+
+// add caches the record, unless something has been invalidated since the read of it began.
+func (c *readCache) add(ck cacheKey, value reflect.Value, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation || c.size <= 0 {
+		return
+	}
+	if el, ok := c.entries[ck]; ok {
+		el.Value.(*cacheEntry).value = value
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[ck] = c.lru.PushFront(&cacheEntry{key: ck, value: value})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// invalidate removes the records which have been written to.
+func (c *readCache) invalidate(keys []cacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, ck := range keys {
+		if el, ok := c.entries[ck]; ok {
+			c.lru.Remove(el)
+			delete(c.entries, ck)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package rod
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestCache(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	s := NewStore(db)
+	s.EnableCache(2)
+
+	check(s.Update(func(tx *bolt.Tx) error {
+		for i := 1; i <= 3; i++ {
+			check(PutJson(tx, "user", fmt.Sprintf("user%d", i), User{fmt.Sprintf("user%d", i), i}))
+		}
+		return nil
+	}))
+
+	t.Run("Hits and misses", func(t *testing.T) {
+		var user User
+		check(s.GetJsonCached("user", "user1", &user))
+		check(s.GetJsonCached("user", "user1", &user))
+		if user.Logins != 1 {
+			t.Fatalf("Unexpected user: %#v", user)
+		}
+
+		var missing User
+		check(s.GetJsonCached("user", "nobody", &missing))
+		if missing.Username != "" {
+			t.Fatal("A missing record should leave v alone")
+		}
+
+		if stats := s.CacheStats(); stats.Hits != 1 || stats.Misses != 2 || stats.Size != 1 {
+			t.Fatalf("Unexpected stats: %#v", stats)
+		}
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		var user User
+		check(s.GetJsonCached("user", "user2", &user))
+		check(s.GetJsonCached("user", "user3", &user))
+		if stats := s.CacheStats(); stats.Evictions != 1 || stats.Size != 2 {
+			t.Fatalf("The least recently used record should have been evicted: %#v", stats)
+		}
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "user", "user3", User{"user3", 30})
+		}))
+
+		var user User
+		check(s.GetJsonCached("user", "user3", &user))
+		if user.Logins != 30 {
+			t.Fatalf("A Put should have removed the record from the cache: %#v", user)
+		}
+
+		check(s.Update(func(tx *bolt.Tx) error {
+			return Del(tx, "user", "user3")
+		}))
+		user = User{}
+		check(s.GetJsonCached("user", "user3", &user))
+		if user.Username != "" {
+			t.Fatalf("A Del should have removed the record from the cache: %#v", user)
+		}
+	})
+
+	t.Run("Different types", func(t *testing.T) {
+		var raw map[string]interface{}
+		check(s.GetJsonCached("user", "user2", &raw))
+		if raw["Username"] != "user2" {
+			t.Fatalf("The record should be decoded into the type asked for: %#v", raw)
+		}
+
+		if err := s.GetJsonCached("user", "user2", User{}); err == nil {
+			t.Fatal("A value which isn't a pointer should be an error")
+		}
+	})
+}
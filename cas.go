@@ -0,0 +1,66 @@
+package rod
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// CASRefsBucket is the top-level bucket which the reference counts for each content-addressed location are kept in.
+const CASRefsBucket = "__rod_cas_refs"
+
+// PutCAS stores data in location under the hex SHA-256 of its content, and returns that hash. Putting the same content
+// again doesn't store it twice, but counts another reference to it, so that it is only deleted once each PutCAS has
+// been matched by a ReleaseCAS.
+//
+//	hash, err := rod.PutCAS(tx, "attachments", data)
+//	err = rod.PutJson(tx, "message", id, Message{Body: body, Attachment: hash})
+func PutCAS(tx Tx, location string, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	refs, err := GetInt64(tx, casRefsLocation(location), hash)
+	if err != nil {
+		return "", wrapErr("PutCAS", location, hash, err)
+	}
+	if refs == 0 {
+		if err := Put(tx, location, hash, data); err != nil {
+			return "", wrapErr("PutCAS", location, hash, err)
+		}
+	}
+	return hash, wrapErr("PutCAS", location, hash, PutInt64(tx, casRefsLocation(location), hash, refs+1))
+}
+
+// GetCAS fetches the content stored with PutCAS under hash. If it doesn't exist (or has been released) it returns
+// nil.
+func GetCAS(tx Tx, location, hash string) ([]byte, error) {
+	return Get(tx, location, hash)
+}
+
+// CASRefs returns how many references there are to the content under hash, which is 0 if it doesn't exist.
+func CASRefs(tx Tx, location, hash string) (int64, error) {
+	return GetInt64(tx, casRefsLocation(location), hash)
+}
+
+// ReleaseCAS drops one reference to the content under hash, deleting it once there are none left. Releasing a hash
+// which doesn't exist returns ErrKeyNotFound, since it means a reference has been released twice.
+func ReleaseCAS(tx Tx, location, hash string) error {
+	refs, err := GetInt64(tx, casRefsLocation(location), hash)
+	if err != nil {
+		return wrapErr("ReleaseCAS", location, hash, err)
+	}
+	if refs == 0 {
+		return wrapErr("ReleaseCAS", location, hash, ErrKeyNotFound)
+	}
+
+	if refs > 1 {
+		return wrapErr("ReleaseCAS", location, hash, PutInt64(tx, casRefsLocation(location), hash, refs-1))
+	}
+	if err := Del(tx, casRefsLocation(location), hash); err != nil {
+		return wrapErr("ReleaseCAS", location, hash, err)
+	}
+	return wrapErr("ReleaseCAS", location, hash, Del(tx, location, hash))
+}
+
+func casRefsLocation(location string) string {
+	return CASRefsBucket + "." + location
+}
@@ -0,0 +1,82 @@
+package rod
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestCAS(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("Duplicate content is stored once", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			a, err := PutCAS(tx, "attachments", []byte("same file"))
+			check(err)
+			b, err := PutCAS(tx, "attachments", []byte("same file"))
+			check(err)
+			c, err := PutCAS(tx, "attachments", []byte("another file"))
+			check(err)
+
+			if a != b || a == c {
+				t.Fatalf("The same content should have the same hash, and different content a different one: %s %s %s", a, b, c)
+			}
+			sum := sha256.Sum256([]byte("same file"))
+			if a != hex.EncodeToString(sum[:]) {
+				t.Fatalf("The hash should be a hex SHA-256, not %q", a)
+			}
+
+			keys, err := AllKeys(tx, "attachments")
+			check(err)
+			if len(keys) != 2 {
+				t.Fatalf("Only two values should have been stored, not %d", len(keys))
+			}
+
+			refs, err := CASRefs(tx, "attachments", a)
+			check(err)
+			if refs != 2 {
+				t.Fatalf("There should be two references, not %d", refs)
+			}
+
+			data, err := GetCAS(tx, "attachments", a)
+			check(err)
+			if string(data) != "same file" {
+				t.Fatalf("Unexpected content: %q", data)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ReleaseCAS", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			hash, err := PutCAS(tx, "attachments", []byte("same file"))
+			check(err)
+
+			// there are three references now
+			check(ReleaseCAS(tx, "attachments", hash))
+			check(ReleaseCAS(tx, "attachments", hash))
+			data, err := GetCAS(tx, "attachments", hash)
+			check(err)
+			if data == nil {
+				t.Fatal("The content should still be there while it has a reference")
+			}
+
+			check(ReleaseCAS(tx, "attachments", hash))
+			data, err = GetCAS(tx, "attachments", hash)
+			check(err)
+			if data != nil {
+				t.Fatal("The content should have gone with its last reference")
+			}
+
+			err = ReleaseCAS(tx, "attachments", hash)
+			if !errors.Is(err, ErrKeyNotFound) {
+				t.Fatalf("Releasing too many times should be ErrKeyNotFound, not %v", err)
+			}
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,99 @@
+package rod
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// ChangelogBucket is the top-level bucket which the changelog is kept in.
+const ChangelogBucket = "__rod_changes"
+
+// Change is a single mutation in the changelog.
+type Change struct {
+	// Seq orders the changes, starting at 1.
+	Seq      uint64
+	Op       string
+	Location string
+	Key      string
+	// Value is the new value for a put, and nil for a del.
+	Value []byte `json:",omitempty"`
+	Time  time.Time
+	// Origin is the ID of the store the change was first made in (see StoreID), if it came from somewhere else by
+	// Replicate or Sync.
+	Origin string `json:",omitempty"`
+}
+
+// EnableChangelog turns on the changelog for this Store. From then on, every Put or Del made with rod inside the
+// Store's Update or UpdateAs is appended to ChangelogBucket within the same transaction. Since it is written in the
+// same transaction, only changes which are committed ever appear. Consumers tail the log with ReadChanges, remembering
+// the Seq of the last change they saw. Call EnableChangelog before using the Store, since it isn't safe to call
+// alongside transactions.
+func (s *Store) EnableChangelog() {
+	s.hooks = append(s.hooks, changelog)
+	s.changelog = true
+}
+
+// changelog is the hook which appends each mutation to the changelog.
+func changelog(tx Tx, st *txState, m *Mutation) error {
+	b, err := createBucket(tx, ChangelogBucket)
+	if err != nil {
+		return err
+	}
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(&Change{
+		Seq:      seq,
+		Op:       m.Op,
+		Location: m.Location,
+		Key:      m.Key,
+		Value:    m.After,
+		Time:     now().UTC(),
+		Origin:   st.origin,
+	})
+	if err != nil {
+		return err
+	}
+
+	// this isn't done with putKey, otherwise we'd log the changes to the changelog
+	return b.Put([]byte(U64Key(seq)), value)
+}
+
+// ReadChanges returns every change after afterSeq, in order. Start with 0 to read from the beginning, then pass the
+// Seq of the last change you got to read only those since.
+func (s *Store) ReadChanges(afterSeq uint64) ([]Change, error) {
+	var changes []Change
+	err := s.View(func(tx *bolt.Tx) error {
+		var err error
+		changes, err = ReadChanges(tx, afterSeq, 0)
+		return err
+	})
+	return changes, err
+}
+
+// ReadChanges returns the changes after afterSeq within the transaction, in order, or at most limit of them if limit
+// isn't 0.
+func ReadChanges(tx Tx, afterSeq uint64, limit int) ([]Change, error) {
+	b, err := getBucket(tx, ChangelogBucket)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	var changes []Change
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(U64Key(afterSeq + 1))); k != nil; k, v = c.Next() {
+		var change Change
+		if err := json.Unmarshal(v, &change); err != nil {
+			return nil, wrapErr("ReadChanges", ChangelogBucket, string(k), err)
+		}
+		changes = append(changes, change)
+		if limit > 0 && len(changes) == limit {
+			break
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,67 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestChangelog(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	store := NewStore(db)
+	store.EnableChangelog()
+
+	err := store.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "config", "name", "rod"))
+		check(PutString(tx, "config", "version", "1"))
+		return nil
+	})
+	check(err)
+
+	err = store.Update(func(tx *bolt.Tx) error {
+		return Del(tx, "config", "version")
+	})
+	check(err)
+
+	t.Run("ReadChanges from the start", func(t *testing.T) {
+		changes, err := store.ReadChanges(0)
+		check(err)
+		if len(changes) != 3 {
+			t.Fatalf("Expected three changes but got %d", len(changes))
+		}
+		if changes[0].Seq != 1 || changes[0].Op != "put" || string(changes[0].Value) != "rod" {
+			t.Fatalf("Unexpected first change: %#v", changes[0])
+		}
+		if changes[2].Op != "del" || changes[2].Key != "version" || changes[2].Value != nil {
+			t.Fatalf("Unexpected last change: %#v", changes[2])
+		}
+	})
+
+	t.Run("ReadChanges after a sequence", func(t *testing.T) {
+		changes, err := store.ReadChanges(2)
+		check(err)
+		if len(changes) != 1 || changes[0].Seq != 3 {
+			t.Fatalf("Expected just the third change: %#v", changes)
+		}
+
+		changes, err = store.ReadChanges(3)
+		check(err)
+		if len(changes) != 0 {
+			t.Fatalf("Expected no more changes: %#v", changes)
+		}
+	})
+
+	t.Run("ReadChanges with a limit", func(t *testing.T) {
+		err := store.View(func(tx *bolt.Tx) error {
+			changes, err := ReadChanges(tx, 0, 2)
+			check(err)
+			if len(changes) != 2 || changes[1].Seq != 2 {
+				t.Fatalf("Expected the first two changes: %#v", changes)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
@@ -0,0 +1,293 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// ErrCompactDstExists is returned by Compact if there is already a file at dstPath.
+	ErrCompactDstExists = errors.New("compaction destination already exists")
+
+	// ErrInvalidCompactBatchSize is returned by Compact and CompactInPlace when CompactBatchSize is given a size which
+	// isn't positive.
+	ErrInvalidCompactBatchSize = errors.New("compact batch size must be positive")
+)
+
+// DefaultCompactBatchSize is how many keys Compact copies in each transaction, unless told otherwise.
+const DefaultCompactBatchSize = 1000
+
+// openBolt opens the compacted file in CompactInPlace, and is only a variable so the tests can make it fail.
+var openBolt = bolt.Open
+
+// CompactProgress tells you how far a compaction has got.
+type CompactProgress struct {
+	// Buckets is the number of buckets finished, out of Total.
+	Buckets int
+	Total   int
+	// Keys and Bytes are the number of keys, and the size of their values, copied so far.
+	Keys  int
+	Bytes int64
+}
+
+// CompactOption configures Compact and CompactInPlace.
+type CompactOption func(*compaction)
+
+// CompactBatchSize sets how many keys are copied in each transaction, which is DefaultCompactBatchSize by default. The
+// size must be positive.
+func CompactBatchSize(n int) CompactOption {
+	return func(c *compaction) {
+		c.batchSize = n
+	}
+}
+
+// OnCompactProgress calls fn after each batch of keys has been copied.
+func OnCompactProgress(fn func(p CompactProgress)) CompactOption {
+	return func(c *compaction) {
+		c.progress = fn
+	}
+}
+
+type compaction struct {
+	batchSize int
+	progress  func(p CompactProgress)
+}
+
+func newCompaction(opts []CompactOption) (*compaction, error) {
+	c := &compaction{batchSize: DefaultCompactBatchSize}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.batchSize <= 0 {
+		return nil, ErrInvalidCompactBatchSize
+	}
+	return c, nil
+}
+
+// Compact copies every bucket and key in the BoltDB at srcPath into a new one at dstPath, which comes out as small as
+// it can be. Bolt never gives space back to the filesystem, so a database which has had a lot deleted from it stays
+// the size it was at its largest until it is compacted. The source is opened read-only, and so must not be open for
+// writing elsewhere; use CompactInPlace for a Store you have open.
+//
+//	err := rod.Compact("rod.db", "rod.compact.db", rod.OnCompactProgress(func(p rod.CompactProgress) {
+//	    log.Printf("%d/%d buckets, %d keys", p.Buckets, p.Total, p.Keys)
+//	}))
+//
+// The keys are copied in batches, each in its own transaction, as with RotateKeys.
+func Compact(srcPath, dstPath string, opts ...CompactOption) error {
+	c, err := newCompaction(opts)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	src, err := bolt.Open(srcPath, info.Mode(), &bolt.Options{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return compact(src, dstPath, info.Mode(), c)
+}
+
+// CompactInPlace compacts the Store's database (see Compact) and then swaps the compacted file in for the original.
+// Transactions wait while it runs, so that nothing written meanwhile can be lost, and carry on with the compacted
+// database once it is done. The new file is written next to the original (with ".compact" on the end) and renamed over
+// it, so the original is untouched should anything fail before then. The original is kept (with ".orig" on the end)
+// until the compacted file has been opened, and if that fails it is put back, so the Store carries on as it was.
+//
+// Since the *bolt.DB is replaced, anything holding on to the one from DB() must fetch it again afterwards.
+func (s *Store) CompactInPlace(opts ...CompactOption) error {
+	if s.readOnly {
+		return ErrReadOnlyStore
+	}
+	c, err := newCompaction(opts)
+	if err != nil {
+		return err
+	}
+
+	s.swapping.Lock()
+	defer s.swapping.Unlock()
+
+	path := s.db.Path()
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".compact"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := compact(s.db, tmp, info.Mode(), c); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	backup := path + ".orig"
+	if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Link(path, backup); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	forgetStats(s.db)
+	if err := s.db.Close(); err != nil {
+		os.Remove(tmp)
+		os.Remove(backup)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		os.Remove(backup)
+		return s.reopen(path, info.Mode(), err)
+	}
+
+	db, err := openBolt(path, info.Mode(), s.options)
+	if err != nil {
+		if renameErr := os.Rename(backup, path); renameErr != nil {
+			return s.reopen(path, info.Mode(), renameErr)
+		}
+		return s.reopen(path, info.Mode(), err)
+	}
+	os.Remove(backup)
+	s.db = db
+	return nil
+}
+
+// reopen opens the file at path again after CompactInPlace has failed with err, so the Store carries on working, and
+// returns err. Should that fail too then there is nothing more to do but return why.
+func (s *Store) reopen(path string, mode os.FileMode, err error) error {
+	db, openErr := openBolt(path, mode, s.options)
+	if openErr != nil {
+		return openErr
+	}
+	s.db = db
+	return err
+}
+
+// compact does the work for Compact, copying everything in src into a new database at dstPath.
+func compact(src *bolt.DB, dstPath string, mode os.FileMode, c *compaction) error {
+	if _, err := os.Stat(dstPath); err == nil {
+		return ErrCompactDstExists
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	dst, err := bolt.Open(dstPath, mode, nil)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	// find every bucket first, as the list of names down to it
+	var buckets [][][]byte
+	err = src.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			buckets = append(buckets, nestedBuckets([][]byte{copyBytes(name)}, b)...)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	progress := CompactProgress{Total: len(buckets)}
+	for _, path := range buckets {
+		var after []byte
+		for done := false; !done; {
+			// read the next batch from src
+			var keys, values [][]byte
+			var sequence uint64
+			err := src.View(func(tx *bolt.Tx) error {
+				b := boltBucketAt(tx, path)
+				if b == nil {
+					done = true
+					return nil
+				}
+				sequence = b.Sequence()
+
+				cur := b.Cursor()
+				k, v := cur.First()
+				if after != nil {
+					k, v = cur.Seek(after)
+					if bytes.Equal(k, after) {
+						k, v = cur.Next()
+					}
+				}
+				for n := 0; n < c.batchSize && k != nil; n++ {
+					// nested buckets are copied separately
+					if v != nil {
+						keys = append(keys, copyBytes(k))
+						values = append(values, copyBytes(v))
+					}
+					after = copyBytes(k)
+					k, v = cur.Next()
+				}
+				if k == nil {
+					done = true
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			// and write it to dst, packing the pages full since the keys go in in order
+			err = dst.Update(func(tx *bolt.Tx) error {
+				b, err := tx.CreateBucketIfNotExists(path[0])
+				if err != nil {
+					return err
+				}
+				for _, name := range path[1:] {
+					b.FillPercent = 1.0
+					if b, err = b.CreateBucketIfNotExists(name); err != nil {
+						return err
+					}
+				}
+				b.FillPercent = 1.0
+				for i, key := range keys {
+					if err := b.Put(key, values[i]); err != nil {
+						return err
+					}
+					progress.Bytes += int64(len(values[i]))
+				}
+				return b.SetSequence(sequence)
+			})
+			if err != nil {
+				return err
+			}
+
+			progress.Keys += len(keys)
+			if done {
+				progress.Buckets++
+			}
+			if c.progress != nil {
+				c.progress(progress)
+			}
+		}
+	}
+
+	return nil
+}
+
+// boltBucketAt returns the bucket at path in tx, or nil if it doesn't exist.
+func boltBucketAt(tx *bolt.Tx, path [][]byte) *bolt.Bucket {
+	b := tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		if b == nil {
+			return nil
+		}
+		b = b.Bucket(name)
+	}
+	return b
+}
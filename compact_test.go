@@ -0,0 +1,186 @@
+package rod
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestCompact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	// fill writes lots of keys and then deletes most of them, leaving the file far bigger than it needs to be
+	fill := func(s *Store) {
+		pad := strings.Repeat("x", 1000)
+		check(s.Update(func(tx *bolt.Tx) error {
+			for i := 0; i < 2000; i++ {
+				check(PutString(tx, "notes", fmt.Sprintf("%04d", i), pad))
+			}
+			check(PutString(tx, "user.chilts", "email", "andychilton@gmail.com"))
+			_, err := createBucket(tx, "empty")
+			check(err)
+			b, err := createBucket(tx, "user")
+			check(err)
+			_, err = b.NextSequence()
+			return err
+		}))
+		check(s.Update(func(tx *bolt.Tx) error {
+			for i := 10; i < 2000; i++ {
+				check(Del(tx, "notes", fmt.Sprintf("%04d", i)))
+			}
+			return nil
+		}))
+	}
+
+	size := func(path string) int64 {
+		info, err := os.Stat(path)
+		check(err)
+		return info.Size()
+	}
+
+	verify := func(s *Store) {
+		check(s.View(func(tx *bolt.Tx) error {
+			n, err := Count(tx, "notes")
+			check(err)
+			if n != 10 {
+				t.Fatalf("Expected 10 notes but got %d", n)
+			}
+			email, err := GetString(tx, "user.chilts", "email")
+			check(err)
+			if email != "andychilton@gmail.com" {
+				t.Fatalf("Unexpected email %q", email)
+			}
+			if tx.Bucket([]byte("empty")) == nil {
+				t.Fatalf("The empty bucket should have been copied")
+			}
+			if seq := tx.Bucket([]byte("user")).Sequence(); seq != 1 {
+				t.Fatalf("The sequence should have been copied, but is %d", seq)
+			}
+			return nil
+		}))
+	}
+
+	t.Run("Compact", func(t *testing.T) {
+		src := filepath.Join(dir, "src.db")
+		s, err := Open(src, 0666, nil)
+		check(err)
+		fill(s)
+		check(s.Close())
+
+		dst := filepath.Join(dir, "dst.db")
+		var last CompactProgress
+		calls := 0
+		check(Compact(src, dst, CompactBatchSize(3), OnCompactProgress(func(p CompactProgress) {
+			calls++
+			last = p
+		})))
+
+		if size(dst) >= size(src)/4 {
+			t.Fatalf("The compacted file should be much smaller: %d vs %d", size(dst), size(src))
+		}
+		if calls < 4 || last.Buckets != 4 || last.Total != 4 || last.Keys != 11 {
+			t.Fatalf("Unexpected progress %#v after %d calls", last, calls)
+		}
+
+		c, err := Open(dst, 0666, nil)
+		check(err)
+		defer c.Close()
+		verify(c)
+
+		if err := Compact(src, dst); err != ErrCompactDstExists {
+			t.Fatalf("Expected ErrCompactDstExists but got %v", err)
+		}
+	})
+
+	t.Run("The batch size must be positive", func(t *testing.T) {
+		path := filepath.Join(dir, "batch.db")
+		s, err := Open(path, 0666, nil)
+		check(err)
+		defer s.Close()
+
+		for _, n := range []int{0, -1} {
+			err := Compact(path, filepath.Join(dir, "batch.compact.db"), CompactBatchSize(n))
+			if err != ErrInvalidCompactBatchSize {
+				t.Fatalf("Compact() expected ErrInvalidCompactBatchSize for %d but got %v", n, err)
+			}
+			if err := s.CompactInPlace(CompactBatchSize(n)); err != ErrInvalidCompactBatchSize {
+				t.Fatalf("CompactInPlace() expected ErrInvalidCompactBatchSize for %d but got %v", n, err)
+			}
+		}
+	})
+
+	t.Run("CompactInPlace", func(t *testing.T) {
+		path := filepath.Join(dir, "inplace.db")
+		s, err := Open(path, 0666, nil)
+		check(err)
+		defer s.Close()
+		fill(s)
+		before := size(path)
+
+		// a write made while compacting waits for it, rather than being lost
+		done := make(chan error)
+		go func() {
+			done <- s.Update(func(tx *bolt.Tx) error {
+				return PutString(tx, "late", "key", "value")
+			})
+		}()
+		check(s.CompactInPlace())
+		check(<-done)
+
+		if after := size(path); after >= before/4 {
+			t.Fatalf("The file should be much smaller: %d vs %d", after, before)
+		}
+		if _, err := os.Stat(path + ".compact"); !os.IsNotExist(err) {
+			t.Fatalf("The temporary file should be gone")
+		}
+		verify(s)
+		check(s.View(func(tx *bolt.Tx) error {
+			value, err := GetString(tx, "late", "key")
+			check(err)
+			if value != "value" {
+				t.Fatalf("The late write should be there")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("CompactInPlace puts the original back if the compacted file won't open", func(t *testing.T) {
+		path := filepath.Join(dir, "failed.db")
+		s, err := Open(path, 0666, nil)
+		check(err)
+		defer s.Close()
+		fill(s)
+		before := size(path)
+
+		errBoom := errors.New("boom")
+		defer func() { openBolt = bolt.Open }()
+		openBolt = func(path string, mode os.FileMode, options *bolt.Options) (*bolt.DB, error) {
+			openBolt = bolt.Open
+			return nil, errBoom
+		}
+		if err := s.CompactInPlace(); err != errBoom {
+			t.Fatalf("Expected the error opening the compacted file but got %v", err)
+		}
+
+		if after := size(path); after != before {
+			t.Fatalf("The original file should be back: %d vs %d", after, before)
+		}
+		for _, suffix := range []string{".compact", ".orig"} {
+			if _, err := os.Stat(path + suffix); !os.IsNotExist(err) {
+				t.Fatalf("The %s file should be gone", suffix)
+			}
+		}
+		verify(s)
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "late", "key", "value")
+		}))
+	})
+}
@@ -0,0 +1,136 @@
+package rod
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrConfigNotFound is returned (or panicked with, by MustGet) when a Config's document hasn't been stored.
+var ErrConfigNotFound = errors.New("config not found")
+
+// Config keeps a copy in memory of the JSON document at location/key, decoded into a T. It watches the document, and
+// whenever it is changed through the Store, reads it in and swaps it for the old copy in one go, so Get never sees a
+// half-updated config. Fields missing from the document are left as they are in the defaults.
+//
+//	type Settings struct {
+//	    Workers int
+//	    Banner  string
+//	}
+//	config, err := rod.NewConfig(store, "config", "settings", Settings{Workers: 4})
+//	workers := config.Get().Workers
+//
+// If T implements Validator then a document which doesn't validate is never swapped in, and the last good copy is
+// kept (with the error in Err).
+type Config[T any] struct {
+	store    *Store
+	location string
+	key      string
+	defaults []byte
+
+	current   atomic.Pointer[loadedConfig[T]]
+	mu        sync.Mutex
+	err       error
+	listeners []func(before, after T)
+}
+
+// loadedConfig is one copy of the config, and whether it came from the document or just the defaults.
+type loadedConfig[T any] struct {
+	value T
+	found bool
+}
+
+// NewConfig returns the Config for the document at location/key, having read it in. Since it watches the location,
+// call NewConfig before using the Store, as with Watch.
+func NewConfig[T any](s *Store, location, key string, defaults T) (*Config[T], error) {
+	raw, err := json.Marshal(defaults)
+	if err != nil {
+		return nil, wrapErr("NewConfig", location, key, err)
+	}
+
+	c := &Config[T]{store: s, location: location, key: key, defaults: raw}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	s.Watch(location, func(m Mutation) {
+		if m.Location == c.location && m.Key == c.key {
+			c.Reload()
+		}
+	})
+	return c, nil
+}
+
+// Get returns the current config, which is the defaults if the document hasn't been stored.
+func (c *Config[T]) Get() T {
+	return c.current.Load().value
+}
+
+// MustGet returns the current config, but panics with ErrConfigNotFound if the document hasn't been stored, for
+// services which can't run on the defaults alone.
+func (c *Config[T]) MustGet() T {
+	loaded := c.current.Load()
+	if !loaded.found {
+		panic(wrapErr("MustGet", c.location, c.key, ErrConfigNotFound))
+	}
+	return loaded.value
+}
+
+// Set stores v as the document, which is then swapped in once the transaction has committed.
+func (c *Config[T]) Set(tx Tx, v T) error {
+	return wrapErr("Set", c.location, c.key, PutJson(tx, c.location, c.key, &v))
+}
+
+// Err returns the error from the last time the document was read in, or nil if it was fine.
+func (c *Config[T]) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// OnChange calls fn whenever a new copy of the config is swapped in, with the copies before and after. Like Watch, fn is
+// called on the goroutine whose Update made the change.
+func (c *Config[T]) OnChange(fn func(before, after T)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Reload reads the document in again, such as after it has been changed outside the Store. If it can't be read (or
+// doesn't validate) then the current copy is kept and the error returned.
+func (c *Config[T]) Reload() error {
+	var v T
+	var found bool
+	err := c.store.View(func(tx *bolt.Tx) error {
+		if err := json.Unmarshal(c.defaults, &v); err != nil {
+			return err
+		}
+		var err error
+		found, err = GetJsonFound(tx, c.location, c.key, &v)
+		if err != nil {
+			return err
+		}
+		if val, ok := interface{}(&v).(Validator); ok && found {
+			return val.Validate()
+		}
+		return nil
+	})
+
+	c.mu.Lock()
+	c.err = wrapErr("Reload", c.location, c.key, err)
+	listeners := c.listeners
+	c.mu.Unlock()
+	if err != nil {
+		return c.Err()
+	}
+
+	old := c.current.Swap(&loadedConfig[T]{value: v, found: found})
+	if old != nil {
+		for _, fn := range listeners {
+			fn(old.value, v)
+		}
+	}
+	return nil
+}
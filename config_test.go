@@ -0,0 +1,90 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type Settings struct {
+	Workers int
+	Banner  string
+	Hosts   []string
+}
+
+func (s *Settings) Validate() error {
+	if s.Workers < 0 {
+		return errors.New("workers can't be negative")
+	}
+	return nil
+}
+
+func TestConfig(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	s := NewStore(db)
+
+	defaults := Settings{Workers: 4, Banner: "hello", Hosts: []string{"a", "b"}}
+	config, err := NewConfig(s, "config", "settings", defaults)
+	check(err)
+
+	var changes int
+	config.OnChange(func(before, after Settings) {
+		changes++
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		if got := config.Get(); got.Workers != 4 || got.Banner != "hello" {
+			t.Fatalf("The defaults should be used without a document: %#v", got)
+		}
+
+		defer func() {
+			if err, ok := recover().(error); !ok || !errors.Is(err, ErrConfigNotFound) {
+				t.Fatalf("MustGet should panic with ErrConfigNotFound, not %v", err)
+			}
+		}()
+		config.MustGet()
+	})
+
+	t.Run("Hot reload", func(t *testing.T) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "settings", `{"Workers":8,"Hosts":["c"]}`)
+		}))
+
+		got := config.MustGet()
+		if got.Workers != 8 || got.Banner != "hello" || len(got.Hosts) != 1 || got.Hosts[0] != "c" {
+			t.Fatalf("The document should have been swapped in over the defaults: %#v", got)
+		}
+		if changes != 1 {
+			t.Fatalf("OnChange should have been called once, not %d times", changes)
+		}
+		if defaults.Hosts[0] != "a" {
+			t.Fatal("The defaults should not have been changed")
+		}
+
+		// other keys in the location don't reload it
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "other", "{}")
+		}))
+		if changes != 1 {
+			t.Fatal("A change to another key should not reload the config")
+		}
+	})
+
+	t.Run("Bad documents are not swapped in", func(t *testing.T) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "settings", `{"Workers":-1}`)
+		}))
+		if config.Get().Workers != 8 || config.Err() == nil {
+			t.Fatalf("The last good config should be kept, with the error in Err: %#v", config.Get())
+		}
+
+		check(s.Update(func(tx *bolt.Tx) error {
+			return config.Set(tx, Settings{Workers: 2})
+		}))
+		if config.Get().Workers != 2 || config.Err() != nil {
+			t.Fatalf("Set should swap in the new config: %#v %v", config.Get(), config.Err())
+		}
+	})
+}
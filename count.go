@@ -0,0 +1,66 @@
+package rod
+
+import (
+	"bytes"
+)
+
+// Count returns the number of keys in the bucket at location, not including any nested buckets. If the bucket doesn't
+// exist then 0 is returned.
+//
+// In a read-only Bolt transaction where the bucket has no nested buckets the count comes straight from Bolt's page
+// stats, otherwise the bucket is walked with a cursor. Either way, no keys or values are copied.
+func Count(tx Tx, location string) (int, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return 0, wrapErr("Count", location, "", err)
+	}
+	if b == nil {
+		return 0, nil
+	}
+
+	// the stats include nested buckets (and their keys), so only trust them if there aren't any, and they don't see
+	// anything uncommitted, so only trust them outside of a writable tx
+	if bb, ok := b.(boltBucket); ok && !tx.Writable() {
+		stats := bb.b.Stats()
+		if stats.BucketN == 1 {
+			return stats.KeyN, nil
+		}
+	}
+
+	count := 0
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if !isBucket(b, k, v) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// CountPrefix returns the number of keys in the bucket at location which start with prefix, not including any nested
+// buckets. An empty prefix is the same as calling Count.
+func CountPrefix(tx Tx, location, prefix string) (int, error) {
+	if prefix == "" {
+		return Count(tx, location)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return 0, wrapErr("CountPrefix", location, "", err)
+	}
+	if b == nil {
+		return 0, nil
+	}
+
+	p := []byte(prefix)
+	count := 0
+	c := b.Cursor()
+	for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+		if !isBucket(b, k, v) {
+			count++
+		}
+	}
+
+	return count, nil
+}
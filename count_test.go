@@ -0,0 +1,134 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestCount(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "flat", "a-1", "one"))
+		check(PutString(tx, "flat", "a-2", "two"))
+		check(PutString(tx, "flat", "b-1", "three"))
+
+		check(PutString(tx, "nested", "a-1", "one"))
+		check(PutString(tx, "nested", "b-1", "two"))
+		check(PutString(tx, "nested.child", "a-1", "three"))
+		check(PutString(tx, "nested.child", "a-2", "four"))
+		return nil
+	})
+	check(err)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		tests := []struct {
+			location string
+			prefix   string
+			want     int
+		}{
+			{"flat", "", 3},
+			{"flat", "a-", 2},
+			{"flat", "b-", 1},
+			{"flat", "c-", 0},
+			{"nested", "", 2},
+			{"nested", "a-", 1},
+			{"nested.child", "", 2},
+			{"does-not-exist", "", 0},
+			{"does-not-exist", "a-", 0},
+		}
+
+		for _, test := range tests {
+			n, err := CountPrefix(tx, test.location, test.prefix)
+			check(err)
+			if n != test.want {
+				t.Fatalf("CountPrefix(%q, %q) should have been %d but was %d", test.location, test.prefix, test.want, n)
+			}
+		}
+
+		n, err := Count(tx, "nested")
+		check(err)
+		if n != 2 {
+			t.Fatalf("Count() should not include nested buckets, expected 2 but got %d", n)
+		}
+
+		if _, err := Count(tx, ""); !errors.Is(err, ErrLocationMustHaveAtLeastOneBucket) {
+			t.Fatalf("Expected ErrLocationMustHaveAtLeastOneBucket but got %v", err)
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestCountInWritableTx(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "uncommitted", "a", "one"))
+		check(PutString(tx, "uncommitted", "b", "two"))
+
+		n, err := Count(tx, "uncommitted")
+		check(err)
+		if n != 2 {
+			t.Fatalf("Count() should see uncommitted keys, expected 2 but got %d", n)
+		}
+
+		// a nil value put in this transaction comes back from the cursor as nil, just like a nested bucket
+		check(Put(tx, "uncommitted", "empty", nil))
+		check(PutString(tx, "uncommitted.child", "a", "three"))
+		n, err = Count(tx, "uncommitted")
+		check(err)
+		if n != 3 {
+			t.Fatalf("Count() should include the empty value but not the bucket, expected 3 but got %d", n)
+		}
+		n, err = CountPrefix(tx, "uncommitted", "e")
+		check(err)
+		if n != 1 {
+			t.Fatalf("CountPrefix() should include the empty value, expected 1 but got %d", n)
+		}
+
+		var keys []string
+		check(Each(tx, "uncommitted", func(key string, value []byte) error {
+			keys = append(keys, key)
+			return nil
+		}))
+		if len(keys) != 3 || keys[2] != "empty" {
+			t.Fatalf("Each() should include the empty value but not the bucket: %v", keys)
+		}
+		if key, _, err := Last(tx, "uncommitted"); err != nil || key != "empty" {
+			t.Fatalf("Last() should be the empty value but was %q (%v)", key, err)
+		}
+
+		values, err := AllValues(tx, "uncommitted")
+		check(err)
+		if len(values) != 3 {
+			t.Fatalf("AllValues() should include the empty value but not the bucket: %q", values)
+		}
+		many, err := GetMany(tx, "uncommitted", []string{"empty", "child"})
+		check(err)
+		if v, ok := many["empty"]; len(many) != 1 || !ok || v == nil {
+			t.Fatalf("GetMany() should include the empty value but not the bucket: %q", many)
+		}
+		h, err := SizeHistogram(tx, "uncommitted")
+		check(err)
+		if h.Count != 3 {
+			t.Fatalf("SizeHistogram() should count the empty value, expected 3 but got %d", h.Count)
+		}
+		if v, err := Pop(tx, "uncommitted", "empty"); err != nil || v == nil {
+			t.Fatalf("Pop() should return the empty value but got %q (%v)", v, err)
+		}
+		check(Put(tx, "uncommitted", "empty", nil))
+		deleted, err := DelMany(tx, "uncommitted", []string{"empty", "child"})
+		check(err)
+		if deleted != 1 {
+			t.Fatalf("DelMany() should delete the empty value but not the bucket, expected 1 but got %d", deleted)
+		}
+		return nil
+	})
+	check(err)
+}
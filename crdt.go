@@ -0,0 +1,265 @@
+package rod
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Counter is a conflict-free counter, which can be changed in several synced Stores at once without losing any of the
+// changes. Each Store keeps its own count up and down, and the value is the sum of them all.
+type Counter struct {
+	Up   map[string]uint64 `json:",omitempty"`
+	Down map[string]uint64 `json:",omitempty"`
+}
+
+// Value returns the current value of the counter.
+func (c Counter) Value() int64 {
+	var value int64
+	for _, n := range c.Up {
+		value += int64(n)
+	}
+	for _, n := range c.Down {
+		value -= int64(n)
+	}
+	return value
+}
+
+// Merge returns the counter containing the changes made to both c and other.
+func (c Counter) Merge(other Counter) Counter {
+	return Counter{Up: mergeMax(c.Up, other.Up), Down: mergeMax(c.Down, other.Down)}
+}
+
+// IncrCounter adds delta (which may be negative) to the Counter at location/key, creating it if needed. The change is
+// recorded against the ID of the Store the transaction is in.
+func IncrCounter(tx Tx, location, key string, delta int64) error {
+	id, err := replicaID(tx)
+	if err != nil {
+		return wrapErr("IncrCounter", location, key, err)
+	}
+
+	var c Counter
+	if err := GetJson(tx, location, key, &c); err != nil {
+		return wrapErr("IncrCounter", location, key, err)
+	}
+	if delta >= 0 {
+		c.Up = incr(c.Up, id, uint64(delta))
+	} else {
+		c.Down = incr(c.Down, id, uint64(-delta))
+	}
+	return wrapErr("IncrCounter", location, key, PutJson(tx, location, key, c))
+}
+
+// GetCounter returns the value of the Counter at location/key, or 0 if there isn't one.
+func GetCounter(tx Tx, location, key string) (int64, error) {
+	var c Counter
+	if err := GetJson(tx, location, key, &c); err != nil {
+		return 0, wrapErr("GetCounter", location, key, err)
+	}
+	return c.Value(), nil
+}
+
+// ORSet is a conflict-free set of strings (an observed-remove set), which can be changed in several synced Stores at
+// once. Each add is tagged uniquely and a remove only removes the adds it has seen, so an element added in one Store
+// while being removed in another stays in the set.
+type ORSet struct {
+	// Adds holds the tags of every add of each element.
+	Adds map[string][]string `json:",omitempty"`
+	// Removed holds the tags of the adds which have since been removed.
+	Removed map[string]bool `json:",omitempty"`
+	// Clock counts the adds made by each Store, to make the tags.
+	Clock map[string]uint64 `json:",omitempty"`
+}
+
+// Contains tells you whether element is in the set.
+func (s ORSet) Contains(element string) bool {
+	for _, tag := range s.Adds[element] {
+		if !s.Removed[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// Members returns the elements in the set, in order.
+func (s ORSet) Members() []string {
+	var members []string
+	for element := range s.Adds {
+		if s.Contains(element) {
+			members = append(members, element)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// Merge returns the set containing the changes made to both s and other.
+func (s ORSet) Merge(other ORSet) ORSet {
+	merged := ORSet{
+		Adds:    make(map[string][]string),
+		Removed: make(map[string]bool),
+		Clock:   mergeMax(s.Clock, other.Clock),
+	}
+	for _, set := range []ORSet{s, other} {
+		for element, tags := range set.Adds {
+			for _, tag := range tags {
+				if !containsString(merged.Adds[element], tag) {
+					merged.Adds[element] = append(merged.Adds[element], tag)
+				}
+			}
+		}
+		for tag := range set.Removed {
+			merged.Removed[tag] = true
+		}
+	}
+	for element := range merged.Adds {
+		sort.Strings(merged.Adds[element])
+	}
+	return merged
+}
+
+// SetAdd adds element to the ORSet at location/key, creating it if needed.
+func SetAdd(tx Tx, location, key, element string) error {
+	id, err := replicaID(tx)
+	if err != nil {
+		return wrapErr("SetAdd", location, key, err)
+	}
+
+	var s ORSet
+	if err := GetJson(tx, location, key, &s); err != nil {
+		return wrapErr("SetAdd", location, key, err)
+	}
+	s.Clock = incr(s.Clock, id, 1)
+	if s.Adds == nil {
+		s.Adds = make(map[string][]string)
+	}
+	s.Adds[element] = append(s.Adds[element], id+":"+strconv.FormatUint(s.Clock[id], 10))
+	return wrapErr("SetAdd", location, key, PutJson(tx, location, key, s))
+}
+
+// SetRemove removes element from the ORSet at location/key. Removing an element which isn't there does nothing.
+func SetRemove(tx Tx, location, key, element string) error {
+	var s ORSet
+	if err := GetJson(tx, location, key, &s); err != nil {
+		return wrapErr("SetRemove", location, key, err)
+	}
+	if !s.Contains(element) {
+		return nil
+	}
+	if s.Removed == nil {
+		s.Removed = make(map[string]bool)
+	}
+	for _, tag := range s.Adds[element] {
+		s.Removed[tag] = true
+	}
+	return wrapErr("SetRemove", location, key, PutJson(tx, location, key, s))
+}
+
+// SetMembers returns the elements of the ORSet at location/key in order, or nil if there isn't one.
+func SetMembers(tx Tx, location, key string) ([]string, error) {
+	var s ORSet
+	if err := GetJson(tx, location, key, &s); err != nil {
+		return nil, wrapErr("SetMembers", location, key, err)
+	}
+	return s.Members(), nil
+}
+
+// MergeCounters is a ConflictResolver for locations holding Counters, keeping the changes made in both Stores.
+//
+//	resolver := rod.ResolveByLocation(map[string]rod.ConflictResolver{
+//	    "likes": rod.MergeCounters,
+//	    "tags":  rod.MergeSets,
+//	}, rod.LastWriteWins)
+func MergeCounters(c Conflict) (Change, error) {
+	return mergeConflict(c, func(a, b []byte) (interface{}, error) {
+		var ac, bc Counter
+		if err := unmarshalIfAny(a, &ac); err != nil {
+			return nil, err
+		}
+		if err := unmarshalIfAny(b, &bc); err != nil {
+			return nil, err
+		}
+		return ac.Merge(bc), nil
+	})
+}
+
+// MergeSets is a ConflictResolver for locations holding ORSets, keeping the changes made in both Stores.
+func MergeSets(c Conflict) (Change, error) {
+	return mergeConflict(c, func(a, b []byte) (interface{}, error) {
+		var as, bs ORSet
+		if err := unmarshalIfAny(a, &as); err != nil {
+			return nil, err
+		}
+		if err := unmarshalIfAny(b, &bs); err != nil {
+			return nil, err
+		}
+		return as.Merge(bs), nil
+	})
+}
+
+// mergeConflict resolves c to the merge of both values. If either side was deleted then the other side is kept, since
+// nothing can be merged with it.
+func mergeConflict(c Conflict, merge func(a, b []byte) (interface{}, error)) (Change, error) {
+	if c.A.Op == "del" {
+		return c.B, nil
+	}
+	if c.B.Op == "del" {
+		return c.A, nil
+	}
+
+	merged, err := merge(c.A.Value, c.B.Value)
+	if err != nil {
+		return Change{}, err
+	}
+	value, err := json.Marshal(merged)
+	if err != nil {
+		return Change{}, err
+	}
+
+	resolved := c.A
+	if c.B.Time.After(c.A.Time) {
+		resolved.Time = c.B.Time
+	}
+	resolved.Value = value
+	return resolved, nil
+}
+
+func unmarshalIfAny(raw []byte, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func mergeMax(a, b map[string]uint64) map[string]uint64 {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	merged := make(map[string]uint64, len(a))
+	for id, n := range a {
+		merged[id] = n
+	}
+	for id, n := range b {
+		if n > merged[id] {
+			merged[id] = n
+		}
+	}
+	return merged
+}
+
+func incr(m map[string]uint64, id string, n uint64) map[string]uint64 {
+	if m == nil {
+		m = make(map[string]uint64)
+	}
+	m[id] += n
+	return m
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package rod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestCrdt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	laptop, err := Open(filepath.Join(dir, "laptop.db"), 0666, nil)
+	check(err)
+	defer laptop.Close()
+	laptop.EnableChangelog()
+
+	server, err := Open(filepath.Join(dir, "server.db"), 0666, nil)
+	check(err)
+	defer server.Close()
+	server.EnableChangelog()
+
+	resolver := ResolveByLocation(map[string]ConflictResolver{
+		"likes": MergeCounters,
+		"tags":  MergeSets,
+	}, LastWriteWins)
+
+	counter := func(s *Store) int64 {
+		var value int64
+		check(s.View(func(tx *bolt.Tx) error {
+			var err error
+			value, err = GetCounter(tx, "likes", "post")
+			return err
+		}))
+		return value
+	}
+
+	members := func(s *Store) []string {
+		var value []string
+		check(s.View(func(tx *bolt.Tx) error {
+			var err error
+			value, err = SetMembers(tx, "tags", "post")
+			return err
+		}))
+		return value
+	}
+
+	t.Run("Counter", func(t *testing.T) {
+		if got := counter(laptop); got != 0 {
+			t.Fatalf("A missing counter should be 0 but is %d", got)
+		}
+
+		check(laptop.Update(func(tx *bolt.Tx) error {
+			check(IncrCounter(tx, "likes", "post", 3))
+			return IncrCounter(tx, "likes", "post", -1)
+		}))
+		if got := counter(laptop); got != 2 {
+			t.Fatalf("Counter should be 2 but is %d", got)
+		}
+		check(Sync(laptop, server, resolver))
+
+		// both sides change it at once
+		check(laptop.Update(func(tx *bolt.Tx) error {
+			return IncrCounter(tx, "likes", "post", 5)
+		}))
+		check(server.Update(func(tx *bolt.Tx) error {
+			return IncrCounter(tx, "likes", "post", -2)
+		}))
+		check(Sync(laptop, server, resolver))
+
+		for _, s := range []*Store{laptop, server} {
+			if got := counter(s); got != 5 {
+				t.Fatalf("Counter should be 5 on both sides but is %d", got)
+			}
+		}
+	})
+
+	t.Run("ORSet", func(t *testing.T) {
+		check(laptop.Update(func(tx *bolt.Tx) error {
+			check(SetAdd(tx, "tags", "post", "go"))
+			check(SetAdd(tx, "tags", "post", "bolt"))
+			check(SetAdd(tx, "tags", "post", "draft"))
+			return SetRemove(tx, "tags", "post", "missing")
+		}))
+		check(Sync(laptop, server, resolver))
+
+		// the laptop removes "go" while the server adds it again, and both remove "draft"
+		check(laptop.Update(func(tx *bolt.Tx) error {
+			check(SetRemove(tx, "tags", "post", "go"))
+			check(SetRemove(tx, "tags", "post", "draft"))
+			return SetAdd(tx, "tags", "post", "rod")
+		}))
+		check(server.Update(func(tx *bolt.Tx) error {
+			check(SetAdd(tx, "tags", "post", "go"))
+			check(SetRemove(tx, "tags", "post", "draft"))
+			return SetRemove(tx, "tags", "post", "bolt")
+		}))
+		check(Sync(server, laptop, resolver))
+
+		want := []string{"go", "rod"}
+		for _, s := range []*Store{laptop, server} {
+			if got := members(s); !reflect.DeepEqual(got, want) {
+				t.Fatalf("Expected %v but got %v", want, got)
+			}
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		a := Counter{Up: map[string]uint64{"a": 2, "b": 1}}
+		b := Counter{Up: map[string]uint64{"b": 3}, Down: map[string]uint64{"a": 1}}
+		if got := a.Merge(b).Value(); got != 4 {
+			t.Fatalf("Merged counter should be 4 but is %d", got)
+		}
+
+		merged, err := MergeSets(Conflict{A: Change{Op: "del"}, B: Change{Op: "put", Value: []byte("{}")}})
+		check(err)
+		if merged.Op != "put" {
+			t.Fatalf("Merging with a delete should keep the other side")
+		}
+	})
+}
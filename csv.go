@@ -0,0 +1,231 @@
+package rod
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrCSVNoKeyColumn is returned by ImportCSV if the header doesn't have the mapping's key column.
+var ErrCSVNoKeyColumn = errors.New("CSV has no key column")
+
+// CSVType says how ImportCSV turns a cell into a JSON value.
+type CSVType int
+
+const (
+	// CSVString puts the cell in as a string, as it is. It is the default.
+	CSVString CSVType = iota
+
+	// CSVInt, CSVFloat and CSVBool parse the cell as a number or a boolean, with an empty cell being null. As well as
+	// what strconv.ParseBool understands, a boolean may be yes or no (or y or n).
+	CSVInt
+	CSVFloat
+	CSVBool
+
+	// CSVJson puts the cell in as the JSON it holds, with an empty cell being null.
+	CSVJson
+)
+
+// CSVMapping says how ImportCSV turns each row into a JSON document. Columns are named by their header.
+type CSVMapping struct {
+	// Key is the column holding each row's key.
+	Key string
+
+	// Fields maps a column to the field it goes in, which may be nested with dots (as in "address.city"). If Fields is
+	// nil then every column goes in under its header, and otherwise only those in Fields do.
+	Fields map[string]string
+
+	// Types says what sort of value is in a column, with CSVString for any not in Types.
+	Types map[string]CSVType
+
+	// Comma is the field delimiter, which is ',' unless set.
+	Comma rune
+
+	// OnError is called with any row which can't be imported. If it returns nil then the row is skipped and the import
+	// carries on, and otherwise ImportCSV stops with the error it returns. If OnError isn't set then the first bad row
+	// stops the import.
+	OnError func(err *CSVRowError) error
+}
+
+// CSVRowError is a row which ImportCSV couldn't import.
+type CSVRowError struct {
+	// Row is the row's line in the file, counting the header as line 1.
+	Row int
+
+	// Column is the column which was wrong, if it was just the one.
+	Column string
+
+	Err error
+}
+
+func (e *CSVRowError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("row %d, column %s: %v", e.Row, e.Column, e.Err)
+	}
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportCSV reads a CSV file with a header row from r, and puts each row at location as a JSON document, keyed by the
+// value in its mapping.Key column. The mapping also says which field each column goes in and what type it is, so
+// that
+//
+//	id,name,logins,city
+//	chilts,Andrew,3,Wellington
+//
+// with this mapping
+//
+//	n, err := rod.ImportCSV(tx, "users", f, rod.CSVMapping{
+//	    Key:    "id",
+//	    Fields: map[string]string{"name": "Name", "logins": "Logins", "city": "Address.City"},
+//	    Types:  map[string]rod.CSVType{"logins": rod.CSVInt},
+//	})
+//
+// puts {"Address":{"City":"Wellington"},"Logins":3,"Name":"Andrew"} at "users"/"chilts". The key column only goes in
+// the document if it is in Fields (or Fields is nil). Each row is put with PutJson, replacing any document already at
+// its key, and ImportCSV returns how many were put. A row which can't be read, has an empty key, or has a cell which
+// doesn't parse as its type is a *CSVRowError, which is handled as mapping.OnError says.
+func ImportCSV(tx Tx, location string, r io.Reader, mapping CSVMapping) (int, error) {
+	cr := csv.NewReader(r)
+	if mapping.Comma != 0 {
+		cr.Comma = mapping.Comma
+	}
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, wrapErr("ImportCSV", location, "", err)
+	}
+	header = append([]string(nil), header...)
+
+	keyColumn := -1
+	for i, name := range header {
+		if name == mapping.Key {
+			keyColumn = i
+			break
+		}
+	}
+	if keyColumn < 0 {
+		return 0, wrapErr("ImportCSV", location, "", ErrCSVNoKeyColumn)
+	}
+
+	rowError := func(err *CSVRowError) error {
+		if mapping.OnError == nil {
+			return err
+		}
+		return mapping.OnError(err)
+	}
+
+	n := 0
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err := rowError(&CSVRowError{Row: row, Err: err}); err != nil {
+				return n, wrapErr("ImportCSV", location, "", err)
+			}
+			continue
+		}
+
+		key := record[keyColumn]
+		if key == "" {
+			if err := rowError(&CSVRowError{Row: row, Column: mapping.Key, Err: ErrKeyNotProvided}); err != nil {
+				return n, wrapErr("ImportCSV", location, "", err)
+			}
+			continue
+		}
+
+		doc, rerr := csvDocument(header, record, &mapping)
+		if rerr != nil {
+			rerr.Row = row
+			if err := rowError(rerr); err != nil {
+				return n, wrapErr("ImportCSV", location, key, err)
+			}
+			continue
+		}
+
+		if err := PutJson(tx, location, key, doc); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// csvDocument turns one row into a document, as mapping says.
+func csvDocument(header, record []string, mapping *CSVMapping) (map[string]interface{}, *CSVRowError) {
+	doc := map[string]interface{}{}
+	for i, column := range header {
+		field := column
+		if mapping.Fields != nil {
+			var ok bool
+			if field, ok = mapping.Fields[column]; !ok {
+				continue
+			}
+		}
+
+		value, err := csvValue(record[i], mapping.Types[column])
+		if err != nil {
+			return nil, &CSVRowError{Column: column, Err: err}
+		}
+
+		// go down to the object the field is in, making any which aren't there yet
+		obj := doc
+		names := strings.Split(field, ".")
+		for _, name := range names[:len(names)-1] {
+			next, ok := obj[name].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				obj[name] = next
+			}
+			obj = next
+		}
+		obj[names[len(names)-1]] = value
+	}
+	return doc, nil
+}
+
+// csvValue coerces a single cell into the JSON value for kind.
+func csvValue(cell string, kind CSVType) (interface{}, error) {
+	if kind == CSVString {
+		return cell, nil
+	}
+
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		return nil, nil
+	}
+
+	switch kind {
+	case CSVInt:
+		return strconv.ParseInt(cell, 10, 64)
+	case CSVFloat:
+		return strconv.ParseFloat(cell, 64)
+	case CSVBool:
+		switch strings.ToLower(cell) {
+		case "yes", "y":
+			return true, nil
+		case "no", "n":
+			return false, nil
+		}
+		return strconv.ParseBool(cell)
+	case CSVJson:
+		if !json.Valid([]byte(cell)) {
+			return nil, errors.New("invalid JSON")
+		}
+		return json.RawMessage(cell), nil
+	}
+	return nil, fmt.Errorf("unknown CSVType %d", kind)
+}
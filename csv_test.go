@@ -0,0 +1,90 @@
+package rod
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestImportCSV(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	data := `id,name,logins,admin,city,tags
+chilts,Andrew,3,yes,Wellington,"[""a"",""b""]"
+bob,Bob,,n,,[]
+`
+
+	t.Run("ImportCSV", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			n, err := ImportCSV(tx, "users", strings.NewReader(data), CSVMapping{
+				Key:    "id",
+				Fields: map[string]string{"name": "Name", "logins": "Logins", "admin": "Admin", "city": "Address.City", "tags": "Tags"},
+				Types:  map[string]CSVType{"logins": CSVInt, "admin": CSVBool, "tags": CSVJson},
+			})
+			check(err)
+			if n != 2 {
+				t.Fatalf("Expected 2 rows to be imported, not %d", n)
+			}
+
+			for key, want := range map[string]string{
+				"chilts": `{"Address":{"City":"Wellington"},"Admin":true,"Logins":3,"Name":"Andrew","Tags":["a","b"]}`,
+				"bob":    `{"Address":{"City":""},"Admin":false,"Logins":null,"Name":"Bob","Tags":[]}`,
+			} {
+				got, err := GetString(tx, "users", key)
+				check(err)
+				if got != want {
+					t.Fatalf("Unexpected document for %s:\n%s\n%s", key, got, want)
+				}
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ImportCSV - every column", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			_, err := ImportCSV(tx, "plain", strings.NewReader("id;n\nx;1\n"), CSVMapping{Key: "id", Comma: ';'})
+			check(err)
+			got, err := GetString(tx, "plain", "x")
+			check(err)
+			if got != `{"id":"x","n":"1"}` {
+				t.Fatalf("Unexpected document %s", got)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ImportCSV - bad rows", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			bad := "id,logins\na,1\nb,lots\n,2\nc,4,extra\nd,5\n"
+			mapping := CSVMapping{Key: "id", Types: map[string]CSVType{"logins": CSVInt}}
+
+			// by default the first bad row stops the import
+			n, err := ImportCSV(tx, "bad", strings.NewReader(bad), mapping)
+			var rowErr *CSVRowError
+			if !errors.As(err, &rowErr) || rowErr.Row != 3 || rowErr.Column != "logins" || n != 1 {
+				t.Fatalf("Expected an error for row 3, not %v (after %d rows)", err, n)
+			}
+
+			// and otherwise each goes to OnError
+			var rows []int
+			mapping.OnError = func(err *CSVRowError) error {
+				rows = append(rows, err.Row)
+				return nil
+			}
+			n, err = ImportCSV(tx, "bad", strings.NewReader(bad), mapping)
+			check(err)
+			if n != 2 || len(rows) != 3 || rows[0] != 3 || rows[1] != 4 || rows[2] != 5 {
+				t.Fatalf("Unexpected import of %d rows, with bad rows %v", n, rows)
+			}
+
+			_, err = ImportCSV(tx, "bad", strings.NewReader("name\nx\n"), mapping)
+			if !errors.Is(err, ErrCSVNoKeyColumn) {
+				t.Fatalf("Expected ErrCSVNoKeyColumn, not %v", err)
+			}
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,119 @@
+package rod
+
+// First returns the first key (and its value) in the bucket at location, skipping any nested buckets. If the bucket
+// doesn't exist or is empty then an empty key and a nil value are returned. As with Get, the value is only valid for
+// the life of the transaction.
+func First(tx Tx, location string) (string, []byte, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return "", nil, wrapErr("First", location, "", err)
+	}
+	if b == nil {
+		return "", nil, nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if !isBucket(b, k, v) {
+			return string(k), v, nil
+		}
+	}
+
+	return "", nil, nil
+}
+
+// Last returns the last key (and its value) in the bucket at location, skipping any nested buckets. This is useful
+// for getting the latest entry from a bucket with time-ordered keys. Everything that applies to First applies here
+// too.
+func Last(tx Tx, location string) (string, []byte, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return "", nil, wrapErr("Last", location, "", err)
+	}
+	if b == nil {
+		return "", nil, nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.Last(); k != nil; k, v = c.Prev() {
+		if !isBucket(b, k, v) {
+			return string(k), v, nil
+		}
+	}
+
+	return "", nil, nil
+}
+
+// FirstJson calls First and decodes the value into v using json.Unmarshal(), returning the key. If there is nothing
+// in the bucket then an empty key is returned and nothing is placed into v.
+func FirstJson(tx Tx, location string, v interface{}) (string, error) {
+	key, raw, err := First(tx, location)
+	if err != nil || raw == nil {
+		return key, wrapErr("FirstJson", location, "", err)
+	}
+	return key, wrapErr("FirstJson", location, key, loadJson(tx, raw, v))
+}
+
+// LastJson calls Last and decodes the value into v using json.Unmarshal(), returning the key. If there is nothing in
+// the bucket then an empty key is returned and nothing is placed into v.
+func LastJson(tx Tx, location string, v interface{}) (string, error) {
+	key, raw, err := Last(tx, location)
+	if err != nil || raw == nil {
+		return key, wrapErr("LastJson", location, "", err)
+	}
+	return key, wrapErr("LastJson", location, key, loadJson(tx, raw, v))
+}
+
+// Seek returns the first key (and its value) in the bucket at location which is equal to or after key, skipping any
+// nested buckets. This is useful for range lookups on time-ordered keys, e.g. "the first event on or after the 1st
+// of June". If there is no such key then an empty key and a nil value are returned.
+func Seek(tx Tx, location, key string) (string, []byte, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return "", nil, wrapErr("Seek", location, "", err)
+	}
+	if b == nil {
+		return "", nil, nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(key)); k != nil; k, v = c.Next() {
+		if !isBucket(b, k, v) {
+			return string(k), v, nil
+		}
+	}
+
+	return "", nil, nil
+}
+
+// SeekBefore returns the last key (and its value) in the bucket at location which is equal to or before key, skipping
+// any nested buckets. If there is no such key then an empty key and a nil value are returned.
+func SeekBefore(tx Tx, location, key string) (string, []byte, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return "", nil, wrapErr("SeekBefore", location, "", err)
+	}
+	if b == nil {
+		return "", nil, nil
+	}
+
+	c := b.Cursor()
+	k, v := c.Seek([]byte(key))
+	if k != nil && string(k) == key && !isBucket(b, k, v) {
+		return key, v, nil
+	}
+
+	// we're now just past where key would be, so step back
+	if k == nil {
+		k, v = c.Last()
+	} else {
+		k, v = c.Prev()
+	}
+	for ; k != nil; k, v = c.Prev() {
+		if !isBucket(b, k, v) {
+			return string(k), v, nil
+		}
+	}
+
+	return "", nil, nil
+}
@@ -0,0 +1,116 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestFirstAndLast(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "event", "2017-01-01", User{"alice", 1}))
+		check(PutJson(tx, "event", "2017-06-15", User{"bob", 2}))
+		check(PutJson(tx, "event", "2017-12-31", User{"carol", 3}))
+		// nested buckets at either end should be skipped
+		check(PutString(tx, "event.0000", "key", "value"))
+		check(PutString(tx, "event.zzzz", "key", "value"))
+		return nil
+	})
+	check(err)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		key, value, err := First(tx, "event")
+		check(err)
+		if key != "2017-01-01" || value == nil {
+			t.Fatalf("First key should have been 2017-01-01, but was %q", key)
+		}
+
+		key, value, err = Last(tx, "event")
+		check(err)
+		if key != "2017-12-31" || value == nil {
+			t.Fatalf("Last key should have been 2017-12-31, but was %q", key)
+		}
+
+		var user User
+		key, err = FirstJson(tx, "event", &user)
+		check(err)
+		if key != "2017-01-01" || user.Username != "alice" {
+			t.Fatalf("FirstJson returned the wrong record: %q %#v", key, user)
+		}
+
+		key, err = LastJson(tx, "event", &user)
+		check(err)
+		if key != "2017-12-31" || user.Username != "carol" {
+			t.Fatalf("LastJson returned the wrong record: %q %#v", key, user)
+		}
+
+		key, value, err = First(tx, "does-not-exist")
+		check(err)
+		if key != "" || value != nil {
+			t.Fatal("First on a missing bucket should return nothing")
+		}
+
+		key, err = LastJson(tx, "does-not-exist", &user)
+		check(err)
+		if key != "" {
+			t.Fatal("LastJson on a missing bucket should return nothing")
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestSeek(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "event", "2017-01-01", "new year"))
+		check(PutString(tx, "event", "2017-06-15", "mid year"))
+		check(PutString(tx, "event", "2017-12-31", "new years eve"))
+		check(PutString(tx, "event.2017-07", "key", "value"))
+		return nil
+	})
+	check(err)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		tests := []struct {
+			key    string
+			after  string
+			before string
+		}{
+			{"2016-01-01", "2017-01-01", ""},
+			{"2017-01-01", "2017-01-01", "2017-01-01"},
+			{"2017-03-01", "2017-06-15", "2017-01-01"},
+			{"2017-07-01", "2017-12-31", "2017-06-15"},
+			{"2018-01-01", "", "2017-12-31"},
+		}
+
+		for _, test := range tests {
+			key, value, err := Seek(tx, "event", test.key)
+			check(err)
+			if key != test.after || (key != "" && value == nil) {
+				t.Fatalf("Seek(%q) should have returned %q but returned %q", test.key, test.after, key)
+			}
+
+			key, value, err = SeekBefore(tx, "event", test.key)
+			check(err)
+			if key != test.before || (key != "" && value == nil) {
+				t.Fatalf("SeekBefore(%q) should have returned %q but returned %q", test.key, test.before, key)
+			}
+		}
+
+		key, _, err := Seek(tx, "does-not-exist", "a")
+		check(err)
+		if key != "" {
+			t.Fatal("Seek on a missing bucket should return nothing")
+		}
+
+		return nil
+	})
+	check(err)
+}
@@ -13,6 +13,9 @@
 //
 // • JSON
 //
+// Every function takes a rod.Tx, which is usually a *bolt.Tx. Other stores can be used instead by providing a
+// rod.BackendTx, with its Bucket and Cursor, such as the in-memory one in rodtest.
+//
 // Again, everything is a convenience and you should be aware of any overhead rod introduces. However, since rod is
 // designed to be minimal we try not to add much overhead at all (in terms of both code size and run-time overhead).
 //
@@ -0,0 +1,197 @@
+package rod
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/boltdb/bolt"
+)
+
+// dumpLine is one line of a dump. A line without a key is a bucket, written before anything in it so that empty
+// buckets and their sequences survive too. A key is in Key, or in Key64 if it isn't valid UTF-8. A value is in Value
+// if it is compact JSON which can be written out exactly as stored, in String if it is other text, or in Bytes if it
+// isn't valid UTF-8, so that loading a dump always gives back exactly the bytes which were dumped.
+type dumpLine struct {
+	Location string          `json:"location"`
+	Key      *string         `json:"key,omitempty"`
+	Key64    []byte          `json:"key64,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	String   *string         `json:"string,omitempty"`
+	Bytes    []byte          `json:"bytes,omitempty"`
+	Sequence uint64          `json:"sequence,omitempty"`
+}
+
+// DumpJsonLines writes everything in db to w as newline-delimited JSON, one line per record in key order, bucket by
+// bucket down the whole tree. Records look like this, which makes a dump easy to diff, grep, or pick apart with jq:
+//
+//	{"location":"users"}
+//	{"location":"users","key":"chilts","value":{"Username":"chilts","Logins":1}}
+//	{"location":"users.chilts.posts","key":"hello","string":"Hello, World!"}
+//
+// Values which aren't JSON are in "string" instead of "value" (or "bytes", base64 encoded, if they aren't text), and
+// the same goes for a key in "key64". Each bucket has its own line before its records, with its sequence if it has
+// one. The whole dump is taken in one read transaction, so it is consistent. Load it back with LoadJsonLines.
+func DumpJsonLines(db *bolt.DB, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	err := db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return dumpBucket(enc, string(name), b)
+		})
+	})
+	if err != nil {
+		return wrapErr("DumpJsonLines", "", "", err)
+	}
+	return wrapErr("DumpJsonLines", "", "", bw.Flush())
+}
+
+// dumpBucket writes the line for b at location, then every record in it, going down into nested buckets as they come.
+func dumpBucket(enc *json.Encoder, location string, b *bolt.Bucket) error {
+	if err := enc.Encode(dumpLine{Location: location, Sequence: b.Sequence()}); err != nil {
+		return err
+	}
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil {
+			if nested := b.Bucket(k); nested != nil {
+				if err := dumpBucket(enc, location+"."+string(k), nested); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		line := dumpLine{Location: location}
+		if utf8.Valid(k) {
+			key := string(k)
+			line.Key = &key
+		} else {
+			line.Key64 = k
+		}
+		switch {
+		case exactJson(v):
+			line.Value = v
+		case utf8.Valid(v):
+			s := string(v)
+			line.String = &s
+		default:
+			line.Bytes = v
+		}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exactJson says whether v is JSON which json.Encoder writes out byte for byte, so that it can go in a dump as it is.
+func exactJson(v []byte) bool {
+	if !json.Valid(v) {
+		return false
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(json.RawMessage(v)); err != nil {
+		return false
+	}
+	return bytes.Equal(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), v)
+}
+
+// LoadJsonLines puts everything in a dump from DumpJsonLines into db. It creates the buckets as it finds them and
+// sets their sequences, overwriting any key already there but leaving everything else in db alone. Lines are loaded
+// DefaultBulkLoadBatchSize at a time, each batch in its own transaction, and it returns how many records it loaded.
+// Blank lines are skipped. A line which doesn't parse stops the load with an error saying which line it was, with
+// the batches before it already committed.
+//
+// Since it is given a *bolt.DB rather than a Store, hooks such as indexes and audit logs don't see the records, just as
+// when loading with BulkLoad.
+func LoadJsonLines(db *bolt.DB, r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+
+	loaded := 0
+	lineNum := 0
+	for done := false; !done; {
+		var batch []dumpLine
+		for len(batch) < DefaultBulkLoadBatchSize {
+			if !scanner.Scan() {
+				done = true
+				break
+			}
+			lineNum++
+			text := scanner.Bytes()
+			if len(bytes.TrimSpace(text)) == 0 {
+				continue
+			}
+
+			var line dumpLine
+			if err := json.Unmarshal(text, &line); err != nil {
+				return loaded, wrapErr("LoadJsonLines", "", "", fmt.Errorf("line %d: %w", lineNum, err))
+			}
+			if line.Location == "" {
+				return loaded, wrapErr("LoadJsonLines", "", "", fmt.Errorf("line %d: no location", lineNum))
+			}
+			batch = append(batch, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return loaded, wrapErr("LoadJsonLines", "", "", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		n := 0
+		err := db.Update(func(tx *bolt.Tx) error {
+			n = 0
+			for _, line := range batch {
+				b, err := createBucket(tx, line.Location)
+				if err != nil {
+					return wrapErr("LoadJsonLines", line.Location, "", err)
+				}
+
+				var key []byte
+				switch {
+				case line.Key != nil:
+					key = []byte(*line.Key)
+				case line.Key64 != nil:
+					key = line.Key64
+				default:
+					if line.Sequence > 0 {
+						if err := b.(boltBucket).b.SetSequence(line.Sequence); err != nil {
+							return wrapErr("LoadJsonLines", line.Location, "", err)
+						}
+					}
+					continue
+				}
+
+				value := []byte{}
+				switch {
+				case line.Value != nil:
+					value = line.Value
+				case line.String != nil:
+					value = []byte(*line.String)
+				case line.Bytes != nil:
+					value = line.Bytes
+				}
+				if err := putKey(tx, b, line.Location, key, value); err != nil {
+					return wrapErr("LoadJsonLines", line.Location, string(key), err)
+				}
+				n++
+			}
+			return nil
+		})
+		if err != nil {
+			return loaded, err
+		}
+		loaded += n
+	}
+	return loaded, nil
+}
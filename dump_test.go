@@ -0,0 +1,101 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestDumpJsonLines(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "users", "chilts", User{"chilts", 1}))
+		check(PutString(tx, "users.posts", "hello", "Hello, <World>!"))
+		check(PutString(tx, "users.posts", "empty", ""))
+		check(Put(tx, "raw", "spaced", []byte(`{ "a": 1 }`)))
+		check(Put(tx, "raw", "binary", []byte{0xff, 0x00, 0xfe}))
+		b, err := tx.CreateBucketIfNotExists([]byte("seq"))
+		check(err)
+		check(b.SetSequence(42))
+		check(b.Put([]byte{0xff, 0x01}, []byte("binary key")))
+		return nil
+	}))
+
+	var dump bytes.Buffer
+
+	t.Run("DumpJsonLines", func(t *testing.T) {
+		check(DumpJsonLines(db, &dump))
+
+		lines := strings.Split(strings.TrimSpace(dump.String()), "\n")
+		want := map[string]bool{
+			`{"location":"users"}`: true,
+			`{"location":"users","key":"chilts","value":{"Username":"chilts","Logins":1}}`: true,
+			`{"location":"users.posts","key":"hello","string":"Hello, <World>!"}`:          true,
+			`{"location":"users.posts","key":"empty","string":""}`:                         true,
+			`{"location":"raw","key":"spaced","string":"{ \"a\": 1 }"}`:                    true,
+			`{"location":"raw","key":"binary","bytes":"/wD+"}`:                             true,
+			`{"location":"seq","sequence":42}`:                                             true,
+			`{"location":"seq","key64":"/wE=","string":"binary key"}`:                      true,
+		}
+		for _, line := range lines {
+			delete(want, line)
+		}
+		if len(want) != 0 {
+			t.Fatalf("Lines missing from the dump %v:\n%s", want, dump.String())
+		}
+	})
+
+	t.Run("LoadJsonLines", func(t *testing.T) {
+		other, done := openTestDB(t)
+		defer done()
+
+		n, err := LoadJsonLines(other, strings.NewReader(dump.String()+"\n\n"))
+		check(err)
+		if n != 6 {
+			t.Fatalf("Expected 6 records to be loaded, not %d", n)
+		}
+
+		var again bytes.Buffer
+		check(DumpJsonLines(other, &again))
+		if again.String() != dump.String() {
+			t.Fatalf("Dumping the loaded database should give the same dump:\n%s\n%s", dump.String(), again.String())
+		}
+
+		check(other.View(func(tx *bolt.Tx) error {
+			raw, err := Get(tx, "raw", "spaced")
+			check(err)
+			if string(raw) != `{ "a": 1 }` {
+				t.Fatalf("Values should come back exactly as they were: %q", raw)
+			}
+			if seq := tx.Bucket([]byte("seq")).Sequence(); seq != 42 {
+				t.Fatalf("Sequence should be 42, not %d", seq)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("LoadJsonLines - bad line", func(t *testing.T) {
+		other, done := openTestDB(t)
+		defer done()
+
+		_, err := LoadJsonLines(other, strings.NewReader("{\"location\":\"a\"}\nnot json\n"))
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Fatalf("Expected an error for line 2, not %v", err)
+		}
+
+		_, err = LoadJsonLines(other, strings.NewReader(`{"key":"a","string":"b"}`))
+		if err == nil {
+			t.Fatal("A line without a location should be an error")
+		}
+
+		_, err = LoadJsonLines(other, strings.NewReader(`{"location":"a..b","key":"a","string":"b"}`))
+		if !errors.Is(err, ErrInvalidLocationBucket) {
+			t.Fatalf("Expected ErrInvalidLocationBucket for a blank bucket, not %v", err)
+		}
+	})
+}
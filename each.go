@@ -0,0 +1,71 @@
+package rod
+
+import (
+	"errors"
+)
+
+// Stop can be returned from any iteration callback to halt the iteration early. It is never returned to the caller,
+// so the iterating function just returns nil as if it had reached the end of the bucket.
+var Stop = errors.New("stop iteration")
+
+// Each calls fn for every key/value in the bucket specified by location, in key order. Nested buckets are skipped. If
+// fn returns Stop then the iteration is halted and nil is returned. Any other error halts the iteration and is returned
+// to you.
+//
+//	err := rod.Each(tx, "users", func(key string, value []byte) error {
+//	    if key == "chilts" {
+//	        found = true
+//	        return rod.Stop
+//	    }
+//	    return nil
+//	})
+//
+// Note that the value passed to fn is only valid for the life of the transaction.
+func Each(tx Tx, location string, fn func(key string, value []byte) error) error {
+	// find this bucket
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("Each", location, "", err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	// use a cursor to iterate through this bucket (skipping any nested buckets)
+	scan := startScan(tx, location)
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		scan.item(v)
+		if err := fn(string(k), v); err != nil {
+			if err == Stop {
+				return scan.done(nil)
+			}
+			return scan.done(err)
+		}
+	}
+
+	return scan.done(nil)
+}
+
+// EachJson is like Each but decodes each value into a new T using json.Unmarshal() before calling fn. If any value
+// fails to decode then the iteration is halted and the decoding error is returned.
+//
+//	err := rod.EachJson(tx, "users", func(key string, user User) error {
+//	    users = append(users, user)
+//	    if len(users) == 10 {
+//	        return rod.Stop
+//	    }
+//	    return nil
+//	})
+func EachJson[T any](tx Tx, location string, fn func(key string, v T) error) error {
+	return Each(tx, location, func(key string, value []byte) error {
+		var v T
+		if err := loadJson(tx, value, &v); err != nil {
+			return wrapErr("EachJson", location, key, err)
+		}
+		return fn(key, v)
+	})
+}
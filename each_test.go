@@ -0,0 +1,104 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestEach(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 3}))
+		check(PutJson(tx, "user", "bob", User{"bob", 5}))
+		check(PutJson(tx, "user", "carol", User{"carol", 7}))
+		// a nested bucket should be skipped
+		check(PutString(tx, "user.dave", "email", "dave@example.com"))
+		return nil
+	})
+	check(err)
+
+	t.Run("Each", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var keys []string
+			err := Each(tx, "user", func(key string, value []byte) error {
+				keys = append(keys, key)
+				return nil
+			})
+			check(err)
+			if len(keys) != 3 {
+				t.Fatalf("Each() should have been called 3 times, but was called %d times", len(keys))
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Each with Stop", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			count := 0
+			err := Each(tx, "user", func(key string, value []byte) error {
+				count++
+				if key == "bob" {
+					return Stop
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Stop should not be returned from Each(), but got %v", err)
+			}
+			if count != 2 {
+				t.Fatalf("Each() should have stopped after 2 calls, but was called %d times", count)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Each returns other errors", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		err := db.View(func(tx *bolt.Tx) error {
+			return Each(tx, "user", func(key string, value []byte) error {
+				return errBoom
+			})
+		})
+		if err != errBoom {
+			t.Fatalf("Expected the callback error to be returned, but got %v", err)
+		}
+	})
+
+	t.Run("EachJson", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var users []User
+			err := EachJson(tx, "user", func(key string, user User) error {
+				users = append(users, user)
+				if len(users) == 2 {
+					return Stop
+				}
+				return nil
+			})
+			check(err)
+			if len(users) != 2 {
+				t.Fatalf("EachJson() should have returned 2 users, but returned %d", len(users))
+			}
+			if users[1].Username != "bob" || users[1].Logins != 5 {
+				t.Fatalf("Second user was not decoded correctly: %#v", users[1])
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("EachJson on a non-existant bucket", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			return EachJson(tx, "does-not-exist", func(key string, user User) error {
+				t.Fatal("Callback should not be called for a missing bucket")
+				return nil
+			})
+		})
+		check(err)
+	})
+}
@@ -0,0 +1,238 @@
+package rod
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrNoEncryptionKey is returned when a value with a field tagged `rod:"encrypt"` is put or read before
+// SetEncryptionKey has been called.
+var ErrNoEncryptionKey = errors.New("no encryption key has been set")
+
+// ErrDecryptionFailed is returned when an encrypted field can't be decrypted, such as with the wrong key.
+var ErrDecryptionFailed = errors.New("encrypted field could not be decrypted")
+
+// ErrUnknownEncryptionKey is returned when an encrypted field was encrypted with a key which has been given to neither
+// SetEncryptionKey nor AddDecryptionKey.
+var ErrUnknownEncryptionKey = errors.New("encrypted field was encrypted with an unknown key")
+
+// encryptedPrefix starts every encrypted field, followed by the ID of the key and a colon, then the base64 ciphertext.
+const encryptedPrefix = "rod:enc:"
+
+// encryptionKey is an AES key ready for use, along with its ID.
+type encryptionKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+var encryption struct {
+	sync.RWMutex
+	current *encryptionKey
+	keys    map[string]*encryptionKey
+}
+
+func newEncryptionKey(key []byte) (*encryptionKey, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &encryptionKey{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// SetEncryptionKey sets the AES key (16, 24 or 32 bytes long) used to encrypt fields tagged `rod:"encrypt"`, which
+// must be strings:
+//
+//	type Integration struct {
+//	    Name  string
+//	    Token string `rod:"encrypt"`
+//	}
+//
+// PutJson encrypts each tagged field with AES-GCM before marshalling, so the rest of the document can still be
+// queried, and the functions which decode JSON into your types decrypt them again. The value given to PutJson isn't
+// changed. Empty strings are left as they are.
+//
+// Each encrypted field is framed with the ID of its key, so after changing the key, give the old one to
+// AddDecryptionKey to keep reading fields encrypted with it until RotateKeys has re-encrypted them. Pass nil to
+// remove every key.
+func SetEncryptionKey(key []byte) error {
+	if key == nil {
+		encryption.Lock()
+		defer encryption.Unlock()
+		encryption.current = nil
+		encryption.keys = nil
+		return nil
+	}
+
+	k, err := newEncryptionKey(key)
+	if err != nil {
+		return err
+	}
+
+	encryption.Lock()
+	defer encryption.Unlock()
+	encryption.current = k
+	addKey(k)
+	return nil
+}
+
+// AddDecryptionKey adds an old key which fields may still be encrypted with. It is only used for decrypting.
+func AddDecryptionKey(key []byte) error {
+	k, err := newEncryptionKey(key)
+	if err != nil {
+		return err
+	}
+
+	encryption.Lock()
+	defer encryption.Unlock()
+	addKey(k)
+	return nil
+}
+
+// addKey adds k to the keys, copying the map rather than changing it since readers use it without the lock. The lock
+// must be held.
+func addKey(k *encryptionKey) {
+	keys := make(map[string]*encryptionKey, len(encryption.keys)+1)
+	for id, key := range encryption.keys {
+		keys[id] = key
+	}
+	keys[k.id] = k
+	encryption.keys = keys
+}
+
+func (k *encryptionKey) encrypt(plain string) (string, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := k.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedPrefix + k.id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (k *encryptionKey) decrypt(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < k.aead.NonceSize() {
+		return "", ErrDecryptionFailed
+	}
+	plain, err := k.aead.Open(nil, sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+	return string(plain), nil
+}
+
+// splitEncrypted splits an encrypted field into its key ID and ciphertext.
+func splitEncrypted(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, encryptedPrefix) {
+		return "", "", false
+	}
+	return strings.Cut(s[len(encryptedPrefix):], ":")
+}
+
+// encryptedFields returns the string fields in t tagged `rod:"encrypt"`. Fields within embedded pointers are left out,
+// since setting them on a copy would change the original.
+func encryptedFields(t reflect.Type) [][]int {
+	var fields [][]int
+	for _, index := range taggedFields(t, "encrypt") {
+		ft, ok := t, true
+		for _, i := range index {
+			if ft.Kind() != reflect.Struct {
+				ok = false
+				break
+			}
+			ft = ft.Field(i).Type
+		}
+		if ok && ft.Kind() == reflect.String {
+			fields = append(fields, index)
+		}
+	}
+	return fields
+}
+
+// encryptFields returns a copy of v with each of its encrypted fields encrypted. If v doesn't have any then it is
+// returned as it is, and false.
+func encryptFields(v interface{}) (interface{}, bool, error) {
+	ref := reflect.Indirect(reflect.ValueOf(v))
+	if !ref.IsValid() || ref.Kind() != reflect.Struct {
+		return v, false, nil
+	}
+	fields := tagsFor(ref.Type()).encrypt
+	if fields == nil {
+		return v, false, nil
+	}
+
+	encryption.RLock()
+	key := encryption.current
+	encryption.RUnlock()
+	if key == nil {
+		return nil, false, ErrNoEncryptionKey
+	}
+
+	item := reflect.New(ref.Type()).Elem()
+	item.Set(ref)
+	for _, index := range fields {
+		field := item.FieldByIndex(index)
+		if field.String() == "" {
+			continue
+		}
+		encrypted, err := key.encrypt(field.String())
+		if err != nil {
+			return nil, false, err
+		}
+		field.SetString(encrypted)
+	}
+	return item.Interface(), true, nil
+}
+
+// decryptFields decrypts each of the encrypted fields in v, which must be a pointer.
+func decryptFields(v interface{}) error {
+	ref := reflect.ValueOf(v)
+	if ref.Kind() != reflect.Ptr || ref.IsNil() || ref.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	item := ref.Elem()
+	fields := tagsFor(item.Type()).encrypt
+	if fields == nil {
+		return nil
+	}
+
+	encryption.RLock()
+	keys := encryption.keys
+	encryption.RUnlock()
+	if keys == nil {
+		return ErrNoEncryptionKey
+	}
+
+	for _, index := range fields {
+		field := item.FieldByIndex(index)
+		if field.String() == "" {
+			continue
+		}
+		id, ciphertext, ok := splitEncrypted(field.String())
+		if !ok {
+			return ErrDecryptionFailed
+		}
+		key, ok := keys[id]
+		if !ok {
+			return ErrUnknownEncryptionKey
+		}
+		plain, err := key.decrypt(ciphertext)
+		if err != nil {
+			return err
+		}
+		field.SetString(plain)
+	}
+	return nil
+}
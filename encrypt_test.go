@@ -0,0 +1,277 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type Integration struct {
+	Name  string
+	Token string `rod:"encrypt"`
+}
+
+func TestEncrypt(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	check(SetEncryptionKey(bytes.Repeat([]byte("k"), 32)))
+	defer SetEncryptionKey(nil)
+
+	t.Run("Encrypted fields are not stored in the clear", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			integration := Integration{"github", "secret-token"}
+			check(PutJson(tx, "integration", "github", &integration))
+			if integration.Token != "secret-token" {
+				t.Fatalf("The value given to PutJson should not be changed: %#v", integration)
+			}
+
+			raw, err := GetString(tx, "integration", "github")
+			check(err)
+			if strings.Contains(raw, "secret-token") || !strings.Contains(raw, `"Name":"github"`) {
+				t.Fatalf("Expected only the token to be encrypted: %s", raw)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Encrypted fields are decrypted on read", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var integration Integration
+			check(GetJson(tx, "integration", "github", &integration))
+			if integration.Token != "secret-token" {
+				t.Fatalf("Expected the token to be decrypted but got '%s'", integration.Token)
+			}
+
+			var all []*Integration
+			check(All(tx, "integration", &all))
+			if len(all) != 1 || all[0].Token != "secret-token" {
+				t.Fatalf("Expected All to decrypt the token: %#v", all)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Every JSON write encrypts", func(t *testing.T) {
+		// stored checks the token was put encrypted, and decrypts again
+		stored := func(tx *bolt.Tx, location, key string) {
+			raw, err := GetString(tx, location, key)
+			check(err)
+			if strings.Contains(raw, "secret-token") || !strings.Contains(raw, encryptedPrefix) {
+				t.Fatalf("Expected the token at %s/%s to be encrypted: %s", location, key, raw)
+			}
+			var integration Integration
+			check(GetJson(tx, location, key, &integration))
+			if integration.Token != "secret-token" {
+				t.Fatalf("Expected the token at %s/%s to decrypt but got '%s'", location, key, integration.Token)
+			}
+		}
+
+		err := db.Update(func(tx *bolt.Tx) error {
+			var got Integration
+			created, err := GetOrPutJson(tx, "writes", "get-or-put", &got, func() interface{} {
+				return Integration{"github", "secret-token"}
+			})
+			check(err)
+			if !created || got.Token != "secret-token" {
+				t.Fatalf("Expected GetOrPutJson to create and decrypt the record: %v %#v", created, got)
+			}
+			stored(tx, "writes", "get-or-put")
+
+			check(PutManyJson(tx, "writes.many", map[string]Integration{
+				"a": {"a", "secret-token"},
+				"b": {"b", "secret-token"},
+			}))
+			stored(tx, "writes.many", "a")
+			var all []Integration
+			check(All(tx, "writes.many", &all))
+			if len(all) != 2 || all[1].Token != "secret-token" {
+				t.Fatalf("Expected All to decrypt every token: %#v", all)
+			}
+
+			integration := Integration{"github", "secret-token"}
+			swapped, err := CompareAndSwapVersion(tx, "writes", "swap", "Version", 0, &integration)
+			check(err)
+			if !swapped || integration.Token != "secret-token" {
+				t.Fatalf("Expected the swap to be made and the token kept: %v %#v", swapped, integration)
+			}
+			stored(tx, "writes", "swap")
+
+			// merges and patches keep the encrypted field as it is stored
+			check(PutJson(tx, "writes", "patched", Integration{"github", "secret-token"}))
+			check(MergeJson(tx, "writes", "patched", []byte(`{"Name":"merged"}`)))
+			check(PatchJson(tx, "writes", "patched", []byte(`[{"op":"replace","path":"/Name","value":"patched"}]`)))
+			stored(tx, "writes", "patched")
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("The wrong key fails", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "integration", "tampered", `{"Token":"rod:enc:`+encryption.current.id+`:bm9wZQ=="}`)
+		})
+		check(err)
+		err = db.View(func(tx *bolt.Tx) error {
+			var integration Integration
+			return GetJson(tx, "integration", "tampered", &integration)
+		})
+		if !errors.Is(err, ErrDecryptionFailed) {
+			t.Fatalf("Expected ErrDecryptionFailed but got %v", err)
+		}
+
+		check(SetEncryptionKey(nil))
+		check(SetEncryptionKey(bytes.Repeat([]byte("x"), 32)))
+		err = db.View(func(tx *bolt.Tx) error {
+			var integration Integration
+			return GetJson(tx, "integration", "github", &integration)
+		})
+		if !errors.Is(err, ErrUnknownEncryptionKey) {
+			t.Fatalf("Expected ErrUnknownEncryptionKey but got %v", err)
+		}
+
+		check(SetEncryptionKey(nil))
+		err = db.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "integration", "gitlab", Integration{"gitlab", "token"})
+		})
+		if !errors.Is(err, ErrNoEncryptionKey) {
+			t.Fatalf("Expected ErrNoEncryptionKey but got %v", err)
+		}
+	})
+}
+
+func TestRotateKeys(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	oldKey := bytes.Repeat([]byte("o"), 32)
+	newKey := bytes.Repeat([]byte("n"), 32)
+	defer SetEncryptionKey(nil)
+
+	// more than a chunk, some of them nested
+	check(SetEncryptionKey(oldKey))
+	err := db.Update(func(tx *bolt.Tx) error {
+		for i := 0; i < rotateChunk+10; i++ {
+			check(PutJson(tx, "integration", U64Key(uint64(i)), Integration{"github", "token"}))
+		}
+		check(PutJson(tx, "team.acme.integration", "slack", Integration{"slack", "slack-token"}))
+		check(PutString(tx, "config", "name", "rod"))
+		return nil
+	})
+	check(err)
+
+	t.Run("RotateKeys re-encrypts with the new key", func(t *testing.T) {
+		n, err := RotateKeys(db, oldKey, newKey)
+		check(err)
+		if n != rotateChunk+11 {
+			t.Fatalf("Expected %d values to be rewritten but got %d", rotateChunk+11, n)
+		}
+
+		// only the new key is known now
+		check(SetEncryptionKey(nil))
+		check(SetEncryptionKey(newKey))
+		err = db.View(func(tx *bolt.Tx) error {
+			var integration Integration
+			check(GetJson(tx, "team.acme.integration", "slack", &integration))
+			if integration.Token != "slack-token" {
+				t.Fatalf("Expected the token to be decrypted but got '%s'", integration.Token)
+			}
+
+			var all []Integration
+			check(All(tx, "integration", &all))
+			if len(all) != rotateChunk+10 || all[rotateChunk+9].Token != "token" {
+				t.Fatalf("Expected every integration to be readable with the new key")
+			}
+			return nil
+		})
+		check(err)
+
+		// and there is nothing left to do
+		n, err = RotateKeys(db, oldKey, newKey)
+		check(err)
+		if n != 0 {
+			t.Fatalf("Expected nothing to be rewritten the second time but got %d", n)
+		}
+	})
+
+	t.Run("RotateKeys only rewrites string values in JSON", func(t *testing.T) {
+		otherKey := bytes.Repeat([]byte("o"), 32)
+		other, err := newEncryptionKey(otherKey)
+		check(err)
+		encrypted, err := other.encrypt("nested-token")
+		check(err)
+		marker := encryptedPrefix + other.id + ":not-encrypted"
+
+		doc := `{ "` + marker + `" : [ {"t": "` + encrypted + `"}, "plain" ] }`
+		err = db.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "rotate", "doc", doc))
+			check(PutString(tx, "rotate", "text", `not JSON "`+marker+`"`))
+			return nil
+		})
+		check(err)
+
+		n, err := RotateKeys(db, otherKey, newKey)
+		check(err)
+		if n != 1 {
+			t.Fatalf("Expected only the document to be rewritten but got %d", n)
+		}
+
+		err = db.View(func(tx *bolt.Tx) error {
+			got, err := GetString(tx, "rotate", "doc")
+			check(err)
+			var v map[string][]interface{}
+			check(json.Unmarshal([]byte(got), &v))
+			rotated := v[marker][0].(map[string]interface{})["t"].(string)
+			if !strings.HasPrefix(got, `{ "`+marker+`" : [ {"t": "`) || !strings.HasSuffix(got, `"}, "plain" ] }`) {
+				t.Fatalf("Expected the rest of the document to be left alone: %s", got)
+			}
+			to, err := newEncryptionKey(newKey)
+			check(err)
+			plain, err := to.decrypt(strings.TrimPrefix(rotated, encryptedPrefix+to.id+":"))
+			check(err)
+			if plain != "nested-token" {
+				t.Fatalf("Expected the nested field to be encrypted with the new key but got %q", plain)
+			}
+
+			text, err := GetString(tx, "rotate", "text")
+			check(err)
+			if text != `not JSON "`+marker+`"` {
+				t.Fatalf("Values which aren't JSON should be left alone: %s", text)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Old keys can be added for decryption", func(t *testing.T) {
+		check(SetEncryptionKey(nil))
+		check(SetEncryptionKey(oldKey))
+		err := db.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "integration", "old", Integration{"old", "old-token"})
+		})
+		check(err)
+
+		check(SetEncryptionKey(newKey))
+		err = db.View(func(tx *bolt.Tx) error {
+			var integration Integration
+			return GetJson(tx, "integration", "old", &integration)
+		})
+		check(err)
+
+		check(SetEncryptionKey(nil))
+		check(SetEncryptionKey(newKey))
+		err = db.View(func(tx *bolt.Tx) error {
+			var integration Integration
+			return GetJson(tx, "integration", "old", &integration)
+		})
+		if !errors.Is(err, ErrUnknownEncryptionKey) {
+			t.Fatalf("Expected ErrUnknownEncryptionKey but got %v", err)
+		}
+	})
+}
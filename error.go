@@ -0,0 +1,55 @@
+package rod
+
+// Error is returned from rod's functions whenever they fail, saying which operation failed and where. The original
+// error (usually one of rod's Err* sentinels, or one from Bolt or encoding/json) is in Err, so you can still check for
+// it with errors.Is:
+//
+//	if errors.Is(err, rod.ErrKeyNotProvided) {
+//	    ...
+//	}
+//
+// Errors returned from your own callbacks (e.g. in Each) are passed back to you untouched.
+type Error struct {
+	Op       string // the rod function which failed, e.g. "Put"
+	Location string // the location it was given, if any
+	Key      string // the key it was working on, if any
+	Err      error  // what actually went wrong
+}
+
+func (e *Error) Error() string {
+	msg := "rod: " + e.Op
+	if e.Location != "" {
+		msg += " " + e.Location
+		if e.Key != "" {
+			msg += "/" + e.Key
+		}
+	} else if e.Key != "" {
+		msg += " " + e.Key
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error, for errors.Is and errors.As.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrapErr wraps err in an *Error for op. If err is already an *Error (e.g. from BucketAt) then it is relabelled with
+// op rather than wrapped twice, keeping any location or key it already had. A nil err gives nil.
+func wrapErr(op, location, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := err.(*Error); ok {
+		if e.Location != "" {
+			location = e.Location
+		}
+		if e.Key != "" {
+			key = e.Key
+		}
+		err = e.Err
+	}
+
+	return &Error{Op: op, Location: location, Key: key, Err: err}
+}
@@ -0,0 +1,155 @@
+package rod
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestError(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("Sentinels still match", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			err := Put(tx, "users", "", []byte("value"))
+			if !errors.Is(err, ErrKeyNotProvided) {
+				t.Fatalf("Expected ErrKeyNotProvided but got %v", err)
+			}
+
+			var rodErr *Error
+			if !errors.As(err, &rodErr) {
+				t.Fatalf("Expected a *rod.Error but got %T", err)
+			}
+			if rodErr.Op != "Put" || rodErr.Location != "users" {
+				t.Fatalf("The error should say where it happened: %#v", rodErr)
+			}
+			if err.Error() != "rod: Put users: key must be specified" {
+				t.Fatalf("Unexpected error message: %s", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Errors are relabelled, not double wrapped", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "users.chilts", "email", "andychilton@gmail.com"))
+
+			_, err := Get(tx, "users..chilts", "email")
+			rodErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("Expected a *rod.Error but got %T", err)
+			}
+			if rodErr.Op != "Get" || rodErr.Location != "users..chilts" || rodErr.Key != "email" {
+				t.Fatalf("Unexpected error: %#v", rodErr)
+			}
+			if rodErr.Err != ErrInvalidLocationBucket {
+				t.Fatalf("The underlying error should be the sentinel, not %#v", rodErr.Err)
+			}
+			if err.Error() != "rod: Get users..chilts/email: invalid location bucket" {
+				t.Fatalf("Unexpected error message: %s", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Decode errors say which key failed", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutJson(tx, "user", "alice", User{"alice", 1}))
+			check(Put(tx, "user", "broken", []byte("{")))
+
+			var users []User
+			err := All(tx, "user", &users)
+			rodErr, ok := err.(*Error)
+			if !ok || rodErr.Op != "All" || rodErr.Key != "broken" {
+				t.Fatalf("Expected an error for the broken key, but got %#v", err)
+			}
+			var syntaxErr *json.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("The JSON error should still be available, but got %v", err)
+			}
+
+			// and the same when sorting
+			err = All(tx, "user", &users, SortBy("Logins"))
+			if rodErr, ok := err.(*Error); !ok || rodErr.Key != "broken" {
+				t.Fatalf("Expected an error for the broken key, but got %#v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("JSON helpers say which one failed", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			var u User
+			for op, fn := range map[string]func() error{
+				"GetOrPutJson": func() error {
+					_, err := GetOrPutJson(tx, "user", "broken", &u, func() interface{} { return User{} })
+					return err
+				},
+				"CompareAndSwapVersion": func() error {
+					_, err := CompareAndSwapVersion(tx, "user", "broken", "Logins", 0, &u)
+					return err
+				},
+				"UpdateJson":  func() error { return UpdateJson(tx, "user", "broken", &u, func() error { return nil }) },
+				"MergeJson":   func() error { return MergeJson(tx, "user", "broken", []byte(`{}`)) },
+				"PatchJson":   func() error { return PatchJson(tx, "user", "broken", []byte(`[]`)) },
+				"IncrCounter": func() error { return IncrCounter(tx, "user", "broken", 1) },
+				"SetRemove":   func() error { return SetRemove(tx, "user", "broken", "a") },
+				"GetJsonField": func() error {
+					_, err := GetJsonField(tx, "user", "broken", "Logins")
+					return err
+				},
+				"PopJson": func() error { return PopJson(tx, "user", "broken", &u) },
+			} {
+				check(Put(tx, "user", "broken", []byte("{"))) // PopJson deletes it
+				err := fn()
+				rodErr, ok := err.(*Error)
+				if !ok || rodErr.Op != op || rodErr.Location != "user" || rodErr.Key != "broken" {
+					t.Fatalf("Expected a %s error for the broken key, but got %#v", op, err)
+				}
+			}
+
+			// a failed patch operation is wrapped too, and still says which operation it was
+			check(PutJson(tx, "user", "bob", User{"bob", 1}))
+			err := PatchJson(tx, "user", "bob", []byte(`[{"op": "test", "path": "/Logins", "value": 2}]`))
+			if rodErr, ok := err.(*Error); !ok || rodErr.Op != "PatchJson" || !errors.Is(err, ErrPatchTestFailed) {
+				t.Fatalf("Expected a PatchJson error but got %#v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Batch errors carry their key", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			err := PutManyJson(tx, "batch", map[string]interface{}{"bad": make(chan int)})
+			errs, ok := err.(KeyErrors)
+			if !ok {
+				t.Fatalf("Expected KeyErrors but got %v", err)
+			}
+			rodErr, ok := errs["bad"].(*Error)
+			if !ok || rodErr.Location != "batch" || rodErr.Key != "bad" {
+				t.Fatalf("Unexpected error: %#v", errs["bad"])
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Callback errors are untouched", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		err := db.View(func(tx *bolt.Tx) error {
+			return Each(tx, "user", func(key string, value []byte) error {
+				return errBoom
+			})
+		})
+		if err != errBoom {
+			t.Fatalf("Expected the callback error itself but got %#v", err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package rod
+
+// EventLog is an append-only log of events of type E, kept at a location, for small event-sourced aggregates. The
+// events are kept in the bucket "<location>.events" keyed by their sequence number (starting at 1), and snapshots of
+// the state built from them in "<location>.snapshots".
+//
+//	log := rod.NewEventLog[AccountEvent]("account.acme")
+//	seq, err := log.Append(tx, AccountEvent{Type: "deposit", Amount: 100})
+type EventLog[E any] struct {
+	location      string
+	snapshotEvery uint64
+}
+
+// NewEventLog returns the EventLog at location.
+func NewEventLog[E any](location string) *EventLog[E] {
+	return &EventLog[E]{location: location}
+}
+
+// SnapshotEvery makes Load save a snapshot of the state whenever it has replayed at least n events since the last
+// one, so that the next Load has fewer to replay. With 0 (the default), snapshots are only saved by SaveSnapshot.
+func (l *EventLog[E]) SnapshotEvery(n uint64) *EventLog[E] {
+	l.snapshotEvery = n
+	return l
+}
+
+func (l *EventLog[E]) events() string {
+	return l.location + ".events"
+}
+
+func (l *EventLog[E]) snapshots() string {
+	return l.location + ".snapshots"
+}
+
+// Append adds the event to the end of the log, returning its sequence number.
+func (l *EventLog[E]) Append(tx Tx, event E) (uint64, error) {
+	seq, err := NextSequence(tx, l.events())
+	if err != nil {
+		return 0, wrapErr("Append", l.events(), "", err)
+	}
+	return seq, wrapErr("Append", l.events(), U64Key(seq), PutJson(tx, l.events(), U64Key(seq), event))
+}
+
+// ReadFrom calls fn for every event from seq onwards, in order. As with Each, fn may return Stop to halt early.
+func (l *EventLog[E]) ReadFrom(tx Tx, seq uint64, fn func(seq uint64, event E) error) error {
+	b, err := BucketAt(tx, l.events())
+	if err != nil {
+		return wrapErr("ReadFrom", l.events(), "", err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(U64Key(seq))); k != nil; k, v = c.Next() {
+		n, err := ParseU64Key(string(k))
+		if err != nil || v == nil {
+			continue
+		}
+		var event E
+		if err := loadJson(tx, v, &event); err != nil {
+			return wrapErr("ReadFrom", l.events(), string(k), err)
+		}
+		if err := fn(n, event); err != nil {
+			if err == Stop {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SaveSnapshot saves state as the state of the aggregate once every event up to and including seq has been applied.
+func (l *EventLog[E]) SaveSnapshot(tx Tx, seq uint64, state interface{}) error {
+	return wrapErr("SaveSnapshot", l.snapshots(), U64Key(seq), PutJson(tx, l.snapshots(), U64Key(seq), state))
+}
+
+// LoadSnapshot decodes the latest snapshot into state, and returns the sequence number it was saved at. If there are
+// no snapshots then 0 is returned and state is left alone.
+func (l *EventLog[E]) LoadSnapshot(tx Tx, state interface{}) (uint64, error) {
+	key, raw, err := Last(tx, l.snapshots())
+	if err != nil || key == "" {
+		return 0, wrapErr("LoadSnapshot", l.snapshots(), "", err)
+	}
+	seq, err := ParseU64Key(key)
+	if err != nil {
+		return 0, wrapErr("LoadSnapshot", l.snapshots(), key, err)
+	}
+	return seq, wrapErr("LoadSnapshot", l.snapshots(), key, loadJson(tx, raw, state))
+}
+
+// Load builds the current state of the aggregate, by loading the latest snapshot into state and then calling apply
+// (which should change state, usually via a closure) for each event since. It returns the sequence number of the last
+// event applied. If SnapshotEvery was set and enough events were applied, a new snapshot is saved, which needs a
+// writable transaction.
+//
+//	var account Account
+//	seq, err := log.Load(tx, &account, func(seq uint64, e AccountEvent) error {
+//	    account.Balance += e.Amount
+//	    return nil
+//	})
+func (l *EventLog[E]) Load(tx Tx, state interface{}, apply func(seq uint64, event E) error) (uint64, error) {
+	from, err := l.LoadSnapshot(tx, state)
+	if err != nil {
+		return 0, err
+	}
+
+	last := from
+	err = l.ReadFrom(tx, from+1, func(seq uint64, event E) error {
+		last = seq
+		return apply(seq, event)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if l.snapshotEvery > 0 && last-from >= l.snapshotEvery && tx.Writable() {
+		if err := l.SaveSnapshot(tx, last, state); err != nil {
+			return 0, err
+		}
+	}
+
+	return last, nil
+}
@@ -0,0 +1,93 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type AccountEvent struct {
+	Amount int
+}
+
+type Ledger struct {
+	Balance int
+}
+
+func TestEventLog(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	log := NewEventLog[AccountEvent]("account.acme").SnapshotEvery(3)
+
+	load := func(tx *bolt.Tx) (Ledger, uint64) {
+		var ledger Ledger
+		seq, err := log.Load(tx, &ledger, func(seq uint64, e AccountEvent) error {
+			ledger.Balance += e.Amount
+			return nil
+		})
+		check(err)
+		return ledger, seq
+	}
+
+	t.Run("Append and ReadFrom", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			for _, amount := range []int{100, -20, 5} {
+				_, err := log.Append(tx, AccountEvent{amount})
+				check(err)
+			}
+
+			var seqs []uint64
+			err := log.ReadFrom(tx, 2, func(seq uint64, e AccountEvent) error {
+				seqs = append(seqs, seq)
+				return nil
+			})
+			check(err)
+			if len(seqs) != 2 || seqs[0] != 2 || seqs[1] != 3 {
+				t.Fatalf("Expected events 2 and 3 but got %v", seqs)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Load saves a snapshot", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			ledger, seq := load(tx)
+			if ledger.Balance != 85 || seq != 3 {
+				t.Fatalf("Expected a balance of 85 at 3 but got %d at %d", ledger.Balance, seq)
+			}
+
+			var snapshot Ledger
+			at, err := log.LoadSnapshot(tx, &snapshot)
+			check(err)
+			if at != 3 || snapshot.Balance != 85 {
+				t.Fatalf("Expected a snapshot of 85 at 3 but got %d at %d", snapshot.Balance, at)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Load replays on top of the snapshot", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			_, err := log.Append(tx, AccountEvent{15})
+			check(err)
+
+			ledger, seq := load(tx)
+			if ledger.Balance != 100 || seq != 4 {
+				t.Fatalf("Expected a balance of 100 at 4 but got %d at %d", ledger.Balance, seq)
+			}
+
+			// only one event since the snapshot, so there isn't a new one
+			var snapshot Ledger
+			at, err := log.LoadSnapshot(tx, &snapshot)
+			check(err)
+			if at != 3 {
+				t.Fatalf("Expected the snapshot to still be at 3 but got %d", at)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
@@ -0,0 +1,49 @@
+package rod
+
+import (
+	"bytes"
+)
+
+// Exists tells you whether the key exists in the bucket at location, without copying its value. Unlike Get, this lets
+// you tell the difference between a key with an empty value and a key which isn't there at all. If any bucket along the
+// way doesn't exist then false is returned with no error. A nested bucket with the same name as key is not counted.
+func Exists(tx Tx, location, key string) (bool, error) {
+	if key == "" {
+		return false, wrapErr("Exists", location, key, ErrKeyNotProvided)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return false, wrapErr("Exists", location, "", err)
+	}
+	if b == nil {
+		return false, nil
+	}
+
+	return hasKey(b, []byte(key)), nil
+}
+
+// hasKey tells you whether key exists in b (and isn't a nested bucket).
+func hasKey(b Bucket, key []byte) bool {
+	k, v := b.Cursor().Seek(key)
+	if k == nil || !bytes.Equal(k, key) {
+		return false
+	}
+	return !isBucket(b, k, v)
+}
+
+// isBucket tells you whether the key k and value v from a cursor on b are a nested bucket. A nil value from the cursor
+// usually means a nested bucket, but it can also be a nil value put earlier in this same transaction, so check for the
+// bucket itself.
+func isBucket(b Bucket, k, v []byte) bool {
+	return v == nil && b.Bucket(k) != nil
+}
+
+// BucketExists tells you whether every bucket in location exists.
+func BucketExists(tx Tx, location string) (bool, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return false, wrapErr("BucketExists", location, "", err)
+	}
+	return b != nil, nil
+}
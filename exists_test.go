@@ -0,0 +1,96 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestExists(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "users.chilts", "email", "andychilton@gmail.com"))
+		check(PutString(tx, "users.chilts", "empty", ""))
+		check(Put(tx, "users.chilts", "nil", nil))
+		check(PutString(tx, "users.chilts.posts", "hello-world", "Hello, World!"))
+		return nil
+	})
+	check(err)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		keys := []struct {
+			location string
+			key      string
+			want     bool
+		}{
+			{"users.chilts", "email", true},
+			{"users.chilts", "empty", true},
+			{"users.chilts", "nil", true},
+			{"users.chilts", "missing", false},
+			{"users.chilts", "posts", false},
+			{"users.nobody", "email", false},
+		}
+		for _, test := range keys {
+			exists, err := Exists(tx, test.location, test.key)
+			check(err)
+			if exists != test.want {
+				t.Fatalf("Exists(%q, %q) should have been %v", test.location, test.key, test.want)
+			}
+		}
+
+		if _, err := Exists(tx, "users", ""); !errors.Is(err, ErrKeyNotProvided) {
+			t.Fatalf("Expected ErrKeyNotProvided but got %v", err)
+		}
+
+		buckets := []struct {
+			location string
+			want     bool
+		}{
+			{"users", true},
+			{"users.chilts.posts", true},
+			{"users.nobody", false},
+			{"nothing", false},
+		}
+		for _, test := range buckets {
+			exists, err := BucketExists(tx, test.location)
+			check(err)
+			if exists != test.want {
+				t.Fatalf("BucketExists(%q) should have been %v", test.location, test.want)
+			}
+		}
+
+		if _, err := BucketExists(tx, "users..chilts"); !errors.Is(err, ErrInvalidLocationBucket) {
+			t.Fatalf("Expected ErrInvalidLocationBucket but got %v", err)
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestExistsInWritableTx(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(Put(tx, "uncommitted", "nil", nil))
+		check(PutString(tx, "uncommitted.nested", "key", "value"))
+
+		exists, err := Exists(tx, "uncommitted", "nil")
+		check(err)
+		if !exists {
+			t.Fatal("A key with a nil value put in this tx should exist")
+		}
+
+		exists, err = Exists(tx, "uncommitted", "nested")
+		check(err)
+		if exists {
+			t.Fatal("A nested bucket should not count as a key")
+		}
+		return nil
+	})
+	check(err)
+}
@@ -0,0 +1,143 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetJsonField returns the raw JSON of a single field from the document stored at location/key, without decoding the
+// whole document. The path is a dotted list of object fields and array indexes, and a JSONPath-style path is also
+// accepted, so these are all the same:
+//
+//	raw, err := rod.GetJsonField(tx, "user", "chilts", "addresses.0.city")
+//	raw, err := rod.GetJsonField(tx, "user", "chilts", "addresses[0].city")
+//	raw, err := rod.GetJsonField(tx, "user", "chilts", "$.addresses[0].city")
+//
+// If the key or the field doesn't exist then nil is returned. Decode the result with json.Unmarshal() as usual.
+func GetJsonField(tx Tx, location, key, path string) ([]byte, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil || raw == nil {
+		return nil, wrapErr("GetJsonField", location, key, err)
+	}
+
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		return nil, wrapErr("GetJsonField", location, key, err)
+	}
+
+	value, err := jsonField(raw, segments)
+	if err != nil {
+		return nil, wrapErr("GetJsonField", location, key, err)
+	}
+	return value, nil
+}
+
+// ValidFieldPath returns an error if path isn't a field path which GetJsonField or Where accept. Where panics on an
+// invalid path, so check any path which comes from outside your program (such as from a query) with this first.
+func ValidFieldPath(path string) error {
+	_, err := parseFieldPath(path)
+	return err
+}
+
+// parseFieldPath splits a dotted (or simple JSONPath) field path into its segments.
+func parseFieldPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	// turn "a[0].b" into "a.0.b"
+	path = strings.Replace(path, "]", "", -1)
+	path = strings.Replace(path, "[", ".", -1)
+
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid field path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// jsonField walks the JSON document in raw token by token to find the value at segments, skipping over everything else
+// without decoding it. It returns nil if there is no such field.
+func jsonField(raw []byte, segments []string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	for _, segment := range segments {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			found := false
+			for dec.More() {
+				name, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				if name == segment {
+					found = true
+					break
+				}
+				if err := skipJsonValue(dec); err != nil {
+					return nil, err
+				}
+			}
+			if !found {
+				return nil, nil
+			}
+		case json.Delim('['):
+			n, err := strconv.Atoi(segment)
+			if err != nil || n < 0 {
+				return nil, nil
+			}
+			for i := 0; i < n; i++ {
+				if !dec.More() {
+					return nil, nil
+				}
+				if err := skipJsonValue(dec); err != nil {
+					return nil, err
+				}
+			}
+			if !dec.More() {
+				return nil, nil
+			}
+		default:
+			// a scalar has no fields
+			return nil, nil
+		}
+	}
+
+	var value json.RawMessage
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// skipJsonValue reads (and throws away) the next complete value from dec.
+func skipJsonValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
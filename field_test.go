@@ -0,0 +1,78 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestGetJsonField(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	doc := `{
+		"name": "chilts",
+		"logins": 5,
+		"tags": ["go", "bolt"],
+		"addresses": [
+			{"city": "Wellington", "country": "NZ"},
+			{"city": "London", "country": "UK", "geo": {"lat": 51.5, "lng": -0.1}}
+		],
+		"settings": {"theme": "dark", "nested": {"deep": [1, [2, 3]]}}
+	}`
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(Put(tx, "user", "chilts", []byte(doc)))
+
+		tests := []struct {
+			path string
+			want string
+		}{
+			{"name", `"chilts"`},
+			{"logins", `5`},
+			{"tags.1", `"bolt"`},
+			{"addresses.1.city", `"London"`},
+			{"addresses[1].geo.lat", `51.5`},
+			{"$.addresses[0].country", `"NZ"`},
+			{"settings.theme", `"dark"`},
+			{"settings.nested.deep.1.0", `2`},
+			{"settings", `{"theme": "dark", "nested": {"deep": [1, [2, 3]]}}`},
+			{"", ``},
+			{"missing", ``},
+			{"tags.2", ``},
+			{"tags.x", ``},
+			{"name.first", ``},
+			{"addresses.5.city", ``},
+		}
+
+		for _, test := range tests {
+			raw, err := GetJsonField(tx, "user", "chilts", test.path)
+			check(err)
+			if test.path == "" {
+				if raw == nil {
+					t.Fatal("An empty path should return the whole document")
+				}
+				continue
+			}
+			if string(raw) != test.want {
+				t.Fatalf("GetJsonField(%q) should have been %s but was %s", test.path, test.want, raw)
+			}
+			if test.want == "" && raw != nil {
+				t.Fatalf("GetJsonField(%q) should have returned nil", test.path)
+			}
+		}
+
+		raw, err := GetJsonField(tx, "user", "nobody", "name")
+		check(err)
+		if raw != nil {
+			t.Fatal("A missing key should return nil")
+		}
+
+		if _, err := GetJsonField(tx, "user", "chilts", "a..b"); err == nil {
+			t.Fatal("An invalid path should return an error")
+		}
+
+		return nil
+	})
+	check(err)
+}
@@ -0,0 +1,282 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrBlobChanged is returned when reading a file from a FileSystem whose blob has been replaced since it was opened.
+var ErrBlobChanged = errors.New("blob changed while it was being read")
+
+// FS serves the keys in a location as files, for fs.FS and (through HTTP) http.FileSystem. See FileSystem.
+type FS struct {
+	db       *bolt.DB
+	location string
+}
+
+// FileSystem returns an fs.FS over the bucket at location, with each key being the path of a file, such as
+// "css/site.css". Directories aren't stored, but are implied by the keys beneath them. A value put with PutBlob is
+// served as the whole blob, and is read a chunk at a time rather than all at once, so large uploads can be served as
+// well as small assets. Each read is its own read-only transaction, so no transaction is held open while a file is.
+//
+//	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(rod.FileSystem(db, "static").HTTP())))
+func FileSystem(db *bolt.DB, location string) *FS {
+	return &FS{db: db, location: location}
+}
+
+// HTTP returns f as an http.FileSystem, for http.FileServer.
+func (f *FS) HTTP() http.FileSystem {
+	return http.FS(f)
+}
+
+// Open opens the file or directory called name.
+func (f *FS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	var file fs.File
+	err := f.db.View(func(tx *bolt.Tx) error {
+		if name != "." {
+			raw, err := Get(tx, f.location, name)
+			if err != nil {
+				return err
+			}
+			if raw != nil {
+				file = f.openFile(name, raw)
+				return nil
+			}
+		}
+
+		entries, err := f.readDir(tx, name)
+		if err != nil {
+			return err
+		}
+		if entries == nil && name != "." {
+			return fs.ErrNotExist
+		}
+		file = &fsDir{info: fileInfo{name: baseName(name), dir: true}, entries: entries}
+		return nil
+	})
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return file, nil
+}
+
+// openFile returns the file for the value raw, which is copied unless it is a blob's manifest.
+func (f *FS) openFile(name string, raw []byte) *fsFile {
+	file := &fsFile{fs: f, name: name, info: fileInfo{name: baseName(name), size: int64(len(raw))}}
+	if blob := parseBlob(raw); blob != nil {
+		file.blob = blob
+		file.info.size = blob.Size
+		return file
+	}
+	file.data = copyBytes(raw)
+	return file
+}
+
+// readDir lists the files and directories directly beneath the directory called name, or nil if there aren't any.
+func (f *FS) readDir(tx Tx, name string) ([]fs.DirEntry, error) {
+	b, err := BucketAt(tx, f.location)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	var entries []fs.DirEntry
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		rest := string(k[len(prefix):])
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			// keys in the same subdirectory are next to each other, so it only needs comparing with the last one
+			dir := rest[:i]
+			if n := len(entries); n == 0 || entries[n-1].Name() != dir || !entries[n-1].IsDir() {
+				entries = append(entries, dirEntry{fileInfo{name: dir, dir: true}})
+			}
+			continue
+		}
+		info := fileInfo{name: rest, size: int64(len(v))}
+		if blob := parseBlob(v); blob != nil {
+			info.size = blob.Size
+		}
+		entries = append(entries, dirEntry{info})
+	}
+	return entries, nil
+}
+
+func baseName(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// fileInfo is the fs.FileInfo for both files and directories. Since rod keeps no times, ModTime is always zero.
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() interface{}   { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+type dirEntry struct {
+	info fileInfo
+}
+
+func (e dirEntry) Name() string               { return e.info.name }
+func (e dirEntry) IsDir() bool                { return e.info.dir }
+func (e dirEntry) Type() fs.FileMode          { return e.info.Mode().Type() }
+func (e dirEntry) Info() (fs.FileInfo, error) { return e.info, nil }
+
+// fsDir is an open directory, whose entries were all read when it was opened.
+type fsDir struct {
+	info    fileInfo
+	entries []fs.DirEntry
+	off     int
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.off:]
+	if n <= 0 {
+		d.off = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.off += n
+	return rest[:n], nil
+}
+
+// fsFile is an open file. A plain value is held in data, whereas a blob is read a chunk at a time (keeping the last
+// one) so that it can be seeked around, as http.ServeContent does for range requests.
+type fsFile struct {
+	fs    *FS
+	name  string
+	info  fileInfo
+	data  []byte
+	blob  *BlobInfo
+	off   int64
+	chunk []byte
+	index int
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *fsFile) Close() error               { return nil }
+
+func (f *fsFile) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.off)
+	f.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += f.info.size
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *fsFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.info.size {
+		return 0, io.EOF
+	}
+	if f.blob == nil {
+		n := copy(p, f.data[off:])
+		if n < len(p) {
+			return n, io.EOF
+		}
+		return n, nil
+	}
+
+	total := 0
+	for total < len(p) && off < f.info.size {
+		chunk, err := f.readChunk(int(off / int64(f.blob.ChunkSize)))
+		if err != nil {
+			return total, &fs.PathError{Op: "read", Path: f.name, Err: err}
+		}
+		n := copy(p[total:], chunk[off%int64(f.blob.ChunkSize):])
+		total += n
+		off += int64(n)
+	}
+	if total < len(p) {
+		return total, io.EOF
+	}
+	return total, nil
+}
+
+// readChunk returns chunk i of the blob, checking that the blob is still the one which was opened.
+func (f *fsFile) readChunk(i int) ([]byte, error) {
+	if f.chunk != nil && f.index == i {
+		return f.chunk, nil
+	}
+
+	err := f.fs.db.View(func(tx *bolt.Tx) error {
+		info, err := StatBlob(tx, f.fs.location, f.name)
+		if err != nil && !errors.Is(err, ErrNotBlob) {
+			return err
+		}
+		if info == nil || info.SHA256 != f.blob.SHA256 {
+			return ErrBlobChanged
+		}
+
+		chunk, err := Get(tx, blobChunksLocation(f.fs.location), blobChunkKey(f.name, i))
+		if err != nil {
+			return err
+		}
+		if chunk == nil {
+			return ErrBlobCorrupt
+		}
+		f.chunk = copyBytes(chunk)
+		f.index = i
+		return nil
+	})
+	return f.chunk, err
+}
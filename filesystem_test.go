@@ -0,0 +1,99 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestFileSystem(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	video := make([]byte, 200<<10)
+	rand.New(rand.NewSource(1)).Read(video)
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "static", "index.html", "<h1>Hello</h1>"))
+		check(PutString(tx, "static", "css/site.css", "body {}"))
+		check(PutString(tx, "static", "css/print/a4.css", "@page {}"))
+		check(PutString(tx, "static", "css-notes.txt", "notes"))
+		return PutBlob(tx, "static", "uploads/video.bin", bytes.NewReader(video), BlobChunkSize(16<<10))
+	}))
+
+	fsys := FileSystem(db, "static")
+
+	t.Run("fs.FS", func(t *testing.T) {
+		check(fstest.TestFS(fsys, "index.html", "css/site.css", "css/print/a4.css", "css-notes.txt", "uploads/video.bin"))
+
+		got, err := fs.ReadFile(fsys, "uploads/video.bin")
+		check(err)
+		if !bytes.Equal(got, video) {
+			t.Fatal("The blob should read back as it was put")
+		}
+
+		entries, err := fs.ReadDir(fsys, ".")
+		check(err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if len(names) != 4 || names[0] != "css" || names[1] != "css-notes.txt" || names[2] != "index.html" || names[3] != "uploads" {
+			t.Fatalf("Unexpected entries: %v", names)
+		}
+
+		_, err = fsys.Open("missing.txt")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+		}
+	})
+
+	t.Run("http.FileSystem", func(t *testing.T) {
+		srv := httptest.NewServer(http.FileServer(fsys.HTTP()))
+		defer srv.Close()
+
+		req, err := http.NewRequest("GET", srv.URL+"/uploads/video.bin", nil)
+		check(err)
+		req.Header.Set("Range", "bytes=16000-40000")
+		res, err := http.DefaultClient.Do(req)
+		check(err)
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		check(err)
+		if res.StatusCode != http.StatusPartialContent || !bytes.Equal(body, video[16000:40001]) {
+			t.Fatalf("A range across chunks should be served, got %d with %d bytes", res.StatusCode, len(body))
+		}
+
+		res, err = http.Get(srv.URL + "/css/site.css")
+		check(err)
+		body, err = io.ReadAll(res.Body)
+		res.Body.Close()
+		check(err)
+		if string(body) != "body {}" {
+			t.Fatalf("Unexpected body: %q", body)
+		}
+	})
+
+	t.Run("A blob replaced while it is open", func(t *testing.T) {
+		f, err := fsys.Open("uploads/video.bin")
+		check(err)
+		defer f.Close()
+
+		check(db.Update(func(tx *bolt.Tx) error {
+			return PutBlob(tx, "static", "uploads/video.bin", bytes.NewReader([]byte("replaced")))
+		}))
+
+		_, err = io.ReadAll(f)
+		if !errors.Is(err, ErrBlobChanged) {
+			t.Fatalf("Expected ErrBlobChanged, got %v", err)
+		}
+	})
+}
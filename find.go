@@ -0,0 +1,152 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Filter decides whether a raw value should be included in the results of Find.
+type Filter func(raw []byte) bool
+
+// Find is like All except that only the values for which filter returns true are decoded into `to` (which must be a
+// pointer to a slice). Since the filter gets the raw value you can skip decoding records you aren't interested in
+// altogether. Nested buckets are skipped.
+//
+//	var admins []User
+//	err := rod.Find(tx, "user", func(raw []byte) bool {
+//	    return bytes.Contains(raw, []byte(`"admin":true`))
+//	}, &admins)
+//
+// For the common case of comparing a single field, use Where:
+//
+//	var regulars []User
+//	err := rod.Find(tx, "user", rod.Where("Logins", ">", 10), &regulars)
+//
+// The same sort options as All can be given.
+func Find(tx Tx, location string, filter func(raw []byte) bool, to interface{}, opts ...QueryOption) error {
+	results, err := newSliceBuilder(tx, to, opts)
+	if err != nil {
+		return wrapErr("Find", location, "", err)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("Find", location, "", err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	scan := startScan(tx, location)
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		scan.item(v)
+		if !filter(v) {
+			continue
+		}
+		if err := results.append(string(k), v); err != nil {
+			return scan.done(wrapErr("Find", location, string(k), err))
+		}
+	}
+
+	return scan.done(wrapErr("Find", location, "", results.set()))
+}
+
+// Where returns a Filter which compares the JSON field at path (as per GetJsonField) with value. The op is one of "=",
+// "!=", "<", "<=", ">" or ">=". Numbers are compared numerically and strings lexically, whereas other types (bools,
+// null, objects and arrays) may only use "=" and "!=". A record which doesn't have the field never matches (other than
+// for "!="), and neither does one where the types are different.
+//
+// Where panics if op is unknown or the path is invalid, since that is a programming error rather than a data one.
+func Where(path, op string, value interface{}) Filter {
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=":
+	default:
+		panic(fmt.Sprintf("rod: unknown Where operator %q", op))
+	}
+
+	segments, err := parseFieldPath(path)
+	if err != nil {
+		panic("rod: " + err.Error())
+	}
+
+	// normalise value by putting it through JSON, so it looks the same as whatever we compare it to
+	raw, err := json.Marshal(value)
+	if err != nil {
+		panic("rod: " + err.Error())
+	}
+	want, err := decodeJson(raw)
+	if err != nil {
+		panic("rod: " + err.Error())
+	}
+
+	return func(doc []byte) bool {
+		field, err := jsonField(doc, segments)
+		if err != nil || field == nil {
+			return op == "!="
+		}
+		got, err := decodeJson(field)
+		if err != nil {
+			return false
+		}
+
+		cmp, ok := compareJson(got, want)
+		if !ok {
+			return op == "!="
+		}
+
+		switch op {
+		case "=":
+			return cmp == 0
+		case "!=":
+			return cmp != 0
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		}
+		return cmp >= 0
+	}
+}
+
+// compareJson compares two decoded JSON values, returning -1, 0 or 1. Only numbers and strings can be ordered, so for
+// anything else the result is 0 if they are equal and 1 if not. If the two values are of different types then ok is
+// false.
+func compareJson(a, b interface{}) (cmp int, ok bool) {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return 0, false
+		}
+		af, errA := av.Float64()
+		bf, errB := bv.Float64()
+		if errA != nil || errB != nil {
+			return 0, false
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare([]byte(av), []byte(bv)), true
+	}
+
+	if jsonEqual(a, b) {
+		return 0, true
+	}
+	return 1, true
+}
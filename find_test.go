@@ -0,0 +1,102 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestFind(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 3}))
+		check(PutJson(tx, "user", "bob", User{"bob", 12}))
+		check(PutJson(tx, "user", "carol", User{"carol", 20}))
+		check(PutJson(tx, "user", "dave", map[string]interface{}{"Username": "dave"}))
+		check(PutString(tx, "user.nested", "key", "value"))
+		return nil
+	})
+	check(err)
+
+	t.Run("Find with a function", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var users []User
+			check(Find(tx, "user", func(raw []byte) bool {
+				return bytes.Contains(raw, []byte(`"bob"`))
+			}, &users))
+			if len(users) != 1 || users[0].Username != "bob" {
+				t.Fatalf("Expected just bob but got %#v", users)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Find with Where", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			tests := []struct {
+				field string
+				op    string
+				value interface{}
+				want  int
+			}{
+				{"Logins", ">", 10, 2},
+				{"Logins", ">=", 12, 2},
+				{"Logins", "<", 12, 1},
+				{"Logins", "<=", 12, 2},
+				{"Logins", "=", 3, 1},
+				{"Logins", "!=", 3, 3},
+				{"Username", "=", "carol", 1},
+				{"Username", ">", "b", 3},
+				{"Username", "=", 3, 0},
+				{"Missing", "=", 3, 0},
+			}
+
+			for _, test := range tests {
+				var users []*User
+				check(Find(tx, "user", Where(test.field, test.op, test.value), &users))
+				if len(users) != test.want {
+					t.Fatalf("Where(%q, %q, %v) should have found %d users but found %d", test.field, test.op, test.value, test.want, len(users))
+				}
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Find on a missing bucket", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var users []User
+			check(Find(tx, "does-not-exist", Where("Logins", ">", 0), &users))
+			if users != nil {
+				t.Fatal("Nothing should be found in a missing bucket")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Find with a bad target", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var user User
+			if err := Find(tx, "user", Where("Logins", ">", 0), &user); !errors.Is(err, ErrSlicePtrNeeded) {
+				t.Fatalf("Expected ErrSlicePtrNeeded but got %v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Where with a bad operator", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Where should panic with an unknown operator")
+			}
+		}()
+		Where("Logins", "~", 1)
+	})
+}
@@ -0,0 +1,170 @@
+package rod
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+// The kinds of Flag.
+const (
+	// FlagBool is on for everyone when it is enabled.
+	FlagBool = "bool"
+	// FlagPercentage is on for a fixed Percentage of subjects when it is enabled. Each subject always gets the same
+	// answer for the same flag, and stays on as the percentage is raised.
+	FlagPercentage = "percentage"
+	// FlagTargeted is on for the subjects in Targets when it is enabled.
+	FlagTargeted = "targeted"
+)
+
+// Flag is the definition of a feature flag, as kept by Flags.
+type Flag struct {
+	Name       string
+	Kind       string
+	Enabled    bool
+	Percentage int      // for FlagPercentage, from 0 to 100
+	Targets    []string // for FlagTargeted
+}
+
+// On returns whether the flag is on for subject (such as a user's ID).
+func (f *Flag) On(subject string) bool {
+	if f == nil || !f.Enabled {
+		return false
+	}
+
+	switch f.Kind {
+	case FlagBool:
+		return true
+	case FlagPercentage:
+		h := fnv.New32a()
+		h.Write([]byte(f.Name + "\x00" + subject))
+		return int(h.Sum32()%100) < f.Percentage
+	case FlagTargeted:
+		return containsString(f.Targets, subject)
+	}
+	return false
+}
+
+// Flags keeps the feature flags defined at a location, with a copy of them all in memory so that checking one doesn't
+// need a transaction. The copy is kept up to date with Store.Watch, so a flag changed with Set (or with rod inside any
+// of the Store's transactions) takes effect as soon as it is committed.
+//
+//	flags, err := rod.NewFlags(store, "flags")
+//	err = store.Update(func(tx *bolt.Tx) error {
+//	    return flags.Set(tx, rod.Flag{Name: "new-checkout", Kind: rod.FlagPercentage, Enabled: true, Percentage: 10})
+//	})
+//	if flags.On("new-checkout", user.ID) {
+//	    ...
+//	}
+type Flags struct {
+	store    *Store
+	location string
+
+	mu        sync.RWMutex
+	flags     map[string]*Flag
+	listeners []func(name string, f *Flag)
+}
+
+// NewFlags returns the Flags at location, having read them all in. Since it watches the location, call NewFlags before
+// using the Store, as with Watch.
+func NewFlags(s *Store, location string) (*Flags, error) {
+	f := &Flags{store: s, location: location}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	s.Watch(location, f.changed)
+	return f, nil
+}
+
+// Reload reads every flag in again, such as after they have been changed outside the Store.
+func (f *Flags) Reload() error {
+	flags := map[string]*Flag{}
+	err := f.store.View(func(tx *bolt.Tx) error {
+		return EachJson(tx, f.location, func(name string, flag Flag) error {
+			flags[name] = &flag
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.flags = flags
+	f.mu.Unlock()
+	return nil
+}
+
+// Set defines (or redefines) the flag called flag.Name.
+func (f *Flags) Set(tx Tx, flag Flag) error {
+	if flag.Name == "" {
+		return wrapErr("Set", f.location, "", ErrKeyNotProvided)
+	}
+	return wrapErr("Set", f.location, flag.Name, PutJson(tx, f.location, flag.Name, flag))
+}
+
+// Delete removes the flag called name, which is then off for everyone.
+func (f *Flags) Delete(tx Tx, name string) error {
+	return wrapErr("Delete", f.location, name, Del(tx, f.location, name))
+}
+
+// Get returns the flag called name, or nil if it isn't defined.
+func (f *Flags) Get(name string) *Flag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if flag, ok := f.flags[name]; ok {
+		c := *flag
+		return &c
+	}
+	return nil
+}
+
+// On returns whether the flag called name is on for subject. A flag which isn't defined is always off.
+func (f *Flags) On(name, subject string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.flags[name].On(subject)
+}
+
+// OnChange calls fn whenever a flag is changed, with its new definition, or nil if it has been deleted. Like Watch, fn
+// is called on the goroutine whose Update made the change.
+func (f *Flags) OnChange(fn func(name string, flag *Flag)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, fn)
+}
+
+// changed is the watcher which keeps the flags in memory up to date.
+func (f *Flags) changed(m Mutation) {
+	if m.Location != f.location {
+		return
+	}
+
+	var flag *Flag
+	if m.After != nil {
+		flag = &Flag{}
+		if err := json.Unmarshal(m.After, flag); err != nil {
+			return
+		}
+	}
+
+	f.mu.Lock()
+	if flag == nil {
+		delete(f.flags, m.Key)
+	} else {
+		f.flags[m.Key] = flag
+	}
+	listeners := f.listeners
+	f.mu.Unlock()
+
+	for _, fn := range listeners {
+		if flag == nil {
+			fn(m.Key, nil)
+			continue
+		}
+		c := *flag
+		fn(m.Key, &c)
+	}
+}
@@ -0,0 +1,72 @@
+package rod
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestFlags(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		return PutJson(tx, "flags", "dark-mode", Flag{Name: "dark-mode", Kind: FlagBool, Enabled: true})
+	}))
+
+	s := NewStore(db)
+	flags, err := NewFlags(s, "flags")
+	check(err)
+
+	var changes []string
+	flags.OnChange(func(name string, flag *Flag) {
+		changes = append(changes, fmt.Sprintf("%s %v", name, flag != nil))
+	})
+
+	t.Run("Existing flags are read in", func(t *testing.T) {
+		if !flags.On("dark-mode", "anyone") || flags.On("missing", "anyone") {
+			t.Fatal("dark-mode should be on and a missing flag off")
+		}
+	})
+
+	t.Run("Percentage and targeted flags", func(t *testing.T) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			check(flags.Set(tx, Flag{Name: "checkout", Kind: FlagPercentage, Enabled: true, Percentage: 25}))
+			return flags.Set(tx, Flag{Name: "beta", Kind: FlagTargeted, Enabled: true, Targets: []string{"chilts"}})
+		}))
+
+		on := 0
+		for i := 0; i < 1000; i++ {
+			subject := fmt.Sprintf("user-%d", i)
+			if flags.On("checkout", subject) {
+				on++
+			}
+			if flags.On("checkout", subject) != flags.On("checkout", subject) {
+				t.Fatal("A subject should always get the same answer")
+			}
+		}
+		if on < 200 || on > 300 {
+			t.Fatalf("About 25%% of subjects should have checkout on, not %d in 1000", on)
+		}
+
+		if !flags.On("beta", "chilts") || flags.On("beta", "someone-else") {
+			t.Fatal("beta should only be on for its target")
+		}
+	})
+
+	t.Run("Changes and notifications", func(t *testing.T) {
+		changes = nil
+		check(s.Update(func(tx *bolt.Tx) error {
+			check(flags.Set(tx, Flag{Name: "dark-mode", Kind: FlagBool, Enabled: false}))
+			return flags.Delete(tx, "beta")
+		}))
+
+		if flags.On("dark-mode", "anyone") || flags.On("beta", "chilts") || flags.Get("beta") != nil {
+			t.Fatal("The changes should have taken effect")
+		}
+		if len(changes) != 2 || changes[0] != "dark-mode true" || changes[1] != "beta false" {
+			t.Fatalf("Unexpected notifications: %v", changes)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package rod
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrReadOnlyReplica is returned when writing to the Store of a FollowerStore, since only replication may change it.
+var ErrReadOnlyReplica = errors.New("read-only replica")
+
+// FollowerStore is a read replica of another Store, kept up to date by replicating its changelog. Reads can be served
+// from the follower, but local writes are rejected with ErrReadOnlyReplica so it can't drift from its leader.
+//
+//	leader.EnableChangelog()
+//	follower := rod.NewFollowerStore(leader, replica)
+//	go follower.Follow(ctx)
+//
+//	err := follower.View(func(tx *bolt.Tx) error {
+//	    return rod.GetJson(tx, "user", "chilts", &user)
+//	})
+type FollowerStore struct {
+	leader *Store
+	store  *Store
+}
+
+// Lag is how far a FollowerStore is behind its leader.
+type Lag struct {
+	// Changes is how many of the leader's changes haven't been applied yet.
+	Changes uint64
+	// Behind is how long ago the oldest change which hasn't been applied yet was made, or 0 if there isn't one.
+	Behind time.Duration
+}
+
+// NewFollowerStore makes store a replica of leader. From then on, store's Update and UpdateAs return
+// ErrReadOnlyReplica, wherever they are called from.
+func NewFollowerStore(leader, store *Store) *FollowerStore {
+	store.replica = true
+	return &FollowerStore{leader: leader, store: store}
+}
+
+// Store returns the follower's own Store.
+func (f *FollowerStore) Store() *Store {
+	return f.store
+}
+
+// Follow replicates the leader's changes to the follower until ctx is done, as with Replicate.
+func (f *FollowerStore) Follow(ctx context.Context, opts ...ReplicateOption) error {
+	return Replicate(ctx, f.leader, f.store, opts...)
+}
+
+// Update always returns ErrReadOnlyReplica.
+func (f *FollowerStore) Update(fn func(tx *bolt.Tx) error) error {
+	return ErrReadOnlyReplica
+}
+
+// View runs fn inside a read-only transaction on the follower.
+func (f *FollowerStore) View(fn func(tx *bolt.Tx) error) error {
+	return f.store.View(fn)
+}
+
+// Lag reports how far the follower is behind the leader.
+func (f *FollowerStore) Lag() (Lag, error) {
+	applied, err := ReplicationCheckpoint(f.store)
+	if err != nil {
+		return Lag{}, err
+	}
+
+	var lag Lag
+	err = f.leader.View(func(tx *bolt.Tx) error {
+		key, _, err := Last(tx, ChangelogBucket)
+		if err != nil || key == "" {
+			return err
+		}
+		last, err := ParseU64Key(key)
+		if err != nil || last <= applied {
+			return err
+		}
+		lag.Changes = last - applied
+
+		next, err := ReadChanges(tx, applied, 1)
+		if err != nil || len(next) == 0 {
+			return err
+		}
+		lag.Behind = now().Sub(next[0].Time)
+		return nil
+	})
+	return lag, err
+}
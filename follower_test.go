@@ -0,0 +1,92 @@
+package rod
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestFollowerStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	leader, err := Open(filepath.Join(dir, "leader.db"), 0666, nil)
+	check(err)
+	defer leader.Close()
+	leader.EnableChangelog()
+
+	replica, err := Open(filepath.Join(dir, "replica.db"), 0666, nil)
+	check(err)
+	defer replica.Close()
+
+	follower := NewFollowerStore(leader, replica)
+
+	t.Run("Local writes are rejected", func(t *testing.T) {
+		write := func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "name", "local")
+		}
+		if err := follower.Update(write); err != ErrReadOnlyReplica {
+			t.Fatalf("Expected ErrReadOnlyReplica but got %v", err)
+		}
+		if err := replica.Update(write); err != ErrReadOnlyReplica {
+			t.Fatalf("Expected ErrReadOnlyReplica from the replica's own Store but got %v", err)
+		}
+	})
+
+	t.Run("Lag", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		now = func() time.Time { return time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+		err := leader.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "config", "name", "leader"))
+			return PutString(tx, "config", "colour", "blue")
+		})
+		check(err)
+
+		now = func() time.Time { return time.Date(2020, 1, 1, 12, 0, 30, 0, time.UTC) }
+		lag, err := follower.Lag()
+		check(err)
+		if lag.Changes != 2 || lag.Behind != 30*time.Second {
+			t.Fatalf("Expected to be 2 changes and 30s behind but got %#v", lag)
+		}
+	})
+
+	t.Run("Follow", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- follower.Follow(ctx, PollInterval(5*time.Millisecond))
+		}()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			lag, err := follower.Lag()
+			check(err)
+			if lag.Changes == 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("The follower didn't catch up: %#v", lag)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		cancel()
+		<-done
+
+		err := follower.View(func(tx *bolt.Tx) error {
+			name, err := GetString(tx, "config", "name")
+			check(err)
+			if name != "leader" {
+				t.Fatalf("Expected the leader's name but got '%s'", name)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
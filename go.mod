@@ -0,0 +1,16 @@
+module github.com/chilts/rod
+
+go 1.23
+
+require (
+	github.com/boltdb/bolt v1.3.1
+	github.com/cockroachdb/pebble v1.1.2
+	github.com/dgraph-io/badger/v4 v4.5.0
+	github.com/gorilla/securecookie v1.1.2
+	github.com/gorilla/sessions v1.4.0
+	github.com/prometheus/client_golang v1.20.5
+	go.etcd.io/bbolt v1.4.0
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/sdk v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+)
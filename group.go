@@ -0,0 +1,51 @@
+package rod
+
+// GroupBy walks the bucket at location in key order, using keyFn to work out which group each value belongs to, and
+// calls fn with each group in turn. Groups are made from consecutive values, so only one group is held in memory at a
+// time. This works best when the group follows from the key, e.g. events with time-ordered keys grouped by day:
+//
+//	err := rod.GroupBy(tx, "event", func(raw []byte) string {
+//	    var ev Event
+//	    json.Unmarshal(raw, &ev)
+//	    return ev.Time.Format("2006-01-02")
+//	}, func(day string, events [][]byte) error {
+//	    fmt.Printf("%s: %d events\n", day, len(events))
+//	    return nil
+//	})
+//
+// If values from the same group aren't next to each other then fn will be called more than once for that group. As
+// with Each, returning Stop from fn halts the walk and nil is returned. The values are only valid for the life of the
+// transaction.
+func GroupBy(tx Tx, location string, keyFn func(raw []byte) string, fn func(group string, items [][]byte) error) error {
+	var (
+		group   string
+		items   [][]byte
+		stopped bool
+	)
+
+	err := Each(tx, location, func(key string, value []byte) error {
+		g := keyFn(value)
+		if items != nil && g != group {
+			if err := fn(group, items); err != nil {
+				stopped = err == Stop
+				return err
+			}
+			items = nil
+		}
+		group = g
+		items = append(items, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// and the final group
+	if items != nil && !stopped {
+		if err := fn(group, items); err != nil && err != Stop {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package rod
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type Event struct {
+	Day  string
+	Name string
+}
+
+func TestGroupBy(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "event", "2017-01-01T09:00", Event{"2017-01-01", "breakfast"}))
+		check(PutJson(tx, "event", "2017-01-01T13:00", Event{"2017-01-01", "lunch"}))
+		check(PutJson(tx, "event", "2017-01-02T09:00", Event{"2017-01-02", "breakfast"}))
+		check(PutJson(tx, "event", "2017-01-03T09:00", Event{"2017-01-03", "breakfast"}))
+		check(PutJson(tx, "event", "2017-01-03T19:00", Event{"2017-01-03", "dinner"}))
+		check(PutJson(tx, "event", "2017-01-03T23:00", Event{"2017-01-03", "supper"}))
+		return nil
+	})
+	check(err)
+
+	byDay := func(raw []byte) string {
+		var ev Event
+		check(json.Unmarshal(raw, &ev))
+		return ev.Day
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		var groups []string
+		check(GroupBy(tx, "event", byDay, func(day string, items [][]byte) error {
+			groups = append(groups, day+"="+string(rune('0'+len(items))))
+			return nil
+		}))
+		if strings.Join(groups, ",") != "2017-01-01=2,2017-01-02=1,2017-01-03=3" {
+			t.Fatalf("Unexpected groups: %v", groups)
+		}
+
+		// stopping after the first group
+		groups = nil
+		check(GroupBy(tx, "event", byDay, func(day string, items [][]byte) error {
+			groups = append(groups, day)
+			return Stop
+		}))
+		if len(groups) != 1 {
+			t.Fatalf("Expected just one group but got %v", groups)
+		}
+
+		// nothing at all
+		check(GroupBy(tx, "does-not-exist", byDay, func(day string, items [][]byte) error {
+			t.Fatal("fn should not be called for a missing bucket")
+			return nil
+		}))
+
+		return nil
+	})
+	check(err)
+}
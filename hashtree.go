@@ -0,0 +1,63 @@
+package rod
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+)
+
+// HashTree returns a hex SHA-256 over every key and value in the bucket at location, including those in its nested
+// buckets. Each nested bucket is hashed on its own and only its hash goes into its parent's, Merkle style, so two
+// subtrees with the same data always hash the same however they were written. A location which doesn't exist hashes
+// the same as an empty one. Comparing the hashes of two stores (or a store and its backup) is a cheap way to find out
+// whether a full diff is worth doing.
+//
+//	a, err := rod.HashTree(txA, "users")
+//	b, err := rod.HashTree(txB, "users")
+//	if a != b {
+//	    // they differ
+//	}
+func HashTree(tx Tx, location string) (string, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return "", wrapErr("HashTree", location, "", err)
+	}
+	return hex.EncodeToString(hashBucket(b)), nil
+}
+
+// hashBucket hashes the hash of each entry in b, in key order. A nil b hashes as an empty bucket.
+func hashBucket(b Bucket) []byte {
+	node := sha256.New()
+	if b == nil {
+		return node.Sum(nil)
+	}
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		entry := sha256.New()
+		if v == nil {
+			if nested := b.Bucket(k); nested != nil {
+				// a nested bucket is tagged so it can't collide with a value holding its hash
+				entry.Write([]byte{1})
+				writeLen(entry, k)
+				entry.Write(hashBucket(nested))
+				node.Write(entry.Sum(nil))
+				continue
+			}
+		}
+		entry.Write([]byte{0})
+		writeLen(entry, k)
+		writeLen(entry, v)
+		node.Write(entry.Sum(nil))
+	}
+	return node.Sum(nil)
+}
+
+// writeLen writes the length of p before p itself, so that no two different keys and values run together the same.
+func writeLen(h io.Writer, p []byte) {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(p)))
+	h.Write(n[:])
+	h.Write(p)
+}
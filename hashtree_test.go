@@ -0,0 +1,78 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestHashTree(t *testing.T) {
+	a, doneA := openTestDB(t)
+	defer doneA()
+	b, doneB := openTestDB(t)
+	defer doneB()
+
+	hashes := func(location string) (string, string) {
+		var ha, hb string
+		check(a.View(func(tx *bolt.Tx) error {
+			var err error
+			ha, err = HashTree(tx, location)
+			return err
+		}))
+		check(b.View(func(tx *bolt.Tx) error {
+			var err error
+			hb, err = HashTree(tx, location)
+			return err
+		}))
+		return ha, hb
+	}
+
+	t.Run("Missing and empty locations", func(t *testing.T) {
+		check(a.Update(func(tx *bolt.Tx) error {
+			_, err := createBucket(tx, "users")
+			return err
+		}))
+		if ha, hb := hashes("users"); ha != hb {
+			t.Fatalf("An empty location should hash the same as a missing one: %s vs %s", ha, hb)
+		}
+	})
+
+	t.Run("The same data in a different order", func(t *testing.T) {
+		check(a.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "users", "alice", "1"))
+			check(PutString(tx, "users", "bob", "2"))
+			return PutString(tx, "users.admin", "carol", "3")
+		}))
+		check(b.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "users.admin", "carol", "3"))
+			check(PutString(tx, "users", "bob", "2"))
+			return PutString(tx, "users", "alice", "1")
+		}))
+		if ha, hb := hashes("users"); ha != hb || len(ha) != 64 {
+			t.Fatalf("The same data should hash the same: %s vs %s", ha, hb)
+		}
+	})
+
+	t.Run("Differences", func(t *testing.T) {
+		check(b.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "users.admin", "carol", "4")
+		}))
+		if ha, hb := hashes("users"); ha == hb {
+			t.Fatal("A change in a nested bucket should change the hash")
+		}
+		if ha, hb := hashes("users.admin"); ha == hb {
+			t.Fatal("A change should change the hash of its own bucket")
+		}
+
+		// keys and values shouldn't run together
+		check(a.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "joined", "ab", "c")
+		}))
+		check(b.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "joined", "a", "bc")
+		}))
+		if ha, hb := hashes("joined"); ha == hb {
+			t.Fatal("Moving bytes between the key and value should change the hash")
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package rod
+
+import (
+	"math"
+	"sort"
+)
+
+// histogramBounds are the largest size in each SizeBucket, in powers of 4 from 64 bytes to 16MB, with the last taking
+// everything bigger.
+var histogramBounds = []int{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, math.MaxInt}
+
+// histogramLargest is how many of the largest values SizeHistogram keeps.
+const histogramLargest = 10
+
+// Histogram is the distribution of the sizes of the keys and values in a location, as returned by SizeHistogram.
+type Histogram struct {
+	// Count is the number of values, and Total their size in bytes.
+	Count int
+	Total int64
+	// Keys and Values count the sizes of each, with a SizeBucket for every size range whether or not it has any.
+	Keys   []SizeBucket
+	Values []SizeBucket
+	// Largest holds the keys of the largest values, largest first.
+	Largest []KeySize
+}
+
+// SizeBucket counts the sizes which are more than the previous bucket's Max, up to and including this one's.
+type SizeBucket struct {
+	Max   int
+	Count int
+	Bytes int64
+}
+
+// KeySize is the size of the value at Key.
+type KeySize struct {
+	Key  string
+	Size int
+}
+
+// SizeHistogram counts the sizes of the keys and values in the bucket at location (not including nested buckets) in
+// ranges from 64 bytes up to 16MB, and finds the largest values, so you can find the few huge documents which take up
+// most of the pages. Any value larger than a page (usually 4KB) needs overflow pages of its own.
+//
+//	h, err := rod.SizeHistogram(tx, "documents")
+//	for _, ks := range h.Largest {
+//	    fmt.Printf("%s is %d bytes\n", ks.Key, ks.Size)
+//	}
+func SizeHistogram(tx Tx, location string) (*Histogram, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return nil, wrapErr("SizeHistogram", location, "", err)
+	}
+
+	h := &Histogram{Keys: newSizeBuckets(), Values: newSizeBuckets()}
+	if b == nil {
+		return h, nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		h.Count++
+		h.Total += int64(len(v))
+		countSize(h.Keys, len(k))
+		countSize(h.Values, len(v))
+		h.addLargest(string(k), len(v))
+	}
+	return h, nil
+}
+
+func newSizeBuckets() []SizeBucket {
+	buckets := make([]SizeBucket, len(histogramBounds))
+	for i, max := range histogramBounds {
+		buckets[i].Max = max
+	}
+	return buckets
+}
+
+func countSize(buckets []SizeBucket, size int) {
+	i := sort.Search(len(buckets), func(i int) bool {
+		return buckets[i].Max >= size
+	})
+	buckets[i].Count++
+	buckets[i].Bytes += int64(size)
+}
+
+// addLargest keeps key among the largest values if it is big enough.
+func (h *Histogram) addLargest(key string, size int) {
+	if len(h.Largest) == histogramLargest && size <= h.Largest[len(h.Largest)-1].Size {
+		return
+	}
+	i := sort.Search(len(h.Largest), func(i int) bool {
+		return h.Largest[i].Size < size
+	})
+	h.Largest = append(h.Largest, KeySize{})
+	copy(h.Largest[i+1:], h.Largest[i:])
+	h.Largest[i] = KeySize{Key: key, Size: size}
+	if len(h.Largest) > histogramLargest {
+		h.Largest = h.Largest[:histogramLargest]
+	}
+}
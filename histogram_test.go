@@ -0,0 +1,73 @@
+package rod
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		for i := 0; i < 100; i++ {
+			check(PutString(tx, "docs", fmt.Sprintf("small-%03d", i), "tiny"))
+		}
+		for i := 0; i < 12; i++ {
+			check(PutString(tx, "docs", fmt.Sprintf("big-%02d", i), strings.Repeat("x", 5000+i)))
+		}
+		check(PutString(tx, "docs", "huge", strings.Repeat("x", 5<<20)))
+		_, err := createBucket(tx, "docs.nested")
+		return err
+	}))
+
+	t.Run("Buckets", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			h, err := SizeHistogram(tx, "docs")
+			check(err)
+			if h.Count != 113 || len(h.Values) != len(histogramBounds) {
+				t.Fatalf("Unexpected histogram %#v", h)
+			}
+
+			counts := map[int]int{}
+			for _, b := range h.Values {
+				counts[b.Max] = b.Count
+			}
+			if counts[64] != 100 || counts[16<<10] != 12 || counts[16<<20] != 1 || counts[4<<10] != 0 {
+				t.Fatalf("Unexpected counts %v", counts)
+			}
+			if h.Values[0].Bytes != 400 || h.Keys[0].Count != 113 {
+				t.Fatalf("Unexpected first buckets %#v and %#v", h.Values[0], h.Keys[0])
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Largest", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			h, err := SizeHistogram(tx, "docs")
+			check(err)
+			if len(h.Largest) != histogramLargest {
+				t.Fatalf("Expected %d of the largest but got %d", histogramLargest, len(h.Largest))
+			}
+			if h.Largest[0].Key != "huge" || h.Largest[1].Key != "big-11" || h.Largest[9].Key != "big-03" {
+				t.Fatalf("Unexpected largest %v", h.Largest)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Missing location", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			h, err := SizeHistogram(tx, "nope")
+			check(err)
+			if h.Count != 0 || h.Largest != nil {
+				t.Fatalf("Unexpected histogram %#v", h)
+			}
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,111 @@
+package rod
+
+import (
+	"sync"
+	"time"
+)
+
+// Mutation describes a single change to a key, as told to the hooks on a Store.
+type Mutation struct {
+	// Op is either "put" or "del".
+	Op       string
+	Location string
+	Key      string
+	// Before is the value before the change, or nil if the key didn't exist.
+	Before []byte
+	// After is the value after the change, or nil for a delete.
+	After []byte
+
+	// namespace is the location of the Namespace the change was made in, if any, which Location is already beneath.
+	namespace string
+}
+
+// hook is called inside the transaction after every mutation made within one of the Store's transactions. Returning
+// an error fails the write which caused it.
+type hook func(tx Tx, st *txState, m *Mutation) error
+
+// txState is what we know about a transaction which was started by a Store with hooks.
+type txState struct {
+	store *Store
+	actor string
+	// origin is the ID of the store the changes being made were first made in, when they are being replicated or
+	// synced from elsewhere.
+	origin string
+	// watched are the mutations made so far which a watcher is interested in, to tell it about after the commit.
+	watched []Mutation
+	// uncached are the keys written to, to remove from the Store's cache after the commit.
+	uncached []cacheKey
+}
+
+// txStates maps each Tx started by a Store with hooks to its *txState, for as long as the transaction is open. This
+// lets the package functions, which are only given the Tx, find the hooks to call.
+var txStates sync.Map
+
+func stateOf(tx Tx) *txState {
+	if t, ok := tx.(*namespaceTx); ok {
+		tx = t.tx
+	}
+	st, ok := txStates.Load(tx)
+	if !ok {
+		return nil
+	}
+	return st.(*txState)
+}
+
+// putKey puts value at key in b (which is at location), and tells any hooks about it.
+func putKey(tx Tx, b Bucket, location string, key, value []byte) error {
+	st := stateOf(tx)
+	if st == nil {
+		return b.Put(key, value)
+	}
+	if st.store.readOnly {
+		return ErrReadOnlyStore
+	}
+
+	root, location, namespace := rooted(tx, location)
+	before := copyBytes(b.Get(key))
+	start := time.Now()
+	err := b.Put(key, value)
+	st.store.observe(Operation{Name: "put", Location: location, Key: string(key), Tx: root, Bytes: len(value), Start: start, Duration: time.Since(start), Err: err})
+	if err != nil {
+		return err
+	}
+	return st.mutated(root, &Mutation{Op: "put", Location: location, Key: string(key), Before: before, After: copyBytes(value), namespace: namespace})
+}
+
+// delKey deletes key from b (which is at location), and tells any hooks about it if the key existed.
+func delKey(tx Tx, b Bucket, location string, key []byte) error {
+	st := stateOf(tx)
+	if st == nil {
+		return b.Delete(key)
+	}
+	if st.store.readOnly {
+		return ErrReadOnlyStore
+	}
+
+	root, location, namespace := rooted(tx, location)
+	before := copyBytes(b.Get(key))
+	start := time.Now()
+	err := b.Delete(key)
+	st.store.observe(Operation{Name: "del", Location: location, Key: string(key), Tx: root, Start: start, Duration: time.Since(start), Err: err})
+	if err != nil || before == nil {
+		return err
+	}
+	return st.mutated(root, &Mutation{Op: "del", Location: location, Key: string(key), Before: before, namespace: namespace})
+}
+
+func (st *txState) mutated(tx Tx, m *Mutation) error {
+	for _, h := range st.store.hooks {
+		if err := h(tx, st, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,98 @@
+package rod
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// HTTPCache is a cache for the responses to outbound HTTP requests kept at a location, which implements the Cache
+// interface of github.com/gregjones/httpcache (Get, Set and Delete by string key) so that its transport can use it, and
+// the cache survives restarts:
+//
+//	cache := rod.NewHTTPCache(store, "httpcache", 24*time.Hour)
+//	client := &http.Client{Transport: httpcache.NewTransport(cache)}
+//
+// Since the Cache interface has no way to return an error, a response which can't be read is a miss, and one which
+// can't be written just isn't cached.
+type HTTPCache struct {
+	store    *Store
+	location string
+	ttl      time.Duration
+}
+
+// NewHTTPCache returns the HTTPCache at location. Responses are kept for ttl, or until they are replaced if ttl is 0.
+// However long they are kept, httpcache still checks whether they are fresh before using them.
+func NewHTTPCache(s *Store, location string, ttl time.Duration) *HTTPCache {
+	return &HTTPCache{store: s, location: location, ttl: ttl}
+}
+
+// Get returns the response cached under key, and whether there was one which hadn't expired.
+func (c *HTTPCache) Get(key string) ([]byte, bool) {
+	var response []byte
+	err := c.store.View(func(tx *bolt.Tx) error {
+		raw, err := Get(tx, c.location, key)
+		if err != nil || len(raw) < 8 {
+			return err
+		}
+		if expires := int64(binary.BigEndian.Uint64(raw)); expires != 0 && time.Now().UnixNano() > expires {
+			return nil
+		}
+		response = copyBytes(raw[8:])
+		return nil
+	})
+	return response, err == nil && response != nil
+}
+
+// Set caches the response under key, replacing any already there.
+func (c *HTTPCache) Set(key string, response []byte) {
+	raw := make([]byte, 8+len(response))
+	if c.ttl > 0 {
+		binary.BigEndian.PutUint64(raw, uint64(time.Now().Add(c.ttl).UnixNano()))
+	}
+	copy(raw[8:], response)
+
+	c.store.Update(func(tx *bolt.Tx) error {
+		return Put(tx, c.location, key, raw)
+	})
+}
+
+// Delete removes the response cached under key.
+func (c *HTTPCache) Delete(key string) {
+	c.store.Update(func(tx *bolt.Tx) error {
+		return Del(tx, c.location, key)
+	})
+}
+
+// Prune deletes every response which has expired, returning how many there were. Expired responses are never
+// returned by Get, but are only removed from the location by Prune.
+func (c *HTTPCache) Prune() (int, error) {
+	now := time.Now().UnixNano()
+	var expired []string
+	err := c.store.Update(func(tx *bolt.Tx) error {
+		err := Each(tx, c.location, func(key string, raw []byte) error {
+			if len(raw) < 8 {
+				return nil
+			}
+			if expires := int64(binary.BigEndian.Uint64(raw)); expires != 0 && now > expires {
+				expired = append(expired, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := Del(tx, c.location, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, wrapErr("Prune", c.location, "", err)
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,58 @@
+package rod
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHTTPCache(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	s := NewStore(db)
+
+	// the interface httpcache.Transport uses
+	var _ interface {
+		Get(key string) ([]byte, bool)
+		Set(key string, response []byte)
+		Delete(key string)
+	} = &HTTPCache{}
+
+	t.Run("Get, Set and Delete", func(t *testing.T) {
+		cache := NewHTTPCache(s, "httpcache", 0)
+		if _, ok := cache.Get("https://example.com/"); ok {
+			t.Fatal("Nothing should be cached yet")
+		}
+
+		cache.Set("https://example.com/", []byte("HTTP/1.1 200 OK\r\n\r\nhello"))
+		got, ok := cache.Get("https://example.com/")
+		if !ok || string(got) != "HTTP/1.1 200 OK\r\n\r\nhello" {
+			t.Fatalf("The response should be cached: %q", got)
+		}
+
+		// an empty response is still a response
+		cache.Set("https://example.com/empty", []byte{})
+		if got, ok := cache.Get("https://example.com/empty"); !ok || len(got) != 0 {
+			t.Fatal("An empty response should be cached")
+		}
+
+		cache.Delete("https://example.com/")
+		if _, ok := cache.Get("https://example.com/"); ok {
+			t.Fatal("The response should have been deleted")
+		}
+	})
+
+	t.Run("TTL and Prune", func(t *testing.T) {
+		cache := NewHTTPCache(s, "httpcache-ttl", time.Millisecond)
+		cache.Set("https://example.com/", []byte("response"))
+		time.Sleep(5 * time.Millisecond)
+
+		if _, ok := cache.Get("https://example.com/"); ok {
+			t.Fatal("An expired response should be a miss")
+		}
+		n, err := cache.Prune()
+		check(err)
+		if n != 1 {
+			t.Fatalf("One response should have been pruned, not %d", n)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+// Package keyspace lays rod's nested buckets out over a flat, ordered key/value store, for the backends (such as
+// Badger and Pebble) which don't have buckets of their own.
+//
+// Every bucket has a path, made from the name of each bucket down to it, escaped so that 0x00 is followed by 0xff and
+// ended by 0x00 0x01. Within a bucket, the keys are:
+//
+//	path 0x00 0x02 key   a value (prefixed with 'v') or a nested bucket (just 'b')
+//	path 0x00 0x03       the bucket's sequence
+//
+// so that a bucket's values and nested buckets are all under the one prefix, in key order, and nothing from a nested
+// bucket is.
+package keyspace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/chilts/rod"
+)
+
+// ErrIncompatibleValue is returned when a key is used as both a value and a bucket, as with Bolt.
+var ErrIncompatibleValue = errors.New("incompatible value")
+
+// ErrBucketNameRequired is returned when creating a bucket with an empty name.
+var ErrBucketNameRequired = errors.New("bucket name required")
+
+const (
+	valueMarker  = 'v'
+	bucketMarker = 'b'
+)
+
+var (
+	nameEnd     = []byte{0x00, 0x01}
+	childrenTag = []byte{0x00, 0x02}
+	sequenceTag = []byte{0x00, 0x03}
+)
+
+// KV is the flat key/value store, within a single transaction. Every key and value it returns must be a copy which
+// stays valid after the next call.
+type KV interface {
+	// Get returns the value at key, or nil if there isn't one.
+	Get(key []byte) ([]byte, error)
+	Set(key, value []byte) error
+	Delete(key []byte) error
+
+	// First returns the first key (and its value) which is equal to or after at and begins with prefix, or a nil key
+	// if there isn't one.
+	First(at, prefix []byte) ([]byte, []byte, error)
+
+	// Last returns the last key (and its value) which is before `before` and begins with prefix, or a nil key if there
+	// isn't one.
+	Last(before, prefix []byte) ([]byte, []byte, error)
+}
+
+// Root returns the bucket which every top-level bucket is nested within.
+func Root(kv KV) rod.Bucket {
+	return &bucket{kv: kv}
+}
+
+// bucket is a location within a KV.
+type bucket struct {
+	kv   KV
+	path []byte
+}
+
+// join returns the concatenation of all of parts, in a new slice.
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// prefix is what every key in the bucket begins with.
+func (b *bucket) prefix() []byte {
+	return join(b.path, childrenTag)
+}
+
+// nested returns the path of the nested bucket called name.
+func (b *bucket) nested(name []byte) []byte {
+	return join(b.path, bytes.ReplaceAll(name, []byte{0x00}, []byte{0x00, 0xff}), nameEnd)
+}
+
+// lookup returns the raw entry at key, which is nil if there isn't one.
+func (b *bucket) lookup(key []byte) ([]byte, error) {
+	return b.kv.Get(join(b.prefix(), key))
+}
+
+// Get returns the value at key, or nil if it doesn't exist or is a nested bucket.
+func (b *bucket) Get(key []byte) []byte {
+	entry, err := b.lookup(key)
+	if err != nil || len(entry) == 0 || entry[0] != valueMarker {
+		return nil
+	}
+	return entry[1:]
+}
+
+// Put sets the value at key.
+func (b *bucket) Put(key, value []byte) error {
+	entry, err := b.lookup(key)
+	if err != nil {
+		return err
+	}
+	if len(entry) > 0 && entry[0] == bucketMarker {
+		return ErrIncompatibleValue
+	}
+	return b.kv.Set(join(b.prefix(), key), join([]byte{valueMarker}, value))
+}
+
+// Delete removes the value at key, if there is one.
+func (b *bucket) Delete(key []byte) error {
+	entry, err := b.lookup(key)
+	if err != nil || len(entry) == 0 {
+		return err
+	}
+	if entry[0] == bucketMarker {
+		return ErrIncompatibleValue
+	}
+	return b.kv.Delete(join(b.prefix(), key))
+}
+
+// Bucket returns the nested bucket called name, or nil if it doesn't exist.
+func (b *bucket) Bucket(name []byte) rod.Bucket {
+	entry, err := b.lookup(name)
+	if err != nil || len(entry) == 0 || entry[0] != bucketMarker {
+		return nil
+	}
+	return &bucket{kv: b.kv, path: b.nested(name)}
+}
+
+// CreateBucketIfNotExists returns the nested bucket called name, creating it first if needed.
+func (b *bucket) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	if len(name) == 0 {
+		return nil, ErrBucketNameRequired
+	}
+
+	entry, err := b.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(entry) > 0 && entry[0] == valueMarker {
+		return nil, ErrIncompatibleValue
+	}
+	if len(entry) == 0 {
+		if err := b.kv.Set(join(b.prefix(), name), []byte{bucketMarker}); err != nil {
+			return nil, err
+		}
+	}
+	return &bucket{kv: b.kv, path: b.nested(name)}, nil
+}
+
+// NextSequence returns the next number in the bucket's sequence, starting at 1.
+func (b *bucket) NextSequence() (uint64, error) {
+	key := join(b.path, sequenceTag)
+
+	raw, err := b.kv.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	var seq uint64
+	if len(raw) == 8 {
+		seq = binary.BigEndian.Uint64(raw)
+	}
+
+	seq++
+	raw = make([]byte, 8)
+	binary.BigEndian.PutUint64(raw, seq)
+	return seq, b.kv.Set(key, raw)
+}
+
+// Cursor returns a cursor over the bucket.
+func (b *bucket) Cursor() rod.Cursor {
+	return &cursor{kv: b.kv, prefix: b.prefix(), end: join(b.path, sequenceTag)}
+}
+
+// cursor moves over the keys in a bucket, finding each one afresh from the one before.
+type cursor struct {
+	kv      KV
+	prefix  []byte
+	end     []byte
+	current []byte
+}
+
+// First moves to the first key in the bucket.
+func (c *cursor) First() ([]byte, []byte) {
+	return c.at(c.kv.First(c.prefix, c.prefix))
+}
+
+// Last moves to the last key in the bucket. The sequence sorts directly after every key in the bucket, so it is the
+// place to look back from.
+func (c *cursor) Last() ([]byte, []byte) {
+	return c.at(c.kv.Last(c.end, c.prefix))
+}
+
+// Next moves to the key after the current one.
+func (c *cursor) Next() ([]byte, []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+	return c.at(c.kv.First(join(c.current, []byte{0x00}), c.prefix))
+}
+
+// Prev moves to the key before the current one.
+func (c *cursor) Prev() ([]byte, []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+	return c.at(c.kv.Last(c.current, c.prefix))
+}
+
+// Seek moves to the first key equal to or after seek.
+func (c *cursor) Seek(seek []byte) ([]byte, []byte) {
+	return c.at(c.kv.First(join(c.prefix, seek), c.prefix))
+}
+
+// at remembers where the cursor now is, and returns the key and value there. A nested bucket has a nil value.
+func (c *cursor) at(key, entry []byte, err error) ([]byte, []byte) {
+	if err != nil || key == nil {
+		c.current = nil
+		return nil, nil
+	}
+
+	c.current = key
+	if len(entry) == 0 || entry[0] != valueMarker {
+		return key[len(c.prefix):], nil
+	}
+	return key[len(c.prefix):], entry[1:]
+}
@@ -0,0 +1,45 @@
+package rod
+
+import (
+	"iter"
+)
+
+// Keys returns an iterator over all of the keys in the bucket specified by location, in key order, for use with
+// range-over-func:
+//
+//	for key := range rod.Keys(tx, "users") {
+//	    fmt.Println(key)
+//	}
+//
+// Since an iterator can't return an error, an invalid location just yields nothing. Use Each if you need to know about
+// errors. The iterator must only be used within the life of the transaction.
+func Keys(tx Tx, location string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		_ = Each(tx, location, func(key string, value []byte) error {
+			if !yield(key) {
+				return Stop
+			}
+			return nil
+		})
+	}
+}
+
+// Items returns an iterator over all of the key/value pairs in the bucket specified by location, with each value
+// decoded into a new T using json.Unmarshal():
+//
+//	for key, user := range rod.Items[User](tx, "users") {
+//	    fmt.Println(key, user.Email)
+//	}
+//
+// As with Keys, an invalid location yields nothing. Iteration also ends at the first value which fails to decode. Use
+// EachJson if you need to know about errors. The iterator must only be used within the life of the transaction.
+func Items[T any](tx Tx, location string) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		_ = EachJson(tx, location, func(key string, v T) error {
+			if !yield(key, v) {
+				return Stop
+			}
+			return nil
+		})
+	}
+}
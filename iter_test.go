@@ -0,0 +1,79 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestIter(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "car", "golf", Car{"Volkswagon", "Golf"}))
+		check(PutJson(tx, "car", "hilux", Car{"Toyota", "Hilux"}))
+		check(PutJson(tx, "car", "leaf", Car{"Nissan", "Leaf"}))
+		return nil
+	})
+	check(err)
+
+	t.Run("Keys", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var keys []string
+			for key := range Keys(tx, "car") {
+				keys = append(keys, key)
+			}
+			if len(keys) != 3 {
+				t.Fatalf("Three keys should have been yielded, but instead %d were", len(keys))
+			}
+			if keys[0] != "golf" || keys[1] != "hilux" || keys[2] != "leaf" {
+				t.Fatalf("Keys were not yielded in order: %v", keys)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Keys with break", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			count := 0
+			for range Keys(tx, "car") {
+				count++
+				break
+			}
+			if count != 1 {
+				t.Fatalf("Only one key should have been yielded, but %d were", count)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Items", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			cars := make(map[string]Car)
+			for key, car := range Items[Car](tx, "car") {
+				cars[key] = car
+			}
+			if len(cars) != 3 {
+				t.Fatalf("Three cars should have been yielded, but instead %d were", len(cars))
+			}
+			if cars["leaf"].Manufacturer != "Nissan" {
+				t.Fatalf("The leaf should have been made by Nissan, not %s", cars["leaf"].Manufacturer)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Items on a non-existant bucket", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			for range Items[Car](tx, "does-not-exist") {
+				t.Fatal("Nothing should be yielded for a missing bucket")
+			}
+			return nil
+		})
+		check(err)
+	})
+}
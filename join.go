@@ -0,0 +1,124 @@
+package rod
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// ErrJoinFieldNeeded is returned from Join if the result type isn't a struct with a field tagged `rod:"join"`.
+var ErrJoinFieldNeeded = errors.New("provided target must be a pointer to a slice of structs with a `rod:\"join\"` field")
+
+// Join decodes every record in leftLocation into a new element of the slice that `to` points to (just like All), then
+// looks up the record in rightLocation whose key is in the left record's foreignKeyField and decodes that into the
+// element's field tagged `rod:"join"`. Each right record is only fetched and decoded once however many left records
+// refer to it.
+//
+//	type PostWithAuthor struct {
+//	    Post
+//	    Author *User `json:"-" rod:"join"`
+//	}
+//
+//	var posts []PostWithAuthor
+//	err := rod.Join(tx, "post", "user", "AuthorID", &posts)
+//
+// The foreign key may be a JSON string or number. If it is missing, or the right record doesn't exist, then the join
+// field is left as its zero value (so use a pointer if you want to tell).
+func Join(tx Tx, leftLocation, rightLocation, foreignKeyField string, to interface{}) error {
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || reflect.Indirect(ref).Kind() != reflect.Slice {
+		return wrapErr("Join", leftLocation, "", ErrSlicePtrNeeded)
+	}
+	sliceType := reflect.Indirect(ref).Type()
+
+	elemType := sliceType.Elem()
+	isPtrWanted := false
+	if elemType.Kind() == reflect.Ptr {
+		isPtrWanted = true
+		elemType = elemType.Elem()
+	}
+
+	// find the join field
+	joinField := taggedField(elemType, "join")
+	if joinField == nil {
+		return wrapErr("Join", leftLocation, "", ErrJoinFieldNeeded)
+	}
+	joinType := elemType.FieldByIndex(joinField).Type
+
+	segments, err := parseFieldPath(foreignKeyField)
+	if err != nil {
+		return wrapErr("Join", leftLocation, "", err)
+	}
+
+	right, err := BucketAt(tx, rightLocation)
+	if err != nil {
+		return wrapErr("Join", rightLocation, "", err)
+	}
+
+	// right records we've already decoded, by key
+	cache := make(map[string]reflect.Value)
+
+	results := reflect.MakeSlice(sliceType, 0, 0)
+	err = Each(tx, leftLocation, func(key string, raw []byte) error {
+		item := reflect.New(elemType)
+		if err := loadJson(tx, raw, item.Interface()); err != nil {
+			return wrapErr("Join", leftLocation, key, err)
+		}
+
+		fk, err := foreignKey(raw, segments)
+		if err != nil {
+			return wrapErr("Join", leftLocation, key, err)
+		}
+
+		if fk != "" && right != nil {
+			joined, ok := cache[fk]
+			if !ok {
+				if v := right.Get([]byte(fk)); v != nil {
+					joined = reflect.New(joinType)
+					if err := loadJson(tx, v, joined.Interface()); err != nil {
+						return wrapErr("Join", rightLocation, fk, err)
+					}
+					joined = joined.Elem()
+				}
+				cache[fk] = joined
+			}
+			if joined.IsValid() {
+				item.Elem().FieldByIndex(joinField).Set(joined)
+			}
+		}
+
+		if isPtrWanted {
+			results = reflect.Append(results, item)
+		} else {
+			results = reflect.Append(results, item.Elem())
+		}
+		return nil
+	})
+	if err != nil {
+		return wrapErr("Join", leftLocation, "", err)
+	}
+
+	reflect.Indirect(ref).Set(results)
+
+	return nil
+}
+
+// foreignKey reads the field at segments from raw as a key, which may be a JSON string or number.
+func foreignKey(raw []byte, segments []string) (string, error) {
+	field, err := jsonField(raw, segments)
+	if err != nil || field == nil {
+		return "", err
+	}
+
+	v, err := decodeJson(field)
+	if err != nil {
+		return "", err
+	}
+	switch fk := v.(type) {
+	case string:
+		return fk, nil
+	case json.Number:
+		return fk.String(), nil
+	}
+	return "", nil
+}
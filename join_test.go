@@ -0,0 +1,69 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type Post struct {
+	Title    string
+	AuthorID string
+}
+
+type PostWithAuthor struct {
+	Post
+	Author *User `json:"-" rod:"join"`
+}
+
+func TestJoin(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 1}))
+		check(PutJson(tx, "user", "bob", User{"bob", 2}))
+		check(PutJson(tx, "post", "1", Post{"Hello", "alice"}))
+		check(PutJson(tx, "post", "2", Post{"World", "bob"}))
+		check(PutJson(tx, "post", "3", Post{"Again", "alice"}))
+		check(PutJson(tx, "post", "4", Post{"Orphan", "nobody"}))
+		check(PutJson(tx, "post", "5", map[string]string{"Title": "Anonymous"}))
+		return nil
+	})
+	check(err)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		var posts []PostWithAuthor
+		check(Join(tx, "post", "user", "AuthorID", &posts))
+		if len(posts) != 5 {
+			t.Fatalf("Expected 5 posts but got %d", len(posts))
+		}
+		if posts[0].Title != "Hello" || posts[0].Author == nil || posts[0].Author.Username != "alice" {
+			t.Fatalf("The first post should have been joined to alice: %#v", posts[0])
+		}
+		if posts[1].Author == nil || posts[1].Author.Logins != 2 {
+			t.Fatalf("The second post should have been joined to bob: %#v", posts[1])
+		}
+		if posts[0].Author != posts[2].Author {
+			t.Fatal("The same author should only be decoded once")
+		}
+		if posts[3].Author != nil || posts[4].Author != nil {
+			t.Fatal("Posts with missing authors should not be joined")
+		}
+
+		var ptrs []*PostWithAuthor
+		check(Join(tx, "post", "user", "AuthorID", &ptrs))
+		if len(ptrs) != 5 || ptrs[1].Author.Username != "bob" {
+			t.Fatal("Joining into pointers didn't work")
+		}
+
+		var plain []Post
+		if err := Join(tx, "post", "user", "AuthorID", &plain); !errors.Is(err, ErrJoinFieldNeeded) {
+			t.Fatalf("Expected ErrJoinFieldNeeded but got %v", err)
+		}
+
+		return nil
+	})
+	check(err)
+}
@@ -0,0 +1,62 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledJsonBuffer is the largest buffer put back in the pool, so that one huge document doesn't keep its buffer
+// around for ever.
+const maxPooledJsonBuffer = 4 << 20
+
+// jsonNoPool is set once pooling has been turned off with SetJsonPooling.
+var jsonNoPool atomic.Bool
+
+// jsonEncoder is a buffer along with an encoder which writes into it, kept in jsonEncoders between uses.
+type jsonEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonEncoders = sync.Pool{
+	New: func() interface{} {
+		e := &jsonEncoder{}
+		e.enc = json.NewEncoder(&e.buf)
+		return e
+	},
+}
+
+// SetJsonPooling turns the pooling of the buffers PutJson and PutManyJson encode into on or off. It is on to start
+// with, so that encoding lots of large documents reuses buffers which have already grown to fit rather than growing a
+// new one each time. The document is still copied out of the buffer once it is encoded, since the backend (Bolt
+// included) may hold on to a value until the transaction ends. Turn pooling off if the pool's memory matters more to
+// you, such as when there is the odd huge document amongst many small ones. Decoding, as in GetJson, needs no buffer of
+// its own since it reads the stored value where it is.
+func SetJsonPooling(enabled bool) {
+	jsonNoPool.Store(!enabled)
+}
+
+// marshalJson encodes v just as json.Marshal does, using a pooled buffer unless pooling has been turned off.
+func marshalJson(v interface{}) ([]byte, error) {
+	if jsonNoPool.Load() {
+		return json.Marshal(v)
+	}
+
+	e := jsonEncoders.Get().(*jsonEncoder)
+	defer func() {
+		if e.buf.Cap() <= maxPooledJsonBuffer {
+			jsonEncoders.Put(e)
+		}
+	}()
+
+	e.buf.Reset()
+	if err := e.enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	// the encoder ends with a newline, which json.Marshal doesn't
+	b := e.buf.Bytes()
+	return append([]byte(nil), b[:len(b)-1]...), nil
+}
@@ -0,0 +1,118 @@
+package rod
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestJsonPool(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	values := []interface{}{
+		nil,
+		"<b>Tom & Jerry</b>",
+		map[string]interface{}{"z": 1, "a": []int{1, 2, 3}},
+		User{Username: "chilts", Logins: 3},
+		json.RawMessage(`{ "spaced" : true }`),
+		map[string]string{"big": strings.Repeat("x", 1<<20)},
+	}
+
+	t.Run("Encoding is the same as json.Marshal, pooled or not", func(t *testing.T) {
+		defer SetJsonPooling(true)
+		for _, pooled := range []bool{true, false} {
+			SetJsonPooling(pooled)
+			for _, v := range values {
+				want, err := json.Marshal(v)
+				check(err)
+				got, err := marshalJson(v)
+				check(err)
+				if string(got) != string(want) {
+					t.Fatalf("Expected %.60s but got %.60s (pooled=%v)", want, got, pooled)
+				}
+			}
+		}
+	})
+
+	t.Run("A failed encoding doesn't spoil the pool", func(t *testing.T) {
+		if _, err := marshalJson(map[string]interface{}{"ch": make(chan int)}); err == nil {
+			t.Fatal("Expected an error encoding a channel")
+		}
+		got, err := marshalJson(User{Username: "bob"})
+		check(err)
+		if string(got) != `{"Username":"bob","Logins":0}` {
+			t.Fatalf("Unexpected encoding %s", got)
+		}
+	})
+
+	t.Run("Documents put in one transaction keep their own bytes", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			for i := 0; i < 100; i++ {
+				check(PutJson(tx, "users", fmt.Sprintf("user-%03d", i), User{Username: fmt.Sprintf("user-%d", i), Logins: i}))
+			}
+			items := map[string]User{}
+			for i := 100; i < 200; i++ {
+				items[fmt.Sprintf("user-%03d", i)] = User{Username: fmt.Sprintf("user-%d", i), Logins: i}
+			}
+			return PutManyJson(tx, "users", items)
+		})
+		check(err)
+
+		err = db.View(func(tx *bolt.Tx) error {
+			for i := 0; i < 200; i++ {
+				var u User
+				check(GetJson(tx, "users", fmt.Sprintf("user-%03d", i), &u))
+				if u.Username != fmt.Sprintf("user-%d", i) || u.Logins != i {
+					t.Fatalf("Unexpected user %d: %v", i, u)
+				}
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Pooling can be turned off", func(t *testing.T) {
+		SetJsonPooling(false)
+		defer SetJsonPooling(true)
+
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutJson(tx, "unpooled", "chilts", User{Username: "chilts"}))
+			var u User
+			check(GetJson(tx, "unpooled", "chilts", &u))
+			if u.Username != "chilts" {
+				t.Fatalf("Expected chilts but got %v", u)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
+
+func BenchmarkPutJson(b *testing.B) {
+	doc := map[string]interface{}{"Username": "chilts", "Posts": strings.Split(strings.Repeat("Hello, World!,", 5000), ",")}
+	for _, pooled := range []bool{true, false} {
+		b.Run(fmt.Sprintf("pooled=%v", pooled), func(b *testing.B) {
+			SetJsonPooling(pooled)
+			defer SetJsonPooling(true)
+
+			db, done := openTestDB(b)
+			defer done()
+
+			tx, err := db.Begin(true)
+			check(err)
+			defer tx.Rollback()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := PutJson(tx, "users", "chilts", doc); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
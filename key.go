@@ -0,0 +1,147 @@
+package rod
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCompositeKey is returned when SplitKey is given a key which wasn't made by Key.
+var ErrInvalidCompositeKey = errors.New("key is not a composite key")
+
+// the type tags for each part of a composite key, chosen so that parts of different types still have an order
+const (
+	keyTagString byte = 0x02
+	keyTagInt    byte = 0x15
+	keyTagUint   byte = 0x16
+	keyTagTime   byte = 0x20
+)
+
+// Key makes a composite key from its parts such that keys sort (byte-wise, as Bolt does) in the same order as their
+// parts would, with the first part being the most significant. Each part may be a string, any signed or unsigned
+// integer, or a time.Time. This means time-ordered keys within a tenant are as simple as:
+//
+//	key := rod.Key("acme", time.Now(), seq)
+//
+// The key from fewer parts is a prefix of keys with more, so Key("acme") may be given to CountPrefix or Seek to find
+// everything for that tenant. Signed and unsigned integers are encoded differently (so Key(1) != Key(uint(1))), and a
+// time is stored as nanoseconds since the Unix epoch in UTC, so it must be between the years 1678 and 2262.
+//
+// Key panics if given a part of any other type, since that is a programming error.
+func Key(parts ...interface{}) string {
+	var sb strings.Builder
+	buf := make([]byte, 8)
+
+	for _, part := range parts {
+		switch p := part.(type) {
+		case string:
+			sb.WriteByte(keyTagString)
+			// escape any zero bytes so that the terminator is unambiguous, and still sorts before everything else
+			sb.WriteString(strings.ReplaceAll(p, "\x00", "\x00\xff"))
+			sb.WriteByte(0x00)
+		case int, int8, int16, int32, int64:
+			n := toInt64(p)
+			sb.WriteByte(keyTagInt)
+			// flipping the sign bit puts negative numbers before positive ones
+			binary.BigEndian.PutUint64(buf, uint64(n)^(1<<63))
+			sb.Write(buf)
+		case uint, uint8, uint16, uint32, uint64:
+			sb.WriteByte(keyTagUint)
+			binary.BigEndian.PutUint64(buf, toUint64(p))
+			sb.Write(buf)
+		case time.Time:
+			sb.WriteByte(keyTagTime)
+			binary.BigEndian.PutUint64(buf, uint64(p.UnixNano())^(1<<63))
+			sb.Write(buf)
+		default:
+			panic(fmt.Sprintf("rod: unsupported composite key part of type %T", part))
+		}
+	}
+
+	return sb.String()
+}
+
+// SplitKey splits a key made by Key back into its parts. Strings come back as a string, signed integers as an int64,
+// unsigned integers as a uint64, and times as a time.Time in UTC.
+//
+//	parts, err := rod.SplitKey(key)
+//	tenant, when, seq := parts[0].(string), parts[1].(time.Time), parts[2].(int64)
+func SplitKey(key string) ([]interface{}, error) {
+	var parts []interface{}
+
+	for len(key) > 0 {
+		tag := key[0]
+		key = key[1:]
+
+		switch tag {
+		case keyTagString:
+			var sb strings.Builder
+			for {
+				i := strings.IndexByte(key, 0x00)
+				if i < 0 {
+					return nil, ErrInvalidCompositeKey
+				}
+				sb.WriteString(key[:i])
+				if i+1 < len(key) && key[i+1] == 0xff {
+					// an escaped zero byte
+					sb.WriteByte(0x00)
+					key = key[i+2:]
+					continue
+				}
+				key = key[i+1:]
+				break
+			}
+			parts = append(parts, sb.String())
+		case keyTagInt, keyTagUint, keyTagTime:
+			if len(key) < 8 {
+				return nil, ErrInvalidCompositeKey
+			}
+			n := binary.BigEndian.Uint64([]byte(key[:8]))
+			key = key[8:]
+			switch tag {
+			case keyTagInt:
+				parts = append(parts, int64(n^(1<<63)))
+			case keyTagUint:
+				parts = append(parts, n)
+			default:
+				parts = append(parts, time.Unix(0, int64(n^(1<<63))).UTC())
+			}
+		default:
+			return nil, ErrInvalidCompositeKey
+		}
+	}
+
+	return parts, nil
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	default:
+		return v.(int64)
+	}
+}
+
+func toUint64(v interface{}) uint64 {
+	switch n := v.(type) {
+	case uint:
+		return uint64(n)
+	case uint8:
+		return uint64(n)
+	case uint16:
+		return uint64(n)
+	case uint32:
+		return uint64(n)
+	default:
+		return v.(uint64)
+	}
+}
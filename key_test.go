@@ -0,0 +1,89 @@
+package rod
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestKey(t *testing.T) {
+	t.Run("Keys sort in the order of their parts", func(t *testing.T) {
+		when := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		want := []string{
+			Key("a", when, -10),
+			Key("a", when, -1),
+			Key("a", when, 0),
+			Key("a", when, 2),
+			Key("a", when, 10),
+			Key("a", when.Add(time.Nanosecond), -100),
+			Key("a\x00b", when, 0),
+			Key("ab", when.Add(-time.Hour), 0),
+			Key("b"),
+		}
+
+		got := append([]string(nil), want...)
+		sort.Strings(got)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("Composite key %d is out of order", i)
+			}
+		}
+
+		if !strings.HasPrefix(Key("acme", when, 1), Key("acme")) {
+			t.Fatal("A key with fewer parts should be a prefix")
+		}
+	})
+
+	t.Run("SplitKey", func(t *testing.T) {
+		when := time.Date(2020, 1, 1, 12, 30, 0, 5, time.FixedZone("NZDT", 13*60*60))
+		parts, err := SplitKey(Key("ac\x00me", when, 42, uint8(7), ""))
+		check(err)
+		if len(parts) != 5 {
+			t.Fatalf("Expected 5 parts but got %d", len(parts))
+		}
+		if parts[0].(string) != "ac\x00me" {
+			t.Fatalf("Expected the tenant but got %q", parts[0])
+		}
+		if !parts[1].(time.Time).Equal(when) {
+			t.Fatalf("Expected %s but got %s", when, parts[1])
+		}
+		if parts[2].(int64) != 42 || parts[3].(uint64) != 7 || parts[4].(string) != "" {
+			t.Fatalf("Unexpected parts: %#v", parts[2:])
+		}
+
+		if _, err := SplitKey("chilts"); !errors.Is(err, ErrInvalidCompositeKey) {
+			t.Fatalf("Expected ErrInvalidCompositeKey but got %v", err)
+		}
+	})
+
+	t.Run("Composite keys in a bucket", func(t *testing.T) {
+		db, done := openTestDB(t)
+		defer done()
+
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "events", Key("acme", 2), "second"))
+			check(PutString(tx, "events", Key("acme", 1), "first"))
+			check(PutString(tx, "events", Key("other", 1), "other"))
+
+			n, err := CountPrefix(tx, "events", Key("acme"))
+			check(err)
+			if n != 2 {
+				t.Fatalf("Expected two events for acme but got %d", n)
+			}
+
+			key, _, err := First(tx, "events")
+			check(err)
+			parts, err := SplitKey(key)
+			check(err)
+			if parts[1].(int64) != 1 {
+				t.Fatalf("Expected the first event to be 1 but got %v", parts[1])
+			}
+			return nil
+		})
+		check(err)
+	})
+}
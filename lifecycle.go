@@ -0,0 +1,57 @@
+package rod
+
+import (
+	"encoding/json"
+)
+
+// Validator may be implemented by the types you store. PutJson (along with PutManyJson, GetOrPutJson,
+// CompareAndSwapVersion and UpdateJson) calls Validate before marshalling the value, and if it returns an error then
+// nothing is put and that error is returned.
+type Validator interface {
+	Validate() error
+}
+
+// BeforeSaver may be implemented by the types you store. PutJson (and the others which call Validate) calls BeforeSave
+// first of all, so it may fill in fields or make other changes in the same transaction. If it returns an error then
+// nothing is put and that error is returned. Use a pointer receiver (and pass a pointer to PutJson) if BeforeSave
+// changes the value.
+type BeforeSaver interface {
+	BeforeSave(tx Tx) error
+}
+
+// AfterLoader may be implemented by the types you store. The functions which decode JSON into your types (GetJson,
+// All, Find, EachJson and the rest) call AfterLoad on each value once it has been decoded, and if it returns an error
+// then that error is returned.
+type AfterLoader interface {
+	AfterLoad(tx Tx) error
+}
+
+// beforeSave calls BeforeSave and then Validate on v, if it implements them.
+func beforeSave(tx Tx, v interface{}) error {
+	if s, ok := v.(BeforeSaver); ok {
+		if err := s.BeforeSave(tx); err != nil {
+			return err
+		}
+	}
+	if val, ok := v.(Validator); ok {
+		if err := val.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadJson decodes raw into v, which must be a pointer, decrypts any encrypted fields, then calls AfterLoad on v if it
+// implements it.
+func loadJson(tx Tx, raw []byte, v interface{}) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return err
+	}
+	if err := decryptFields(v); err != nil {
+		return err
+	}
+	if l, ok := v.(AfterLoader); ok {
+		return l.AfterLoad(tx)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package rod
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+var errNoTitle = errors.New("a title is required")
+
+type Article struct {
+	Title string
+	Slug  string
+	// Loaded isn't stored, and is set by AfterLoad
+	Loaded bool `json:"-"`
+}
+
+func (a *Article) BeforeSave(tx Tx) error {
+	a.Slug = strings.ToLower(strings.ReplaceAll(a.Title, " ", "-"))
+	return nil
+}
+
+func (a *Article) Validate() error {
+	if a.Title == "" {
+		return errNoTitle
+	}
+	return nil
+}
+
+func (a *Article) AfterLoad(tx Tx) error {
+	a.Loaded = true
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("BeforeSave and Validate", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			article := Article{Title: "Hello World"}
+			check(PutJson(tx, "article", "1", &article))
+			if article.Slug != "hello-world" {
+				t.Fatalf("Expected BeforeSave to set the slug but got '%s'", article.Slug)
+			}
+
+			err := PutJson(tx, "article", "2", &Article{})
+			if !errors.Is(err, errNoTitle) {
+				t.Fatalf("Expected the validation error but got %v", err)
+			}
+			exists, err := Exists(tx, "article", "2")
+			check(err)
+			if exists {
+				t.Fatal("An invalid article should not have been put")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("AfterLoad", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var article Article
+			check(GetJson(tx, "article", "1", &article))
+			if !article.Loaded || article.Slug != "hello-world" {
+				t.Fatalf("Expected AfterLoad to have been called: %#v", article)
+			}
+
+			var articles []Article
+			check(All(tx, "article", &articles))
+			if len(articles) != 1 || !articles[0].Loaded {
+				t.Fatalf("Expected AfterLoad to have been called by All: %#v", articles)
+			}
+
+			err := EachJson(tx, "article", func(key string, a Article) error {
+				if !a.Loaded {
+					t.Fatalf("Expected AfterLoad to have been called by EachJson")
+				}
+				return nil
+			})
+			check(err)
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Every JSON write calls BeforeSave and Validate", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			// created records are saved, validated, and loaded again
+			var got Article
+			created, err := GetOrPutJson(tx, "hooks", "created", &got, func() interface{} {
+				return &Article{Title: "Get Or Put"}
+			})
+			check(err)
+			if !created || got.Slug != "get-or-put" || !got.Loaded {
+				t.Fatalf("Expected GetOrPutJson to run the hooks: %#v", got)
+			}
+			_, err = GetOrPutJson(tx, "hooks", "invalid", &got, func() interface{} { return &Article{} })
+			if !errors.Is(err, errNoTitle) {
+				t.Fatalf("Expected the validation error from GetOrPutJson but got %v", err)
+			}
+
+			many := map[string]*Article{"a": {Title: "Put Many"}, "b": {}}
+			err = PutManyJson(tx, "hooks", many)
+			var errs KeyErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs["b"], errNoTitle) {
+				t.Fatalf("Expected the validation error for b from PutManyJson but got %v", err)
+			}
+			if many["a"].Slug != "put-many" {
+				t.Fatalf("Expected PutManyJson to call BeforeSave: %#v", many["a"])
+			}
+
+			swap := Article{Title: "Swapped"}
+			swapped, err := CompareAndSwapVersion(tx, "hooks", "swap", "Version", 0, &swap)
+			check(err)
+			if !swapped || swap.Slug != "swapped" || !swap.Loaded {
+				t.Fatalf("Expected CompareAndSwapVersion to run the hooks: %#v", swap)
+			}
+			_, err = CompareAndSwapVersion(tx, "hooks", "invalid", "Version", 0, &Article{})
+			if !errors.Is(err, errNoTitle) {
+				t.Fatalf("Expected the validation error from CompareAndSwapVersion but got %v", err)
+			}
+
+			for _, key := range []string{"invalid", "b"} {
+				exists, err := Exists(tx, "hooks", key)
+				check(err)
+				if exists {
+					t.Fatalf("The invalid article %s should not have been put", key)
+				}
+			}
+			return nil
+		})
+		check(err)
+	})
+}
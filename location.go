@@ -0,0 +1,24 @@
+package rod
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// nextBucket splits the first bucket name off location, returning it and the rest of the location after its '.'. more
+// is false once name is the last bucket in location. Walking a location like this doesn't allocate, unlike splitting
+// it up front with strings.Split.
+func nextBucket(location string) (name, rest string, more bool) {
+	i := strings.IndexByte(location, '.')
+	if i < 0 {
+		return location, "", false
+	}
+	return location[:i], location[i+1:], true
+}
+
+// lookup returns the bytes of s without copying them, for passing a name or key to a Bucket method which only reads
+// it (as the Bucket interface requires). The bytes must never be written to, so lookup is only for names and keys
+// which go no further than the backend, and never for those handed on to hooks or given back to the caller.
+func lookup(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
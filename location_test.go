@@ -0,0 +1,68 @@
+package rod
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestLocation(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("nextBucket walks a location as strings.Split would split it", func(t *testing.T) {
+		for _, location := range []string{"users", "users.chilts", "users.chilts.posts", ".users", "users.", "a..b", "."} {
+			var got []string
+			name, rest, more := nextBucket(location)
+			got = append(got, name)
+			for more {
+				name, rest, more = nextBucket(rest)
+				got = append(got, name)
+			}
+
+			want := strings.Split(location, ".")
+			if strings.Join(got, "|") != strings.Join(want, "|") || len(got) != len(want) {
+				t.Fatalf("Expected %q for %q but got %q", want, location, got)
+			}
+		}
+	})
+
+	t.Run("Invalid buckets are still refused", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			for _, location := range []string{".users", "users.", "users..chilts", "."} {
+				if err := PutString(tx, location, "key", "value"); !errors.Is(err, ErrInvalidLocationBucket) {
+					t.Fatalf("Expected ErrInvalidLocationBucket putting at %q but got %v", location, err)
+				}
+				if _, err := Get(tx, location, "key"); !errors.Is(err, ErrInvalidLocationBucket) {
+					t.Fatalf("Expected ErrInvalidLocationBucket getting from %q but got %v", location, err)
+				}
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Keys and names are left as they were", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			location := []byte("users.chilts.posts")
+			key := []byte("hello-world")
+			check(PutString(tx, string(location), string(key), "Hello, World!"))
+
+			v, err := GetString(tx, string(location), string(key))
+			check(err)
+			if v != "Hello, World!" {
+				t.Fatalf("Expected 'Hello, World!' but got '%s'", v)
+			}
+
+			keys, err := AllKeys(tx, "users.chilts.posts")
+			check(err)
+			if len(keys) != 1 || keys[0] != "hello-world" {
+				t.Fatalf("Expected [hello-world] but got %v", keys)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
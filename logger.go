@@ -0,0 +1,151 @@
+package rod
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Level is how important a logged operation is.
+type Level int
+
+const (
+	// LevelDebug is for each Get, Put, Del and scan within a transaction.
+	LevelDebug Level = iota
+	// LevelInfo is for each whole transaction.
+	LevelInfo
+	// LevelWarn is for operations which were slow.
+	LevelWarn
+	// LevelError is for operations which failed.
+	LevelError
+)
+
+// String returns the name of the level, such as "debug".
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Logger is told about the operations made through a Store, once EnableLogging has been called.
+type Logger interface {
+	Log(level Level, op Operation)
+}
+
+// LogOption configures EnableLogging.
+type LogOption func(*logging)
+
+// LogLevel only logs operations at level or above. The default is LevelInfo, which leaves out each key.
+func LogLevel(level Level) LogOption {
+	return func(l *logging) {
+		l.level = level
+	}
+}
+
+// LogSample only logs one in every n of the operations below LevelWarn, so that a busy Store doesn't flood the log.
+// Slow and failed operations are always logged.
+func LogSample(n int) LogOption {
+	return func(l *logging) {
+		l.sample = uint64(n)
+	}
+}
+
+// LogSlow logs every operation taking longer than threshold at LevelWarn (as OnSlow finds them), whatever its level
+// would otherwise be.
+func LogSlow(threshold time.Duration) LogOption {
+	return func(l *logging) {
+		l.slow = threshold
+	}
+}
+
+// logging is what EnableLogging sets up.
+type logging struct {
+	logger Logger
+	level  Level
+	sample uint64
+	seen   uint64
+	slow   time.Duration
+}
+
+// EnableLogging turns on logging for this Store, telling logger about each operation made through it (see Observe)
+// with its duration, location, key, size and error. Use SlogLogger to log with log/slog. Call EnableLogging before
+// using the Store, since it isn't safe to call alongside transactions.
+//
+//	s.EnableLogging(rod.SlogLogger(slog.Default()), rod.LogLevel(rod.LevelDebug), rod.LogSample(100))
+func (s *Store) EnableLogging(logger Logger, opts ...LogOption) {
+	l := &logging{logger: logger, level: LevelInfo, sample: 1}
+	for _, opt := range opts {
+		opt(l)
+	}
+	s.Observe(l.observe)
+}
+
+// levelOf returns the level op is logged at.
+func levelOf(op Operation) Level {
+	switch {
+	case op.Err != nil:
+		return LevelError
+	case op.Tx == nil:
+		return LevelInfo
+	}
+	return LevelDebug
+}
+
+func (l *logging) observe(op Operation) {
+	level := levelOf(op)
+	if l.slow > 0 && op.Duration > l.slow && level < LevelWarn {
+		level = LevelWarn
+	}
+	if level < l.level {
+		return
+	}
+	if level < LevelWarn && l.sample > 1 && atomic.AddUint64(&l.seen, 1)%l.sample != 1 {
+		return
+	}
+	l.logger.Log(level, op)
+}
+
+// SlogLogger returns a Logger which logs to logger, with each operation's details as attributes.
+func SlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+var slogLevels = map[Level]slog.Level{
+	LevelDebug: slog.LevelDebug,
+	LevelInfo:  slog.LevelInfo,
+	LevelWarn:  slog.LevelWarn,
+	LevelError: slog.LevelError,
+}
+
+func (l slogLogger) Log(level Level, op Operation) {
+	attrs := []slog.Attr{slog.Duration("duration", op.Duration)}
+	if op.Location != "" {
+		attrs = append(attrs, slog.String("location", op.Location))
+	}
+	if op.Key != "" {
+		attrs = append(attrs, slog.String("key", op.Key))
+	}
+	if op.Tx != nil {
+		attrs = append(attrs, slog.Int("bytes", op.Bytes))
+	}
+	if op.Name == "scan" {
+		attrs = append(attrs, slog.Int("items", op.Items))
+	}
+	if op.Err != nil {
+		attrs = append(attrs, slog.String("error", op.Err.Error()))
+	}
+	l.logger.LogAttrs(context.Background(), slogLevels[level], "rod "+op.Name, attrs...)
+}
@@ -0,0 +1,139 @@
+package rod
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *testLogger) Log(level Level, op Operation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, level.String()+" "+op.Name)
+}
+
+func (l *testLogger) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := l.entries
+	l.entries = nil
+	return entries
+}
+
+func TestLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	open := func(name string) *Store {
+		s, err := Open(filepath.Join(dir, name), 0666, nil)
+		check(err)
+		return s
+	}
+
+	write := func(s *Store) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "notes", "shopping", "milk"))
+			return Del(tx, "notes", "shopping")
+		}))
+	}
+
+	t.Run("Levels", func(t *testing.T) {
+		s := open("levels.db")
+		defer s.Close()
+		info, debug := &testLogger{}, &testLogger{}
+		s.EnableLogging(info)
+		s.EnableLogging(debug, LogLevel(LevelDebug))
+
+		write(s)
+		s.View(func(tx *bolt.Tx) error {
+			return Put(tx, "notes", "nope", []byte("x"))
+		})
+
+		if got := strings.Join(info.take(), ","); got != "info update,error view" {
+			t.Fatalf("Unexpected entries %q", got)
+		}
+		if got := strings.Join(debug.take(), ","); got != "debug put,debug del,info update,error view" {
+			t.Fatalf("Unexpected entries %q", got)
+		}
+	})
+
+	t.Run("Sampling", func(t *testing.T) {
+		s := open("sampling.db")
+		defer s.Close()
+		l := &testLogger{}
+		s.EnableLogging(l, LogSample(3))
+
+		for i := 0; i < 6; i++ {
+			write(s)
+		}
+		s.View(func(tx *bolt.Tx) error {
+			return ErrKeyNotProvided
+		})
+
+		if got := strings.Join(l.take(), ","); got != "info update,info update,error view" {
+			t.Fatalf("Unexpected entries %q", got)
+		}
+	})
+
+	t.Run("LogSlow", func(t *testing.T) {
+		s := open("slow.db")
+		defer s.Close()
+		l := &testLogger{}
+		s.EnableLogging(l, LogLevel(LevelWarn), LogSlow(10*time.Millisecond))
+
+		write(s)
+		check(s.View(func(tx *bolt.Tx) error {
+			return Each(tx, "notes", func(key string, value []byte) error {
+				return nil
+			})
+		}))
+		check(s.Update(func(tx *bolt.Tx) error {
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}))
+
+		if got := strings.Join(l.take(), ","); got != "warn update" {
+			t.Fatalf("Unexpected entries %q", got)
+		}
+	})
+
+	t.Run("SlogLogger", func(t *testing.T) {
+		s := open("slog.db")
+		defer s.Close()
+		var buf bytes.Buffer
+		s.EnableLogging(SlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))), LogLevel(LevelDebug))
+
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "notes", "shopping", "milk")
+		}))
+		check(s.View(func(tx *bolt.Tx) error {
+			_, err := AllKeys(tx, "notes")
+			return err
+		}))
+
+		out := buf.String()
+		if !strings.Contains(out, `level=DEBUG msg="rod put"`) || !strings.Contains(out, "location=notes key=shopping bytes=4") {
+			t.Fatalf("Unexpected output %q", out)
+		}
+		if !strings.Contains(out, `msg="rod scan"`) || !strings.Contains(out, "location=notes bytes=0 items=1") {
+			t.Fatalf("Unexpected output %q", out)
+		}
+		if !strings.Contains(out, `level=INFO msg="rod update"`) {
+			t.Fatalf("Unexpected output %q", out)
+		}
+	})
+}
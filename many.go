@@ -0,0 +1,320 @@
+package rod
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrMapOrSlicePtrNeeded is returned when an unexpected value is given, instead of a pointer to a map or slice.
+var ErrMapOrSlicePtrNeeded = errors.New("provided target must be a pointer to a map or slice")
+
+// KeyErrors is returned from the batch functions when one or more individual keys failed. It maps each key which
+// failed to the error it failed with.
+type KeyErrors map[string]error
+
+func (e KeyErrors) Error() string {
+	keys := e.Keys()
+	msgs := make([]string, len(keys))
+	for i, key := range keys {
+		msgs[i] = fmt.Sprintf("%s: %s", key, e[key])
+	}
+	return fmt.Sprintf("%d key(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Keys returns the keys which failed, in order.
+func (e KeyErrors) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Unwrap returns each of the individual errors (in key order) so that errors.Is and errors.As can look inside.
+func (e KeyErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, key := range e.Keys() {
+		errs = append(errs, e[key])
+	}
+	return errs
+}
+
+// BatchOption changes how the batch functions deal with a key which fails.
+type BatchOption func(*batch)
+
+// batch holds the options given to one of the batch functions.
+type batch struct {
+	abort bool
+}
+
+// AbortOnError makes a batch function stop at the first key which fails, rather than carrying on with the rest. The
+// KeyErrors returned then only holds that one key. Return it from your Update() so that the transaction is rolled back
+// and none of the batch is kept.
+func AbortOnError() BatchOption {
+	return func(b *batch) {
+		b.abort = true
+	}
+}
+
+// ContinueOnError makes a batch function attempt every key, even once one has failed, and then return all of the
+// failures together as KeyErrors. Those keys which succeeded are kept in the transaction, so you may inspect the
+// KeyErrors and still commit if you're happy to.
+func ContinueOnError() BatchOption {
+	return func(b *batch) {
+		b.abort = false
+	}
+}
+
+// newBatch returns the options for a batch function, which by default carries on past a key which fails, as with
+// ContinueOnError.
+func newBatch(opts []BatchOption) *batch {
+	b := &batch{}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// GetMany fetches each of the keys from the bucket at location, with the location only being resolved once. Keys which
+// don't exist are skipped, so the returned map only contains the keys which were found. If the bucket doesn't exist
+// then an empty map is returned. As with Get, the values are only valid for the life of the transaction.
+func GetMany(tx Tx, location string, keys []string) (map[string][]byte, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return nil, wrapErr("GetMany", location, "", err)
+	}
+
+	values := make(map[string][]byte, len(keys))
+	if b == nil {
+		return values, nil
+	}
+
+	for _, key := range keys {
+		if key == "" {
+			return nil, wrapErr("GetMany", location, "", ErrKeyNotProvided)
+		}
+		if !hasKey(b, []byte(key)) {
+			continue
+		}
+		if v := b.Get([]byte(key)); v != nil {
+			values[key] = v
+		} else {
+			values[key] = []byte{}
+		}
+	}
+
+	return values, nil
+}
+
+// GetManyJson fetches each of the keys from the bucket at location and decodes them into to, which must either be a
+// pointer to a map of string to your type, or a pointer to a slice of your type (or pointers to your type). A map is
+// keyed by the record key, whereas a slice is filled in the same order as keys. Either way, missing keys are skipped.
+//
+//	users := map[string]User{}
+//	err := rod.GetManyJson(tx, "user", []string{"chilts", "bob"}, &users)
+//
+//	var posts []*Post
+//	err := rod.GetManyJson(tx, "post", ids, &posts)
+func GetManyJson(tx Tx, location string, keys []string, to interface{}) error {
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr {
+		return wrapErr("GetManyJson", location, "", ErrMapOrSlicePtrNeeded)
+	}
+	container := reflect.Indirect(ref)
+	kind := container.Kind()
+	if kind != reflect.Map && kind != reflect.Slice {
+		return wrapErr("GetManyJson", location, "", ErrMapOrSlicePtrNeeded)
+	}
+	if kind == reflect.Map && container.Type().Key().Kind() != reflect.String {
+		return wrapErr("GetManyJson", location, "", ErrMapOrSlicePtrNeeded)
+	}
+
+	// figure out the element type, and whether we're to hand back pointers to it
+	elemType := container.Type().Elem()
+	isPtrWanted := false
+	if elemType.Kind() == reflect.Ptr {
+		isPtrWanted = true
+		elemType = elemType.Elem()
+	}
+
+	values, err := GetMany(tx, location, keys)
+	if err != nil {
+		return wrapErr("GetManyJson", location, "", err)
+	}
+
+	// create the new container
+	var results reflect.Value
+	if kind == reflect.Map {
+		results = container
+		if results.IsNil() {
+			results = reflect.MakeMapWithSize(container.Type(), len(values))
+		}
+	} else {
+		results = reflect.MakeSlice(container.Type(), 0, len(values))
+	}
+
+	for _, key := range keys {
+		raw, ok := values[key]
+		if !ok {
+			continue
+		}
+
+		item := reflect.New(elemType)
+		if err := loadJson(tx, raw, item.Interface()); err != nil {
+			return wrapErr("GetManyJson", location, key, err)
+		}
+		if !isPtrWanted {
+			item = reflect.Indirect(item)
+		}
+
+		if kind == reflect.Map {
+			results.SetMapIndex(reflect.ValueOf(key).Convert(container.Type().Key()), item)
+		} else {
+			results = reflect.Append(results, item)
+		}
+	}
+
+	container.Set(results)
+
+	return nil
+}
+
+// PutMany puts every one of the items into the bucket at location, creating the bucket hierarchy (once) first, just
+// like Put. Items are written in key order. By default every item is attempted, and any which fail are returned
+// together as KeyErrors, but see AbortOnError. An error with the location itself is returned on its own since nothing
+// could be written.
+func PutMany(tx Tx, location string, items map[string][]byte, opts ...BatchOption) error {
+	opt := newBatch(opts)
+
+	b, err := createBucket(tx, location)
+	if err != nil {
+		return wrapErr("PutMany", location, "", err)
+	}
+
+	// writing in order is kinder to Bolt
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := KeyErrors{}
+	for _, key := range keys {
+		if key == "" {
+			err = ErrKeyNotProvided
+		} else {
+			err = putKey(tx, b, location, []byte(key), items[key])
+		}
+		if err != nil {
+			errs[key] = wrapErr("PutMany", location, key, err)
+			if opt.abort {
+				break
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// PutManyJson prepares and serialises each of the items just as PutJson does (hooks, tagged fields, encryption and any
+// schema included) and then calls PutMany with the results. Any item which fails to marshal is reported in the
+// returned KeyErrors alongside any which fail to be put. With AbortOnError, nothing is put if any item fails to
+// marshal. As with PutJson, items which are json.RawMessage or []byte are put as they are, as long as they are valid
+// JSON.
+func PutManyJson[T any](tx Tx, location string, items map[string]T, opts ...BatchOption) error {
+	opt := newBatch(opts)
+
+	errs := KeyErrors{}
+	raw := make(map[string][]byte, len(items))
+	dones := make(map[string]func(), len(items))
+	for key, v := range items {
+		value, done, err := encodeJson(tx, location, key, v, nil)
+		if err != nil {
+			errs[key] = wrapErr("PutManyJson", location, key, err)
+			if opt.abort {
+				return errs
+			}
+			continue
+		}
+		raw[key] = value
+		dones[key] = done
+	}
+
+	putErrs := KeyErrors{}
+	if err := PutMany(tx, location, raw, opts...); err != nil {
+		var ok bool
+		if putErrs, ok = err.(KeyErrors); !ok {
+			return wrapErr("PutManyJson", location, "", err)
+		}
+		for key, err := range putErrs {
+			errs[key] = err
+		}
+	}
+
+	// hand back the new versions and timestamps of those which were put, which with AbortOnError is only if all were
+	if !opt.abort || len(putErrs) == 0 {
+		for key, done := range dones {
+			if _, failed := putErrs[key]; !failed {
+				done()
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DelMany deletes each of the keys from the bucket at location, with the location only being resolved once, and tells
+// you how many of them actually existed. As with Del, missing keys (or a missing bucket) are not an error. As with
+// PutMany, by default every key is attempted, and any which fail are returned together as KeyErrors, but see
+// AbortOnError. An empty key fails before anything is deleted, as does an error with the location, which is returned
+// on its own.
+func DelMany(tx Tx, location string, keys []string, opts ...BatchOption) (int, error) {
+	opt := newBatch(opts)
+
+	for _, key := range keys {
+		if key == "" {
+			return 0, KeyErrors{key: wrapErr("DelMany", location, key, ErrKeyNotProvided)}
+		}
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return 0, wrapErr("DelMany", location, "", err)
+	}
+	if b == nil {
+		return 0, nil
+	}
+
+	deleted := 0
+	errs := KeyErrors{}
+	c := b.Cursor()
+	for _, key := range keys {
+		k, v := c.Seek([]byte(key))
+		if k == nil || string(k) != key || isBucket(b, k, v) {
+			continue
+		}
+		if err := delKey(tx, b, location, []byte(key)); err != nil {
+			errs[key] = wrapErr("DelMany", location, key, err)
+			if opt.abort {
+				break
+			}
+			continue
+		}
+		deleted++
+	}
+
+	if len(errs) > 0 {
+		return deleted, errs
+	}
+	return deleted, nil
+}
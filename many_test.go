@@ -0,0 +1,267 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestGetMany(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 1}))
+		check(PutJson(tx, "user", "bob", User{"bob", 2}))
+		check(PutJson(tx, "user", "carol", User{"carol", 3}))
+		return nil
+	})
+	check(err)
+
+	keys := []string{"carol", "nobody", "alice"}
+
+	t.Run("GetMany", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			values, err := GetMany(tx, "user", keys)
+			check(err)
+			if len(values) != 2 {
+				t.Fatalf("Expected 2 values but got %d", len(values))
+			}
+			if _, ok := values["nobody"]; ok {
+				t.Fatal("Missing keys should be skipped")
+			}
+
+			values, err = GetMany(tx, "does-not-exist", keys)
+			check(err)
+			if len(values) != 0 {
+				t.Fatalf("Expected no values from a missing bucket but got %d", len(values))
+			}
+
+			if _, err := GetMany(tx, "user", []string{"alice", ""}); !errors.Is(err, ErrKeyNotProvided) {
+				t.Fatalf("Expected ErrKeyNotProvided but got %v", err)
+			}
+
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("GetManyJson into a map", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			users := make(map[string]User)
+			check(GetManyJson(tx, "user", keys, &users))
+			if len(users) != 2 || users["carol"].Logins != 3 {
+				t.Fatalf("Map was not filled in correctly: %#v", users)
+			}
+
+			// a nil map is created for us
+			var ptrs map[string]*User
+			check(GetManyJson(tx, "user", keys, &ptrs))
+			if len(ptrs) != 2 || ptrs["alice"].Username != "alice" {
+				t.Fatalf("Map was not filled in correctly: %#v", ptrs)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("GetManyJson into a slice", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var users []User
+			check(GetManyJson(tx, "user", keys, &users))
+			if len(users) != 2 {
+				t.Fatalf("Expected 2 users but got %d", len(users))
+			}
+			if users[0].Username != "carol" || users[1].Username != "alice" {
+				t.Fatalf("Users should be in the same order as the keys: %#v", users)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("GetManyJson with a bad target", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var user User
+			if err := GetManyJson(tx, "user", keys, &user); !errors.Is(err, ErrMapOrSlicePtrNeeded) {
+				t.Fatalf("Expected ErrMapOrSlicePtrNeeded but got %v", err)
+			}
+			if err := GetManyJson(tx, "user", keys, map[string]User{}); !errors.Is(err, ErrMapOrSlicePtrNeeded) {
+				t.Fatalf("Expected ErrMapOrSlicePtrNeeded but got %v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
+
+func TestPutMany(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("PutMany", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutMany(tx, "colour.primary", map[string][]byte{
+				"red":   []byte("#f00"),
+				"green": []byte("#0f0"),
+				"blue":  []byte("#00f"),
+			}))
+
+			n, err := Count(tx, "colour.primary")
+			check(err)
+			if n != 3 {
+				t.Fatalf("Expected 3 colours but got %d", n)
+			}
+
+			green, err := GetString(tx, "colour.primary", "green")
+			check(err)
+			if green != "#0f0" {
+				t.Fatalf("Expected green to be #0f0 but got %s", green)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("PutMany with a bad key", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			err := PutMany(tx, "colour.secondary", map[string][]byte{
+				"cyan": []byte("#0ff"),
+				"":     []byte("#fff"),
+			})
+			errs, ok := err.(KeyErrors)
+			if !ok {
+				t.Fatalf("Expected KeyErrors but got %v", err)
+			}
+			if len(errs) != 1 || !errors.Is(errs[""], ErrKeyNotProvided) {
+				t.Fatalf("Expected only the empty key to fail: %v", errs)
+			}
+
+			// the good one should still have been written
+			exists, err := Exists(tx, "colour.secondary", "cyan")
+			check(err)
+			if !exists {
+				t.Fatal("The valid key should still have been written")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("PutMany with AbortOnError", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			err := PutMany(tx, "colour.tertiary", map[string][]byte{
+				"":       []byte("#fff"),
+				"orange": []byte("#f80"),
+			}, AbortOnError())
+			errs, ok := err.(KeyErrors)
+			if !ok || len(errs) != 1 || !errors.Is(err, ErrKeyNotProvided) {
+				t.Fatalf("Expected KeyErrors holding just the empty key but got %v", err)
+			}
+
+			// the empty key sorts first, so nothing after it should have been written
+			exists, err := Exists(tx, "colour.tertiary", "orange")
+			check(err)
+			if exists {
+				t.Fatal("Nothing should have been written after the failure")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("PutMany with a bad location", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return PutMany(tx, "colour..bad", map[string][]byte{"a": nil})
+		})
+		if !errors.Is(err, ErrInvalidLocationBucket) {
+			t.Fatalf("Expected ErrInvalidLocationBucket but got %v", err)
+		}
+	})
+
+	t.Run("PutManyJson", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutManyJson(tx, "user", map[string]User{
+				"alice": {"alice", 1},
+				"bob":   {"bob", 2},
+			}))
+
+			var bob User
+			check(GetJson(tx, "user", "bob", &bob))
+			if bob.Logins != 2 {
+				t.Fatalf("Expected bob to have 2 logins but got %d", bob.Logins)
+			}
+
+			err := PutManyJson(tx, "bad", map[string]interface{}{
+				"ok":  1,
+				"bad": make(chan int),
+			})
+			errs, ok := err.(KeyErrors)
+			if !ok || len(errs) != 1 || errs["bad"] == nil {
+				t.Fatalf("Expected only the channel to fail to marshal: %v", err)
+			}
+
+			err = PutManyJson(tx, "worse", map[string]interface{}{
+				"ok":  1,
+				"bad": make(chan int),
+			}, AbortOnError())
+			if _, ok := err.(KeyErrors); !ok {
+				t.Fatalf("Expected KeyErrors but got %v", err)
+			}
+			exists, err := Exists(tx, "worse", "ok")
+			check(err)
+			if exists {
+				t.Fatal("Nothing should be put when aborting on a marshal error")
+			}
+			return nil
+		})
+		check(err)
+	})
+}
+
+func TestDelMany(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutMany(tx, "fruit", map[string][]byte{
+			"apple":  []byte("red"),
+			"banana": []byte("yellow"),
+			"cherry": []byte("red"),
+		}))
+		check(PutString(tx, "fruit.citrus", "lemon", "yellow"))
+
+		n, err := DelMany(tx, "fruit", []string{"apple", "cherry", "durian", "citrus"})
+		check(err)
+		if n != 2 {
+			t.Fatalf("Expected 2 keys to have been deleted but got %d", n)
+		}
+
+		keys, err := AllKeys(tx, "fruit")
+		check(err)
+		if len(keys) != 2 || keys[0] != "banana" || keys[1] != "citrus" {
+			t.Fatalf("Only banana and the nested citrus bucket should be left: %v", keys)
+		}
+
+		n, err = DelMany(tx, "does-not-exist", []string{"apple"})
+		check(err)
+		if n != 0 {
+			t.Fatalf("Nothing should be deleted from a missing bucket but got %d", n)
+		}
+
+		_, err = DelMany(tx, "fruit", []string{"banana", ""})
+		var errs KeyErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs[""], ErrKeyNotProvided) {
+			t.Fatalf("Expected KeyErrors holding ErrKeyNotProvided but got %v", err)
+		}
+		exists, err := Exists(tx, "fruit", "banana")
+		check(err)
+		if !exists {
+			t.Fatal("Nothing should be deleted if any key is invalid")
+		}
+
+		return nil
+	})
+	check(err)
+}
@@ -0,0 +1,314 @@
+package rod
+
+import (
+	"container/list"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// ErrInvalidTenant is returned if a tenant ID is empty, or isn't safe to use as a filename.
+	ErrInvalidTenant = errors.New("invalid tenant")
+
+	// ErrMultiClosed is returned if a Multi is used after it has been closed.
+	ErrMultiClosed = errors.New("multi is closed")
+)
+
+// DefaultMaxOpen is how many tenants' files a Multi keeps open at once, unless told otherwise with MaxOpen.
+const DefaultMaxOpen = 64
+
+// MultiOption changes how a Multi opens and keeps its tenants' files.
+type MultiOption func(*Multi)
+
+// MaxOpen sets how many tenants' files may be open at once. Once there are more, the least recently used one which
+// isn't in the middle of a transaction is closed.
+func MaxOpen(n int) MultiOption {
+	return func(m *Multi) {
+		m.maxOpen = n
+	}
+}
+
+// IdleTimeout closes a tenant's file once it hasn't been used for this long. With 0 (the default) files are only
+// closed to stay within MaxOpen.
+func IdleTimeout(d time.Duration) MultiOption {
+	return func(m *Multi) {
+		m.idle = d
+	}
+}
+
+// FileOptions sets the mode and options each tenant's file is opened with, which are passed straight through to
+// bolt.Open(). The default is 0600 and no options.
+func FileOptions(mode os.FileMode, options *bolt.Options) MultiOption {
+	return func(m *Multi) {
+		m.mode = mode
+		m.options = options
+	}
+}
+
+// OnOpen calls fn with every tenant's Store as it is opened, so that features such as EnableAudit or SetSchema can be
+// set up on each one. If fn returns an error the Store is closed again and the error returned.
+func OnOpen(fn func(tenant string, s *Store) error) MultiOption {
+	return func(m *Multi) {
+		m.onOpen = fn
+	}
+}
+
+// Multi gives every tenant its own Bolt file, named "<tenant>.db" in a directory, for hard isolation between them.
+// Files are opened when first used and kept open for the next time, up to MaxOpen of them.
+//
+//	tenants := rod.NewMulti("/var/lib/app/tenants", rod.MaxOpen(100), rod.IdleTimeout(10*time.Minute))
+//	defer tenants.Close()
+//
+//	err := tenants.Update("acme", func(tx *bolt.Tx) error {
+//	    return rod.PutJson(tx, "user", "chilts", &user)
+//	})
+//
+// Tenant IDs may only contain letters, digits, '-', '_' and '.', and may not start with a '.'.
+type Multi struct {
+	dir     string
+	maxOpen int
+	idle    time.Duration
+	mode    os.FileMode
+	options *bolt.Options
+	onOpen  func(tenant string, s *Store) error
+
+	mu     sync.Mutex
+	open   map[string]*list.Element
+	lru    *list.List // of *tenantStore, most recently used first
+	closed bool
+	done   chan struct{}
+}
+
+// tenantStore is an open tenant's Store, and how it is being used. It is in the Multi from the moment the file starts
+// being opened, so that only one Open happens for each tenant, but store and err are only set once ready is closed.
+type tenantStore struct {
+	tenant   string
+	store    *Store
+	err      error
+	ready    chan struct{}
+	refs     int
+	lastUsed time.Time
+}
+
+// NewMulti returns a Multi keeping its tenants' files in dir, which must already exist.
+func NewMulti(dir string, opts ...MultiOption) *Multi {
+	m := &Multi{
+		dir:     dir,
+		maxOpen: DefaultMaxOpen,
+		mode:    0600,
+		open:    make(map[string]*list.Element),
+		lru:     list.New(),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.idle > 0 {
+		go m.closeIdle()
+	}
+	return m
+}
+
+// validTenant checks tenant is safe to use as a filename.
+func validTenant(tenant string) bool {
+	if tenant == "" || strings.HasPrefix(tenant, ".") {
+		return false
+	}
+	for _, r := range tenant {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.'
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Acquire returns the Store for tenant, opening it if needed. The Store won't be closed until you call release, so
+// call it as soon as you're done. Update and View do all of this for you. The file is opened without holding up any
+// other tenant, while anyone else wanting the same tenant waits for it.
+func (m *Multi) Acquire(tenant string) (store *Store, release func(), err error) {
+	if !validTenant(tenant) {
+		return nil, nil, ErrInvalidTenant
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, nil, ErrMultiClosed
+	}
+
+	el, ok := m.open[tenant]
+	if ok {
+		m.lru.MoveToFront(el)
+	} else {
+		m.evict(m.maxOpen - 1)
+		el = m.lru.PushFront(&tenantStore{tenant: tenant, ready: make(chan struct{})})
+		m.open[tenant] = el
+	}
+	ts := el.Value.(*tenantStore)
+	ts.refs++
+	m.mu.Unlock()
+
+	if ok {
+		<-ts.ready
+	} else {
+		m.openTenant(el)
+	}
+	if ts.err != nil {
+		return nil, nil, ts.err
+	}
+
+	var once sync.Once
+	return ts.store, func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			ts.refs--
+			ts.lastUsed = time.Now()
+		})
+	}, nil
+}
+
+// openTenant opens the file for the placeholder el, then either sets its store or, if that failed, its error and
+// forgets it, before telling anyone waiting that it is ready.
+func (m *Multi) openTenant(el *list.Element) {
+	ts := el.Value.(*tenantStore)
+	s, err := Open(filepath.Join(m.dir, ts.tenant+".db"), m.mode, m.options)
+	if err == nil && m.onOpen != nil {
+		if err = m.onOpen(ts.tenant, s); err != nil {
+			s.Close()
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil && m.closed {
+		s.Close()
+		err = ErrMultiClosed
+	}
+	if err != nil {
+		ts.err = err
+		if !m.closed {
+			m.lru.Remove(el)
+			delete(m.open, ts.tenant)
+		}
+	} else {
+		ts.store = s
+	}
+	close(ts.ready)
+}
+
+// evict closes the least recently used files which aren't in use until at most n are open.
+func (m *Multi) evict(n int) {
+	for el := m.lru.Back(); el != nil && m.lru.Len() > n; {
+		prev := el.Prev()
+		if ts := el.Value.(*tenantStore); ts.refs == 0 {
+			m.remove(el)
+		}
+		el = prev
+	}
+}
+
+// remove closes the file for el and forgets it.
+func (m *Multi) remove(el *list.Element) {
+	ts := el.Value.(*tenantStore)
+	ts.store.Close()
+	m.lru.Remove(el)
+	delete(m.open, ts.tenant)
+}
+
+// closeIdle closes the files which have been idle for too long, until the Multi is closed.
+func (m *Multi) closeIdle() {
+	ticker := time.NewTicker(m.idle / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		for el := m.lru.Back(); el != nil; {
+			prev := el.Prev()
+			if ts := el.Value.(*tenantStore); ts.refs == 0 && time.Since(ts.lastUsed) >= m.idle {
+				m.remove(el)
+			}
+			el = prev
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Update is the same as Store.Update, on tenant's Store.
+func (m *Multi) Update(tenant string, fn func(tx *bolt.Tx) error) error {
+	return m.UpdateAs(tenant, "", fn)
+}
+
+// UpdateAs is the same as Store.UpdateAs, on tenant's Store.
+func (m *Multi) UpdateAs(tenant, actor string, fn func(tx *bolt.Tx) error) error {
+	store, release, err := m.Acquire(tenant)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return store.UpdateAs(actor, fn)
+}
+
+// View is the same as Store.View, on tenant's Store.
+func (m *Multi) View(tenant string, fn func(tx *bolt.Tx) error) error {
+	store, release, err := m.Acquire(tenant)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return store.View(fn)
+}
+
+// OpenTenants returns the tenants whose files are currently open, in order.
+func (m *Multi) OpenTenants() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tenants := make([]string, 0, len(m.open))
+	for tenant := range m.open {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Close closes every tenant's file. It should only be called once nothing is using the Multi.
+func (m *Multi) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	close(m.done)
+
+	// any file still being opened is closed by openTenant, once it sees the Multi is closed
+	var first error
+	for el := m.lru.Front(); el != nil; el = el.Next() {
+		ts := el.Value.(*tenantStore)
+		if ts.store == nil {
+			continue
+		}
+		if err := ts.store.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	m.open = nil
+	m.lru.Init()
+	return first
+}
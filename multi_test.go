@@ -0,0 +1,177 @@
+package rod
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestMulti(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	t.Run("Every tenant has its own file", func(t *testing.T) {
+		tenants := NewMulti(dir)
+		defer tenants.Close()
+
+		for _, tenant := range []string{"acme", "globex"} {
+			err := tenants.Update(tenant, func(tx *bolt.Tx) error {
+				return PutString(tx, "config", "name", tenant)
+			})
+			check(err)
+		}
+
+		err := tenants.View("acme", func(tx *bolt.Tx) error {
+			name, err := GetString(tx, "config", "name")
+			check(err)
+			if name != "acme" {
+				t.Fatalf("Expected acme's own name but got '%s'", name)
+			}
+			return nil
+		})
+		check(err)
+
+		if _, err := os.Stat(filepath.Join(dir, "globex.db")); err != nil {
+			t.Fatalf("Expected globex to have its own file: %v", err)
+		}
+	})
+
+	t.Run("Invalid tenants", func(t *testing.T) {
+		tenants := NewMulti(dir)
+		defer tenants.Close()
+
+		for _, tenant := range []string{"", "../etc", ".hidden", "a/b"} {
+			err := tenants.View(tenant, func(tx *bolt.Tx) error { return nil })
+			if !errors.Is(err, ErrInvalidTenant) {
+				t.Fatalf("Expected ErrInvalidTenant for '%s' but got %v", tenant, err)
+			}
+		}
+	})
+
+	t.Run("MaxOpen closes the least recently used", func(t *testing.T) {
+		tenants := NewMulti(dir, MaxOpen(2))
+		defer tenants.Close()
+
+		view := func(tenant string) {
+			check(tenants.View(tenant, func(tx *bolt.Tx) error { return nil }))
+		}
+		view("a")
+		view("b")
+		view("a")
+		view("c")
+
+		open := tenants.OpenTenants()
+		if len(open) != 2 || open[0] != "a" || open[1] != "c" {
+			t.Fatalf("Expected a and c to be open but got %v", open)
+		}
+
+		// a file in use is never closed, even to stay within MaxOpen
+		err := tenants.View("b", func(tx *bolt.Tx) error {
+			view("d")
+			if len(tenants.OpenTenants()) != 2 {
+				t.Fatalf("Expected only b and d to be left open but got %v", tenants.OpenTenants())
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("IdleTimeout", func(t *testing.T) {
+		tenants := NewMulti(dir, IdleTimeout(20*time.Millisecond))
+		defer tenants.Close()
+
+		check(tenants.View("acme", func(tx *bolt.Tx) error { return nil }))
+		time.Sleep(100 * time.Millisecond)
+		if open := tenants.OpenTenants(); len(open) != 0 {
+			t.Fatalf("Expected the idle file to have been closed, but %v are open", open)
+		}
+	})
+
+	t.Run("OnOpen and Closed", func(t *testing.T) {
+		opened := 0
+		tenants := NewMulti(dir, OnOpen(func(tenant string, s *Store) error {
+			opened++
+			s.EnableChangelog()
+			return nil
+		}))
+
+		err := tenants.Update("acme", func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "plan", "gold")
+		})
+		check(err)
+		if opened != 1 {
+			t.Fatalf("Expected OnOpen to be called once but it was called %d times", opened)
+		}
+
+		check(tenants.Close())
+		if err := tenants.View("acme", func(tx *bolt.Tx) error { return nil }); !errors.Is(err, ErrMultiClosed) {
+			t.Fatalf("Expected ErrMultiClosed but got %v", err)
+		}
+	})
+
+	t.Run("Opening a tenant doesn't hold up the others", func(t *testing.T) {
+		opening := make(chan struct{})
+		carryOn := make(chan struct{})
+		tenants := NewMulti(dir, OnOpen(func(tenant string, s *Store) error {
+			if tenant == "slow" {
+				close(opening)
+				<-carryOn
+			}
+			return nil
+		}))
+		defer tenants.Close()
+
+		stores := make(chan *Store, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				store, release, err := tenants.Acquire("slow")
+				check(err)
+				defer release()
+				stores <- store
+			}()
+		}
+		<-opening
+
+		// while "slow" is still being opened, another tenant can be used
+		err := tenants.Update("fast", func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "plan", "gold")
+		})
+		check(err)
+		close(carryOn)
+
+		// and everyone waiting for "slow" gets the one Store
+		if a, b := <-stores, <-stores; a == nil || a != b {
+			t.Fatalf("Expected both to get the same Store, but got %p and %p", a, b)
+		}
+	})
+
+	t.Run("A tenant which fails to open isn't kept", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		fail := true
+		tenants := NewMulti(dir, OnOpen(func(tenant string, s *Store) error {
+			if fail {
+				return errBoom
+			}
+			return nil
+		}))
+		defer tenants.Close()
+
+		if _, _, err := tenants.Acquire("flaky"); err != errBoom {
+			t.Fatalf("Expected the OnOpen error but got %v", err)
+		}
+		if open := tenants.OpenTenants(); len(open) != 0 {
+			t.Fatalf("Nothing should be open, but %v are", open)
+		}
+
+		fail = false
+		_, release, err := tenants.Acquire("flaky")
+		check(err)
+		release()
+	})
+}
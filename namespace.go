@@ -0,0 +1,132 @@
+package rod
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+// Namespace is a view of a Store in which every location is beneath the Namespace's own, so that each tenant of a
+// multi-tenant app can have its own "users" and so on without every call site having to remember to add the tenant.
+// The transaction given to its Update and View is a Tx whose top-level buckets are those beneath the Namespace's
+// location, so everything in rod which takes a Tx (Get, Put, Del, All, Find, Each, Count and the rest) stays inside
+// it:
+//
+//	tenant := store.Namespace("tenant-42")
+//	err := tenant.Update(func(tx rod.Tx) error {
+//	    return rod.PutJson(tx, "users", "chilts", &user) // at "tenant-42.users"
+//	})
+//
+// Features enabled on the Store (such as the changelog, audit log and watchers) see the full location of each change,
+// such as "tenant-42.users". Views added with AddView apply within each Namespace too, so a view of "users" into
+// "users-by-email" keeps "tenant-42.users-by-email" up to date from "tenant-42.users". Since the Tx isn't a *bolt.Tx,
+// Bolt's own methods can't be used on it, which would go around the Namespace anyway.
+type Namespace struct {
+	store    *Store
+	location string
+}
+
+// Namespace returns the Namespace at location, which may itself be nested, such as "tenants.42".
+func (s *Store) Namespace(location string) *Namespace {
+	return &Namespace{store: s, location: location}
+}
+
+// Namespace returns the Namespace at location beneath this one.
+func (n *Namespace) Namespace(location string) *Namespace {
+	return &Namespace{store: n.store, location: n.location + "." + location}
+}
+
+// Location returns the location everything in the Namespace is beneath.
+func (n *Namespace) Location() string {
+	return n.location
+}
+
+// Update runs fn inside a read-write transaction of the Store, as with Store.Update, but with a Tx rooted at the
+// Namespace.
+func (n *Namespace) Update(fn func(tx Tx) error) error {
+	return n.UpdateAs("", fn)
+}
+
+// UpdateAs is the same as Update except the writes are attributed to actor, as with Store.UpdateAs.
+func (n *Namespace) UpdateAs(actor string, fn func(tx Tx) error) error {
+	return n.store.UpdateAs(actor, func(tx *bolt.Tx) error {
+		return fn(&namespaceTx{tx: tx, location: n.location})
+	})
+}
+
+// View runs fn inside a read-only transaction of the Store, as with Store.View, but with a Tx rooted at the
+// Namespace.
+func (n *Namespace) View(fn func(tx Tx) error) error {
+	return n.store.View(func(tx *bolt.Tx) error {
+		return fn(&namespaceTx{tx: tx, location: n.location})
+	})
+}
+
+// Stats returns the statistics of the Store's database as with Stats, except that Locations holds the number of keys
+// in each of the Namespace's own top-level buckets. They are counted afresh each time, rather than being cached.
+func (n *Namespace) Stats() (DBStats, error) {
+	var stats DBStats
+	err := n.View(func(tx Tx) error {
+		btx := tx.(*namespaceTx).tx.(*bolt.Tx)
+		stats.PageSize = btx.DB().Info().PageSize
+		stats.Size = btx.Size()
+		stats.Locations = make(map[string]int)
+
+		root, err := getBucket(btx, n.location)
+		if err != nil || root == nil {
+			return err
+		}
+		c := root.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v != nil {
+				continue
+			}
+			count, err := Count(tx, string(k))
+			if err != nil {
+				return err
+			}
+			stats.Locations[string(k)] = count
+		}
+		return nil
+	})
+	if err != nil {
+		return DBStats{}, wrapErr("Stats", n.location, "", err)
+	}
+
+	stats.Bolt = n.store.DB().Stats()
+	stats.CountedAt = now()
+	return stats, nil
+}
+
+// namespaceTx is the Tx given to a Namespace's Update and View, whose top-level buckets are those beneath location.
+type namespaceTx struct {
+	tx       Tx
+	location string
+}
+
+func (t *namespaceTx) Writable() bool {
+	return t.tx.Writable()
+}
+
+func (t *namespaceTx) Bucket(name []byte) Bucket {
+	root, err := getBucket(t.tx, t.location)
+	if err != nil || root == nil {
+		return nil
+	}
+	return root.Bucket(name)
+}
+
+func (t *namespaceTx) CreateBucketIfNotExists(name []byte) (Bucket, error) {
+	root, err := createBucket(t.tx, t.location)
+	if err != nil {
+		return nil, err
+	}
+	return root.CreateBucketIfNotExists(name)
+}
+
+// rooted returns the transaction tx wraps if it is a Namespace's, along with location as seen from there and the
+// Namespace's own location. Otherwise it returns tx and location as they are.
+func rooted(tx Tx, location string) (Tx, string, string) {
+	if t, ok := tx.(*namespaceTx); ok {
+		return t.tx, t.location + "." + location, t.location
+	}
+	return tx, location, ""
+}
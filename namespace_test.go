@@ -0,0 +1,102 @@
+package rod
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestNamespace(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	s := NewStore(db)
+
+	var changes []string
+	s.Watch("tenant-42", func(m Mutation) {
+		changes = append(changes, m.Op+" "+m.Location+"/"+m.Key)
+	})
+	s.AddView(MaterializedView{
+		Source: "users",
+		Target: "users-by-email",
+		Transform: func(key string, value []byte) (string, []byte, bool) {
+			var u struct{ Email string }
+			if json.Unmarshal(value, &u) != nil || u.Email == "" {
+				return "", nil, false
+			}
+			return u.Email, []byte(key), true
+		},
+	})
+
+	a := s.Namespace("tenant-42")
+	b := s.Namespace("tenants").Namespace("43")
+
+	t.Run("Locations are rooted in the namespace", func(t *testing.T) {
+		check(a.Update(func(tx Tx) error {
+			check(PutJson(tx, "users", "chilts", map[string]string{"Email": "andy@example.com"}))
+			return PutJson(tx, "users", "bob", map[string]string{"Email": "bob@example.com"})
+		}))
+		check(b.Update(func(tx Tx) error {
+			return PutJson(tx, "users", "carol", map[string]string{"Email": "carol@example.com"})
+		}))
+
+		check(a.View(func(tx Tx) error {
+			keys, err := AllKeys(tx, "users")
+			check(err)
+			if len(keys) != 2 || keys[0] != "bob" || keys[1] != "chilts" {
+				t.Fatalf("The namespace should only see its own users: %v", keys)
+			}
+			return nil
+		}))
+
+		check(db.View(func(tx *bolt.Tx) error {
+			n, err := Count(tx, "tenant-42.users")
+			check(err)
+			m, err := Count(tx, "tenants.43.users")
+			check(err)
+			u, err := GetBucket(tx, "users")
+			check(err)
+			if n != 2 || m != 1 || u != nil {
+				t.Fatalf("The users should be beneath their namespaces: %d %d %v", n, m, u)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Deletes, views and watchers", func(t *testing.T) {
+		check(a.Update(func(tx Tx) error {
+			return Del(tx, "users", "bob")
+		}))
+		// the view's own changes are seen too
+		if len(changes) != 6 || changes[0] != "put tenant-42.users/chilts" || changes[1] != "put tenant-42.users-by-email/andy@example.com" || changes[4] != "del tenant-42.users/bob" {
+			t.Fatalf("Watchers should see the full location: %v", changes)
+		}
+
+		check(a.View(func(tx Tx) error {
+			key, err := GetString(tx, "users-by-email", "andy@example.com")
+			check(err)
+			gone, err := Exists(tx, "users-by-email", "bob@example.com")
+			check(err)
+			if key != "chilts" || gone {
+				t.Fatalf("The view should be kept within the namespace: %q %v", key, gone)
+			}
+			return nil
+		}))
+		check(b.View(func(tx Tx) error {
+			key, err := GetString(tx, "users-by-email", "carol@example.com")
+			check(err)
+			if key != "carol" {
+				t.Fatalf("Each namespace should have its own view: %q", key)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		stats, err := a.Stats()
+		check(err)
+		if len(stats.Locations) != 2 || stats.Locations["users"] != 1 || stats.Locations["users-by-email"] != 1 {
+			t.Fatalf("Unexpected locations: %v", stats.Locations)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package rod
+
+import (
+	"time"
+)
+
+// Operation is a single operation made through a Store, as told to the observers added with Observe.
+type Operation struct {
+	// Name is "update" or "view" for a whole transaction, "get", "put" or "del" for a single key within one, or "scan"
+	// for going through a whole location (such as with All, Find or Each).
+	Name     string
+	Location string
+	Key      string
+	// Tx is the transaction a single key operation was made in, and nil for a whole transaction.
+	Tx Tx
+	// Bytes is the size of the value read or written, or of all of them for a scan.
+	Bytes int
+	// Items is the number of keys a scan went through.
+	Items    int
+	Start    time.Time
+	Duration time.Duration
+	// Err is the error the operation failed with, if any.
+	Err error
+}
+
+// Observe calls fn after every operation made through the Store, such as to collect metrics (see rodprom). Reads are
+// seen when made with Get (and the functions built on it, such as GetJson) or scanned with All, Find, Each, AllKeys or
+// AllValues, and writes from every rod function. It should be called before the Store is used, and fn should be quick
+// since it is called inside the transaction.
+func (s *Store) Observe(fn func(op Operation)) {
+	s.observers = append(s.observers, fn)
+}
+
+// OnSlow calls fn for every operation made through the Store (see Observe) which takes longer than threshold, including
+// whole transactions and scans, so you can find what is holding the write lock. Each Operation has the location and,
+// for a scan, how many items it went through. Use LogSlow instead to just log them.
+//
+//	s.OnSlow(100*time.Millisecond, func(op rod.Operation) {
+//	    log.Printf("slow %s of %q (%d items) took %s", op.Name, op.Location, op.Items, op.Duration)
+//	})
+func (s *Store) OnSlow(threshold time.Duration, fn func(op Operation)) {
+	s.Observe(func(op Operation) {
+		if op.Duration > threshold {
+			fn(op)
+		}
+	})
+}
+
+// observe tells the Store's observers about op.
+func (s *Store) observe(op Operation) {
+	for _, fn := range s.observers {
+		fn(op)
+	}
+}
+
+// observing returns the txState of tx if its Store has observers, else nil.
+func observing(tx Tx) *txState {
+	st := stateOf(tx)
+	if st == nil || len(st.store.observers) == 0 {
+		return nil
+	}
+	return st
+}
+
+// scan observes going through a location, for a Store with observers. A nil *scan does nothing.
+type scan struct {
+	st       *txState
+	tx       Tx
+	location string
+	start    time.Time
+	items    int
+	bytes    int
+}
+
+// startScan starts observing a scan of location within tx, returning nil if its Store has no observers.
+func startScan(tx Tx, location string) *scan {
+	st := observing(tx)
+	if st == nil {
+		return nil
+	}
+	root, location, _ := rooted(tx, location)
+	return &scan{st: st, tx: root, location: location, start: time.Now()}
+}
+
+// item counts one more key, with value raw.
+func (s *scan) item(raw []byte) {
+	if s != nil {
+		s.items++
+		s.bytes += len(raw)
+	}
+}
+
+// done tells the observers about the scan, and returns err.
+func (s *scan) done(err error) error {
+	if s != nil {
+		s.st.store.observe(Operation{
+			Name:     "scan",
+			Location: s.location,
+			Tx:       s.tx,
+			Bytes:    s.bytes,
+			Items:    s.items,
+			Start:    s.start,
+			Duration: time.Since(s.start),
+			Err:      err,
+		})
+	}
+	return err
+}
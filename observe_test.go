@@ -0,0 +1,129 @@
+package rod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestObserve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	s, err := Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(err)
+	defer s.Close()
+
+	var mu sync.Mutex
+	var ops []Operation
+	s.Observe(func(op Operation) {
+		mu.Lock()
+		defer mu.Unlock()
+		ops = append(ops, op)
+	})
+	names := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var names []string
+		for _, op := range ops {
+			names = append(names, op.Name)
+		}
+		ops = nil
+		return names
+	}
+
+	t.Run("Writes are observed", func(t *testing.T) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "notes", "shopping", "milk"))
+			return Del(tx, "notes", "shopping")
+		}))
+
+		got := names()
+		if len(got) != 3 || got[0] != "put" || got[1] != "del" || got[2] != "update" {
+			t.Fatalf("Unexpected operations %v", got)
+		}
+	})
+
+	t.Run("Reads are observed", func(t *testing.T) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "notes", "work", "report")
+		}))
+		names()
+
+		check(s.View(func(tx *bolt.Tx) error {
+			_, err := GetString(tx, "notes", "work")
+			return err
+		}))
+
+		mu.Lock()
+		got := ops
+		mu.Unlock()
+		if len(got) != 2 || got[0].Name != "get" || got[1].Name != "view" {
+			t.Fatalf("Unexpected operations %#v", got)
+		}
+		if got[0].Location != "notes" || got[0].Key != "work" || got[0].Bytes != 6 {
+			t.Fatalf("Unexpected get %#v", got[0])
+		}
+		names()
+	})
+
+	t.Run("Scans are observed", func(t *testing.T) {
+		check(s.View(func(tx *bolt.Tx) error {
+			var values []string
+			return Each(tx, "notes", func(key string, value []byte) error {
+				values = append(values, string(value))
+				return nil
+			})
+		}))
+
+		mu.Lock()
+		scan := ops[0]
+		mu.Unlock()
+		if scan.Name != "scan" || scan.Location != "notes" || scan.Items != 1 || scan.Bytes != 6 {
+			t.Fatalf("Unexpected scan %#v", scan)
+		}
+		names()
+	})
+
+	t.Run("OnSlow", func(t *testing.T) {
+		var slow []Operation
+		s.OnSlow(10*time.Millisecond, func(op Operation) {
+			slow = append(slow, op)
+		})
+
+		check(s.View(func(tx *bolt.Tx) error {
+			_, err := AllKeys(tx, "notes")
+			check(err)
+			return Each(tx, "notes", func(key string, value []byte) error {
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+		}))
+		names()
+
+		if len(slow) != 2 || slow[0].Name != "scan" || slow[0].Items != 1 || slow[1].Name != "view" {
+			t.Fatalf("Unexpected slow operations %#v", slow)
+		}
+	})
+
+	t.Run("Errors are observed", func(t *testing.T) {
+		err := s.View(func(tx *bolt.Tx) error {
+			return Put(tx, "notes", "nope", []byte("x"))
+		})
+		if err == nil {
+			t.Fatalf("Writing in a View should fail")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if last := ops[len(ops)-1]; last.Name != "view" || last.Err == nil {
+			t.Fatalf("The failed view should have been observed: %#v", last)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package rod
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+
+	"github.com/boltdb/bolt"
+)
+
+// AllParallel calls fn for every key/value in the bucket at location using workers goroutines. The keyspace is split
+// into (roughly) equal partitions using the keys themselves as seek points, then each worker opens its own read-only
+// transaction, seeks to the start of its partition and processes it. This is useful when decoding is the bottleneck
+// of a big scan.
+//
+// Since fn is called concurrently it must be safe to do so. The order in which keys are seen across workers is
+// undefined, though each worker processes its own partition in key order. As with Each, returning Stop from fn halts
+// every worker and nil is returned. Any other error halts every worker and the first one is returned, even if another
+// worker had already returned Stop.
+//
+// The raw value passed to fn is only valid until fn returns.
+func AllParallel(db *bolt.DB, location string, workers int, fn func(key string, raw []byte) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	// figure out where each partition starts
+	var starts [][]byte
+	err := db.View(func(tx *bolt.Tx) error {
+		b, err := BucketAt(tx, location)
+		if err != nil {
+			return wrapErr("AllParallel", location, "", err)
+		}
+		if b == nil {
+			return nil
+		}
+
+		// firstly, count the keys (skipping any nested buckets)
+		count := 0
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if !isBucket(b, k, v) {
+				count++
+			}
+		}
+		if count == 0 {
+			return nil
+		}
+
+		// then take every n'th key as the start of the next partition
+		size := (count + workers - 1) / workers
+		i := 0
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if isBucket(b, k, v) {
+				continue
+			}
+			if i%size == 0 {
+				start := make([]byte, len(k))
+				copy(start, k)
+				starts = append(starts, start)
+			}
+			i++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(starts) == 0 {
+		return nil
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		halted   int32
+	)
+
+	halt := func(err error) {
+		mu.Lock()
+		if err != Stop && firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		atomic.StoreInt32(&halted, 1)
+	}
+
+	for i, start := range starts {
+		var end []byte
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+
+		wg.Add(1)
+		go func(start, end []byte) {
+			defer wg.Done()
+
+			err := db.View(func(tx *bolt.Tx) error {
+				b, err := BucketAt(tx, location)
+				if err != nil || b == nil {
+					return wrapErr("AllParallel", location, "", err)
+				}
+
+				c := b.Cursor()
+				for k, v := c.Seek(start); k != nil; k, v = c.Next() {
+					if end != nil && bytes.Compare(k, end) >= 0 {
+						break
+					}
+					if atomic.LoadInt32(&halted) == 1 {
+						break
+					}
+					if isBucket(b, k, v) {
+						continue
+					}
+					if err := fn(string(k), v); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				halt(err)
+			}
+		}(start, end)
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
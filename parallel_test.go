@@ -0,0 +1,119 @@
+package rod
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestAllParallel(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	total := 1000
+	err := db.Update(func(tx *bolt.Tx) error {
+		for i := 0; i < total; i++ {
+			check(PutJson(tx, "user", fmt.Sprintf("user-%04d", i), User{fmt.Sprintf("user-%04d", i), i}))
+		}
+		return nil
+	})
+	check(err)
+
+	t.Run("Every key is processed exactly once", func(t *testing.T) {
+		var mu sync.Mutex
+		seen := make(map[string]int)
+		err := AllParallel(db, "user", 7, func(key string, raw []byte) error {
+			var user User
+			if err := json.Unmarshal(raw, &user); err != nil {
+				return err
+			}
+			mu.Lock()
+			seen[key]++
+			mu.Unlock()
+			return nil
+		})
+		check(err)
+
+		if len(seen) != total {
+			t.Fatalf("Expected %d keys to be processed, but %d were", total, len(seen))
+		}
+		for key, n := range seen {
+			if n != 1 {
+				t.Fatalf("Key %s was processed %d times", key, n)
+			}
+		}
+	})
+
+	t.Run("More workers than keys", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "small", "only", "one")
+		}))
+
+		var count int32
+		err := AllParallel(db, "small", 16, func(key string, raw []byte) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		})
+		check(err)
+		if count != 1 {
+			t.Fatalf("Expected one key to be processed, but %d were", count)
+		}
+	})
+
+	t.Run("Errors are returned", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		err := AllParallel(db, "user", 4, func(key string, raw []byte) error {
+			return errBoom
+		})
+		if err != errBoom {
+			t.Fatalf("Expected the callback error to be returned, but got %v", err)
+		}
+	})
+
+	t.Run("Stop is not returned", func(t *testing.T) {
+		err := AllParallel(db, "user", 4, func(key string, raw []byte) error {
+			return Stop
+		})
+		if err != nil {
+			t.Fatalf("Stop should not be returned, but got %v", err)
+		}
+	})
+
+	t.Run("Errors are returned after a Stop", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		started := make(chan struct{})
+		stopped := make(chan struct{})
+		// with two workers the second partition starts at user-0500, which fails once the first worker has stopped
+		err := AllParallel(db, "user", 2, func(key string, raw []byte) error {
+			switch key {
+			case "user-0000":
+				<-started
+				close(stopped)
+				return Stop
+			case "user-0500":
+				close(started)
+				<-stopped
+				time.Sleep(10 * time.Millisecond)
+				return errBoom
+			}
+			return nil
+		})
+		if err != errBoom {
+			t.Fatalf("Expected the callback error to be returned, but got %v", err)
+		}
+	})
+
+	t.Run("Missing bucket", func(t *testing.T) {
+		err := AllParallel(db, "does-not-exist", 4, func(key string, raw []byte) error {
+			t.Fatal("Callback should not be called for a missing bucket")
+			return nil
+		})
+		check(err)
+	})
+}
@@ -0,0 +1,386 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPatchTestFailed is returned from PatchJson when a "test" operation doesn't match the document.
+var ErrPatchTestFailed = errors.New("patch test operation failed")
+
+// MergeJson applies patch to the JSON document stored at location/key as per RFC 7386 (JSON Merge Patch) and puts the
+// result back. Fields in the patch replace those in the document, nested objects are merged, and a null removes the
+// field. If the key doesn't exist yet then the patch is applied to an empty document.
+//
+// The result is checked against any schema for location, as PutJson does. Since the document has no Go type here,
+// fields tagged `rod:"encrypt"` can't be found, so they are left as they are stored and a patch mustn't set them.
+//
+//	err := rod.MergeJson(tx, "user", "chilts", []byte(`{"Logins":5,"Nickname":null}`))
+func MergeJson(tx Tx, location, key string, patch []byte) error {
+	raw, err := Get(tx, location, key)
+	if err != nil {
+		return wrapErr("MergeJson", location, key, err)
+	}
+
+	var doc interface{}
+	if raw != nil {
+		if doc, err = decodeJson(raw); err != nil {
+			return wrapErr("MergeJson", location, key, err)
+		}
+	}
+
+	p, err := decodeJson(patch)
+	if err != nil {
+		return wrapErr("MergeJson", location, key, err)
+	}
+
+	value, _, err := encodeJson(tx, location, key, mergePatch(doc, p), nil)
+	if err != nil {
+		return wrapErr("MergeJson", location, key, err)
+	}
+
+	return wrapErr("MergeJson", location, key, Put(tx, location, key, value))
+}
+
+// decodeJson decodes raw into the generic types from encoding/json, keeping numbers as json.Number so they survive
+// being re-encoded exactly.
+func decodeJson(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// mergePatch is the MergePatch function straight from RFC 7386.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+		} else {
+			t[name] = mergePatch(t[name], value)
+		}
+	}
+
+	return t
+}
+
+// patchOp is a single operation in an RFC 6902 JSON Patch.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// PatchJson applies the list of RFC 6902 (JSON Patch) operations in ops to the JSON document stored at location/key
+// and puts the result back. All of "add", "remove", "replace", "move", "copy" and "test" are supported. The operations
+// are applied in order to an in-memory copy of the document, so if any of them fail (including a "test" which returns
+// ErrPatchTestFailed) then nothing is written and the error is returned, which you'd normally use to fail the whole
+// transaction. If the key doesn't exist then ErrKeyNotFound is returned. As with MergeJson, the result is checked
+// against any schema for location, and operations mustn't set fields tagged `rod:"encrypt"`.
+//
+//	err := rod.PatchJson(tx, "user", "chilts", []byte(`[
+//	    {"op": "test", "path": "/Logins", "value": 4},
+//	    {"op": "replace", "path": "/Logins", "value": 5}
+//	]`))
+func PatchJson(tx Tx, location, key string, ops []byte) error {
+	raw, err := Get(tx, location, key)
+	if err != nil {
+		return wrapErr("PatchJson", location, key, err)
+	}
+	if raw == nil {
+		return wrapErr("PatchJson", location, key, ErrKeyNotFound)
+	}
+
+	doc, err := decodeJson(raw)
+	if err != nil {
+		return wrapErr("PatchJson", location, key, err)
+	}
+
+	var patch []patchOp
+	if err := json.Unmarshal(ops, &patch); err != nil {
+		return wrapErr("PatchJson", location, key, err)
+	}
+
+	for i, op := range patch {
+		if doc, err = applyPatchOp(doc, op); err != nil {
+			return wrapErr("PatchJson", location, key, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err))
+		}
+	}
+
+	value, _, err := encodeJson(tx, location, key, doc, nil)
+	if err != nil {
+		return wrapErr("PatchJson", location, key, err)
+	}
+
+	return wrapErr("PatchJson", location, key, Put(tx, location, key, value))
+}
+
+// applyPatchOp applies the single op to doc, returning the new document.
+func applyPatchOp(doc interface{}, op patchOp) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// most of the ops need a value
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+		if value, err = decodeJson(op.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return pointerAdd(doc, path, value)
+	case "remove":
+		return pointerRemove(doc, path)
+	case "replace":
+		if doc, err = pointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, value)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, errors.New("cannot move a location into one of its children")
+			}
+			if doc, err = pointerRemove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// copy it so the two don't share any maps or slices
+			b, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			if value, err = decodeJson(b); err != nil {
+				return nil, err
+			}
+		}
+		return pointerAdd(doc, path, value)
+	case "test":
+		current, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses token as an index into an array of length n. If allowEnd is set then n itself (or "-") is allowed,
+// for adding to the end.
+func arrayIndex(token string, n int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > n || (i == n && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// pointerGet returns the value in doc at path.
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("field %q not found", token)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("cannot find %q in a scalar", token)
+		}
+	}
+	return doc, nil
+}
+
+// pointerUpdate finds the parent of path in doc and calls fn with it and the final token. Whatever fn returns replaces
+// the parent, and the (possibly new) document is returned.
+func pointerUpdate(doc interface{}, path []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("field %q not found", path[0])
+		}
+		child, err := pointerUpdate(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[path[0]] = child
+		return node, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		child, err := pointerUpdate(node[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = child
+		return node, nil
+	}
+
+	return nil, fmt.Errorf("cannot find %q in a scalar", path[0])
+}
+
+// pointerAdd adds value into doc at path, replacing the whole document if path is empty.
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		return nil, fmt.Errorf("cannot add %q to a scalar", token)
+	})
+}
+
+// pointerRemove removes the value in doc at path, which must exist.
+func pointerRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	return pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("field %q not found", token)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %q from a scalar", token)
+	})
+}
+
+// jsonEqual compares two decoded JSON values, with numbers being equal if they have the same numeric value.
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			w, ok := bv[k]
+			if !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if av == bv {
+			return true
+		}
+		af, errA := av.Float64()
+		bf, errB := bv.Float64()
+		return errA == nil && errB == nil && af == bf
+	}
+	return a == b
+}
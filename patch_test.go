@@ -0,0 +1,151 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestMergeJson(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		// the examples from RFC 7386
+		tests := []struct {
+			original string
+			patch    string
+			result   string
+		}{
+			{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+			{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+			{`{"a":"b"}`, `{"a":null}`, `{}`},
+			{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+			{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+			{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+			{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+			{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+			{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+			{`{"a":"b"}`, `["c"]`, `["c"]`},
+			{`{"a":"foo"}`, `null`, `null`},
+			{`{"a":"foo"}`, `"bar"`, `"bar"`},
+			{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+			{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+			{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		}
+
+		for _, test := range tests {
+			check(Put(tx, "doc", "test", []byte(test.original)))
+			check(MergeJson(tx, "doc", "test", []byte(test.patch)))
+			result, err := GetString(tx, "doc", "test")
+			check(err)
+			if result != test.result {
+				t.Fatalf("Merging %s into %s should have given %s, but gave %s", test.patch, test.original, test.result, result)
+			}
+		}
+
+		// merging into a missing key creates it
+		check(MergeJson(tx, "user", "chilts", []byte(`{"Username":"chilts","Logins":5}`)))
+		var user User
+		check(GetJson(tx, "user", "chilts", &user))
+		if user.Logins != 5 {
+			t.Fatalf("Expected 5 logins but got %d", user.Logins)
+		}
+
+		// big numbers shouldn't lose precision
+		check(Put(tx, "doc", "big", []byte(`{"id":12345678901234567890}`)))
+		check(MergeJson(tx, "doc", "big", []byte(`{"name":"big"}`)))
+		result, err := GetString(tx, "doc", "big")
+		check(err)
+		if result != `{"id":12345678901234567890,"name":"big"}` {
+			t.Fatalf("Numbers should be kept exactly, but got %s", result)
+		}
+
+		if err := MergeJson(tx, "doc", "big", []byte(`{`)); err == nil {
+			t.Fatal("An invalid patch should return an error")
+		}
+
+		return nil
+	})
+	check(err)
+}
+
+func TestPatchJson(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		// mostly from the examples in RFC 6902 appendix A
+		tests := []struct {
+			original string
+			patch    string
+			result   string
+		}{
+			{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+			{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+			{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"baz"}]`, `{"foo":["bar","baz"]}`},
+			{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+			{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+			{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+			{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+			{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+			{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+			{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`},
+			{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`, `{"~1":10}`},
+			{`{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+			{`{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":"qux"}}]`, `{"baz":"qux"}`},
+		}
+
+		for _, test := range tests {
+			check(Put(tx, "doc", "test", []byte(test.original)))
+			err := PatchJson(tx, "doc", "test", []byte(test.patch))
+			if err != nil {
+				t.Fatalf("Applying %s to %s failed: %v", test.patch, test.original, err)
+			}
+			result, err := GetString(tx, "doc", "test")
+			check(err)
+			if result != test.result {
+				t.Fatalf("Applying %s to %s should have given %s, but gave %s", test.patch, test.original, test.result, result)
+			}
+		}
+
+		failures := []struct {
+			original string
+			patch    string
+		}{
+			{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+			{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+			{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/5","value":"baz"}]`},
+			{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/01","value":"baz"}]`},
+			{`{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`},
+			{`{"foo":"bar"}`, `[{"op":"frobnicate","path":"/foo"}]`},
+			{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`},
+		}
+		for _, test := range failures {
+			check(Put(tx, "doc", "test", []byte(test.original)))
+			if err := PatchJson(tx, "doc", "test", []byte(test.patch)); err == nil {
+				t.Fatalf("Applying %s to %s should have failed", test.patch, test.original)
+			}
+		}
+
+		// a failing test op means nothing is written at all
+		check(Put(tx, "doc", "test", []byte(`{"logins":4}`)))
+		err := PatchJson(tx, "doc", "test", []byte(`[{"op":"replace","path":"/logins","value":5},{"op":"test","path":"/logins","value":4}]`))
+		if !errors.Is(err, ErrPatchTestFailed) {
+			t.Fatalf("Expected ErrPatchTestFailed but got %v", err)
+		}
+		result, err := GetString(tx, "doc", "test")
+		check(err)
+		if result != `{"logins":4}` {
+			t.Fatalf("Nothing should have been written, but the document is now %s", result)
+		}
+
+		if err := PatchJson(tx, "doc", "missing", []byte(`[]`)); !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("Expected ErrKeyNotFound but got %v", err)
+		}
+
+		return nil
+	})
+	check(err)
+}
@@ -0,0 +1,54 @@
+package rod
+
+import (
+	"encoding/json"
+)
+
+// GetRaw fetches the JSON document at key, just as it was stored. It is handy for handing documents on (say, in an
+// HTTP response) without decoding and re-encoding them. Unlike Get, the document is a copy so it may be kept after the
+// transaction is done. If any bucket or the key doesn't exist then nil is returned, and if the value stored isn't
+// valid JSON then ErrInvalidJson is.
+func GetRaw(tx Tx, location, key string) (json.RawMessage, error) {
+	value, err := Get(tx, location, key)
+	if err != nil {
+		return nil, wrapErr("GetRaw", location, key, err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	if !json.Valid(value) {
+		return nil, wrapErr("GetRaw", location, key, ErrInvalidJson)
+	}
+	return append(json.RawMessage(nil), value...), nil
+}
+
+// rawJson returns the JSON in v if it is a json.RawMessage or []byte (or a pointer to one), which the functions
+// writing JSON documents put as it is rather than encoding.
+func rawJson(v interface{}) ([]byte, bool) {
+	switch v := v.(type) {
+	case json.RawMessage:
+		return v, true
+	case *json.RawMessage:
+		if v != nil {
+			return *v, true
+		}
+	case []byte:
+		return v, true
+	case *[]byte:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return nil, false
+}
+
+// validRaw returns raw if it is valid JSON, taking nil to be null as json.Marshal does, and ErrInvalidJson if not.
+func validRaw(raw []byte) ([]byte, error) {
+	if raw == nil {
+		return []byte("null"), nil
+	}
+	if !json.Valid(raw) {
+		return nil, ErrInvalidJson
+	}
+	return raw, nil
+}
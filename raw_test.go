@@ -0,0 +1,117 @@
+package rod
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestRaw(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("PutJson puts raw JSON as it is", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			doc := `{ "Username" : "chilts",  "Logins": 3 }`
+			check(PutJson(tx, "users", "chilts", json.RawMessage(doc)))
+			check(PutJson(tx, "users", "bob", []byte(`["not", "base64"]`)))
+			raw := json.RawMessage(`"pointer"`)
+			check(PutJson(tx, "users", "ptr", &raw))
+			check(PutJson(tx, "users", "nil", json.RawMessage(nil)))
+
+			for key, want := range map[string]string{
+				"chilts": doc,
+				"bob":    `["not", "base64"]`,
+				"ptr":    `"pointer"`,
+				"nil":    `null`,
+			} {
+				got, err := GetString(tx, "users", key)
+				check(err)
+				if got != want {
+					t.Fatalf("Expected %s at %s but got %s", want, key, got)
+				}
+			}
+
+			var u User
+			check(GetJson(tx, "users", "chilts", &u))
+			if u.Username != "chilts" || u.Logins != 3 {
+				t.Fatalf("Unexpected user %v", u)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("PutJson refuses raw JSON which isn't valid", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			for _, v := range []interface{}{json.RawMessage(`{"Username":`), []byte("plain text"), []byte{}} {
+				if err := PutJson(tx, "invalid", "key", v); !errors.Is(err, ErrInvalidJson) {
+					t.Fatalf("Expected ErrInvalidJson putting %s but got %v", v, err)
+				}
+			}
+			exists, err := Exists(tx, "invalid", "key")
+			check(err)
+			if exists {
+				t.Fatal("Nothing should have been put")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("PutManyJson puts raw JSON as it is", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			err := PutManyJson(tx, "many", map[string]json.RawMessage{
+				"good": json.RawMessage(`{ "a": 1 }`),
+				"bad":  json.RawMessage(`{`),
+			})
+			var errs KeyErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs["bad"], ErrInvalidJson) {
+				t.Fatalf("Expected ErrInvalidJson for bad but got %v", err)
+			}
+
+			got, err := GetString(tx, "many", "good")
+			check(err)
+			if got != `{ "a": 1 }` {
+				t.Fatalf("Unexpected value %s", got)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("GetRaw", func(t *testing.T) {
+		var raw json.RawMessage
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "raw", "text", "not JSON"))
+
+			var err error
+			raw, err = GetRaw(tx, "users", "chilts")
+			check(err)
+
+			missing, err := GetRaw(tx, "raw", "missing")
+			check(err)
+			if missing != nil {
+				t.Fatalf("Expected nil for a missing key but got %s", missing)
+			}
+			missing, err = GetRaw(tx, "nowhere", "missing")
+			check(err)
+			if missing != nil {
+				t.Fatalf("Expected nil for a missing bucket but got %s", missing)
+			}
+
+			if _, err := GetRaw(tx, "raw", "text"); !errors.Is(err, ErrInvalidJson) {
+				t.Fatalf("Expected ErrInvalidJson but got %v", err)
+			}
+			return nil
+		})
+		check(err)
+
+		// the document was copied, so it is still fine after the transaction
+		if string(raw) != `{ "Username" : "chilts",  "Logins": 3 }` {
+			t.Fatalf("Unexpected document %s", raw)
+		}
+	})
+}
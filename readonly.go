@@ -0,0 +1,57 @@
+package rod
+
+import (
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrReadOnlyStore is returned when writing to a Store opened with OpenReadOnly or wrapped with NewReadOnlyStore.
+var ErrReadOnlyStore = errors.New("read-only store")
+
+// OpenReadOnly opens the BoltDB at path read-only and returns a Store wrapping it, for reporting jobs and debugging
+// tools which must never change the file. Its Update and UpdateAs (and CompactInPlace) return ErrReadOnlyStore, as do
+// Put, Del and the rest of rod's writes made inside its View. Bolt takes a shared lock on the file, so it can be opened
+// read-only by several processes at once, but not while a process has it open for writing; set options.Timeout to
+// fail rather than wait.
+func OpenReadOnly(path string, options *bolt.Options) (*Store, error) {
+	opts := bolt.Options{}
+	if options != nil {
+		opts = *options
+	}
+	opts.ReadOnly = true
+
+	db, err := bolt.Open(path, 0444, &opts)
+	if err != nil {
+		return nil, err
+	}
+	s := NewReadOnlyStore(db)
+	s.options = &opts
+	return s, nil
+}
+
+// NewReadOnlyStore returns a Store wrapping the already opened BoltDB which refuses to write to it, as with
+// OpenReadOnly. Since db itself may still be writable, writes made to it other than through the Store aren't stopped.
+func NewReadOnlyStore(db *bolt.DB) *Store {
+	s := NewStore(db)
+	s.readOnly = true
+	return s
+}
+
+// ReadOnly returns whether the Store refuses writes, having been opened with OpenReadOnly or wrapped with
+// NewReadOnlyStore.
+func (s *Store) ReadOnly() bool {
+	return s.readOnly
+}
+
+// checkWritable returns ErrReadOnlyStore if tx belongs to a read-only Store. Since a read-only Store only ever starts
+// read-only transactions, a writable tx is always fine.
+func checkWritable(tx Tx) error {
+	if tx.Writable() {
+		return nil
+	}
+	if st := stateOf(tx); st != nil && st.store.readOnly {
+		return ErrReadOnlyStore
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package rod
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestReadOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "rod.db")
+
+	s, err := Open(filename, 0666, nil)
+	check(err)
+	check(s.Update(func(tx *bolt.Tx) error {
+		return PutString(tx, "report", "total", "42")
+	}))
+	check(s.Close())
+
+	t.Run("OpenReadOnly", func(t *testing.T) {
+		s, err := OpenReadOnly(filename, nil)
+		check(err)
+		defer s.Close()
+
+		if !s.ReadOnly() {
+			t.Fatal("The Store should be read-only")
+		}
+
+		err = s.Update(func(tx *bolt.Tx) error {
+			t.Fatal("Update should not run fn")
+			return nil
+		})
+		if !errors.Is(err, ErrReadOnlyStore) {
+			t.Fatalf("Expected ErrReadOnlyStore from Update, got %v", err)
+		}
+		if err := s.CompactInPlace(); !errors.Is(err, ErrReadOnlyStore) {
+			t.Fatalf("Expected ErrReadOnlyStore from CompactInPlace, got %v", err)
+		}
+
+		check(s.View(func(tx *bolt.Tx) error {
+			total, err := GetString(tx, "report", "total")
+			check(err)
+			if total != "42" {
+				t.Fatalf("Unexpected total %q", total)
+			}
+
+			if err := PutString(tx, "report", "total", "0"); !errors.Is(err, ErrReadOnlyStore) {
+				t.Fatalf("Expected ErrReadOnlyStore from Put, got %v", err)
+			}
+			if err := Del(tx, "missing", "total"); !errors.Is(err, ErrReadOnlyStore) {
+				t.Fatalf("Expected ErrReadOnlyStore from Del, got %v", err)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("NewReadOnlyStore", func(t *testing.T) {
+		db, err := bolt.Open(filename, 0666, nil)
+		check(err)
+		defer db.Close()
+
+		s := NewReadOnlyStore(db)
+		err = s.UpdateAs("reporter", func(tx *bolt.Tx) error { return nil })
+		if !errors.Is(err, ErrReadOnlyStore) {
+			t.Fatalf("Expected ErrReadOnlyStore from UpdateAs, got %v", err)
+		}
+
+		// a plain Store is unaffected
+		check(NewStore(db).Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "report", "total", "43")
+		}))
+	})
+}
@@ -0,0 +1,157 @@
+package rod
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// ReplicationBucket is the top-level bucket in a follower which Replicate keeps its checkpoint in.
+const ReplicationBucket = "__rod_replication"
+
+// replicationCheckpoint is the key in ReplicationBucket holding the Seq of the last change applied.
+const replicationCheckpoint = "checkpoint"
+
+// ErrInvalidReplicationBatchSize is returned by Replicate when ReplicationBatchSize is given a size which isn't
+// positive.
+var ErrInvalidReplicationBatchSize = errors.New("replication batch size must be positive")
+
+// ReplicateOption changes how Replicate tails the source.
+type ReplicateOption func(*replication)
+
+type replication struct {
+	pollInterval time.Duration
+	batchSize    int
+}
+
+// PollInterval sets how long Replicate waits before looking again once it has caught up. The default is a second.
+func PollInterval(d time.Duration) ReplicateOption {
+	return func(r *replication) {
+		r.pollInterval = d
+	}
+}
+
+// ReplicationBatchSize sets how many changes Replicate applies in each transaction on the follower. The default is
+// 1000, and the size must be positive.
+func ReplicationBatchSize(n int) ReplicateOption {
+	return func(r *replication) {
+		r.batchSize = n
+	}
+}
+
+// Replicate keeps dst up to date with src, by tailing src's changelog (see EnableChangelog) and applying each change
+// to dst, until ctx is done. It then returns ctx.Err(), or any other error which stopped it. It returns
+// ErrInvalidReplicationBatchSize straight away if ReplicationBatchSize isn't positive.
+//
+//	src.EnableChangelog()
+//	go rod.Replicate(ctx, src, standby)
+//
+// Each batch of changes is applied in one transaction on dst, along with the Seq of the last one as a checkpoint, so
+// Replicate can be stopped and started again at any time and will carry on from exactly where it left off. Only the
+// changes made since src's changelog was enabled are replicated, so copy the file first (see bolt.Tx.WriteTo) if
+// there was anything in it before.
+//
+// The changes are applied as with dst's Update (even if dst is a FollowerStore's), so any features enabled on dst (such
+// as its own changelog, for another follower further down) see them too.
+func Replicate(ctx context.Context, src, dst *Store, opts ...ReplicateOption) error {
+	r := replication{pollInterval: time.Second, batchSize: 1000}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	if r.batchSize <= 0 {
+		return ErrInvalidReplicationBatchSize
+	}
+
+	for {
+		n, err := replicateBatch(src, dst, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		// carry straight on if there may well be more, otherwise wait a while
+		if n < r.batchSize {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.pollInterval):
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// replicateBatch applies up to limit changes from src to dst, returning how many there were.
+func replicateBatch(src, dst *Store, limit int) (int, error) {
+	after, err := ReplicationCheckpoint(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	var changes []Change
+	err = src.View(func(tx *bolt.Tx) error {
+		changes, err = ReadChanges(tx, after, limit)
+		return err
+	})
+	if err != nil || len(changes) == 0 {
+		return 0, err
+	}
+
+	return len(changes), dst.update("", func(tx *bolt.Tx) error {
+		return applyChanges(tx, changes)
+	})
+}
+
+// applyChanges makes each change within tx, and records the last one as the checkpoint.
+func applyChanges(tx Tx, changes []Change) error {
+	for _, change := range changes {
+		if err := applyChange(tx, change); err != nil {
+			return err
+		}
+	}
+
+	// this isn't done with putKey, since the checkpoint is about this follower and mustn't be passed on
+	b, err := createBucket(tx, ReplicationBucket)
+	if err != nil {
+		return err
+	}
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, changes[len(changes)-1].Seq)
+	return b.Put([]byte(replicationCheckpoint), seq)
+}
+
+// applyChange makes the change within tx, keeping where it came from for the hooks.
+func applyChange(tx Tx, change Change) error {
+	if st := stateOf(tx); st != nil {
+		st.origin = change.Origin
+		defer func() { st.origin = "" }()
+	}
+
+	switch change.Op {
+	case "put":
+		value := change.Value
+		if value == nil {
+			value = []byte{}
+		}
+		return Put(tx, change.Location, change.Key, value)
+	case "del":
+		return Del(tx, change.Location, change.Key)
+	}
+	return nil
+}
+
+// ReplicationCheckpoint returns the Seq of the last change Replicate applied to dst, or 0 if it hasn't applied any.
+func ReplicationCheckpoint(dst *Store) (uint64, error) {
+	var seq uint64
+	err := dst.View(func(tx *bolt.Tx) error {
+		raw, err := Get(tx, ReplicationBucket, replicationCheckpoint)
+		if len(raw) == 8 {
+			seq = binary.BigEndian.Uint64(raw)
+		}
+		return err
+	})
+	return seq, err
+}
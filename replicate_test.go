@@ -0,0 +1,121 @@
+package rod
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestReplicate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	src, err := Open(filepath.Join(dir, "src.db"), 0666, nil)
+	check(err)
+	defer src.Close()
+	src.EnableChangelog()
+
+	dst, err := Open(filepath.Join(dir, "dst.db"), 0666, nil)
+	check(err)
+	defer dst.Close()
+
+	err = src.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "chilts", User{"chilts", 1}))
+		check(PutJson(tx, "user", "bob", User{"bob", 2}))
+		check(PutString(tx, "config", "empty", ""))
+		return Del(tx, "user", "bob")
+	})
+	check(err)
+
+	// waitFor runs Replicate until dst has caught up to seq
+	waitFor := func(seq uint64, opts ...ReplicateOption) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- Replicate(ctx, src, dst, append(opts, PollInterval(5*time.Millisecond))...)
+		}()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got, err := ReplicationCheckpoint(dst)
+			check(err)
+			if got >= seq {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Replication didn't reach %d, only %d", seq, got)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Fatalf("Expected Replicate to stop with context.Canceled but got %v", err)
+		}
+	}
+
+	t.Run("Changes are applied", func(t *testing.T) {
+		waitFor(4)
+
+		err := dst.View(func(tx *bolt.Tx) error {
+			var user User
+			check(GetJson(tx, "user", "chilts", &user))
+			if user.Logins != 1 {
+				t.Fatalf("chilts should have been replicated: %#v", user)
+			}
+
+			bob, err := Get(tx, "user", "bob")
+			check(err)
+			if bob != nil {
+				t.Fatalf("bob should have been deleted on the follower too")
+			}
+
+			value, found, err := GetFound(tx, "config", "empty")
+			check(err)
+			if !found || value == nil {
+				t.Fatalf("An empty value should be replicated as an empty value")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Replication carries on from its checkpoint", func(t *testing.T) {
+		err := src.Update(func(tx *bolt.Tx) error {
+			for i := 0; i < 5; i++ {
+				check(PutInt64(tx, "counter", "n", int64(i)))
+			}
+			return nil
+		})
+		check(err)
+
+		// a batch size of 2 leaves it part way through a batch at times
+		waitFor(9, ReplicationBatchSize(2))
+
+		err = dst.View(func(tx *bolt.Tx) error {
+			n, err := GetInt64(tx, "counter", "n")
+			check(err)
+			if n != 4 {
+				t.Fatalf("Expected the counter to end at 4 but got %d", n)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("The batch size must be positive", func(t *testing.T) {
+		for _, n := range []int{0, -1} {
+			err := Replicate(context.Background(), src, dst, ReplicationBatchSize(n))
+			if !errors.Is(err, ErrInvalidReplicationBatchSize) {
+				t.Fatalf("Expected ErrInvalidReplicationBatchSize for %d but got %v", n, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package rod
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Repo is a typed layer over the bucket at a location, so application code can save and load its own types without
+// dealing with locations and raw bytes.
+//
+//	users := rod.NewRepo("user", func(u *User) string { return u.Username })
+//	err := users.Save(tx, &user)
+//	user, err := users.Load(tx, "chilts")
+type Repo[T any] struct {
+	location string
+	key      func(v *T) string
+}
+
+// NewRepo returns a Repo for the records of type T at location, where key gives the key for each record. If key is
+// nil then the field of T tagged `rod:"key"` is used instead (see PutJsonAuto).
+func NewRepo[T any](location string, key func(v *T) string) *Repo[T] {
+	if key == nil {
+		index := taggedField(reflect.TypeOf((*T)(nil)).Elem(), "key")
+		key = func(v *T) string {
+			if index == nil {
+				return ""
+			}
+			field, err := reflect.ValueOf(v).Elem().FieldByIndexErr(index)
+			if err != nil {
+				return ""
+			}
+			key, _ := keyFromField(field)
+			return key
+		}
+	}
+	return &Repo[T]{location: location, key: key}
+}
+
+// Location returns the location the records are kept at.
+func (r *Repo[T]) Location() string {
+	return r.location
+}
+
+// Save puts the record with PutJson, so any tagged fields (such as `rod:"updated"`) are filled in on v.
+func (r *Repo[T]) Save(tx Tx, v *T) error {
+	key := r.key(v)
+	return wrapErr("Save", r.location, key, PutJson(tx, r.location, key, v))
+}
+
+// Load gets the record with this key, returning ErrKeyNotFound if there isn't one.
+func (r *Repo[T]) Load(tx Tx, key string) (*T, error) {
+	v := new(T)
+	found, err := GetJsonFound(tx, r.location, key, v)
+	if err != nil {
+		return nil, wrapErr("Load", r.location, key, err)
+	}
+	if !found {
+		return nil, wrapErr("Load", r.location, key, ErrKeyNotFound)
+	}
+	r.setKey(v, key)
+	return v, nil
+}
+
+// Delete deletes the record with this key. As with Del, a missing record is not an error.
+func (r *Repo[T]) Delete(tx Tx, key string) error {
+	return wrapErr("Delete", r.location, key, Del(tx, r.location, key))
+}
+
+// List returns every record, in key order unless a sort option is given.
+func (r *Repo[T]) List(tx Tx, opts ...QueryOption) ([]*T, error) {
+	var list []*T
+	return list, wrapErr("List", r.location, "", All(tx, r.location, &list, opts...))
+}
+
+// Find returns every record which matches filter, just like the package level Find.
+func (r *Repo[T]) Find(tx Tx, filter Filter, opts ...QueryOption) ([]*T, error) {
+	var list []*T
+	return list, wrapErr("Find", r.location, "", Find(tx, r.location, filter, &list, opts...))
+}
+
+// Page returns up to limit records in key order, starting after the key given (or from the start with ""). It also
+// returns the key to pass as after to get the next page, which is "" once there are no more. A limit of 0 means no
+// limit.
+//
+//	page, next, err := users.Page(tx, "", 20)
+//	page, next, err = users.Page(tx, next, 20)
+func (r *Repo[T]) Page(tx Tx, after string, limit int) ([]*T, string, error) {
+	b, err := BucketAt(tx, r.location)
+	if err != nil {
+		return nil, "", wrapErr("Page", r.location, "", err)
+	}
+	if b == nil {
+		return nil, "", nil
+	}
+
+	var page []*T
+	var last string
+	c := b.Cursor()
+	k, v := c.First()
+	if after != "" {
+		k, v = c.Seek([]byte(after))
+		if k != nil && bytes.Equal(k, []byte(after)) {
+			k, v = c.Next()
+		}
+	}
+	for ; k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		if limit > 0 && len(page) == limit {
+			// there's at least one more
+			return page, last, nil
+		}
+		item := new(T)
+		if err := loadJson(tx, v, item); err != nil {
+			return nil, "", wrapErr("Page", r.location, string(k), err)
+		}
+		r.setKey(item, string(k))
+		page = append(page, item)
+		last = string(k)
+	}
+
+	return page, "", nil
+}
+
+// setKey fills in the `rod:"key"` field of v, if it has one, just as All does.
+func (r *Repo[T]) setKey(v *T, key string) {
+	index := taggedField(reflect.TypeOf(v).Elem(), "key")
+	if index == nil {
+		return
+	}
+	if field, err := reflect.ValueOf(v).Elem().FieldByIndexErr(index); err == nil {
+		setKeyField(field, key)
+	}
+}
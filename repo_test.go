@@ -0,0 +1,93 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestRepo(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	users := NewRepo("user", func(u *User) string { return u.Username })
+
+	t.Run("Save, Load and Delete", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(users.Save(tx, &User{"chilts", 1}))
+
+			user, err := users.Load(tx, "chilts")
+			check(err)
+			if user.Logins != 1 {
+				t.Fatalf("Expected 1 login but got %d", user.Logins)
+			}
+
+			check(users.Delete(tx, "chilts"))
+			_, err = users.Load(tx, "chilts")
+			if !errors.Is(err, ErrKeyNotFound) {
+				t.Fatalf("Expected ErrKeyNotFound but got %v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("List, Find and Page", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			for i, name := range []string{"a", "b", "c", "d", "e"} {
+				check(users.Save(tx, &User{name, i}))
+			}
+
+			list, err := users.List(tx, SortByDesc("Logins"))
+			check(err)
+			if len(list) != 5 || list[0].Username != "e" {
+				t.Fatalf("Expected five users starting with e: %#v", list)
+			}
+
+			found, err := users.Find(tx, Where("Logins", ">=", 3))
+			check(err)
+			if len(found) != 2 {
+				t.Fatalf("Expected two users but got %d", len(found))
+			}
+
+			var names string
+			after := ""
+			for pages := 0; ; pages++ {
+				page, next, err := users.Page(tx, after, 2)
+				check(err)
+				for _, user := range page {
+					names += user.Username
+				}
+				if next == "" {
+					if pages != 2 {
+						t.Fatalf("Expected three pages but got %d", pages+1)
+					}
+					break
+				}
+				after = next
+			}
+			if names != "abcde" {
+				t.Fatalf("Expected every user in order but got %s", names)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Keys from the struct tag", func(t *testing.T) {
+		accounts := NewRepo[Account]("account", nil)
+
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(accounts.Save(tx, &Account{"chilts", "andy@example.com"}))
+
+			account, err := accounts.Load(tx, "chilts")
+			check(err)
+			if account.Username != "chilts" || account.Email != "andy@example.com" {
+				t.Fatalf("Expected the key to be filled in: %#v", account)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
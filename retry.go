@@ -0,0 +1,148 @@
+package rod
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// The defaults for UpdateWithRetry.
+const (
+	DefaultRetryAttempts = 5
+	DefaultRetryBackoff  = 10 * time.Millisecond
+	DefaultRetryMaxWait  = time.Second
+)
+
+// ErrInvalidRetryAttempts is returned by UpdateWithRetry when RetryAttempts is given fewer than one attempt.
+var ErrInvalidRetryAttempts = errors.New("retry attempts must be at least one")
+
+// Updater is anything which runs a function in a read-write Bolt transaction, such as a *bolt.DB or a *Store.
+type Updater interface {
+	Update(fn func(tx *bolt.Tx) error) error
+}
+
+// RetryError is returned by UpdateWithRetry when every attempt failed with a transient error. The last of them is in
+// Err, so errors.Is still finds it.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("rod: gave up after %d attempts: %s", e.Attempts, e.Err)
+}
+
+// Unwrap returns the last error, for errors.Is and errors.As.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryable marks an error as transient, for Retryable.
+type retryable struct {
+	err error
+}
+
+func (r retryable) Error() string { return r.err.Error() }
+func (r retryable) Unwrap() error { return r.err }
+
+// Retryable marks err as transient, so that returning it from the function given to UpdateWithRetry makes it try
+// again rather than give up, such as when a service the function calls is briefly unavailable.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryable{err}
+}
+
+// RetryOption configures UpdateWithRetry.
+type RetryOption func(*retry)
+
+// RetryAttempts sets how many times the function is tried in all, which is DefaultRetryAttempts by default. It must be
+// at least one.
+func RetryAttempts(n int) RetryOption {
+	return func(r *retry) {
+		r.attempts = n
+	}
+}
+
+// RetryBackoff sets the wait after the first failure, which doubles after each one up to max. They are
+// DefaultRetryBackoff and DefaultRetryMaxWait by default.
+func RetryBackoff(initial, max time.Duration) RetryOption {
+	return func(r *retry) {
+		r.backoff = initial
+		r.max = max
+	}
+}
+
+// RetryIf decides which errors are transient, instead of just bolt.ErrTimeout and those marked with Retryable. Since
+// Bolt only times out while bolt.Open waits for the file lock, never in Update, the default in practice only retries
+// the errors fn marks with Retryable.
+func RetryIf(fn func(err error) bool) RetryOption {
+	return func(r *retry) {
+		r.transient = fn
+	}
+}
+
+type retry struct {
+	attempts  int
+	backoff   time.Duration
+	max       time.Duration
+	transient func(err error) bool
+}
+
+// isTransient is the default for RetryIf. Update itself never returns bolt.ErrTimeout, but fn might, such as when it
+// opens another database.
+func isTransient(err error) bool {
+	var r retryable
+	return errors.Is(err, bolt.ErrTimeout) || errors.As(err, &r)
+}
+
+// UpdateWithRetry calls db.Update with fn, trying again if it fails with a transient error, after waiting for an
+// exponential backoff with jitter (so that several processes which failed together don't all try again together). Any
+// other error is returned as soon as it happens. If every attempt fails, the last error is returned in a *RetryError.
+// Since fn may be run more than once, it must not have effects outside the transaction which can't be repeated. Only
+// the errors from db.Update are retried, so a lock timeout opening db must be dealt with before calling it.
+//
+//	err := rod.UpdateWithRetry(store, func(tx *bolt.Tx) error {
+//	    return rod.PutJson(tx, "user", "chilts", &user)
+//	}, rod.RetryAttempts(10))
+func UpdateWithRetry(db Updater, fn func(tx *bolt.Tx) error, opts ...RetryOption) error {
+	r := &retry{attempts: DefaultRetryAttempts, backoff: DefaultRetryBackoff, max: DefaultRetryMaxWait, transient: isTransient}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.attempts < 1 {
+		return ErrInvalidRetryAttempts
+	}
+
+	wait := r.backoff
+	var err error
+	for attempt := 1; attempt <= r.attempts; attempt++ {
+		err = db.Update(fn)
+		if err == nil || !r.transient(err) {
+			return unwrapRetryable(err)
+		}
+		if attempt == r.attempts {
+			break
+		}
+
+		// wait somewhere between half and all of the backoff
+		time.Sleep(wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1)))
+		wait *= 2
+		if wait > r.max {
+			wait = r.max
+		}
+	}
+	return &RetryError{Attempts: r.attempts, Err: unwrapRetryable(err)}
+}
+
+// unwrapRetryable removes the mark Retryable put on err, if it is on the outside.
+func unwrapRetryable(err error) error {
+	if r, ok := err.(retryable); ok {
+		return r.err
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestUpdateWithRetry(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	s := NewStore(db)
+
+	backoff := RetryBackoff(time.Millisecond, 2*time.Millisecond)
+	unavailable := errors.New("unavailable")
+
+	t.Run("Transient errors are retried", func(t *testing.T) {
+		calls := 0
+		err := UpdateWithRetry(s, func(tx *bolt.Tx) error {
+			calls++
+			if calls < 3 {
+				return Retryable(unavailable)
+			}
+			return PutString(tx, "retry", "key", "value")
+		}, backoff)
+		check(err)
+		if calls != 3 {
+			t.Fatalf("Expected 3 calls, not %d", calls)
+		}
+	})
+
+	t.Run("Other errors are not", func(t *testing.T) {
+		calls := 0
+		err := UpdateWithRetry(db, func(tx *bolt.Tx) error {
+			calls++
+			return unavailable
+		}, backoff)
+		if err != unavailable || calls != 1 {
+			t.Fatalf("A permanent error should be returned straight away: %v after %d calls", err, calls)
+		}
+	})
+
+	t.Run("Giving up", func(t *testing.T) {
+		calls := 0
+		err := UpdateWithRetry(db, func(tx *bolt.Tx) error {
+			calls++
+			return bolt.ErrTimeout
+		}, backoff, RetryAttempts(4))
+
+		var retryErr *RetryError
+		if !errors.As(err, &retryErr) || retryErr.Attempts != 4 || calls != 4 || !errors.Is(err, bolt.ErrTimeout) {
+			t.Fatalf("Expected a RetryError after 4 attempts, got %v after %d calls", err, calls)
+		}
+	})
+
+	t.Run("RetryIf", func(t *testing.T) {
+		calls := 0
+		err := UpdateWithRetry(db, func(tx *bolt.Tx) error {
+			calls++
+			return unavailable
+		}, backoff, RetryAttempts(2), RetryIf(func(err error) bool { return errors.Is(err, unavailable) }))
+		if !errors.Is(err, unavailable) || calls != 2 {
+			t.Fatalf("RetryIf should decide what is transient: %v after %d calls", err, calls)
+		}
+	})
+
+	t.Run("There must be at least one attempt", func(t *testing.T) {
+		calls := 0
+		err := UpdateWithRetry(db, func(tx *bolt.Tx) error {
+			calls++
+			return nil
+		}, RetryAttempts(0))
+		if err != ErrInvalidRetryAttempts || calls != 0 {
+			t.Fatalf("Expected ErrInvalidRetryAttempts without any calls, got %v after %d calls", err, calls)
+		}
+	})
+}
@@ -4,7 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"reflect"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/boltdb/bolt"
 )
@@ -22,29 +23,39 @@ var (
 
 	// ErrSlicePtrNeeded is returned when an unexpected value is given, instead of a pointer to slice.
 	ErrSlicePtrNeeded = errors.New("provided target must be a pointer to slice")
+
+	// ErrKeyNotFound is returned by functions which need the key to already exist, but it doesn't.
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrInvalidJson is returned if a value which should already be JSON isn't, such as a json.RawMessage given to
+	// PutJson.
+	ErrInvalidJson = errors.New("invalid JSON")
 )
 
 // Del will find your bucket location and delete the key specified. It doesn't matter what is in the key's value, since
 // it is ignored during this operation. If the bucket doesn't exist, no error is returned since technically you've
 // already got what you asked for. Similarly, if the key doesn't exist, no error is returned for the same reason.
-func Del(tx *bolt.Tx, location, key string) error {
+func Del(tx Tx, location, key string) error {
 	if location == "" {
-		return ErrLocationMustHaveAtLeastOneBucket
+		return wrapErr("Del", location, key, ErrLocationMustHaveAtLeastOneBucket)
 	}
 	if key == "" {
-		return ErrKeyNotProvided
+		return wrapErr("Del", location, key, ErrKeyNotProvided)
+	}
+	if err := checkWritable(tx); err != nil {
+		return wrapErr("Del", location, key, err)
 	}
 
-	b, err := GetBucket(tx, location)
+	b, err := BucketAt(tx, location)
 	if err != nil {
-		return err
+		return wrapErr("Del", location, key, err)
 	}
 	if b == nil {
 		return nil
 	}
 
 	// now delete the key
-	return b.Delete([]byte(key))
+	return wrapErr("Del", location, key, delKey(tx, b, location, []byte(key)))
 }
 
 // Put will find your bucket location and put your value into the key specified. The location is specified as a
@@ -58,62 +69,167 @@ func Del(tx *bolt.Tx, location, key string) error {
 //
 // Examples:
 //
-//    rod.Put(tx, "social", "twitter-123456", []byte("chilts"))
-//    rod.Put(tx, "users.chilts", "email", []byte("andychilton@gmail.com"))
-//    rod.Put(tx, "users.chilts.posts", "hello-world", []byte("Hello, World!"))
+//	rod.Put(tx, "social", "twitter-123456", []byte("chilts"))
+//	rod.Put(tx, "users.chilts", "email", []byte("andychilton@gmail.com"))
+//	rod.Put(tx, "users.chilts.posts", "hello-world", []byte("Hello, World!"))
 //
 // The location must have at least one bucket ("" is not allowed), and the key must also be a non-empty string. The
 // transaction must be a writeable one otherwise an error is returned.
-func Put(tx *bolt.Tx, location, key string, value []byte) error {
+func Put(tx Tx, location, key string, value []byte) error {
 	if location == "" {
-		return ErrLocationMustHaveAtLeastOneBucket
+		return wrapErr("Put", location, key, ErrLocationMustHaveAtLeastOneBucket)
 	}
 	if key == "" {
-		return ErrKeyNotProvided
+		return wrapErr("Put", location, key, ErrKeyNotProvided)
+	}
+
+	b, err := createBucket(tx, location)
+	if err != nil {
+		return wrapErr("Put", location, key, err)
 	}
 
-	// split the 'bucket' on '.'
-	buckets := strings.Split(location, ".")
-	if buckets[0] == "" {
-		return ErrInvalidLocationBucket
+	return wrapErr("Put", location, key, putKey(tx, b, location, []byte(key), value))
+}
+
+// createBucket calls CreateBucketIfNotExists() for every bucket in location, returning the final one.
+func createBucket(tx Tx, location string) (Bucket, error) {
+	if location == "" {
+		return nil, ErrLocationMustHaveAtLeastOneBucket
+	}
+
+	// walk the location one bucket at a time, rather than splitting it all up first
+	name, rest, more := nextBucket(location)
+	if name == "" {
+		return nil, ErrInvalidLocationBucket
+	}
+	if err := checkWritable(tx); err != nil {
+		return nil, err
+	}
+
+	btx, err := backend(tx)
+	if err != nil {
+		return nil, err
 	}
 
 	// get the first bucket
-	b, errCreateTopLevel := tx.CreateBucketIfNotExists([]byte(buckets[0]))
+	b, errCreateTopLevel := btx.CreateBucketIfNotExists(lookup(name))
 	if errCreateTopLevel != nil {
-		return errCreateTopLevel
+		return nil, errCreateTopLevel
 	}
 
-	// now, only loop through if we have more than 2
-	if len(buckets) > 1 {
-		for _, name := range buckets[1:] {
-			if name == "" {
-				return ErrInvalidLocationBucket
-			}
-			var err error
-			b, err = b.CreateBucketIfNotExists([]byte(name))
-			if err != nil {
-				return err
-			}
+	// now, loop through the rest
+	for more {
+		name, rest, more = nextBucket(rest)
+		if name == "" {
+			return nil, ErrInvalidLocationBucket
+		}
+		var err error
+		b, err = b.CreateBucketIfNotExists(lookup(name))
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return b.Put([]byte(key), value)
+	return b, nil
 }
 
 // PutString converts the string to []byte and calls Put. Everything that applies there applies here too.
-func PutString(tx *bolt.Tx, location, key, value string) error {
+func PutString(tx Tx, location, key, value string) error {
 	return Put(tx, location, key, []byte(value))
 }
 
-// PutJson calls json.Marshal() to serialise the value into []byte and calls rod.Put with the result.
-func PutJson(tx *bolt.Tx, location, key string, v interface{}) error {
-	// now put this value in this key
-	value, err := json.Marshal(v)
+// PutJson serialises the value into []byte as json.Marshal() would (in a pooled buffer, see SetJsonPooling) and calls
+// rod.Put with the result.
+//
+// If v is a struct with a time.Time field tagged `rod:"created"` or `rod:"updated"` then these are stamped first. The
+// updated time is set on every put, whereas the created time is only set if the record doesn't already exist and
+// otherwise kept from the stored record.
+//
+// If v has an integer field tagged `rod:"version"` then it must match the version of the stored record (0 if there
+// isn't one) or ErrVersionConflict is returned and nothing is put. Otherwise the version is incremented as it is put.
+// This gives you optimistic locking: read the record, change it, and put it back, and if anyone else put it in the
+// meantime you'll get ErrVersionConflict rather than overwriting their change.
+//
+// Pass a pointer if you'd like to see the stamped times and new version in v itself. They are only set once the put
+// has succeeded, so v is left as it was if anything fails and may be put again.
+//
+// A json.RawMessage or []byte is taken to be JSON already, so it is checked to be valid (and against any schema) and
+// then put exactly as it is, rather than being re-encoded, which would compact a json.RawMessage and put a []byte as a
+// base64 string:
+//
+//	rod.PutJson(tx, "users", "chilts", json.RawMessage(`{"Username": "chilts"}`))
+//
+// If it isn't valid JSON then ErrInvalidJson is returned and nothing is put.
+//
+// If v implements BeforeSaver or Validator then these are called first of all, and if the Store has a schema for
+// location (see SetSchema) then the document is validated before it is put. Any fields tagged `rod:"encrypt"` are
+// encrypted (see SetEncryptionKey).
+func PutJson(tx Tx, location, key string, v interface{}) error {
+	value, done, err := encodeJson(tx, location, key, v, nil)
 	if err != nil {
-		return err
+		return wrapErr("PutJson", location, key, err)
+	}
+	if err := Put(tx, location, key, value); err != nil {
+		return wrapErr("PutJson", location, key, err)
 	}
-	return Put(tx, location, key, value)
+	done()
+	return nil
+}
+
+// encodeJson does everything PutJson does to v before it is put, which every function writing a JSON document goes
+// through so that none of them skip a step: BeforeSave and Validate are called, the tagged fields are prepared, and the
+// document is marshalled and checked against any schema for location. Only then are any encrypted fields encrypted, so
+// the schema sees what was given rather than ciphertext. A json.RawMessage or []byte only needs to be valid and match
+// the schema. If edit isn't nil then it may change the marshalled document, before it is checked and once more after
+// encryption. Call done once the document has been put, so v gets its new version and timestamps.
+func encodeJson(tx Tx, location, key string, v interface{}, edit func([]byte) ([]byte, error)) ([]byte, func(), error) {
+	marshal := func(v interface{}) ([]byte, error) {
+		value, err := marshalJson(v)
+		if err != nil || edit == nil {
+			return value, err
+		}
+		return edit(value)
+	}
+
+	if raw, ok := rawJson(v); ok {
+		value, err := validRaw(raw)
+		if err == nil && edit != nil {
+			value, err = edit(value)
+		}
+		if err == nil {
+			err = validateJson(tx, location, value)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return value, func() {}, nil
+	}
+
+	if err := beforeSave(tx, v); err != nil {
+		return nil, nil, err
+	}
+	v, done, err := prepareJson(tx, location, key, v)
+	if err != nil {
+		return nil, nil, err
+	}
+	value, err := marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateJson(tx, location, value); err != nil {
+		return nil, nil, err
+	}
+
+	encrypted, ok, err := encryptFields(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		if value, err = marshal(encrypted); err != nil {
+			return nil, nil, err
+		}
+	}
+	return value, done, nil
 }
 
 // Get will fetch the raw bytes from the BoltDB. If any bucket doesn't exist it will return nil. If the key doesn't
@@ -122,26 +238,34 @@ func PutJson(tx *bolt.Tx, location, key string, v interface{}) error {
 // Error returned from this function are:
 // * ErrLocationMustHaveAtLeastOneBucket if no location was specified
 // * ErrKeyNotProvided if no key was specified
-func Get(tx *bolt.Tx, location, key string) ([]byte, error) {
-	b, err := GetBucket(tx, location)
+func Get(tx Tx, location, key string) ([]byte, error) {
+	b, err := BucketAt(tx, location)
 	if err != nil {
-		return nil, err
+		return nil, wrapErr("Get", location, key, err)
 	}
 	if b == nil {
 		return nil, nil
 	}
 
 	if key == "" {
-		return nil, ErrKeyNotProvided
+		return nil, wrapErr("Get", location, key, ErrKeyNotProvided)
 	}
 
 	// get this key
-	return b.Get([]byte(key)), nil
+	st := observing(tx)
+	if st == nil {
+		return b.Get(lookup(key)), nil
+	}
+	root, location, _ := rooted(tx, location)
+	start := time.Now()
+	value := b.Get(lookup(key))
+	st.store.observe(Operation{Name: "get", Location: location, Key: key, Tx: root, Bytes: len(value), Start: start, Duration: time.Since(start)})
+	return value, nil
 }
 
 // GetString calls Get and converts the []byte to a string before returning it to you. Everything that applies there
 // applies here too.
-func GetString(tx *bolt.Tx, location, key string) (string, error) {
+func GetString(tx Tx, location, key string) (string, error) {
 	raw, err := Get(tx, location, key)
 	if err != nil {
 		return "", err
@@ -151,11 +275,11 @@ func GetString(tx *bolt.Tx, location, key string) (string, error) {
 
 // GetJson calls Get and then json.Unmarshal() with the result to deserialise the value into interface{}. If any bucket
 // doesn't exist we just return nil with nothing placed into v. The same if the key doesn't exist.
-func GetJson(tx *bolt.Tx, location, key string, v interface{}) error {
+func GetJson(tx Tx, location, key string, v interface{}) error {
 	// get this key
 	raw, err := Get(tx, location, key)
 	if err != nil {
-		return err
+		return wrapErr("GetJson", location, key, err)
 	}
 	if raw == nil {
 		// no key exists
@@ -163,38 +287,91 @@ func GetJson(tx *bolt.Tx, location, key string, v interface{}) error {
 	}
 
 	// decode to the v interface{}
-	return json.Unmarshal(raw, &v)
+	return wrapErr("GetJson", location, key, loadJson(tx, raw, v))
+}
+
+// GetFound is like Get except it also tells you whether the key was found, so you can tell the difference between a
+// missing key (or bucket) and a key with an empty value. As with Get, the value is only valid for the life of the
+// transaction.
+func GetFound(tx Tx, location, key string) ([]byte, bool, error) {
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return nil, false, wrapErr("GetFound", location, key, err)
+	}
+	if key == "" {
+		return nil, false, wrapErr("GetFound", location, key, ErrKeyNotProvided)
+	}
+	if b == nil || !hasKey(b, lookup(key)) {
+		return nil, false, nil
+	}
+
+	v := b.Get(lookup(key))
+	if v == nil {
+		v = []byte{}
+	}
+	return v, true, nil
+}
+
+// GetJsonFound is like GetJson except it also tells you whether the key was found. If it wasn't then nothing is placed
+// into v.
+func GetJsonFound(tx Tx, location, key string, v interface{}) (bool, error) {
+	raw, found, err := GetFound(tx, location, key)
+	if err != nil || !found {
+		return false, wrapErr("GetJsonFound", location, key, err)
+	}
+
+	return true, wrapErr("GetJsonFound", location, key, loadJson(tx, raw, v))
 }
 
 // GetBucket returns this nested bucket from the store. If any bucket along the way does not exist, then no bucket is
-// returned (nil) but not error is returned either.
+// returned (nil) but not error is returned either. Use BucketAt to get the bucket from any backend's transaction.
 func GetBucket(tx *bolt.Tx, location string) (*bolt.Bucket, error) {
+	b, err := getBucket(tx, location)
+	if err != nil || b == nil {
+		return nil, wrapErr("GetBucket", location, "", err)
+	}
+	return b.(boltBucket).b, nil
+}
+
+// BucketAt is GetBucket for any transaction rod can work with, returning the backend's Bucket. If any bucket along the
+// way does not exist, then nil is returned with no error.
+func BucketAt(tx Tx, location string) (Bucket, error) {
+	b, err := getBucket(tx, location)
+	return b, wrapErr("BucketAt", location, "", err)
+}
+
+// getBucket does the work for GetBucket, without wrapping any errors.
+func getBucket(tx Tx, location string) (Bucket, error) {
 	if location == "" {
 		return nil, ErrLocationMustHaveAtLeastOneBucket
 	}
 
-	// split the 'bucket' on '.'
-	buckets := strings.Split(location, ".")
-	if buckets[0] == "" {
+	// walk the location one bucket at a time, rather than splitting it all up first
+	name, rest, more := nextBucket(location)
+	if name == "" {
 		return nil, ErrInvalidLocationBucket
 	}
 
+	btx, err := backend(tx)
+	if err != nil {
+		return nil, err
+	}
+
 	// get the first bucket
-	b := tx.Bucket([]byte(buckets[0]))
+	b := btx.Bucket(lookup(name))
 	if b == nil {
 		return nil, nil
 	}
 
-	// loop through if we have more than 2
-	if len(buckets) > 1 {
-		for _, name := range buckets[1:] {
-			if name == "" {
-				return nil, ErrInvalidLocationBucket
-			}
-			b = b.Bucket([]byte(name))
-			if b == nil {
-				return nil, nil
-			}
+	// loop through the rest
+	for more {
+		name, rest, more = nextBucket(rest)
+		if name == "" {
+			return nil, ErrInvalidLocationBucket
+		}
+		b = b.Bucket(lookup(name))
+		if b == nil {
+			return nil, nil
 		}
 	}
 
@@ -205,20 +382,20 @@ func GetBucket(tx *bolt.Tx, location string) (*bolt.Bucket, error) {
 // location. The newItem function you pass in will be called for every key in the bucket and should just return an
 // empty instance of your type. Append will also be called for every item once unmarshalling has taken place.
 //
-//   animals := make([]*Animal, 0)
-//   err := SelAll(tx, "animal", func() interface{} {
-//       return Animal{}
-//   }, func(v interface{}) {
-//       a, _ := v.(Animal)
-//       animals = append(animals, &a)
-//   })
+//	animals := make([]*Animal, 0)
+//	err := SelAll(tx, "animal", func() interface{} {
+//	    return Animal{}
+//	}, func(v interface{}) {
+//	    a, _ := v.(Animal)
+//	    animals = append(animals, &a)
+//	})
 //
 // It's a bit of boilerplate but you could just pass in a newItem function declared earlier in the program. This API
 // is subject to change since it could probably be improved upon.
-func SelAll(tx *bolt.Tx, location string, newItem func() interface{}, append func(interface{})) error {
-	b, err := GetBucket(tx, location)
+func SelAll(tx Tx, location string, newItem func() interface{}, append func(interface{})) error {
+	b, err := BucketAt(tx, location)
 	if err != nil {
-		return err
+		return wrapErr("SelAll", location, "", err)
 	}
 	if b == nil {
 		return nil
@@ -231,7 +408,7 @@ func SelAll(tx *bolt.Tx, location string, newItem func() interface{}, append fun
 		item := newItem()
 		err := json.Unmarshal(v, &item)
 		if err != nil {
-			return err
+			return wrapErr("SelAll", location, string(k), err)
 		}
 
 		// now call the append function
@@ -243,20 +420,69 @@ func SelAll(tx *bolt.Tx, location string, newItem func() interface{}, append fun
 
 // All will give you everything inside the bucket specified by location.
 //
-//   var users []User
-//   err := rod.All(tx, "user", &users)
+//	var users []User
+//	err := rod.All(tx, "user", &users)
 //
-//   var animals []Animals
-//   err := rod.All(tx, "animal", &animals)
+//	var animals []Animals
+//	err := rod.All(tx, "animal", &animals)
 //
 // This function supercedes SelAll() so use this instead of that.
-func All(tx *bolt.Tx, location string, to interface{}) error {
+//
+// The results are in key order unless you pass one of the sort options:
+//
+//	err := rod.All(tx, "user", &users, rod.SortByDesc("Logins"))
+func All(tx Tx, location string, to interface{}, opts ...QueryOption) error {
 	// figure out what slice we have been given
+	results, err := newSliceBuilder(tx, to, opts)
+	if err != nil {
+		return wrapErr("All", location, "", err)
+	}
+
+	// find this bucket
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("All", location, "", err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	// use a cursor to iterate through this bucket
+	scan := startScan(tx, location)
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		// decode and add to the slice of results
+		scan.item(v)
+		if err := results.append(string(k), v); err != nil {
+			return scan.done(wrapErr("All", location, string(k), err))
+		}
+	}
+
+	// set these results back into `to`
+	return scan.done(wrapErr("All", location, "", results.set()))
+}
+
+// sliceBuilder decodes JSON values into new elements of the slice which `to` points to, for All() and friends.
+type sliceBuilder struct {
+	tx          Tx
+	ref         reflect.Value
+	results     reflect.Value
+	elemType    reflect.Type
+	isPtrWanted bool
+	keyField    []int
+	query       query
+	pending     []sortable
+	// badMu guards query.bad, since ShardedStore.All decodes from several shards at once
+	badMu sync.Mutex
+}
+
+// newSliceBuilder checks `to` is a pointer to a slice and figures out what type each element should be.
+func newSliceBuilder(tx Tx, to interface{}, opts []QueryOption) (*sliceBuilder, error) {
 	ref := reflect.ValueOf(to)
 
 	// check we have not been given a slice (a pointer to a slice in fact)
 	if ref.Kind() != reflect.Ptr || reflect.Indirect(ref).Kind() != reflect.Slice {
-		return ErrSlicePtrNeeded
+		return nil, ErrSlicePtrNeeded
 	}
 
 	// see what the actual type of this ref is
@@ -272,50 +498,110 @@ func All(tx *bolt.Tx, location string, to interface{}) error {
 		elemType = elemType.Elem()
 	}
 
-	// create the container for the results (which actually sets into `ref` and therefore `to`)
-	results := reflect.MakeSlice(reflect.Indirect(ref).Type(), 0, 0)
+	b := &sliceBuilder{
+		tx:          tx,
+		ref:         ref,
+		results:     reflect.MakeSlice(sliceType, 0, 0),
+		elemType:    elemType,
+		isPtrWanted: isPtrWanted,
+		keyField:    taggedField(elemType, "key"),
+	}
+	for _, opt := range opts {
+		opt(&b.query)
+	}
+	return b, nil
+}
+
+// append decodes raw into a new element and adds it to the results. If the results are to be sorted then decoding is
+// left until set() is called.
+func (s *sliceBuilder) append(key string, raw []byte) error {
+	if s.query.sortField != nil {
+		s.pending = append(s.pending, newSortable(key, raw, s.query.sortField))
+		return nil
+	}
+	return s.decode(key, raw)
+}
 
-	// find this bucket
-	b, err := GetBucket(tx, location)
+// decode decodes raw into a new element and adds it to the results. If the element has a field tagged `rod:"key"` then
+// it is set to key.
+func (s *sliceBuilder) decode(key string, raw []byte) error {
+	item, err := s.newItem(s.tx, key, raw)
 	if err != nil {
+		if s.quarantine(key, raw, err) {
+			return nil
+		}
 		return err
 	}
-	if b == nil {
-		return nil
-	}
+	s.add(item)
+	return nil
+}
 
-	// use a cursor to iterate through this bucket
-	c := b.Cursor()
-	for k, v := c.First(); k != nil; k, v = c.Next() {
-		// create a new elemType we want
-		item := reflect.Indirect(reflect.New(elemType))
+// newItem decodes raw (from within tx) into a new element, without adding it to the results.
+func (s *sliceBuilder) newItem(tx Tx, key string, raw []byte) (reflect.Value, error) {
+	// create a new elemType we want
+	item := reflect.Indirect(reflect.New(s.elemType))
 
-		// get a new thing
-		err := json.Unmarshal(v, item.Addr().Interface())
-		if err != nil {
-			return err
+	// get a new thing
+	err := loadJson(tx, raw, item.Addr().Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if s.keyField != nil {
+		if field, err := item.FieldByIndexErr(s.keyField); err == nil {
+			setKeyField(field, key)
 		}
+	}
 
-		// add to the slice of results
-		if isPtrWanted {
-			results = reflect.Append(results, item.Addr())
-		} else {
-			results = reflect.Append(results, item)
-		}
+	return item, nil
+}
+
+// quarantine records that the value at key failed to decode with err, if SkipBad was given, telling you whether it
+// should be skipped. Nested buckets (with a nil raw) are skipped without being recorded.
+func (s *sliceBuilder) quarantine(key string, raw []byte, err error) bool {
+	if s.query.bad == nil {
+		return false
+	}
+	if raw == nil {
+		return true
 	}
 
-	// set these results back into `to` (using the origin `ref` which is `reflect.ValueOf(to)`)
-	reflect.Indirect(ref).Set(results)
+	s.badMu.Lock()
+	defer s.badMu.Unlock()
+	*s.query.bad = append(*s.query.bad, BadRecord{Key: key, Value: copyBytes(raw), Err: err})
+	return true
+}
+
+// add adds an element from newItem to the slice of results.
+func (s *sliceBuilder) add(item reflect.Value) {
+	if s.isPtrWanted {
+		s.results = reflect.Append(s.results, item.Addr())
+	} else {
+		s.results = reflect.Append(s.results, item)
+	}
+}
+
+// set sorts and decodes any pending results, then puts the results back into `to` (using the original `ref` which is
+// `reflect.ValueOf(to)`).
+func (s *sliceBuilder) set() error {
+	if s.pending != nil {
+		sortSortables(s.pending, s.query.sortDesc)
+		for _, item := range s.pending {
+			if err := s.decode(item.key, item.raw); err != nil {
+				return &Error{Key: item.key, Err: err}
+			}
+		}
+	}
 
+	reflect.Indirect(s.ref).Set(s.results)
 	return nil
 }
 
 // AllKeys will return you a slice of strings of all of the keys in this bucket.
-func AllKeys(tx *bolt.Tx, location string) ([]string, error) {
+func AllKeys(tx Tx, location string) ([]string, error) {
 	// find this bucket
-	b, err := GetBucket(tx, location)
+	b, err := BucketAt(tx, location)
 	if err != nil {
-		return nil, err
+		return nil, wrapErr("AllKeys", location, "", err)
 	}
 	if b == nil {
 		return nil, nil
@@ -325,10 +611,79 @@ func AllKeys(tx *bolt.Tx, location string) ([]string, error) {
 	keys := make([]string, 0)
 
 	// use a cursor to iterate through this bucket
+	scan := startScan(tx, location)
 	c := b.Cursor()
 	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		scan.item(nil)
 		keys = append(keys, string(k))
 	}
 
-	return keys, nil
+	return keys, scan.done(nil)
+}
+
+// AllValues will return you a slice of all of the raw values in this bucket, in key order. No decoding is done, so this
+// is useful if you're using your own serialisation instead of JSON. Each value is copied out of the transaction so it
+// remains valid after the transaction has closed.
+func AllValues(tx Tx, location string) ([][]byte, error) {
+	// find this bucket
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return nil, wrapErr("AllValues", location, "", err)
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	// create a slice for the values
+	values := make([][]byte, 0)
+
+	// use a cursor to iterate through this bucket (skipping any nested buckets)
+	scan := startScan(tx, location)
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		scan.item(v)
+		value := make([]byte, len(v))
+		copy(value, v)
+		values = append(values, value)
+	}
+
+	return values, scan.done(nil)
+}
+
+// AllValuesFunc is the streaming form of AllValues. Instead of building up a slice, fn is called for every raw value in
+// the bucket in key order. If fn returns Stop the iteration is halted and nil is returned, otherwise any error from fn
+// halts the iteration and is returned.
+//
+// Note that the value passed to fn is only valid for the life of the transaction (as per Bolt) so copy it if you need
+// to keep it.
+func AllValuesFunc(tx Tx, location string, fn func(value []byte) error) error {
+	// find this bucket
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("AllValuesFunc", location, "", err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	// use a cursor to iterate through this bucket (skipping any nested buckets)
+	scan := startScan(tx, location)
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		scan.item(v)
+		if err := fn(v); err != nil {
+			if err == Stop {
+				return scan.done(nil)
+			}
+			return scan.done(err)
+		}
+	}
+
+	return scan.done(nil)
 }
@@ -31,6 +31,25 @@ func check(err error) {
 	}
 }
 
+// openTestDB opens a fresh Bolt database in a temporary directory. Call the returned function to close and remove it.
+func openTestDB(t testing.TB) (*bolt.DB, func()) {
+	dir, err := ioutil.TempDir("", "rod-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
 func TestAll(t *testing.T) {
 	dir, err := ioutil.TempDir("", "rod-")
 	if err != nil {
@@ -115,6 +134,48 @@ func TestAll(t *testing.T) {
 		check(err)
 	})
 
+	t.Run("GetFound and GetJsonFound", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "found", "empty", ""))
+			check(PutJson(tx, "found", "user", User{"chilts", 2}))
+
+			value, found, err := GetFound(tx, "found", "empty")
+			check(err)
+			if !found || value == nil || len(value) != 0 {
+				t.Fatalf("An empty value should be found and not be nil")
+			}
+
+			value, found, err = GetFound(tx, "found", "missing")
+			check(err)
+			if found || value != nil {
+				t.Fatalf("A missing key should not be found")
+			}
+
+			_, found, err = GetFound(tx, "not-found", "missing")
+			check(err)
+			if found {
+				t.Fatalf("A key in a missing bucket should not be found")
+			}
+
+			var user User
+			found, err = GetJsonFound(tx, "found", "user", &user)
+			check(err)
+			if !found || user.Logins != 2 {
+				t.Fatalf("The user should have been found: %#v", user)
+			}
+
+			found, err = GetJsonFound(tx, "found", "nobody", &user)
+			check(err)
+			if found {
+				t.Fatalf("A missing user should not be found")
+			}
+
+			return nil
+		})
+
+		check(err)
+	})
+
 	t.Run("SelAll (DEPRECATED)", func(t *testing.T) {
 		// Start a read-write transaction.
 		if err := db.Update(func(tx *bolt.Tx) error {
@@ -253,6 +314,46 @@ func TestAll(t *testing.T) {
 		check(err)
 	})
 
+	t.Run("AllValues", func(t *testing.T) {
+		if err := db.Update(func(tx *bolt.Tx) error {
+			location := "values"
+			check(PutString(tx, location, "a", "apple"))
+			check(PutString(tx, location, "b", "banana"))
+			check(PutString(tx, location, "c", "cherry"))
+
+			values, err := AllValues(tx, location)
+			check(err)
+			if len(values) != 3 {
+				t.Fatalf("Three values should have been returned from AllValues(), but instead %d were\n", len(values))
+			}
+			if string(values[1]) != "banana" {
+				t.Fatalf("Second value should have been 'banana' but was '%s'\n", string(values[1]))
+			}
+
+			// and the streaming version
+			count := 0
+			err = AllValuesFunc(tx, location, func(value []byte) error {
+				count++
+				return nil
+			})
+			check(err)
+			if count != 3 {
+				t.Fatalf("AllValuesFunc() should have been called three times, but instead was called %d times\n", count)
+			}
+
+			// a non-existant bucket gives nothing
+			values, err = AllValues(tx, "does-not-exist")
+			check(err)
+			if values != nil {
+				t.Fatalf("Should have been returned a nil slice due to the bucket not existing\n")
+			}
+
+			return nil
+		}); err != nil {
+			log.Fatal(err)
+		}
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		location := "delete"
 		key := "key"
@@ -281,3 +382,51 @@ func TestAll(t *testing.T) {
 		check(err)
 	})
 }
+
+func BenchmarkPut(b *testing.B) {
+	for _, location := range []string{"users", "users.chilts.posts.drafts"} {
+		b.Run(location, func(b *testing.B) {
+			db, done := openTestDB(b)
+			defer done()
+
+			value := []byte(`{"Username":"chilts","Logins":1}`)
+			tx, err := db.Begin(true)
+			check(err)
+			defer tx.Rollback()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := Put(tx, location, "chilts", value); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, location := range []string{"users", "users.chilts.posts.drafts"} {
+		b.Run(location, func(b *testing.B) {
+			db, done := openTestDB(b)
+			defer done()
+
+			err := db.Update(func(tx *bolt.Tx) error {
+				return PutString(tx, location, "chilts", `{"Username":"chilts","Logins":1}`)
+			})
+			check(err)
+
+			tx, err := db.Begin(false)
+			check(err)
+			defer tx.Rollback()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if v, err := Get(tx, location, "chilts"); err != nil || v == nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
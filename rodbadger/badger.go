@@ -0,0 +1,87 @@
+package rodbadger
+
+import (
+	"github.com/chilts/rod"
+	"github.com/chilts/rod/internal/keyspace"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// DB is a Badger database for rod to use.
+type DB struct {
+	db *badger.DB
+}
+
+// Open opens the Badger database with these options.
+func Open(opts badger.Options) (*DB, error) {
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return New(db), nil
+}
+
+// New returns a DB for the already open Badger database.
+func New(db *badger.DB) *DB {
+	return &DB{db: db}
+}
+
+// Badger returns the underlying *badger.DB.
+func (db *DB) Badger() *badger.DB {
+	return db.db
+}
+
+// Close closes the underlying Badger database.
+func (db *DB) Close() error {
+	return db.db.Close()
+}
+
+// Update runs fn inside a read-write transaction, which is committed if fn returns nil. As with Badger, it may fail
+// with badger.ErrConflict if another transaction changed the same keys, in which case it can be retried.
+func (db *DB) Update(fn func(tx *Tx) error) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		return fn(Wrap(txn, true))
+	})
+}
+
+// View runs fn inside a read-only transaction.
+func (db *DB) View(fn func(tx *Tx) error) error {
+	return db.db.View(func(txn *badger.Txn) error {
+		return fn(Wrap(txn, false))
+	})
+}
+
+// Tx is a Badger transaction as a rod.BackendTx.
+type Tx struct {
+	txn      *badger.Txn
+	writable bool
+}
+
+// Wrap returns the Tx for a Badger transaction you have started yourself. Badger doesn't say whether a transaction is
+// read-write, so pass writable as you did to db.NewTransaction().
+func Wrap(txn *badger.Txn, writable bool) *Tx {
+	return &Tx{txn: txn, writable: writable}
+}
+
+// Txn returns the underlying *badger.Txn.
+func (tx *Tx) Txn() *badger.Txn {
+	return tx.txn
+}
+
+// Writable tells you whether this is a read-write transaction.
+func (tx *Tx) Writable() bool {
+	return tx.writable
+}
+
+// Bucket returns the top-level bucket called name, or nil if it doesn't exist.
+func (tx *Tx) Bucket(name []byte) rod.Bucket {
+	return tx.root().Bucket(name)
+}
+
+// CreateBucketIfNotExists returns the top-level bucket called name, creating it first if needed.
+func (tx *Tx) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	return tx.root().CreateBucketIfNotExists(name)
+}
+
+func (tx *Tx) root() rod.Bucket {
+	return keyspace.Root(kv{tx.txn})
+}
@@ -0,0 +1,131 @@
+package rodbadger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chilts/rod"
+	"github.com/dgraph-io/badger/v4"
+)
+
+type User struct {
+	Username string
+	Logins   int
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBadger(t *testing.T) {
+	db, err := Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	check(t, err)
+	defer db.Close()
+
+	t.Run("Put, Get and Del", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			check(t, rod.PutString(tx, "users.chilts", "email", "andychilton@gmail.com"))
+
+			email, err := rod.GetString(tx, "users.chilts", "email")
+			check(t, err)
+			if email != "andychilton@gmail.com" {
+				t.Fatalf("Unexpected email '%s'", email)
+			}
+
+			check(t, rod.Del(tx, "users.chilts", "email"))
+			value, err := rod.Get(tx, "users.chilts", "email")
+			check(t, err)
+			if value != nil {
+				t.Fatalf("The email should have been deleted")
+			}
+			return nil
+		})
+		check(t, err)
+
+		err = db.View(func(tx *Tx) error {
+			if err := rod.PutString(tx, "users", "key", "value"); err == nil {
+				t.Fatalf("Expected an error putting within a View")
+			}
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("All, AllKeys and cursors", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			check(t, rod.PutJson(tx, "people", "bob", User{"bob", 2}))
+			check(t, rod.PutJson(tx, "people", "alice", User{"alice", 1}))
+			check(t, rod.PutJson(tx, "people", "carol", User{"carol", 3}))
+			// keys in a nested bucket aren't in the bucket itself, but the nested bucket is
+			check(t, rod.PutString(tx, "people.dave", "email", "dave@example.com"))
+			return nil
+		})
+		check(t, err)
+
+		err = db.View(func(tx *Tx) error {
+			keys, err := rod.AllKeys(tx, "people")
+			check(t, err)
+			if len(keys) != 4 || keys[0] != "alice" || keys[3] != "dave" {
+				t.Fatalf("Unexpected keys %v", keys)
+			}
+
+			count, err := rod.Count(tx, "people")
+			check(t, err)
+			if count != 3 {
+				t.Fatalf("Expected 3 people but got %d", count)
+			}
+
+			key, _, err := rod.Last(tx, "people")
+			check(t, err)
+			if key != "carol" {
+				t.Fatalf("Expected the last person to be carol but got '%s'", key)
+			}
+
+			key, _, err = rod.SeekBefore(tx, "people", "bz")
+			check(t, err)
+			if key != "bob" {
+				t.Fatalf("Expected bob just before 'bz' but got '%s'", key)
+			}
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("Buckets and values", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			check(t, rod.PutString(tx, "things", "a", "apple"))
+
+			err := rod.PutString(tx, "things.a", "b", "banana")
+			if !errors.Is(err, ErrIncompatibleValue) {
+				t.Fatalf("Expected ErrIncompatibleValue but got %v", err)
+			}
+
+			// names containing the separator bytes stay apart
+			check(t, rod.Put(tx, "things", "x\x00\x02y", []byte("odd")))
+			value, err := rod.Get(tx, "things", "x\x00\x02y")
+			check(t, err)
+			if string(value) != "odd" {
+				t.Fatalf("Unexpected value '%s'", value)
+			}
+
+			seq, err := rod.NextSequence(tx, "things")
+			check(t, err)
+			seq, err = rod.NextSequence(tx, "things")
+			check(t, err)
+			if seq != 2 {
+				t.Fatalf("Expected the second sequence to be 2 but got %d", seq)
+			}
+
+			keys, err := rod.AllKeys(tx, "things")
+			check(t, err)
+			if len(keys) != 2 {
+				t.Fatalf("The sequence should not be a key: %q", keys)
+			}
+			return nil
+		})
+		check(t, err)
+	})
+}
@@ -0,0 +1,17 @@
+// Package rodbadger is a BadgerDB backend for rod, for when Bolt's single writer becomes the bottleneck. Transactions
+// from a rodbadger.DB can be given to any of rod's functions, just as a *bolt.Tx would be:
+//
+//	db, err := rodbadger.Open(badger.DefaultOptions("/path/to/data"))
+//	err = db.Update(func(tx *rodbadger.Tx) error {
+//	    return rod.PutJson(tx, "users.chilts", "profile", &profile)
+//	})
+//
+// Badger has no buckets, so each location becomes a prefix on the keys within it (see internal/keyspace). A bucket's
+// keys and nested buckets are kept next to each other in key order, so iterating over a location is a prefix scan.
+// Badger should be given to rod whole, since rod expects to own every key in it.
+//
+// Values come back copied out of Badger, and each cursor step runs its own short-lived iterator, so walking a large
+// location is slower than with Bolt even though writes are faster.
+//
+// (Ends)
+package rodbadger
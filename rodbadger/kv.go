@@ -0,0 +1,68 @@
+package rodbadger
+
+import (
+	"bytes"
+
+	"github.com/chilts/rod/internal/keyspace"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// ErrIncompatibleValue is returned when a key is used as both a value and a bucket, as with Bolt.
+var ErrIncompatibleValue = keyspace.ErrIncompatibleValue
+
+// kv is a Badger transaction as a keyspace.KV. Badger needs every iterator closed before the transaction ends, and
+// rod never closes a cursor, so each step opens an iterator, seeks to where it needs to be, and closes it again.
+type kv struct {
+	txn *badger.Txn
+}
+
+func (kv kv) Get(key []byte) ([]byte, error) {
+	item, err := kv.txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+func (kv kv) Set(key, value []byte) error {
+	return kv.txn.Set(key, value)
+}
+
+func (kv kv) Delete(key []byte) error {
+	return kv.txn.Delete(key)
+}
+
+func (kv kv) First(at, prefix []byte) ([]byte, []byte, error) {
+	it := kv.txn.NewIterator(badger.IteratorOptions{Prefix: prefix})
+	defer it.Close()
+
+	it.Seek(at)
+	return item(it, prefix)
+}
+
+func (kv kv) Last(before, prefix []byte) ([]byte, []byte, error) {
+	it := kv.txn.NewIterator(badger.IteratorOptions{Reverse: true})
+	defer it.Close()
+
+	// seeking backwards finds the last key equal to or before `before`, so step past it if it's there
+	it.Seek(before)
+	if it.Valid() && bytes.Equal(it.Item().Key(), before) {
+		it.Next()
+	}
+	return item(it, prefix)
+}
+
+// item returns a copy of the key and value the iterator is at, if it's still within prefix.
+func item(it *badger.Iterator, prefix []byte) ([]byte, []byte, error) {
+	if !it.ValidForPrefix(prefix) {
+		return nil, nil, nil
+	}
+	value, err := it.Item().ValueCopy(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return it.Item().KeyCopy(nil), value, nil
+}
@@ -0,0 +1,87 @@
+package rodbbolt
+
+import (
+	"github.com/chilts/rod"
+	"go.etcd.io/bbolt"
+)
+
+func init() {
+	rod.RegisterBackend(func(tx rod.Tx) (rod.BackendTx, bool) {
+		if t, ok := tx.(*bbolt.Tx); ok {
+			return Wrap(t), true
+		}
+		return nil, false
+	})
+}
+
+// Wrap returns the rod.BackendTx for tx. There's no need to call this since rod accepts a *bbolt.Tx as it is, but it
+// can be useful to see a bbolt transaction through rod's interfaces.
+func Wrap(tx *bbolt.Tx) rod.BackendTx {
+	return boltTx{tx}
+}
+
+// boltTx adapts a *bbolt.Tx to a rod.BackendTx.
+type boltTx struct {
+	tx *bbolt.Tx
+}
+
+func (t boltTx) Writable() bool {
+	return t.tx.Writable()
+}
+
+func (t boltTx) Bucket(name []byte) rod.Bucket {
+	return wrapBucket(t.tx.Bucket(name))
+}
+
+func (t boltTx) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	b, err := t.tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return bucket{b}, nil
+}
+
+// bucket adapts a *bbolt.Bucket to a rod.Bucket.
+type bucket struct {
+	b *bbolt.Bucket
+}
+
+// wrapBucket makes sure a missing bucket is a nil rod.Bucket, rather than one wrapping nil.
+func wrapBucket(b *bbolt.Bucket) rod.Bucket {
+	if b == nil {
+		return nil
+	}
+	return bucket{b}
+}
+
+func (b bucket) Get(key []byte) []byte {
+	return b.b.Get(key)
+}
+
+func (b bucket) Put(key, value []byte) error {
+	return b.b.Put(key, value)
+}
+
+func (b bucket) Delete(key []byte) error {
+	return b.b.Delete(key)
+}
+
+func (b bucket) Bucket(name []byte) rod.Bucket {
+	return wrapBucket(b.b.Bucket(name))
+}
+
+func (b bucket) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	nb, err := b.b.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return bucket{nb}, nil
+}
+
+func (b bucket) NextSequence() (uint64, error) {
+	return b.b.NextSequence()
+}
+
+func (b bucket) Cursor() rod.Cursor {
+	return b.b.Cursor()
+}
@@ -0,0 +1,83 @@
+package rodbbolt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chilts/rod"
+	"go.etcd.io/bbolt"
+)
+
+type User struct {
+	Username string
+	Logins   int
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBbolt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rodbbolt-")
+	check(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := bbolt.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(t, err)
+	defer db.Close()
+
+	t.Run("rod accepts a *bbolt.Tx", func(t *testing.T) {
+		err := db.Update(func(tx *bbolt.Tx) error {
+			check(t, rod.PutJson(tx, "users", "chilts", User{"chilts", 2}))
+			check(t, rod.PutJson(tx, "users", "bob", User{"bob", 1}))
+
+			var user User
+			check(t, rod.GetJson(tx, "users", "chilts", &user))
+			if user.Logins != 2 {
+				t.Fatalf("Expected 2 logins but got %d", user.Logins)
+			}
+			return nil
+		})
+		check(t, err)
+
+		err = db.View(func(tx *bbolt.Tx) error {
+			var users []User
+			check(t, rod.All(tx, "users", &users))
+			if len(users) != 2 || users[0].Username != "bob" {
+				t.Fatalf("Expected bob then chilts: %#v", users)
+			}
+
+			b, err := rod.BucketAt(tx, "users.missing")
+			check(t, err)
+			if b != nil {
+				t.Fatalf("A missing bucket should be nil")
+			}
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("Nested locations and sequences", func(t *testing.T) {
+		err := db.Update(func(tx *bbolt.Tx) error {
+			check(t, rod.PutString(tx, "blog.chilts.posts", "hello", "Hello, World!"))
+			seq, err := rod.NextSequence(tx, "blog.chilts.posts")
+			check(t, err)
+			if seq != 1 {
+				t.Fatalf("Expected the first sequence to be 1 but got %d", seq)
+			}
+
+			post, err := rod.GetString(Wrap(tx), "blog.chilts.posts", "hello")
+			check(t, err)
+			if post != "Hello, World!" {
+				t.Fatalf("Unexpected post '%s'", post)
+			}
+			return nil
+		})
+		check(t, err)
+	})
+}
@@ -0,0 +1,13 @@
+// Package rodbbolt lets rod work with go.etcd.io/bbolt, the maintained fork of Bolt. Importing it registers bbolt as a
+// rod backend, after which a *bbolt.Tx can be given to any of rod's functions just as a *bolt.Tx would be:
+//
+//	import _ "github.com/chilts/rod/rodbbolt"
+//
+//	err := db.Update(func(tx *bbolt.Tx) error {
+//	    return rod.PutJson(tx, "user", "chilts", &user)
+//	})
+//
+// The rod.Store (and so audit, changelogs and views) and the functions which take a whole *bolt.DB still need Bolt.
+//
+// (Ends)
+package rodbbolt
@@ -0,0 +1,16 @@
+// Package rodotel traces the operations made through a rod.Store with OpenTelemetry, so slow rod calls show up in your
+// distributed traces. Wrap the Store and pass your request's context to each call:
+//
+//	store := rodotel.New(s)
+//	err := store.PutJson(ctx, "users", "chilts", &user)
+//	err = store.Update(ctx, func(tx *bolt.Tx) error {
+//	    return rod.PutString(tx, "users.chilts", "email", email)
+//	})
+//
+// Each call gets a span ("rod.put", "rod.update" and so on) with the location, key and payload size as attributes. With
+// the TxHelpers option, each Get, Put, Del and scan (such as All) made inside an Update or View gets its own span too,
+// as a child of the transaction's. The tracer comes from otel.GetTracerProvider() unless you give one with
+// WithTracerProvider.
+//
+// (Ends)
+package rodotel
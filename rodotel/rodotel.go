@@ -0,0 +1,200 @@
+package rodotel
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer spans are made with.
+const TracerName = "github.com/chilts/rod/rodotel"
+
+// Attributes given to the spans.
+const (
+	LocationKey = attribute.Key("rod.location")
+	KeyKey      = attribute.Key("rod.key")
+	BytesKey    = attribute.Key("rod.bytes")
+)
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithTracerProvider makes spans with tp rather than otel.GetTracerProvider().
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Store) {
+		s.provider = tp
+	}
+}
+
+// TxHelpers gives each Get, Put, Del and scan made inside a transaction its own span. Since these are usually very
+// quick, and there may be many of them, they are left out unless asked for.
+func TxHelpers() Option {
+	return func(s *Store) {
+		s.helpers = true
+	}
+}
+
+// Store wraps a rod.Store, tracing each operation made through it.
+type Store struct {
+	store    *rod.Store
+	provider trace.TracerProvider
+	tracer   trace.Tracer
+	helpers  bool
+	// txs maps each open transaction to the context of its span, for TxHelpers.
+	txs sync.Map
+}
+
+// New returns a Store tracing the operations made through s.
+func New(s *rod.Store, opts ...Option) *Store {
+	store := &Store{store: s}
+	for _, opt := range opts {
+		opt(store)
+	}
+	if store.provider == nil {
+		store.provider = otel.GetTracerProvider()
+	}
+	store.tracer = store.provider.Tracer(TracerName)
+	if store.helpers {
+		s.Observe(store.observe)
+	}
+	return store
+}
+
+// Store returns the underlying rod.Store.
+func (s *Store) Store() *rod.Store {
+	return s.store
+}
+
+// Update runs fn inside a read-write transaction, as rod.Store.Update does, within a "rod.update" span.
+func (s *Store) Update(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	return s.UpdateAs(ctx, "", fn)
+}
+
+// UpdateAs is the same as Update except the writes are attributed to actor, as with rod.Store.UpdateAs.
+func (s *Store) UpdateAs(ctx context.Context, actor string, fn func(tx *bolt.Tx) error) error {
+	ctx, span := s.tracer.Start(ctx, "rod.update")
+	defer span.End()
+	return end(span, s.store.UpdateAs(actor, s.within(ctx, fn)))
+}
+
+// View runs fn inside a read-only transaction, as rod.Store.View does, within a "rod.view" span.
+func (s *Store) View(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	ctx, span := s.tracer.Start(ctx, "rod.view")
+	defer span.End()
+	return end(span, s.store.View(s.within(ctx, fn)))
+}
+
+// Get returns the value at location/key, as rod.Get does, within a "rod.get" span.
+func (s *Store) Get(ctx context.Context, location, key string) ([]byte, error) {
+	ctx, span := s.start(ctx, "rod.get", location, key)
+	defer span.End()
+
+	var value []byte
+	err := s.store.View(s.within(ctx, func(tx *bolt.Tx) error {
+		raw, err := rod.Get(tx, location, key)
+		if raw != nil {
+			// the value is only valid for the life of the transaction
+			value = append([]byte{}, raw...)
+		}
+		return err
+	}))
+	span.SetAttributes(BytesKey.Int(len(value)))
+	return value, end(span, err)
+}
+
+// GetJson decodes the value at location/key into v, as rod.GetJson does, within a "rod.get" span.
+func (s *Store) GetJson(ctx context.Context, location, key string, v interface{}) error {
+	ctx, span := s.start(ctx, "rod.get", location, key)
+	defer span.End()
+
+	err := s.store.View(s.within(ctx, func(tx *bolt.Tx) error {
+		raw, err := rod.Get(tx, location, key)
+		if err != nil || raw == nil {
+			return err
+		}
+		span.SetAttributes(BytesKey.Int(len(raw)))
+		return rod.GetJson(tx, location, key, v)
+	}))
+	return end(span, err)
+}
+
+// Put puts value at location/key, as rod.Put does, within a "rod.put" span.
+func (s *Store) Put(ctx context.Context, location, key string, value []byte) error {
+	ctx, span := s.start(ctx, "rod.put", location, key)
+	defer span.End()
+	span.SetAttributes(BytesKey.Int(len(value)))
+
+	return end(span, s.store.Update(s.within(ctx, func(tx *bolt.Tx) error {
+		return rod.Put(tx, location, key, value)
+	})))
+}
+
+// PutJson encodes v and puts it at location/key, as rod.PutJson does, within a "rod.put" span.
+func (s *Store) PutJson(ctx context.Context, location, key string, v interface{}) error {
+	ctx, span := s.start(ctx, "rod.put", location, key)
+	defer span.End()
+	if raw, err := json.Marshal(v); err == nil {
+		span.SetAttributes(BytesKey.Int(len(raw)))
+	}
+
+	return end(span, s.store.Update(s.within(ctx, func(tx *bolt.Tx) error {
+		return rod.PutJson(tx, location, key, v)
+	})))
+}
+
+// Del deletes location/key, as rod.Del does, within a "rod.del" span.
+func (s *Store) Del(ctx context.Context, location, key string) error {
+	ctx, span := s.start(ctx, "rod.del", location, key)
+	defer span.End()
+
+	return end(span, s.store.Update(s.within(ctx, func(tx *bolt.Tx) error {
+		return rod.Del(tx, location, key)
+	})))
+}
+
+func (s *Store) start(ctx context.Context, name, location, key string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name, trace.WithAttributes(LocationKey.String(location), KeyKey.String(key)))
+}
+
+// within wraps fn so that, with TxHelpers, the operations made in its transaction are traced as children of ctx.
+func (s *Store) within(ctx context.Context, fn func(tx *bolt.Tx) error) func(tx *bolt.Tx) error {
+	if !s.helpers {
+		return fn
+	}
+	return func(tx *bolt.Tx) error {
+		s.txs.Store(rod.Tx(tx), ctx)
+		defer s.txs.Delete(rod.Tx(tx))
+		return fn(tx)
+	}
+}
+
+// observe makes a span for a Get, Put, Del or scan made within one of our transactions, once it is done.
+func (s *Store) observe(op rod.Operation) {
+	ctx, ok := s.txs.Load(op.Tx)
+	if !ok {
+		return
+	}
+
+	_, span := s.tracer.Start(ctx.(context.Context), "rod.tx."+op.Name,
+		trace.WithTimestamp(op.Start),
+		trace.WithAttributes(LocationKey.String(op.Location), KeyKey.String(op.Key), BytesKey.Int(op.Bytes)),
+	)
+	end(span, op.Err)
+	span.End(trace.WithTimestamp(op.Start.Add(op.Duration)))
+}
+
+// end records err (if any) on span, and returns it.
+func end(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package rodotel
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type User struct {
+	Username string
+	Logins   int
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestRodotel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rodotel-")
+	check(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := rod.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(t, err)
+	defer s.Close()
+
+	ctx := context.Background()
+
+	t.Run("Store operations have spans", func(t *testing.T) {
+		sr := tracetest.NewSpanRecorder()
+		store := New(s, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))))
+
+		check(t, store.PutJson(ctx, "users", "chilts", &User{"chilts", 1}))
+		var user User
+		check(t, store.GetJson(ctx, "users", "chilts", &user))
+		if user.Logins != 1 {
+			t.Fatalf("Unexpected user %#v", user)
+		}
+		value, err := store.Get(ctx, "users", "nobody")
+		check(t, err)
+		if value != nil {
+			t.Fatalf("A missing key should be nil")
+		}
+		check(t, store.Del(ctx, "users", "chilts"))
+
+		spans := sr.Ended()
+		names := []string{"rod.put", "rod.get", "rod.get", "rod.del"}
+		if len(spans) != len(names) {
+			t.Fatalf("Expected %d spans but got %d", len(names), len(spans))
+		}
+		for i, span := range spans {
+			if span.Name() != names[i] {
+				t.Fatalf("Span %d should be %s but is %s", i, names[i], span.Name())
+			}
+			if v, _ := attr(span, LocationKey); v.AsString() != "users" {
+				t.Fatalf("Span %s has location %q", span.Name(), v.AsString())
+			}
+		}
+		if v, _ := attr(spans[0], KeyKey); v.AsString() != "chilts" {
+			t.Fatalf("Unexpected key %q", v.AsString())
+		}
+		if v, _ := attr(spans[1], BytesKey); v.AsInt64() != int64(len(`{"Username":"chilts","Logins":1}`)) {
+			t.Fatalf("Unexpected size %d", v.AsInt64())
+		}
+	})
+
+	t.Run("Errors are recorded", func(t *testing.T) {
+		sr := tracetest.NewSpanRecorder()
+		store := New(s, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))))
+
+		if err := store.Put(ctx, "", "key", []byte("value")); err == nil {
+			t.Fatalf("Expected an error")
+		}
+		spans := sr.Ended()
+		if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+			t.Fatalf("The span should have recorded the error")
+		}
+	})
+
+	t.Run("TxHelpers", func(t *testing.T) {
+		sr := tracetest.NewSpanRecorder()
+		store := New(s, TxHelpers(), WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))))
+
+		check(t, store.Update(ctx, func(tx *bolt.Tx) error {
+			check(t, rod.PutString(tx, "notes", "shopping", "milk"))
+			_, err := rod.GetString(tx, "notes", "shopping")
+			return err
+		}))
+		// transactions not made through the wrapper aren't traced
+		check(t, s.View(func(tx *bolt.Tx) error {
+			_, err := rod.GetString(tx, "notes", "shopping")
+			return err
+		}))
+
+		spans := sr.Ended()
+		if len(spans) != 3 {
+			t.Fatalf("Expected 3 spans but got %d", len(spans))
+		}
+		put, get, update := spans[0], spans[1], spans[2]
+		if put.Name() != "rod.tx.put" || get.Name() != "rod.tx.get" || update.Name() != "rod.update" {
+			t.Fatalf("Unexpected spans %s, %s and %s", put.Name(), get.Name(), update.Name())
+		}
+		for _, span := range []sdktrace.ReadOnlySpan{put, get} {
+			if span.Parent().SpanID() != update.SpanContext().SpanID() {
+				t.Fatalf("%s should be a child of the update", span.Name())
+			}
+			if v, _ := attr(span, BytesKey); v.AsInt64() != 4 {
+				t.Fatalf("%s has size %d", span.Name(), v.AsInt64())
+			}
+		}
+	})
+}
@@ -0,0 +1,18 @@
+// Package rodpebble is a Pebble backend for rod, for large datasets where Bolt's B+tree becomes the bottleneck.
+// Transactions from a rodpebble.DB can be given to any of rod's functions, just as a *bolt.Tx would be:
+//
+//	db, err := rodpebble.Open("/path/to/data", &pebble.Options{})
+//	err = db.Update(func(tx *rodpebble.Tx) error {
+//	    return rod.PutJson(tx, "users.chilts", "profile", &profile)
+//	})
+//
+// Locations are laid out as key prefixes just as with rodbadger (see internal/keyspace), and Pebble should be given
+// to rod whole.
+//
+// An Update collects its writes in an indexed batch, so that it can read them back, and commits the batch at the end.
+// Pebble checks nothing between batches, so Updates run one at a time, as they do with Bolt, to keep read-modify-write
+// helpers such as NextSequence and versioned PutJson safe. Writing to Pebble is quick so this is rarely a bottleneck.
+// A View reads from a snapshot, and any number can run at once.
+//
+// (Ends)
+package rodpebble
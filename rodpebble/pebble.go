@@ -0,0 +1,170 @@
+package rodpebble
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/chilts/rod"
+	"github.com/chilts/rod/internal/keyspace"
+	"github.com/cockroachdb/pebble"
+)
+
+// ErrTxNotWritable is returned when writing within a View.
+var ErrTxNotWritable = errors.New("tx not writable")
+
+// ErrIncompatibleValue is returned when a key is used as both a value and a bucket, as with Bolt.
+var ErrIncompatibleValue = keyspace.ErrIncompatibleValue
+
+// DB is a Pebble database for rod to use.
+type DB struct {
+	mu sync.Mutex
+	db *pebble.DB
+}
+
+// Open opens the Pebble database in dir with these options.
+func Open(dir string, opts *pebble.Options) (*DB, error) {
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return New(db), nil
+}
+
+// New returns a DB for the already open Pebble database.
+func New(db *pebble.DB) *DB {
+	return &DB{db: db}
+}
+
+// Pebble returns the underlying *pebble.DB.
+func (db *DB) Pebble() *pebble.DB {
+	return db.db
+}
+
+// Close closes the underlying Pebble database.
+func (db *DB) Close() error {
+	return db.db.Close()
+}
+
+// Update runs fn inside a read-write transaction. Its writes are committed (and synced) if fn returns nil, otherwise
+// they are thrown away.
+func (db *DB) Update(fn func(tx *Tx) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	batch := db.db.NewIndexedBatch()
+	defer batch.Close()
+
+	if err := fn(&Tx{r: batch, batch: batch}); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// View runs fn inside a read-only transaction, against a snapshot of the database.
+func (db *DB) View(fn func(tx *Tx) error) error {
+	snap := db.db.NewSnapshot()
+	defer snap.Close()
+
+	return fn(&Tx{r: snap})
+}
+
+// reader is what a batch and a snapshot have in common.
+type reader interface {
+	Get(key []byte) ([]byte, io.Closer, error)
+	NewIter(o *pebble.IterOptions) (*pebble.Iterator, error)
+}
+
+// Tx is a Pebble batch or snapshot as a rod.BackendTx.
+type Tx struct {
+	r     reader
+	batch *pebble.Batch
+}
+
+// Writable tells you whether this transaction came from Update.
+func (tx *Tx) Writable() bool {
+	return tx.batch != nil
+}
+
+// Bucket returns the top-level bucket called name, or nil if it doesn't exist.
+func (tx *Tx) Bucket(name []byte) rod.Bucket {
+	return tx.root().Bucket(name)
+}
+
+// CreateBucketIfNotExists returns the top-level bucket called name, creating it first if needed.
+func (tx *Tx) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	return tx.root().CreateBucketIfNotExists(name)
+}
+
+func (tx *Tx) root() rod.Bucket {
+	return keyspace.Root(kv{tx})
+}
+
+// kv is a Pebble batch or snapshot as a keyspace.KV.
+type kv struct {
+	tx *Tx
+}
+
+func (kv kv) Get(key []byte) ([]byte, error) {
+	value, closer, err := kv.tx.r.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return append([]byte{}, value...), nil
+}
+
+func (kv kv) Set(key, value []byte) error {
+	if kv.tx.batch == nil {
+		return ErrTxNotWritable
+	}
+	return kv.tx.batch.Set(key, value, nil)
+}
+
+func (kv kv) Delete(key []byte) error {
+	if kv.tx.batch == nil {
+		return ErrTxNotWritable
+	}
+	return kv.tx.batch.Delete(key, nil)
+}
+
+func (kv kv) First(at, prefix []byte) ([]byte, []byte, error) {
+	it, err := kv.tx.r.NewIter(&pebble.IterOptions{LowerBound: prefix, UpperBound: upperBound(prefix)})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer it.Close()
+
+	if !it.SeekGE(at) {
+		return nil, nil, it.Error()
+	}
+	return append([]byte{}, it.Key()...), append([]byte{}, it.Value()...), nil
+}
+
+func (kv kv) Last(before, prefix []byte) ([]byte, []byte, error) {
+	it, err := kv.tx.r.NewIter(&pebble.IterOptions{LowerBound: prefix, UpperBound: upperBound(prefix)})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer it.Close()
+
+	if !it.SeekLT(before) {
+		return nil, nil, it.Error()
+	}
+	return append([]byte{}, it.Key()...), append([]byte{}, it.Value()...), nil
+}
+
+// upperBound returns the first key after every key beginning with prefix, or nil if there isn't one.
+func upperBound(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
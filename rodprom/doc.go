@@ -0,0 +1,13 @@
+// Package rodprom exposes metrics about a rod.Store as a prometheus.Collector, so that a service using rod can be
+// monitored without instrumenting each call itself:
+//
+//	s, err := rod.Open("/path/to/rod.db", 0666, nil)
+//	prometheus.MustRegister(rodprom.Instrument(s))
+//
+// It counts every operation and how long it took, split by operation ("update", "view", "get", "put", "del" and
+// "scan"), the bytes read and written, and the operations made on each location. Since the number of locations is up to
+// you, keep them to a set which doesn't grow with your data (e.g. "users" rather than "users.chilts"), or turn the
+// per-location counter off with WithoutLocations.
+//
+// (Ends)
+package rodprom
@@ -0,0 +1,145 @@
+package rodprom
+
+import (
+	"github.com/chilts/rod"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// Namespace prefixes each metric name with namespace, such as "myapp" for "myapp_rod_operations_total".
+func Namespace(namespace string) Option {
+	return func(c *Collector) {
+		c.namespace = namespace
+	}
+}
+
+// Buckets sets the buckets (in seconds) of the operation latency histogram, which are prometheus.DefBuckets by
+// default.
+func Buckets(buckets []float64) Option {
+	return func(c *Collector) {
+		c.buckets = buckets
+	}
+}
+
+// WithoutLocations turns off the per-location operation counter.
+func WithoutLocations() Option {
+	return func(c *Collector) {
+		c.noLocations = true
+	}
+}
+
+// Collector is a prometheus.Collector for the operations made through a rod.Store. Give its Observe method to
+// Store.Observe, or use Instrument to do both at once.
+type Collector struct {
+	namespace   string
+	buckets     []float64
+	noLocations bool
+
+	ops       *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	read      prometheus.Counter
+	written   prometheus.Counter
+	locations *prometheus.CounterVec
+}
+
+// New returns a Collector which isn't observing anything yet.
+func New(opts ...Option) *Collector {
+	c := &Collector{buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.ops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: "rod",
+		Name:      "operations_total",
+		Help:      "Operations made through the rod Store.",
+	}, []string{"op"})
+	c.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: "rod",
+		Name:      "operation_errors_total",
+		Help:      "Operations made through the rod Store which failed.",
+	}, []string{"op"})
+	c.latency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: c.namespace,
+		Subsystem: "rod",
+		Name:      "operation_duration_seconds",
+		Help:      "How long operations made through the rod Store took.",
+		Buckets:   c.buckets,
+	}, []string{"op"})
+	c.read = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: "rod",
+		Name:      "read_bytes_total",
+		Help:      "Bytes of values read from the rod Store.",
+	})
+	c.written = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: "rod",
+		Name:      "written_bytes_total",
+		Help:      "Bytes of values written to the rod Store.",
+	})
+	c.locations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: c.namespace,
+		Subsystem: "rod",
+		Name:      "location_operations_total",
+		Help:      "Operations made on each location in the rod Store.",
+	}, []string{"location", "op"})
+
+	return c
+}
+
+// Instrument returns a new Collector observing s.
+func Instrument(s *rod.Store, opts ...Option) *Collector {
+	c := New(opts...)
+	s.Observe(c.Observe)
+	return c
+}
+
+// Observe records op.
+func (c *Collector) Observe(op rod.Operation) {
+	c.ops.WithLabelValues(op.Name).Inc()
+	c.latency.WithLabelValues(op.Name).Observe(op.Duration.Seconds())
+	if op.Err != nil {
+		c.errors.WithLabelValues(op.Name).Inc()
+	}
+
+	switch op.Name {
+	case "get", "scan":
+		c.read.Add(float64(op.Bytes))
+	case "put":
+		c.written.Add(float64(op.Bytes))
+	}
+
+	if op.Location != "" && !c.noLocations {
+		c.locations.WithLabelValues(op.Location, op.Name).Inc()
+	}
+}
+
+// Describe is part of prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.ops.Describe(ch)
+	c.errors.Describe(ch)
+	c.latency.Describe(ch)
+	c.read.Describe(ch)
+	c.written.Describe(ch)
+	if !c.noLocations {
+		c.locations.Describe(ch)
+	}
+}
+
+// Collect is part of prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.ops.Collect(ch)
+	c.errors.Collect(ch)
+	c.latency.Collect(ch)
+	c.read.Collect(ch)
+	c.written.Collect(ch)
+	if !c.noLocations {
+		c.locations.Collect(ch)
+	}
+}
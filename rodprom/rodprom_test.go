@@ -0,0 +1,83 @@
+package rodprom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRodprom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rodprom-")
+	check(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := rod.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(t, err)
+	defer s.Close()
+
+	c := Instrument(s, Namespace("test"))
+	prometheus.NewRegistry().MustRegister(c)
+
+	check(t, s.Update(func(tx *bolt.Tx) error {
+		check(t, rod.PutString(tx, "notes", "shopping", "milk"))
+		check(t, rod.PutString(tx, "notes", "work", "report"))
+		return rod.Del(tx, "notes", "shopping")
+	}))
+	check(t, s.View(func(tx *bolt.Tx) error {
+		_, err := rod.GetString(tx, "notes", "work")
+		return err
+	}))
+	if err := s.View(func(tx *bolt.Tx) error {
+		return bolt.ErrTxNotWritable
+	}); err == nil {
+		t.Fatalf("The View should have failed")
+	}
+
+	t.Run("Operations are counted", func(t *testing.T) {
+		for op, want := range map[string]float64{"update": 1, "view": 2, "get": 1, "put": 2, "del": 1} {
+			if got := testutil.ToFloat64(c.ops.WithLabelValues(op)); got != want {
+				t.Fatalf("Expected %v %s operations but got %v", want, op, got)
+			}
+		}
+		if got := testutil.ToFloat64(c.errors.WithLabelValues("view")); got != 1 {
+			t.Fatalf("Expected 1 failed view but got %v", got)
+		}
+	})
+
+	t.Run("Bytes are counted", func(t *testing.T) {
+		if got := testutil.ToFloat64(c.written); got != 10 {
+			t.Fatalf("Expected 10 bytes written but got %v", got)
+		}
+		if got := testutil.ToFloat64(c.read); got != 6 {
+			t.Fatalf("Expected 6 bytes read but got %v", got)
+		}
+	})
+
+	t.Run("Locations are counted", func(t *testing.T) {
+		if got := testutil.ToFloat64(c.locations.WithLabelValues("notes", "put")); got != 2 {
+			t.Fatalf("Expected 2 puts to notes but got %v", got)
+		}
+		if got := testutil.CollectAndCount(c, "test_rod_location_operations_total"); got != 3 {
+			t.Fatalf("Expected 3 location counters but got %d", got)
+		}
+
+		off := New(WithoutLocations())
+		off.Observe(rod.Operation{Name: "get", Location: "notes"})
+		if got := testutil.CollectAndCount(off, "rod_location_operations_total"); got != 0 {
+			t.Fatalf("Expected no location counters but got %d", got)
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package rodredis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// skippedCommands are the commands which write to a key of a type which isn't imported, so that key is skipped.
+var skippedCommands = map[string]bool{
+	"LPUSH": true, "RPUSH": true, "LPUSHX": true, "RPUSHX": true, "LINSERT": true, "LSET": true, "LMOVE": true,
+	"RPOPLPUSH": true, "SADD": true, "SMOVE": true, "SINTERSTORE": true, "SUNIONSTORE": true, "SDIFFSTORE": true,
+	"ZADD": true, "ZINCRBY": true, "ZUNIONSTORE": true, "ZINTERSTORE": true, "ZDIFFSTORE": true, "XADD": true,
+	"XGROUP": true, "PFADD": true, "PFMERGE": true, "GEOADD": true,
+}
+
+// commandArgs is how many arguments each replayed command needs.
+var commandArgs = map[string]int{
+	"SELECT": 1, "SET": 2, "SETNX": 2, "SETEX": 3, "PSETEX": 3, "GETSET": 2, "APPEND": 2, "SETRANGE": 3,
+	"INCR": 1, "DECR": 1, "INCRBY": 2, "DECRBY": 2, "HSET": 3, "HMSET": 3, "HSETNX": 3, "HDEL": 2, "HINCRBY": 3,
+	"DEL": 1, "UNLINK": 1, "RENAME": 2, "RENAMENX": 2, "EXPIRE": 2, "PEXPIRE": 2, "EXPIREAT": 2, "PEXPIREAT": 2,
+	"PERSIST": 1, "MSET": 2, "MSETNX": 2,
+}
+
+// ImportAOF replays the Redis append-only file in r, and puts the string and hash keys it leaves behind into db. An
+// AOF which starts with an RDB preamble, as Redis writes by default, is read as well. Commands which change strings
+// and hashes (such as SET, APPEND, INCRBY, HSET and HDEL) are replayed, along with DEL, RENAME, SELECT, FLUSHDB and
+// the EXPIRE family, while keys written by commands for other types (such as LPUSH or ZADD) are skipped. Any other
+// command is ignored. For Redis 7, which splits the AOF into several files, import the base file and then each
+// incremental file in turn.
+//
+//	f, err := os.Open("/var/lib/redis/appendonly.aof")
+//	res, err := rodredis.ImportAOF(db, f, rodredis.Prefix("user:", "users"))
+func ImportAOF(db *bolt.DB, r io.Reader, opts ...Option) (Result, error) {
+	im := newImporter(opts)
+	br := bufio.NewReader(r)
+
+	if magic, _ := br.Peek(5); string(magic) == "REDIS" {
+		if err := im.readRDB(br); err != nil {
+			return Result{}, err
+		}
+	}
+
+	for {
+		args, err := readCommand(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		if err := im.apply(args); err != nil {
+			return Result{}, err
+		}
+	}
+	return im.write(db)
+}
+
+// readCommand reads one command, as an array of bulk strings. It returns io.EOF if there are no more.
+func readCommand(br *bufio.Reader) ([][]byte, error) {
+	readLine := func() (string, error) {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasSuffix(line, "\r\n") {
+			return "", ErrBadAOF
+		}
+		return line[:len(line)-2], nil
+	}
+
+	line, err := readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[0] != '*' {
+		return nil, ErrBadAOF
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 {
+		return nil, ErrBadAOF
+	}
+
+	args := make([][]byte, n)
+	for i := range args {
+		line, err := readLine()
+		if err != nil {
+			return nil, badAOF(err)
+		}
+		if len(line) < 2 || line[0] != '$' {
+			return nil, ErrBadAOF
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, ErrBadAOF
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(br, arg); err != nil {
+			return nil, badAOF(err)
+		}
+		if !bytes.HasSuffix(arg, []byte("\r\n")) {
+			return nil, ErrBadAOF
+		}
+		args[i] = arg[:size]
+	}
+	return args, nil
+}
+
+// badAOF turns running out of data part way through a command into ErrBadAOF.
+func badAOF(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated command", ErrBadAOF)
+	}
+	return err
+}
+
+// apply replays a single command.
+func (im *importer) apply(args [][]byte) error {
+	cmd := strings.ToUpper(string(args[0]))
+	args = args[1:]
+
+	if n, ok := commandArgs[cmd]; ok && len(args) < n {
+		return fmt.Errorf("%w: %s needs %d arguments", ErrBadAOF, cmd, n)
+	}
+
+	switch cmd {
+	case "SELECT":
+		n, err := strconv.Atoi(string(args[0]))
+		if err != nil {
+			return fmt.Errorf("%w: bad database %q", ErrBadAOF, args[0])
+		}
+		im.db = n
+
+	case "FLUSHDB", "FLUSHALL":
+		if im.selected() || cmd == "FLUSHALL" {
+			im.keys = map[string]*entry{}
+			im.skipped = map[string]bool{}
+		}
+
+	case "SET":
+		key := string(args[0])
+		e := &entry{str: args[1]}
+		old := im.keys[key]
+		exists := old != nil || im.skipped[key]
+		for i := 2; i < len(args); i++ {
+			opt := strings.ToUpper(string(args[i]))
+			switch opt {
+			case "NX":
+				if exists {
+					return nil
+				}
+			case "XX":
+				if !exists {
+					return nil
+				}
+			case "KEEPTTL":
+				if old != nil {
+					e.expires = old.expires
+				}
+			case "EX", "PX", "EXAT", "PXAT":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%w: SET %s needs a time", ErrBadAOF, opt)
+				}
+				i++
+				expires, err := im.expiry(opt, args[i])
+				if err != nil {
+					return err
+				}
+				e.expires = expires
+			}
+		}
+		im.set(key, e)
+
+	case "SETNX":
+		if im.keys[string(args[0])] == nil && !im.skipped[string(args[0])] {
+			im.set(string(args[0]), &entry{str: args[1]})
+		}
+
+	case "SETEX", "PSETEX":
+		unit := "EX"
+		if cmd == "PSETEX" {
+			unit = "PX"
+		}
+		expires, err := im.expiry(unit, args[1])
+		if err != nil {
+			return err
+		}
+		im.set(string(args[0]), &entry{str: args[2], expires: expires})
+
+	case "GETSET":
+		im.set(string(args[0]), &entry{str: args[1]})
+
+	case "MSET", "MSETNX":
+		if len(args)%2 != 0 {
+			return fmt.Errorf("%w: %s needs keys and values", ErrBadAOF, cmd)
+		}
+		if cmd == "MSETNX" {
+			for i := 0; i < len(args); i += 2 {
+				if im.keys[string(args[i])] != nil || im.skipped[string(args[i])] {
+					return nil
+				}
+			}
+		}
+		for i := 0; i < len(args); i += 2 {
+			im.set(string(args[i]), &entry{str: args[i+1]})
+		}
+
+	case "APPEND":
+		if e := im.stringEntry(string(args[0])); e != nil {
+			e.str = append(e.str, args[1]...)
+		}
+
+	case "SETRANGE":
+		offset, err := strconv.Atoi(string(args[1]))
+		if err != nil || offset < 0 {
+			return fmt.Errorf("%w: bad offset %q", ErrBadAOF, args[1])
+		}
+		if e := im.stringEntry(string(args[0])); e != nil {
+			if end := offset + len(args[2]); end > len(e.str) {
+				e.str = append(e.str, make([]byte, end-len(e.str))...)
+			}
+			copy(e.str[offset:], args[2])
+		}
+
+	case "INCR", "DECR", "INCRBY", "DECRBY":
+		by := int64(1)
+		if len(args) > 1 {
+			n, err := strconv.ParseInt(string(args[1]), 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: bad increment %q", ErrBadAOF, args[1])
+			}
+			by = n
+		}
+		if cmd == "DECR" || cmd == "DECRBY" {
+			by = -by
+		}
+		if e := im.stringEntry(string(args[0])); e != nil {
+			n, _ := strconv.ParseInt(string(e.str), 10, 64)
+			e.str = []byte(strconv.FormatInt(n+by, 10))
+		}
+
+	case "HSET", "HMSET", "HSETNX":
+		if len(args)%2 != 1 {
+			return fmt.Errorf("%w: %s needs fields and values", ErrBadAOF, cmd)
+		}
+		if e := im.hashEntry(string(args[0])); e != nil {
+			for i := 1; i < len(args); i += 2 {
+				field := string(args[i])
+				if _, ok := e.hash[field]; ok && cmd == "HSETNX" {
+					continue
+				}
+				e.hash[field] = string(args[i+1])
+			}
+		}
+
+	case "HINCRBY":
+		by, err := strconv.ParseInt(string(args[2]), 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: bad increment %q", ErrBadAOF, args[2])
+		}
+		if e := im.hashEntry(string(args[0])); e != nil {
+			n, _ := strconv.ParseInt(e.hash[string(args[1])], 10, 64)
+			e.hash[string(args[1])] = strconv.FormatInt(n+by, 10)
+		}
+
+	case "HDEL":
+		if e := im.keys[string(args[0])]; e != nil && e.isHash && im.selected() {
+			for _, field := range args[1:] {
+				delete(e.hash, string(field))
+			}
+			// Redis removes a hash once it has no fields
+			if len(e.hash) == 0 {
+				im.del(string(args[0]))
+			}
+		}
+
+	case "DEL", "UNLINK":
+		for _, key := range args {
+			im.del(string(key))
+		}
+
+	case "RENAME", "RENAMENX":
+		from, to := string(args[0]), string(args[1])
+		if !im.selected() || from == to {
+			return nil
+		}
+		if cmd == "RENAMENX" && (im.keys[to] != nil || im.skipped[to]) {
+			return nil
+		}
+		e, skipped := im.keys[from], im.skipped[from]
+		im.del(from)
+		switch {
+		case e != nil:
+			im.set(to, e)
+		case skipped:
+			im.skip(to)
+		}
+
+	case "EXPIRE", "PEXPIRE", "EXPIREAT", "PEXPIREAT":
+		unit := map[string]string{"EXPIRE": "EX", "PEXPIRE": "PX", "EXPIREAT": "EXAT", "PEXPIREAT": "PXAT"}[cmd]
+		expires, err := im.expiry(unit, args[1])
+		if err != nil {
+			return err
+		}
+		if e := im.keys[string(args[0])]; e != nil && im.selected() {
+			e.expires = expires
+		}
+
+	case "PERSIST":
+		if e := im.keys[string(args[0])]; e != nil && im.selected() {
+			e.expires = time.Time{}
+		}
+
+	default:
+		if skippedCommands[cmd] && len(args) > 0 {
+			im.skip(string(args[0]))
+		}
+	}
+	return nil
+}
+
+// expiry works out when a key expires, from a time given in the way the SET option unit does. Relative times are from
+// the start of the import.
+func (im *importer) expiry(unit string, arg []byte) (time.Time, error) {
+	n, err := strconv.ParseInt(string(arg), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: bad time %q", ErrBadAOF, arg)
+	}
+	switch unit {
+	case "EX":
+		return im.now.Add(time.Duration(n) * time.Second), nil
+	case "PX":
+		return im.now.Add(time.Duration(n) * time.Millisecond), nil
+	case "EXAT":
+		return time.Unix(n, 0), nil
+	}
+	return time.Unix(0, n*int64(time.Millisecond)), nil
+}
+
+// stringEntry returns the string at key so that it can be changed, making an empty one if there isn't one yet. It
+// returns nil if key is a hash or skipped, or isn't in the database being imported.
+func (im *importer) stringEntry(key string) *entry {
+	if !im.selected() || im.skipped[key] {
+		return nil
+	}
+	e := im.keys[key]
+	if e == nil {
+		e = &entry{}
+		im.keys[key] = e
+	}
+	if e.isHash {
+		return nil
+	}
+	return e
+}
+
+// hashEntry is stringEntry for a hash.
+func (im *importer) hashEntry(key string) *entry {
+	if !im.selected() || im.skipped[key] {
+		return nil
+	}
+	e := im.keys[key]
+	if e == nil {
+		e = &entry{hash: map[string]string{}, isHash: true}
+		im.keys[key] = e
+	}
+	if !e.isHash {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,127 @@
+package rodredis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+// aof builds an append-only file from commands, each given as a single space-separated string.
+func aof(commands ...string) []byte {
+	var buf bytes.Buffer
+	for _, command := range commands {
+		args := strings.Split(command, " ")
+		fmt.Fprintf(&buf, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestImportAOF(t *testing.T) {
+	t.Run("ImportAOF", func(t *testing.T) {
+		db, done := openTestDB(t)
+		defer done()
+
+		file := aof(
+			"SELECT 0",
+			"SET a 1",
+			"INCRBY a 5",
+			"SET a 99 NX",
+			"SET b x EX 100",
+			"APPEND b yz",
+			"MSET c 1 d 2",
+			"DEL d",
+			"RENAME c e",
+			"SETRANGE e 1 23",
+			"MULTI",
+			"HSET user:1 name andy logins 1",
+			"HINCRBY user:1 logins 2",
+			"EXEC",
+			"HSET user:2 name tmp",
+			"HDEL user:2 name",
+			"RPUSH queue a b",
+			"SET gone v PXAT 1000",
+			"SET later v",
+			"PEXPIREAT later 1000",
+			"SELECT 1",
+			"SET other v",
+			"FLUSHDB",
+			"SELECT 0",
+		)
+		res, err := ImportAOF(db, bytes.NewReader(file), Prefix("user:", "users"))
+		check(t, err)
+		if res != (Result{Strings: 3, Hashes: 1, Skipped: 1, Expired: 2}) {
+			t.Fatalf("Unexpected result %#v", res)
+		}
+
+		check(t, db.View(func(tx *bolt.Tx) error {
+			for key, want := range map[string]string{"a": "6", "b": "xyz", "e": "123"} {
+				got, err := rod.GetString(tx, DefaultStringLocation, key)
+				check(t, err)
+				if got != want {
+					t.Fatalf("Expected %q for %s, not %q", want, key, got)
+				}
+			}
+
+			user, err := rod.GetString(tx, "users", "1")
+			check(t, err)
+			if user != `{"logins":"3","name":"andy"}` {
+				t.Fatalf("Unexpected user %s", user)
+			}
+
+			found, err := rod.Exists(tx, "users", "2")
+			check(t, err)
+			if found {
+				t.Fatal("A hash with no fields left shouldn't be imported")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("RDB preamble", func(t *testing.T) {
+		db, done := openTestDB(t)
+		defer done()
+
+		preamble := newRDB()
+		preamble.set("greeting", "hello")
+		preamble.set("keep", "me")
+		file := append(preamble.end(), aof("SET greeting bye")...)
+
+		res, err := ImportAOF(db, bytes.NewReader(file))
+		check(t, err)
+		if res.Strings != 2 {
+			t.Fatalf("Unexpected result %#v", res)
+		}
+		check(t, db.View(func(tx *bolt.Tx) error {
+			greeting, err := rod.GetString(tx, DefaultStringLocation, "greeting")
+			check(t, err)
+			if greeting != "bye" {
+				t.Fatalf("The command after the preamble should have replaced the greeting, not %q", greeting)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		db, done := openTestDB(t)
+		defer done()
+
+		file := aof("SET a 1")
+		if _, err := ImportAOF(db, bytes.NewReader(file[:len(file)-3])); !errors.Is(err, ErrBadAOF) {
+			t.Fatalf("Expected ErrBadAOF for a truncated file, not %v", err)
+		}
+		if _, err := ImportAOF(db, strings.NewReader("SET a 1\r\n")); !errors.Is(err, ErrBadAOF) {
+			t.Fatalf("Expected ErrBadAOF for an inline command, not %v", err)
+		}
+		if _, err := ImportAOF(db, bytes.NewReader(aof("SET a"))); !errors.Is(err, ErrBadAOF) {
+			t.Fatalf("Expected ErrBadAOF for a command missing an argument, not %v", err)
+		}
+	})
+}
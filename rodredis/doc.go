@@ -0,0 +1,19 @@
+// Package rodredis imports a Redis dataset into rod, to help move a small Redis deployment over to an embedded rod
+// store. It reads either an RDB snapshot (dump.rdb) or an append-only file (including one starting with an RDB
+// preamble), and puts every string and hash key from one Redis database into rod:
+//
+//	f, err := os.Open("dump.rdb")
+//	res, err := rodredis.ImportRDB(db, f, rodredis.Prefix("user:", "users"), rodredis.Prefix("session:", "sessions"))
+//
+// A string is put as it is, and a hash as a JSON object of its fields, so a Redis hash "user:42" becomes a record at
+// "users"/"42" which GetJson can read into a struct of strings. Keys are routed to locations by their prefix (see
+// Prefix), and any other key goes to StringLocation or HashLocation. Lists, sets, sorted sets and the like are skipped
+// and counted in the Result, as are keys which have already expired. Streams and module types can't be read, so an RDB
+// file holding any of them is an error.
+//
+// The whole dataset is read into memory before anything is written, since an append-only file can change a key many
+// times, so this is meant for datasets which fit comfortably in memory. The keys are then written
+// DefaultBatchSize at a time, each batch in its own transaction.
+//
+// (Ends)
+package rodredis
@@ -0,0 +1,589 @@
+package rodredis
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// errCorrupt is returned for data which doesn't decode, such as an encoded list which runs off its end.
+var errCorrupt = errors.New("rodredis: corrupt RDB data")
+
+// RDB opcodes, which come where a value type would.
+const (
+	opSlotInfo     = 0xf4
+	opFunction2    = 0xf5
+	opModuleAux    = 0xf7
+	opIdle         = 0xf8
+	opFreq         = 0xf9
+	opAux          = 0xfa
+	opResizeDB     = 0xfb
+	opExpireTimeMs = 0xfc
+	opExpireTime   = 0xfd
+	opSelectDB     = 0xfe
+	opEOF          = 0xff
+)
+
+// RDB value types.
+const (
+	typeString         = 0
+	typeList           = 1
+	typeSet            = 2
+	typeZset           = 3
+	typeHash           = 4
+	typeZset2          = 5
+	typeHashZipmap     = 9
+	typeListZiplist    = 10
+	typeSetIntset      = 11
+	typeZsetZiplist    = 12
+	typeHashZiplist    = 13
+	typeListQuicklist  = 14
+	typeHashListpack   = 16
+	typeZsetListpack   = 17
+	typeListQuicklist2 = 18
+	typeSetListpack    = 20
+)
+
+// ImportRDB reads an RDB snapshot from r, such as the dump.rdb which Redis saves, and puts its string and hash keys
+// into db. The checksum at the end of the file isn't checked.
+//
+//	f, err := os.Open("/var/lib/redis/dump.rdb")
+//	res, err := rodredis.ImportRDB(db, f, rodredis.Prefix("user:", "users"))
+func ImportRDB(db *bolt.DB, r io.Reader, opts ...Option) (Result, error) {
+	im := newImporter(opts)
+	if err := im.readRDB(bufio.NewReader(r)); err != nil {
+		return Result{}, err
+	}
+	return im.write(db)
+}
+
+// rdbReader reads the parts of an RDB file.
+type rdbReader struct {
+	r *bufio.Reader
+}
+
+func (rr rdbReader) readByte() (byte, error) {
+	b, err := rr.r.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return b, err
+}
+
+func (rr rdbReader) readBytes(n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, errCorrupt
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(rr.r, p); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// readLength reads a length, which if encoded is instead the kind of special encoding which follows.
+func (rr rdbReader) readLength() (n uint64, encoded bool, err error) {
+	b, err := rr.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch b >> 6 {
+	case 0:
+		return uint64(b & 0x3f), false, nil
+	case 1:
+		next, err := rr.readByte()
+		return uint64(b&0x3f)<<8 | uint64(next), false, err
+	case 2:
+		switch b {
+		case 0x80:
+			p, err := rr.readBytes(4)
+			if err != nil {
+				return 0, false, err
+			}
+			return uint64(binary.BigEndian.Uint32(p)), false, nil
+		case 0x81:
+			p, err := rr.readBytes(8)
+			if err != nil {
+				return 0, false, err
+			}
+			return binary.BigEndian.Uint64(p), false, nil
+		}
+		return 0, false, errCorrupt
+	}
+	return uint64(b & 0x3f), true, nil
+}
+
+// readPlainLength reads a length which can't be one of the special encodings.
+func (rr rdbReader) readPlainLength() (uint64, error) {
+	n, encoded, err := rr.readLength()
+	if err == nil && encoded {
+		err = errCorrupt
+	}
+	return n, err
+}
+
+// readString reads a string, which may be stored as an integer or compressed.
+func (rr rdbReader) readString() ([]byte, error) {
+	n, encoded, err := rr.readLength()
+	if err != nil {
+		return nil, err
+	}
+	if !encoded {
+		return rr.readBytes(n)
+	}
+
+	switch n {
+	case 0:
+		p, err := rr.readBytes(1)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int8(p[0])))), nil
+	case 1:
+		p, err := rr.readBytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int16(binary.LittleEndian.Uint16(p))))), nil
+	case 2:
+		p, err := rr.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.Itoa(int(int32(binary.LittleEndian.Uint32(p))))), nil
+	case 3:
+		clen, err := rr.readPlainLength()
+		if err != nil {
+			return nil, err
+		}
+		ulen, err := rr.readPlainLength()
+		if err != nil {
+			return nil, err
+		}
+		compressed, err := rr.readBytes(clen)
+		if err != nil {
+			return nil, err
+		}
+		return lzfDecompress(compressed, ulen)
+	}
+	return nil, errCorrupt
+}
+
+// skipStrings reads and throws away n strings.
+func (rr rdbReader) skipStrings(n uint64) error {
+	for i := uint64(0); i < n; i++ {
+		if _, err := rr.readString(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRDB reads a whole RDB file, up to and including its checksum.
+func (im *importer) readRDB(r *bufio.Reader) error {
+	rr := rdbReader{r}
+
+	header, err := rr.readBytes(9)
+	if err != nil || string(header[:5]) != "REDIS" {
+		return ErrNotRDB
+	}
+	version, err := strconv.Atoi(string(header[5:]))
+	if err != nil {
+		return ErrNotRDB
+	}
+
+	var expires time.Time
+	for {
+		op, err := rr.readByte()
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case opEOF:
+			if version >= 5 {
+				_, err = rr.readBytes(8)
+			}
+			return err
+		case opAux:
+			err = rr.skipStrings(2)
+		case opResizeDB:
+			if _, err = rr.readPlainLength(); err == nil {
+				_, err = rr.readPlainLength()
+			}
+		case opSlotInfo:
+			for i := 0; i < 3 && err == nil; i++ {
+				_, err = rr.readPlainLength()
+			}
+		case opSelectDB:
+			var n uint64
+			n, err = rr.readPlainLength()
+			im.db = int(n)
+		case opExpireTime:
+			var p []byte
+			if p, err = rr.readBytes(4); err == nil {
+				expires = time.Unix(int64(binary.LittleEndian.Uint32(p)), 0)
+			}
+		case opExpireTimeMs:
+			var p []byte
+			if p, err = rr.readBytes(8); err == nil {
+				expires = time.Unix(0, int64(binary.LittleEndian.Uint64(p))*int64(time.Millisecond))
+			}
+		case opIdle:
+			_, err = rr.readPlainLength()
+		case opFreq:
+			_, err = rr.readByte()
+		case opFunction2:
+			_, err = rr.readString()
+		case opModuleAux:
+			return fmt.Errorf("%w: module data", ErrUnsupportedType)
+		default:
+			err = im.readValue(rr, op, expires)
+			expires = time.Time{}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readValue reads a key and its value of type kind.
+func (im *importer) readValue(rr rdbReader, kind byte, expires time.Time) error {
+	rawKey, err := rr.readString()
+	if err != nil {
+		return err
+	}
+	key := string(rawKey)
+
+	switch kind {
+	case typeString:
+		value, err := rr.readString()
+		if err != nil {
+			return err
+		}
+		im.set(key, &entry{str: value, expires: expires})
+		return nil
+
+	case typeHash:
+		n, err := rr.readPlainLength()
+		if err != nil {
+			return err
+		}
+		hash := map[string]string{}
+		for i := uint64(0); i < n; i++ {
+			field, err := rr.readString()
+			if err != nil {
+				return err
+			}
+			value, err := rr.readString()
+			if err != nil {
+				return err
+			}
+			hash[string(field)] = string(value)
+		}
+		im.set(key, &entry{hash: hash, isHash: true, expires: expires})
+		return nil
+
+	case typeHashZipmap, typeHashZiplist, typeHashListpack:
+		data, err := rr.readString()
+		if err != nil {
+			return err
+		}
+		var items []string
+		switch kind {
+		case typeHashZipmap:
+			items, err = zipmapItems(data)
+		case typeHashZiplist:
+			items, err = ziplistItems(data)
+		default:
+			items, err = listpackItems(data)
+		}
+		if err != nil {
+			return err
+		}
+		if len(items)%2 != 0 {
+			return errCorrupt
+		}
+		hash := map[string]string{}
+		for i := 0; i < len(items); i += 2 {
+			hash[items[i]] = items[i+1]
+		}
+		im.set(key, &entry{hash: hash, isHash: true, expires: expires})
+		return nil
+
+	case typeList, typeSet, typeListQuicklist:
+		n, err := rr.readPlainLength()
+		if err == nil {
+			err = rr.skipStrings(n)
+		}
+		if err != nil {
+			return err
+		}
+
+	case typeZset, typeZset2:
+		n, err := rr.readPlainLength()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if _, err := rr.readString(); err != nil {
+				return err
+			}
+			if kind == typeZset2 {
+				_, err = rr.readBytes(8)
+			} else {
+				// a score is a length byte and then that many characters, or a length of 253 to 255 for NaN and
+				// the infinities
+				var l byte
+				if l, err = rr.readByte(); err == nil && l < 253 {
+					_, err = rr.readBytes(uint64(l))
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+	case typeListZiplist, typeSetIntset, typeZsetZiplist, typeZsetListpack, typeSetListpack:
+		if _, err := rr.readString(); err != nil {
+			return err
+		}
+
+	case typeListQuicklist2:
+		n, err := rr.readPlainLength()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if _, err := rr.readPlainLength(); err != nil {
+				return err
+			}
+			if _, err := rr.readString(); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: type %d", ErrUnsupportedType, kind)
+	}
+
+	im.skip(key)
+	return nil
+}
+
+// lzfDecompress decompresses LZF data, which should come to ulen bytes.
+func lzfDecompress(in []byte, ulen uint64) ([]byte, error) {
+	if ulen > math.MaxInt32 {
+		return nil, errCorrupt
+	}
+	out := make([]byte, 0, ulen)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+
+		if ctrl < 32 {
+			// a run of ctrl+1 literal bytes
+			n := ctrl + 1
+			if i+n > len(in) {
+				return nil, errCorrupt
+			}
+			out = append(out, in[i:i+n]...)
+			i += n
+			continue
+		}
+
+		// a back reference, of length ctrl>>5 (or more, if that's 7) plus 2
+		n := ctrl >> 5
+		if n == 7 {
+			if i >= len(in) {
+				return nil, errCorrupt
+			}
+			n += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errCorrupt
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, errCorrupt
+		}
+		for j := 0; j < n+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if uint64(len(out)) != ulen {
+		return nil, errCorrupt
+	}
+	return out, nil
+}
+
+// blob reads through an encoded list, remembering the first time it runs off the end.
+type blob struct {
+	b   []byte
+	p   int
+	err error
+}
+
+func (b *blob) take(n int) []byte {
+	if b.err != nil || n < 0 || b.p+n > len(b.b) {
+		b.err = errCorrupt
+		// give back zeros for the caller to decode, since it only checks err once it's done
+		if n < 0 || n > 8 {
+			return nil
+		}
+		return make([]byte, n)
+	}
+	p := b.b[b.p : b.p+n]
+	b.p += n
+	return p
+}
+
+func (b *blob) peek() byte {
+	if b.err != nil || b.p >= len(b.b) {
+		b.err = errCorrupt
+		return 0xff
+	}
+	return b.b[b.p]
+}
+
+// leInt reads an n byte little-endian signed integer.
+func leInt(p []byte) int64 {
+	var u uint64
+	for i := len(p) - 1; i >= 0; i-- {
+		u = u<<8 | uint64(p[i])
+	}
+	shift := uint(64 - 8*len(p))
+	return int64(u<<shift) >> shift
+}
+
+// ziplistItems returns every entry in a ziplist, with integers as their decimal strings.
+func ziplistItems(data []byte) ([]string, error) {
+	b := &blob{b: data}
+	b.take(10)
+
+	var items []string
+	for b.err == nil && b.peek() != 0xff {
+		// skip the length of the entry before
+		if b.take(1)[0] == 0xfe {
+			b.take(4)
+		}
+
+		enc := b.take(1)[0]
+		switch {
+		case enc>>6 == 0:
+			items = append(items, string(b.take(int(enc&0x3f))))
+		case enc>>6 == 1:
+			n := int(enc&0x3f)<<8 | int(b.take(1)[0])
+			items = append(items, string(b.take(n)))
+		case enc>>6 == 2:
+			n := int(binary.BigEndian.Uint32(b.take(4)))
+			items = append(items, string(b.take(n)))
+		case enc == 0xc0:
+			items = append(items, strconv.FormatInt(leInt(b.take(2)), 10))
+		case enc == 0xd0:
+			items = append(items, strconv.FormatInt(leInt(b.take(4)), 10))
+		case enc == 0xe0:
+			items = append(items, strconv.FormatInt(leInt(b.take(8)), 10))
+		case enc == 0xf0:
+			items = append(items, strconv.FormatInt(leInt(b.take(3)), 10))
+		case enc == 0xfe:
+			items = append(items, strconv.FormatInt(leInt(b.take(1)), 10))
+		case enc >= 0xf1 && enc <= 0xfd:
+			items = append(items, strconv.Itoa(int(enc&0x0f)-1))
+		default:
+			return nil, errCorrupt
+		}
+	}
+	return items, b.err
+}
+
+// listpackItems returns every entry in a listpack, with integers as their decimal strings.
+func listpackItems(data []byte) ([]string, error) {
+	b := &blob{b: data}
+	b.take(6)
+
+	var items []string
+	for b.err == nil && b.peek() != 0xff {
+		start := b.p
+		enc := b.take(1)[0]
+		switch {
+		case enc&0x80 == 0:
+			items = append(items, strconv.Itoa(int(enc)))
+		case enc&0xc0 == 0x80:
+			items = append(items, string(b.take(int(enc&0x3f))))
+		case enc&0xe0 == 0xc0:
+			v := int(enc&0x1f)<<8 | int(b.take(1)[0])
+			if v >= 1<<12 {
+				v -= 1 << 13
+			}
+			items = append(items, strconv.Itoa(v))
+		case enc&0xf0 == 0xe0:
+			n := int(enc&0x0f)<<8 | int(b.take(1)[0])
+			items = append(items, string(b.take(n)))
+		case enc == 0xf0:
+			n := int(binary.LittleEndian.Uint32(b.take(4)))
+			items = append(items, string(b.take(n)))
+		case enc == 0xf1:
+			items = append(items, strconv.FormatInt(leInt(b.take(2)), 10))
+		case enc == 0xf2:
+			items = append(items, strconv.FormatInt(leInt(b.take(3)), 10))
+		case enc == 0xf3:
+			items = append(items, strconv.FormatInt(leInt(b.take(4)), 10))
+		case enc == 0xf4:
+			items = append(items, strconv.FormatInt(leInt(b.take(8)), 10))
+		default:
+			return nil, errCorrupt
+		}
+
+		// skip the entry's length, which comes after it so a listpack can be read backwards
+		switch n := b.p - start; {
+		case n <= 127:
+			b.take(1)
+		case n < 16383:
+			b.take(2)
+		case n < 2097151:
+			b.take(3)
+		case n < 268435455:
+			b.take(4)
+		default:
+			b.take(5)
+		}
+	}
+	return items, b.err
+}
+
+// zipmapItems returns every key and value in a zipmap, the encoding for small hashes before Redis 2.6.
+func zipmapItems(data []byte) ([]string, error) {
+	b := &blob{b: data}
+	b.take(1)
+
+	length := func() int {
+		n := b.take(1)[0]
+		if n < 254 {
+			return int(n)
+		}
+		return int(binary.LittleEndian.Uint32(b.take(4)))
+	}
+
+	var items []string
+	for b.err == nil && b.peek() != 0xff {
+		items = append(items, string(b.take(length())))
+		n := length()
+		free := int(b.take(1)[0])
+		items = append(items, string(b.take(n)))
+		b.take(free)
+	}
+	return items, b.err
+}
@@ -0,0 +1,193 @@
+package rodredis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+func TestImportRDB(t *testing.T) {
+	data := newRDB()
+	data.WriteByte(opAux)
+	data.str("redis-ver")
+	data.str("7.2.0")
+	data.WriteByte(opSelectDB)
+	data.length(0)
+	data.WriteByte(opResizeDB)
+	data.length(12)
+	data.length(2)
+
+	data.set("greeting", "hello")
+
+	// an integer-encoded string
+	data.WriteByte(typeString)
+	data.str("count")
+	data.Write([]byte{0xc0, 42})
+
+	// an LZF compressed "abcabcabcabc", as the literal "abc" and then a back reference 3 bytes back for 9 bytes
+	data.WriteByte(typeString)
+	data.str("lzf")
+	data.WriteByte(0xc3)
+	data.length(7)
+	data.length(12)
+	data.Write([]byte{0x02, 'a', 'b', 'c', 0xe0, 0x00, 0x02})
+
+	data.WriteByte(typeHash)
+	data.str("user:1")
+	data.length(2)
+	data.str("name")
+	data.str("andy")
+	data.str("logins")
+	data.str("3")
+
+	// a hash as a listpack, with a 7 bit uint and a 13 bit int
+	lp := []byte{0, 0, 0, 0, 6, 0}
+	lp = append(lp, 0x84, 'n', 'a', 'm', 'e', 5)
+	lp = append(lp, 0x83, 'b', 'o', 'b', 4)
+	lp = append(lp, 0x83, 'a', 'g', 'e', 4)
+	lp = append(lp, 30, 1)
+	lp = append(lp, 0x85, 's', 'c', 'o', 'r', 'e', 6)
+	lp = append(lp, 0xdf, 0xfb, 2)
+	lp = append(lp, 0xff)
+	data.WriteByte(typeHashListpack)
+	data.str("user:2")
+	data.str(string(lp))
+
+	// a hash as a ziplist, with a 4 bit immediate int
+	zl := make([]byte, 10)
+	zl = append(zl, 0, 0x04, 'n', 'a', 'm', 'e')
+	zl = append(zl, 6, 0x05, 'c', 'a', 'r', 'o', 'l')
+	zl = append(zl, 7, 0x01, 'n')
+	zl = append(zl, 3, 0xfd)
+	zl = append(zl, 0xff)
+	data.WriteByte(typeHashZiplist)
+	data.str("user:3")
+	data.str(string(zl))
+
+	// a hash as a zipmap
+	data.WriteByte(typeHashZipmap)
+	data.str("user:4")
+	data.str(string([]byte{1, 4, 'n', 'a', 'm', 'e', 4, 0, 'd', 'a', 'v', 'e', 0xff}))
+
+	// one key which has expired, and one which hasn't yet
+	data.WriteByte(opExpireTimeMs)
+	binary.Write(data, binary.LittleEndian, uint64(1000))
+	data.set("old", "gone")
+	data.WriteByte(opExpireTimeMs)
+	binary.Write(data, binary.LittleEndian, uint64(time.Now().Add(time.Hour).UnixNano()/int64(time.Millisecond)))
+	data.set("fresh", "yes")
+
+	// and some which are skipped
+	data.WriteByte(typeList)
+	data.str("queue")
+	data.length(2)
+	data.str("a")
+	data.str("b")
+	data.WriteByte(typeZset)
+	data.str("board")
+	data.length(1)
+	data.str("a")
+	data.WriteByte(3)
+	data.WriteString("1.5")
+	data.WriteByte(typeSetListpack)
+	data.str("tags")
+	data.str("not read")
+
+	// only database 0 is imported by default
+	data.WriteByte(opSelectDB)
+	data.length(1)
+	data.set("other", "db1")
+	file := data.end()
+
+	t.Run("ImportRDB", func(t *testing.T) {
+		db, done := openTestDB(t)
+		defer done()
+
+		res, err := ImportRDB(db, bytes.NewReader(file), Prefix("user:", "users"))
+		check(t, err)
+		if res != (Result{Strings: 4, Hashes: 4, Skipped: 3, Expired: 1}) {
+			t.Fatalf("Unexpected result %#v", res)
+		}
+
+		check(t, db.View(func(tx *bolt.Tx) error {
+			for key, want := range map[string]string{
+				"greeting": "hello",
+				"count":    "42",
+				"lzf":      "abcabcabcabc",
+				"fresh":    "yes",
+			} {
+				got, err := rod.GetString(tx, DefaultStringLocation, key)
+				check(t, err)
+				if got != want {
+					t.Fatalf("Expected %q for %s, not %q", want, key, got)
+				}
+			}
+
+			for key, want := range map[string]string{
+				"1": `{"logins":"3","name":"andy"}`,
+				"2": `{"age":"30","name":"bob","score":"-5"}`,
+				"3": `{"n":"12","name":"carol"}`,
+				"4": `{"name":"dave"}`,
+			} {
+				got, err := rod.GetString(tx, "users", key)
+				check(t, err)
+				if got != want {
+					t.Fatalf("Expected %s for user %s, not %s", want, key, got)
+				}
+			}
+
+			for _, key := range []string{"old", "queue", "other"} {
+				found, err := rod.Exists(tx, DefaultStringLocation, key)
+				check(t, err)
+				if found {
+					t.Fatalf("%s shouldn't have been imported", key)
+				}
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Database", func(t *testing.T) {
+		db, done := openTestDB(t)
+		defer done()
+
+		res, err := ImportRDB(db, bytes.NewReader(file), Database(1))
+		check(t, err)
+		if res != (Result{Strings: 1}) {
+			t.Fatalf("Unexpected result %#v", res)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		db, done := openTestDB(t)
+		defer done()
+
+		if _, err := ImportRDB(db, bytes.NewReader([]byte("NOT A DUMP"))); !errors.Is(err, ErrNotRDB) {
+			t.Fatalf("Expected ErrNotRDB, not %v", err)
+		}
+
+		stream := newRDB()
+		stream.WriteByte(21)
+		stream.str("events")
+		if _, err := ImportRDB(db, bytes.NewReader(stream.end())); !errors.Is(err, ErrUnsupportedType) {
+			t.Fatalf("Expected ErrUnsupportedType, not %v", err)
+		}
+
+		if _, err := ImportRDB(db, bytes.NewReader(file[:len(file)-20])); err == nil {
+			t.Fatal("A truncated file should be an error")
+		}
+
+		bad := newRDB()
+		bad.WriteByte(typeHashListpack)
+		bad.str("user:9")
+		bad.str(string([]byte{0, 0, 0, 0, 1, 0, 0x85, 'a'}))
+		if _, err := ImportRDB(db, bytes.NewReader(bad.end())); err == nil {
+			t.Fatal("A listpack which runs off its end should be an error")
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package rodredis
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+const (
+	// DefaultStringLocation is where a string key goes if no Prefix matches it, unless StringLocation says otherwise.
+	DefaultStringLocation = "redis.strings"
+
+	// DefaultHashLocation is where a hash key goes if no Prefix matches it, unless HashLocation says otherwise.
+	DefaultHashLocation = "redis.hashes"
+
+	// DefaultBatchSize is how many keys are written in each transaction.
+	DefaultBatchSize = 1000
+)
+
+var (
+	// ErrNotRDB is returned by ImportRDB if the data doesn't start with an RDB header.
+	ErrNotRDB = errors.New("rodredis: not an RDB file")
+
+	// ErrUnsupportedType is returned if an RDB file holds a value of a type which can't be read, such as a stream.
+	ErrUnsupportedType = errors.New("rodredis: unsupported value type")
+
+	// ErrBadAOF is returned by ImportAOF if the data isn't a sequence of Redis commands.
+	ErrBadAOF = errors.New("rodredis: malformed append-only file")
+)
+
+// Result says what an import did.
+type Result struct {
+	// Strings and Hashes are how many of each were put into rod.
+	Strings int
+	Hashes  int
+
+	// Skipped is how many keys were of a type which isn't imported, and Expired how many had already expired.
+	Skipped int
+	Expired int
+}
+
+// Option configures ImportRDB and ImportAOF.
+type Option func(*importer)
+
+// Database imports keys from Redis database n, rather than database 0.
+func Database(n int) Option {
+	return func(im *importer) {
+		im.database = n
+	}
+}
+
+// StringLocation puts string keys which no Prefix matches in location, rather than DefaultStringLocation.
+func StringLocation(location string) Option {
+	return func(im *importer) {
+		im.stringLocation = location
+	}
+}
+
+// HashLocation puts hash keys which no Prefix matches in location, rather than DefaultHashLocation.
+func HashLocation(location string) Option {
+	return func(im *importer) {
+		im.hashLocation = location
+	}
+}
+
+// Prefix puts every key starting with prefix into location, with the prefix taken off, so that with Prefix("user:",
+// "users") the key "user:42" is put at "users"/"42". If more than one Prefix matches a key then the longest wins.
+func Prefix(prefix, location string) Option {
+	return func(im *importer) {
+		im.prefixes = append(im.prefixes, route{prefix, location})
+	}
+}
+
+// BatchSize writes n keys in each transaction, rather than DefaultBatchSize.
+func BatchSize(n int) Option {
+	return func(im *importer) {
+		im.batchSize = n
+	}
+}
+
+type route struct {
+	prefix   string
+	location string
+}
+
+// entry is a single Redis key, either a string or a hash.
+type entry struct {
+	str     []byte
+	hash    map[string]string
+	isHash  bool
+	expires time.Time
+}
+
+// importer holds the dataset being read, for the Redis database being imported.
+type importer struct {
+	database       int
+	stringLocation string
+	hashLocation   string
+	prefixes       []route
+	batchSize      int
+
+	now     time.Time
+	db      int
+	keys    map[string]*entry
+	skipped map[string]bool
+}
+
+func newImporter(opts []Option) *importer {
+	im := &importer{
+		stringLocation: DefaultStringLocation,
+		hashLocation:   DefaultHashLocation,
+		batchSize:      DefaultBatchSize,
+		now:            time.Now(),
+		keys:           map[string]*entry{},
+		skipped:        map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+	// the longest prefix wins, so try those first
+	sort.SliceStable(im.prefixes, func(i, j int) bool {
+		return len(im.prefixes[i].prefix) > len(im.prefixes[j].prefix)
+	})
+	return im
+}
+
+// selected says whether the database being read is the one being imported.
+func (im *importer) selected() bool {
+	return im.db == im.database
+}
+
+// set replaces key with e, in the database being imported.
+func (im *importer) set(key string, e *entry) {
+	if !im.selected() {
+		return
+	}
+	delete(im.skipped, key)
+	im.keys[key] = e
+}
+
+// skip records that key is of a type which isn't imported.
+func (im *importer) skip(key string) {
+	if !im.selected() {
+		return
+	}
+	delete(im.keys, key)
+	im.skipped[key] = true
+}
+
+// del removes key, whatever it is.
+func (im *importer) del(key string) {
+	if !im.selected() {
+		return
+	}
+	delete(im.keys, key)
+	delete(im.skipped, key)
+}
+
+// locate returns the location and rod key for a Redis key.
+func (im *importer) locate(key string, isHash bool) (string, string) {
+	for _, r := range im.prefixes {
+		if strings.HasPrefix(key, r.prefix) {
+			return r.location, key[len(r.prefix):]
+		}
+	}
+	if isHash {
+		return im.hashLocation, key
+	}
+	return im.stringLocation, key
+}
+
+// write puts everything read into db.
+func (im *importer) write(db *bolt.DB) (Result, error) {
+	res := Result{Skipped: len(im.skipped)}
+
+	keys := make([]string, 0, len(im.keys))
+	for key, e := range im.keys {
+		if !e.expires.IsZero() && !e.expires.After(im.now) {
+			res.Expired++
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for len(keys) > 0 {
+		n := im.batchSize
+		if n <= 0 || n > len(keys) {
+			n = len(keys)
+		}
+		batch := keys[:n]
+		keys = keys[n:]
+
+		strs, hashes := 0, 0
+		err := db.Update(func(tx *bolt.Tx) error {
+			strs, hashes = 0, 0
+			for _, key := range batch {
+				e := im.keys[key]
+				location, k := im.locate(key, e.isHash)
+				if e.isHash {
+					if err := rod.PutJson(tx, location, k, e.hash); err != nil {
+						return err
+					}
+					hashes++
+					continue
+				}
+				if err := rod.Put(tx, location, k, e.str); err != nil {
+					return err
+				}
+				strs++
+			}
+			return nil
+		})
+		if err != nil {
+			return res, err
+		}
+		res.Strings += strs
+		res.Hashes += hashes
+	}
+	return res, nil
+}
@@ -0,0 +1,123 @@
+package rodredis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// openTestDB opens a fresh Bolt database in a temporary directory. Call the returned function to close and remove it.
+func openTestDB(t *testing.T) (*bolt.DB, func()) {
+	dir, err := ioutil.TempDir("", "rodredis-")
+	check(t, err)
+	db, err := bolt.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// rdb builds an RDB file by hand, for the tests to read.
+type rdb struct {
+	bytes.Buffer
+}
+
+func newRDB() *rdb {
+	r := &rdb{}
+	r.WriteString("REDIS0011")
+	return r
+}
+
+func (r *rdb) length(n int) {
+	switch {
+	case n < 64:
+		r.WriteByte(byte(n))
+	case n < 16384:
+		r.WriteByte(byte(0x40 | n>>8))
+		r.WriteByte(byte(n))
+	default:
+		r.WriteByte(0x80)
+		binary.Write(r, binary.BigEndian, uint32(n))
+	}
+}
+
+func (r *rdb) str(s string) {
+	r.length(len(s))
+	r.WriteString(s)
+}
+
+// set writes a plain string key.
+func (r *rdb) set(key, value string) {
+	r.WriteByte(typeString)
+	r.str(key)
+	r.str(value)
+}
+
+// end writes the EOF opcode and a checksum, which isn't checked.
+func (r *rdb) end() []byte {
+	r.WriteByte(opEOF)
+	r.Write(make([]byte, 8))
+	return r.Bytes()
+}
+
+func TestRodredis(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	data := newRDB()
+	data.set("user:42", "a user")
+	data.set("user:admin:1", "an admin")
+	data.set("plain", "string")
+	data.WriteByte(typeHash)
+	data.str("config")
+	data.length(1)
+	data.str("debug")
+	data.str("true")
+
+	t.Run("Locations", func(t *testing.T) {
+		res, err := ImportRDB(db, bytes.NewReader(data.end()),
+			Prefix("user:", "users"),
+			Prefix("user:admin:", "admins"),
+			StringLocation("kv"),
+			HashLocation("settings"),
+			BatchSize(1),
+		)
+		check(t, err)
+		if res.Strings != 3 || res.Hashes != 1 {
+			t.Fatalf("Unexpected result %#v", res)
+		}
+
+		check(t, db.View(func(tx *bolt.Tx) error {
+			for _, want := range [][3]string{
+				{"users", "42", "a user"},
+				{"admins", "1", "an admin"},
+				{"kv", "plain", "string"},
+				{"settings", "config", `{"debug":"true"}`},
+			} {
+				got, err := rod.GetString(tx, want[0], want[1])
+				check(t, err)
+				if got != want[2] {
+					t.Fatalf("Expected %q at %s/%s, not %q", want[2], want[0], want[1], got)
+				}
+			}
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,263 @@
+package rodremote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chilts/rod"
+)
+
+// DB is a connection to a rod server.
+type DB struct {
+	url    string
+	client *http.Client
+}
+
+// New returns a DB for the rod server at baseURL, using http.DefaultClient.
+func New(baseURL string) *DB {
+	return NewWithClient(baseURL, http.DefaultClient)
+}
+
+// NewWithClient is the same as New, but makes its requests with client, for when you need timeouts or TLS settings.
+func NewWithClient(baseURL string, client *http.Client) *DB {
+	return &DB{url: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// post sends v (if not nil) as JSON to path on the server, and decodes the reply into out.
+func (db *DB) post(path string, v, out interface{}) error {
+	body := &bytes.Buffer{}
+	if v != nil {
+		if err := json.NewEncoder(body).Encode(v); err != nil {
+			return err
+		}
+	}
+
+	resp, err := db.client.Post(db.url+path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rodremote: %s from %s", resp.Status, path)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// begin starts a transaction on the server.
+func (db *DB) begin(writable bool) (*Tx, error) {
+	var b begun
+	if err := db.post("/tx?writable="+url.QueryEscape(fmt.Sprint(writable)), nil, &b); err != nil {
+		return nil, err
+	}
+	if b.Tx == "" {
+		return nil, fmt.Errorf("rodremote: no transaction returned")
+	}
+	return &Tx{db: db, id: b.Tx, writable: writable}, nil
+}
+
+// finish commits or rolls back the transaction on the server.
+func (tx *Tx) finish(how string) error {
+	var res response
+	if err := tx.db.post("/tx/"+tx.id+"/"+how, nil, &res); err != nil {
+		return err
+	}
+	return decodeError(res.Error)
+}
+
+// Update runs fn inside a read-write transaction on the server, which is committed if fn returns nil and nothing
+// went wrong talking to the server, and rolled back otherwise.
+func (db *DB) Update(fn func(tx *Tx) error) error {
+	tx, err := db.begin(true)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.finish("rollback")
+		return err
+	}
+	if tx.err != nil {
+		tx.finish("rollback")
+		return tx.err
+	}
+	return tx.finish("commit")
+}
+
+// View runs fn inside a read-only transaction on the server.
+func (db *DB) View(fn func(tx *Tx) error) error {
+	tx, err := db.begin(false)
+	if err != nil {
+		return err
+	}
+	err = fn(tx)
+	if err == nil {
+		err = tx.err
+	}
+	if rerr := tx.finish("rollback"); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// Tx is a transaction on a rod server, as a rod.BackendTx.
+type Tx struct {
+	db       *DB
+	id       string
+	writable bool
+	err      error
+}
+
+// Err returns the first error talking to the server within this transaction, if there was one.
+func (tx *Tx) Err() error {
+	return tx.err
+}
+
+// Writable tells you whether this transaction came from Update.
+func (tx *Tx) Writable() bool {
+	return tx.writable
+}
+
+// do sends req to the server. Once anything has failed, nothing more is sent.
+func (tx *Tx) do(req *request) (*response, error) {
+	if tx.err != nil {
+		return nil, tx.err
+	}
+
+	var res response
+	if err := tx.db.post("/tx/"+tx.id, req, &res); err != nil {
+		tx.err = err
+		return nil, err
+	}
+	if err := decodeError(res.Error); err != nil {
+		// errors from Bolt are fine to carry on from, but a missing transaction means it's gone
+		if err == ErrTxNotFound {
+			tx.err = err
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Bucket returns the top-level bucket called name, or nil if it doesn't exist.
+func (tx *Tx) Bucket(name []byte) rod.Bucket {
+	return (&bucket{tx: tx}).Bucket(name)
+}
+
+// CreateBucketIfNotExists returns the top-level bucket called name, creating it first if needed.
+func (tx *Tx) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	return (&bucket{tx: tx}).CreateBucketIfNotExists(name)
+}
+
+// bucket is a bucket on the server, known by its path.
+type bucket struct {
+	tx   *Tx
+	path [][]byte
+}
+
+// nested returns the path of the nested bucket called name.
+func (b *bucket) nested(name []byte) [][]byte {
+	return append(append([][]byte{}, b.path...), name)
+}
+
+func (b *bucket) Get(key []byte) []byte {
+	res, err := b.tx.do(&request{Op: "get", Path: b.path, Key: key})
+	if err != nil || !res.Found {
+		return nil
+	}
+	if res.Value == nil {
+		return []byte{}
+	}
+	return res.Value
+}
+
+func (b *bucket) Put(key, value []byte) error {
+	_, err := b.tx.do(&request{Op: "put", Path: b.path, Key: key, Value: value})
+	return err
+}
+
+func (b *bucket) Delete(key []byte) error {
+	_, err := b.tx.do(&request{Op: "delete", Path: b.path, Key: key})
+	return err
+}
+
+func (b *bucket) Bucket(name []byte) rod.Bucket {
+	path := b.nested(name)
+	res, err := b.tx.do(&request{Op: "bucket", Path: path})
+	if err != nil || !res.Found {
+		return nil
+	}
+	return &bucket{tx: b.tx, path: path}
+}
+
+func (b *bucket) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	path := b.nested(name)
+	if _, err := b.tx.do(&request{Op: "create", Path: path}); err != nil {
+		return nil, err
+	}
+	return &bucket{tx: b.tx, path: path}, nil
+}
+
+func (b *bucket) NextSequence() (uint64, error) {
+	res, err := b.tx.do(&request{Op: "sequence", Path: b.path})
+	if err != nil {
+		return 0, err
+	}
+	return res.Sequence, nil
+}
+
+func (b *bucket) Cursor() rod.Cursor {
+	return &cursor{bucket: b}
+}
+
+// cursor moves over a bucket on the server. The server keeps no cursor, so each step asks for the key next to the
+// current one.
+type cursor struct {
+	bucket  *bucket
+	current []byte
+}
+
+func (c *cursor) First() ([]byte, []byte) {
+	return c.step("first", nil)
+}
+
+func (c *cursor) Last() ([]byte, []byte) {
+	return c.step("last", nil)
+}
+
+func (c *cursor) Next() ([]byte, []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+	return c.step("first", append(append([]byte{}, c.current...), 0x00))
+}
+
+func (c *cursor) Prev() ([]byte, []byte) {
+	if c.current == nil {
+		return nil, nil
+	}
+	return c.step("last", c.current)
+}
+
+func (c *cursor) Seek(seek []byte) ([]byte, []byte) {
+	return c.step("first", seek)
+}
+
+func (c *cursor) step(op string, key []byte) ([]byte, []byte) {
+	res, err := c.bucket.tx.do(&request{Op: op, Path: c.bucket.path, Key: key})
+	if err != nil || !res.Found {
+		c.current = nil
+		return nil, nil
+	}
+
+	c.current = res.Key
+	if res.Bucket {
+		return res.Key, nil
+	}
+	if res.Value == nil {
+		return res.Key, []byte{}
+	}
+	return res.Key, res.Value
+}
@@ -0,0 +1,30 @@
+// Package rodremote lets rod run against a Bolt file on another machine, so the same code can use a local file in
+// development and a shared server in production. The server side is a Handler around a *bolt.DB, which refuses every
+// request until it is told who may use it:
+//
+//	handler := rodremote.NewHandler(db)
+//	handler.Authorize = func(r *http.Request, writable bool) error {
+//	    if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+//	        return errors.New("bad token")
+//	    }
+//	    return nil
+//	}
+//	http.ListenAndServe("localhost:8080", handler)
+//
+// and the client side is a DB whose transactions can be given to any of rod's functions:
+//
+//	db := rodremote.New("http://localhost:8080")
+//	err := db.Update(func(tx *rodremote.Tx) error {
+//	    return rod.PutJson(tx, "users", "chilts", &user)
+//	})
+//
+// Each transaction is a real Bolt transaction held open on the server, so everything within it is consistent just as
+// it is locally, and as with Bolt only one Update runs at a time. Every bucket, key and cursor step is a round trip
+// though, so keep transactions short. A transaction left idle for longer than the Handler's Timeout, or open for
+// longer than its MaxLifetime, is rolled back.
+//
+// Should a request fail, the transaction remembers the error and Update returns it (rolling back) in place of
+// committing, since some of rod's interfaces (such as Bucket.Get) have no way to return an error themselves.
+//
+// (Ends)
+package rodremote
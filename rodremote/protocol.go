@@ -0,0 +1,78 @@
+package rodremote
+
+import (
+	"errors"
+
+	"github.com/boltdb/bolt"
+)
+
+// The protocol is JSON over HTTP:
+//
+//	POST /tx?writable=true    begins a transaction, returning {"tx": id}
+//	POST /tx/{id}             runs a request within it, returning a response
+//	POST /tx/{id}/commit      commits it
+//	POST /tx/{id}/rollback    rolls it back
+//
+// Paths are the names of the buckets from the top down, and []byte values are base64 encoded as usual.
+
+// begun is the reply to beginning a transaction.
+type begun struct {
+	Tx string `json:"tx"`
+}
+
+// request is a single operation within a transaction. Op is one of "get", "put", "delete", "bucket", "create",
+// "sequence", "first" (the first key equal to or after Key, or the very first if there's no Key) and "last" (the last
+// key before Key, or the very last if there's no Key).
+type request struct {
+	Op    string   `json:"op"`
+	Path  [][]byte `json:"path,omitempty"`
+	Key   []byte   `json:"key,omitempty"`
+	Value []byte   `json:"value,omitempty"`
+}
+
+// response is the result of a request. Found says whether there was a key, value or bucket to return, and Bucket
+// whether the key found by a cursor is a nested bucket.
+type response struct {
+	Found    bool   `json:"found,omitempty"`
+	Key      []byte `json:"key,omitempty"`
+	Value    []byte `json:"value,omitempty"`
+	Bucket   bool   `json:"bucket,omitempty"`
+	Sequence uint64 `json:"sequence,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ErrTxNotFound is returned when the server doesn't know the transaction, usually because it timed out.
+var ErrTxNotFound = errors.New("transaction not found")
+
+// knownErrors turns the errors which come back from the server into the same values they were on the server, so
+// that errors.Is() works just as it does for a local Bolt file.
+var knownErrors = map[string]error{}
+
+func init() {
+	for _, err := range []error{
+		ErrTxNotFound,
+		bolt.ErrTxNotWritable,
+		bolt.ErrTxClosed,
+		bolt.ErrBucketNotFound,
+		bolt.ErrBucketExists,
+		bolt.ErrBucketNameRequired,
+		bolt.ErrKeyRequired,
+		bolt.ErrKeyTooLarge,
+		bolt.ErrValueTooLarge,
+		bolt.ErrIncompatibleValue,
+		bolt.ErrDatabaseReadOnly,
+	} {
+		knownErrors[err.Error()] = err
+	}
+}
+
+// decodeError returns the error for msg.
+func decodeError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	if err, ok := knownErrors[msg]; ok {
+		return err
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,201 @@
+package rodremote
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+type User struct {
+	Username string
+	Logins   int
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rodremote-")
+	check(t, err)
+	defer os.RemoveAll(dir)
+
+	bdb, err := bolt.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(t, err)
+	defer bdb.Close()
+
+	handler := NewHandler(bdb)
+	handler.Authorize = AllowAll
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	db := New(server.URL)
+
+	t.Run("Put, Get and All", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			check(t, rod.PutJson(tx, "users", "chilts", User{"chilts", 2}))
+			check(t, rod.PutJson(tx, "users", "bob", User{"bob", 1}))
+			check(t, rod.PutString(tx, "empty", "key", ""))
+			return nil
+		})
+		check(t, err)
+
+		// and it's in the file on the server
+		err = bdb.View(func(tx *bolt.Tx) error {
+			var user User
+			check(t, rod.GetJson(tx, "users", "chilts", &user))
+			if user.Logins != 2 {
+				t.Fatalf("The user should have been put in the server's file: %#v", user)
+			}
+			return nil
+		})
+		check(t, err)
+
+		err = db.View(func(tx *Tx) error {
+			var users []User
+			check(t, rod.All(tx, "users", &users))
+			if len(users) != 2 || users[0].Username != "bob" {
+				t.Fatalf("Expected bob then chilts: %#v", users)
+			}
+
+			key, _, err := rod.Last(tx, "users")
+			check(t, err)
+			if key != "chilts" {
+				t.Fatalf("Expected the last user to be chilts but got '%s'", key)
+			}
+
+			value, found, err := rod.GetFound(tx, "empty", "key")
+			check(t, err)
+			if !found || value == nil || len(value) != 0 {
+				t.Fatalf("An empty value should be found and not be nil")
+			}
+
+			if err := rod.PutString(tx, "users", "alice", "nope"); !errors.Is(err, bolt.ErrTxNotWritable) {
+				t.Fatalf("Expected bolt.ErrTxNotWritable but got %v", err)
+			}
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("Failed updates are rolled back", func(t *testing.T) {
+		failed := errors.New("failed")
+		err := db.Update(func(tx *Tx) error {
+			check(t, rod.PutString(tx, "users", "ghost", "boo"))
+			return failed
+		})
+		if err != failed {
+			t.Fatalf("Expected the error from the update but got %v", err)
+		}
+
+		err = db.View(func(tx *Tx) error {
+			value, err := rod.Get(tx, "users", "ghost")
+			check(t, err)
+			if value != nil {
+				t.Fatalf("Nothing from a failed update should be kept")
+			}
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("Errors are Bolt's", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			return rod.PutString(tx, "users.chilts", "email", "andychilton@gmail.com")
+		})
+		if !errors.Is(err, bolt.ErrIncompatibleValue) {
+			t.Fatalf("Expected bolt.ErrIncompatibleValue but got %v", err)
+		}
+	})
+
+	t.Run("Idle transactions are rolled back", func(t *testing.T) {
+		handler.Timeout = 10 * time.Millisecond
+		defer func() { handler.Timeout = DefaultTimeout }()
+
+		err := db.Update(func(tx *Tx) error {
+			time.Sleep(50 * time.Millisecond)
+			return rod.PutString(tx, "users", "late", "too late")
+		})
+		if !errors.Is(err, ErrTxNotFound) {
+			t.Fatalf("Expected ErrTxNotFound but got %v", err)
+		}
+
+		// and the writer has been let go
+		err = db.Update(func(tx *Tx) error {
+			return rod.PutString(tx, "users", "prompt", "on time")
+		})
+		check(t, err)
+	})
+
+	t.Run("Transactions have a maximum lifetime", func(t *testing.T) {
+		handler.MaxLifetime = 30 * time.Millisecond
+		defer func() { handler.MaxLifetime = DefaultMaxLifetime }()
+
+		// a busy transaction isn't kept open by its requests
+		err := db.Update(func(tx *Tx) error {
+			for start := time.Now(); time.Since(start) < 100*time.Millisecond; {
+				if err := rod.PutString(tx, "users", "busy", "busy"); err != nil {
+					return err
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			return nil
+		})
+		if !errors.Is(err, ErrTxNotFound) {
+			t.Fatalf("Expected ErrTxNotFound but got %v", err)
+		}
+	})
+
+	t.Run("Requests must be authorized", func(t *testing.T) {
+		defer func() { handler.Authorize = AllowAll }()
+
+		handler.Authorize = nil
+		if err := db.View(func(tx *Tx) error { return nil }); err == nil || !strings.Contains(err.Error(), "403") {
+			t.Fatalf("Expected everything to be refused without Authorize but got %v", err)
+		}
+
+		handler.Authorize = func(r *http.Request, writable bool) error {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				return errors.New("bad token")
+			}
+			if writable {
+				return errors.New("read only")
+			}
+			return nil
+		}
+		if err := db.View(func(tx *Tx) error { return nil }); err == nil {
+			t.Fatal("Expected a request without the token to be refused")
+		}
+
+		authed := NewWithClient(server.URL, &http.Client{Transport: bearer("secret")})
+		err := authed.View(func(tx *Tx) error {
+			var user User
+			return rod.GetJson(tx, "users", "chilts", &user)
+		})
+		check(t, err)
+		if err := authed.Update(func(tx *Tx) error { return nil }); err == nil {
+			t.Fatal("Expected a writable transaction to be refused")
+		}
+	})
+}
+
+// bearer adds the token to every request as a bearer token.
+type bearer string
+
+func (b bearer) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+string(b))
+	return http.DefaultTransport.RoundTrip(r)
+}
@@ -0,0 +1,309 @@
+package rodremote
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// DefaultTimeout is how long a transaction may sit idle before the Handler rolls it back.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMaxLifetime is how long a transaction may be open at all before the Handler rolls it back.
+const DefaultMaxLifetime = 2 * time.Minute
+
+// ErrNoAuthorize is the reason every request is refused by a Handler without an Authorize func.
+var ErrNoAuthorize = errors.New("no Authorize func has been set")
+
+// AllowAll is an Authorize func which lets every request through. Only use it where the Handler can't be reached by
+// anyone who shouldn't have full read and write access to the whole database.
+func AllowAll(r *http.Request, writable bool) error {
+	return nil
+}
+
+// Handler serves a *bolt.DB to rodremote clients.
+type Handler struct {
+	// Authorize is called with every request, along with whether the transaction it begins or runs in is writable,
+	// and the request is refused with 403 Forbidden if it returns an error. Since a client may read and write anything
+	// in the database, a Handler refuses everything until Authorize is set. Send credentials, such as a bearer token,
+	// from the client with NewWithClient and a http.Client whose Transport adds them.
+	Authorize func(r *http.Request, writable bool) error
+
+	// Timeout is how long a transaction may sit idle before it is rolled back.
+	Timeout time.Duration
+
+	// MaxLifetime is how long a transaction may be open before it is rolled back, however busy it is. Since only one
+	// writable transaction can be open at a time, this stops any client holding Bolt's writer lock for longer.
+	MaxLifetime time.Duration
+
+	db  *bolt.DB
+	mux *http.ServeMux
+
+	mu  sync.Mutex
+	txs map[string]*serverTx
+}
+
+// serverTx is a transaction held open for a client.
+type serverTx struct {
+	mu      sync.Mutex
+	tx      *bolt.Tx
+	timer   *time.Timer
+	expires time.Time
+}
+
+// idle returns how long st may now sit idle, which is never past when it expires.
+func (h *Handler) idle(st *serverTx) time.Duration {
+	d := h.Timeout
+	if left := time.Until(st.expires); left < d {
+		d = left
+	}
+	return d
+}
+
+// NewHandler returns a Handler for db.
+func NewHandler(db *bolt.DB) *Handler {
+	h := &Handler{
+		Timeout:     DefaultTimeout,
+		MaxLifetime: DefaultMaxLifetime,
+		db:          db,
+		mux:         http.NewServeMux(),
+		txs:         make(map[string]*serverTx),
+	}
+	h.mux.HandleFunc("POST /tx", h.begin)
+	h.mux.HandleFunc("POST /tx/{id}", h.run)
+	h.mux.HandleFunc("POST /tx/{id}/commit", h.commit)
+	h.mux.HandleFunc("POST /tx/{id}/rollback", h.rollback)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func reply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// authorize checks the request with Authorize, replying with 403 Forbidden if it is refused.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, writable bool) bool {
+	err := ErrNoAuthorize
+	if h.Authorize != nil {
+		err = h.Authorize(r, writable)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) begin(w http.ResponseWriter, r *http.Request) {
+	writable := r.URL.Query().Get("writable") == "true"
+	if !h.authorize(w, r, writable) {
+		return
+	}
+
+	// Begin blocks while another writer is open, just as Update does, so don't take h.mu until after
+	tx, err := h.db.Begin(writable)
+	if err != nil {
+		reply(w, &response{Error: err.Error()})
+		return
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		tx.Rollback()
+		reply(w, &response{Error: err.Error()})
+		return
+	}
+	id := hex.EncodeToString(raw)
+
+	st := &serverTx{tx: tx, expires: time.Now().Add(h.MaxLifetime)}
+	h.mu.Lock()
+	st.timer = time.AfterFunc(h.idle(st), func() { h.remove(id, false) })
+	h.txs[id] = st
+	h.mu.Unlock()
+
+	reply(w, &begun{Tx: id})
+}
+
+// lookup returns the transaction with id, if it is still open.
+func (h *Handler) lookup(id string) (*serverTx, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st, ok := h.txs[id]
+	return st, ok
+}
+
+// remove forgets the transaction, and commits or rolls it back.
+func (h *Handler) remove(id string, commit bool) error {
+	h.mu.Lock()
+	st, ok := h.txs[id]
+	delete(h.txs, id)
+	h.mu.Unlock()
+	if !ok {
+		return ErrTxNotFound
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.timer.Stop()
+	if commit {
+		return st.tx.Commit()
+	}
+	return st.tx.Rollback()
+}
+
+func (h *Handler) commit(w http.ResponseWriter, r *http.Request) {
+	h.end(w, r, true)
+}
+
+func (h *Handler) rollback(w http.ResponseWriter, r *http.Request) {
+	h.end(w, r, false)
+}
+
+func (h *Handler) end(w http.ResponseWriter, r *http.Request, commit bool) {
+	st, ok := h.lookup(r.PathValue("id"))
+	if ok && !h.authorize(w, r, st.tx.Writable()) {
+		return
+	}
+	if err := h.remove(r.PathValue("id"), commit); err != nil {
+		reply(w, &response{Error: err.Error()})
+		return
+	}
+	reply(w, &response{})
+}
+
+func (h *Handler) run(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	st, ok := h.lookup(r.PathValue("id"))
+	if !ok {
+		reply(w, &response{Error: ErrTxNotFound.Error()})
+		return
+	}
+	if !h.authorize(w, r, st.tx.Writable()) {
+		return
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.timer.Stop() {
+		// still running, so wait for the next request, but never past when the transaction expires
+		st.timer.Reset(h.idle(st))
+	} else {
+		// being rolled back already
+		reply(w, &response{Error: ErrTxNotFound.Error()})
+		return
+	}
+
+	res, err := serve(st.tx, &req)
+	if err != nil {
+		res = &response{Error: err.Error()}
+	}
+	reply(w, res)
+}
+
+// serve runs req within tx.
+func serve(tx *bolt.Tx, req *request) (*response, error) {
+	// the bucket ops are on the last bucket in the path, from within its parent
+	if req.Op == "bucket" || req.Op == "create" {
+		if len(req.Path) == 0 {
+			return nil, bolt.ErrBucketNameRequired
+		}
+		parent, name := req.Path[:len(req.Path)-1], req.Path[len(req.Path)-1]
+		if len(parent) == 0 {
+			if req.Op == "bucket" {
+				return &response{Found: tx.Bucket(name) != nil}, nil
+			}
+			_, err := tx.CreateBucketIfNotExists(name)
+			return &response{Found: err == nil}, err
+		}
+		b := walk(tx, parent)
+		if b == nil {
+			return nil, bolt.ErrBucketNotFound
+		}
+		if req.Op == "bucket" {
+			return &response{Found: b.Bucket(name) != nil}, nil
+		}
+		_, err := b.CreateBucketIfNotExists(name)
+		return &response{Found: err == nil}, err
+	}
+
+	b := walk(tx, req.Path)
+	if b == nil {
+		return nil, bolt.ErrBucketNotFound
+	}
+
+	switch req.Op {
+	case "get":
+		v := b.Get(req.Key)
+		return &response{Found: v != nil, Value: v}, nil
+	case "put":
+		// an empty value arrives as nil, but Bolt would then treat it as a nested bucket within the tx
+		if req.Value == nil {
+			req.Value = []byte{}
+		}
+		return &response{}, b.Put(req.Key, req.Value)
+	case "delete":
+		return &response{}, b.Delete(req.Key)
+	case "sequence":
+		seq, err := b.NextSequence()
+		return &response{Sequence: seq}, err
+	case "first":
+		c := b.Cursor()
+		var k, v []byte
+		if req.Key == nil {
+			k, v = c.First()
+		} else {
+			k, v = c.Seek(req.Key)
+		}
+		return found(k, v), nil
+	case "last":
+		c := b.Cursor()
+		var k, v []byte
+		if req.Key == nil {
+			k, v = c.Last()
+		} else if k, _ = c.Seek(req.Key); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+		return found(k, v), nil
+	}
+	return nil, fmt.Errorf("unknown op %q", req.Op)
+}
+
+// walk returns the bucket at path, or nil if any of it doesn't exist.
+func walk(tx *bolt.Tx, path [][]byte) *bolt.Bucket {
+	if len(path) == 0 {
+		return nil
+	}
+	b := tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		if b == nil {
+			return nil
+		}
+		b = b.Bucket(name)
+	}
+	return b
+}
+
+func found(k, v []byte) *response {
+	if k == nil {
+		return &response{}
+	}
+	return &response{Found: true, Key: k, Value: v, Bucket: v == nil}
+}
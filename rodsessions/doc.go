@@ -0,0 +1,17 @@
+// Package rodsessions is a gorilla/sessions Store which keeps each session in a rod location, so a web app already
+// using rod needs nothing else to persist its sessions:
+//
+//	s, err := rod.Open("/path/to/rod.db", 0666, nil)
+//	store := rodsessions.New(s, "sessions", []byte(hashKey), []byte(blockKey))
+//
+//	session, err := store.Get(r, "session-name")
+//	session.Values["user"] = "chilts"
+//	err = session.Save(r, w)
+//
+// Only the session's ID goes in the cookie, signed (and encrypted, if a block key is given) with gorilla/securecookie.
+// Its values are gob encoded and put in the location under the ID, along with when it expires, which is MaxAge seconds
+// after it was last saved. An expired session is treated as a new one, and DeleteExpired removes them all from the
+// location, which you might do every hour or so.
+//
+// (Ends)
+package rodsessions
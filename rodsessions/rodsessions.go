@@ -0,0 +1,186 @@
+package rodsessions
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/gob"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// DefaultMaxAge is how long a session lasts (in seconds) unless Options.MaxAge says otherwise, which is 30 days as in
+// gorilla/sessions.
+const DefaultMaxAge = 86400 * 30
+
+// Store is a sessions.Store which keeps sessions in a rod location.
+type Store struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options // the default options for each new session
+
+	store    *rod.Store
+	location string
+}
+
+// record is what is put in the location for each session.
+type record struct {
+	Values  []byte
+	Expires time.Time
+}
+
+// New returns a Store keeping its sessions in location. The keyPairs are given to securecookie.CodecsFromPairs to
+// sign and encrypt each session's cookie, so keys can be rotated in the same way as with the other gorilla/sessions stores.
+func New(s *rod.Store, location string, keyPairs ...[]byte) *Store {
+	st := &Store{
+		Codecs:   securecookie.CodecsFromPairs(keyPairs...),
+		Options:  &sessions.Options{Path: "/", MaxAge: DefaultMaxAge},
+		store:    s,
+		location: location,
+	}
+	st.MaxAge(st.Options.MaxAge)
+	return st
+}
+
+// MaxAge sets how long sessions last, in seconds, for both the cookies and the sessions kept in the location. Zero
+// means a session lasts until the browser is closed, and isn't ever expired from the location.
+func (s *Store) MaxAge(age int) {
+	s.Options.MaxAge = age
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
+
+// Get returns the session called name for the request, from the request's registry if it has already been fetched.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the session called name for the request, which is a new one if the request has no (valid) cookie for
+// it, or the session it refers to has expired.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		// no cookie is just a new session
+		return session, nil
+	}
+	if err := securecookie.DecodeMulti(name, c.Value, &session.ID, s.Codecs...); err != nil {
+		return session, err
+	}
+
+	found, err := s.load(session)
+	if err != nil {
+		return session, err
+	}
+	if !found {
+		// start afresh rather than carry on with an ID which has expired
+		session.ID = ""
+		return session, nil
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+// Save puts the session in the location and sets its cookie. A session with a negative MaxAge is deleted instead,
+// along with its cookie.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if err := s.erase(session); err != nil {
+			return err
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+	}
+	if err := s.save(session); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// DeleteExpired removes every expired session from the location, returning how many there were.
+func (s *Store) DeleteExpired() (int, error) {
+	now := time.Now()
+	var expired []string
+	err := s.store.Update(func(tx *bolt.Tx) error {
+		err := rod.EachJson(tx, s.location, func(key string, rec record) error {
+			if !rec.Expires.IsZero() && now.After(rec.Expires) {
+				expired = append(expired, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := rod.Del(tx, s.location, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(expired), err
+}
+
+func (s *Store) save(session *sessions.Session) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(session.Values); err != nil {
+		return err
+	}
+
+	rec := record{Values: buf.Bytes()}
+	if session.Options.MaxAge > 0 {
+		rec.Expires = time.Now().Add(time.Duration(session.Options.MaxAge) * time.Second)
+	}
+	return s.store.Update(func(tx *bolt.Tx) error {
+		return rod.PutJson(tx, s.location, session.ID, rec)
+	})
+}
+
+// load fills in the session's values from the location, returning false if it doesn't exist or has expired.
+func (s *Store) load(session *sessions.Session) (bool, error) {
+	var rec record
+	var found bool
+	err := s.store.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = rod.GetJsonFound(tx, s.location, session.ID, &rec)
+		return err
+	})
+	if err != nil || !found {
+		return false, err
+	}
+	if !rec.Expires.IsZero() && time.Now().After(rec.Expires) {
+		return false, nil
+	}
+	return true, gob.NewDecoder(bytes.NewReader(rec.Values)).Decode(&session.Values)
+}
+
+func (s *Store) erase(session *sessions.Session) error {
+	if session.ID == "" {
+		return nil
+	}
+	return s.store.Update(func(tx *bolt.Tx) error {
+		return rod.Del(tx, s.location, session.ID)
+	})
+}
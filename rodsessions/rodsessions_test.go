@@ -0,0 +1,111 @@
+package rodsessions
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// request returns a request carrying the cookies set on w.
+func request(w *httptest.ResponseRecorder) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func TestRodsessions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rodsessions-")
+	check(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := rod.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(t, err)
+	defer s.Close()
+
+	store := New(s, "sessions", []byte("hash-key-hash-key-hash-key-hash!"), []byte("block-key-block-key-block-key-32"))
+
+	var saved *httptest.ResponseRecorder
+
+	t.Run("Save and load", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		session, err := store.Get(r, "app")
+		check(t, err)
+		if !session.IsNew {
+			t.Fatal("A request without a cookie should get a new session")
+		}
+		session.Values["user"] = "chilts"
+		session.Values[42] = 7
+
+		saved = httptest.NewRecorder()
+		check(t, session.Save(r, saved))
+
+		cookies := saved.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Value == session.ID {
+			t.Fatalf("The cookie should hold the encoded session ID: %v", cookies)
+		}
+
+		loaded, err := store.New(request(saved), "app")
+		check(t, err)
+		if loaded.IsNew || loaded.ID != session.ID || loaded.Values["user"] != "chilts" || loaded.Values[42] != 7 {
+			t.Fatalf("The session should have been loaded: %#v", loaded)
+		}
+	})
+
+	t.Run("Tampered cookies", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(&http.Cookie{Name: "app", Value: "bm90LWEtcmVhbC1jb29raWU="})
+		session, err := store.New(r, "app")
+		if err == nil || !session.IsNew {
+			t.Fatal("A cookie which isn't signed should give an error and a new session")
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		check(t, s.Update(func(tx *bolt.Tx) error {
+			return rod.PutJson(tx, "sessions", "old", record{Expires: time.Unix(1, 0)})
+		}))
+
+		n, err := store.DeleteExpired()
+		check(t, err)
+		if n != 1 {
+			t.Fatalf("One session should have expired, not %d", n)
+		}
+
+		loaded, err := store.New(request(saved), "app")
+		check(t, err)
+		if loaded.IsNew {
+			t.Fatal("A session which hasn't expired should be kept")
+		}
+	})
+
+	t.Run("Deleting a session", func(t *testing.T) {
+		r := request(saved)
+		session, err := store.New(r, "app")
+		check(t, err)
+		session.Options.MaxAge = -1
+		w := httptest.NewRecorder()
+		check(t, session.Save(r, w))
+
+		loaded, err := store.New(request(saved), "app")
+		check(t, err)
+		if !loaded.IsNew || loaded.ID != "" {
+			t.Fatal("A deleted session should not be loaded again")
+		}
+	})
+}
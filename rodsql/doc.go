@@ -0,0 +1,19 @@
+// Package rodsql is a small, read-only SQL layer over a rod store so that data kept in BoltDB can be looked at with
+// familiar tools. Tables are rod locations (e.g. "users" or "users.chilts.posts") and columns are the top-level fields
+// of the JSON documents in them, with the special column _key being the key of each record.
+//
+// Only a single-table SELECT is understood:
+//
+//	SELECT * | column [, column...]
+//	FROM table
+//	[WHERE column op value [AND column op value...]]
+//	[ORDER BY column [ASC|DESC] [, column [ASC|DESC]...]]
+//	[LIMIT n [OFFSET n]]
+//
+// where op is one of =, !=, <>, <, <=, >, >= and value is a 'string', a number, true, false, null or a ? placeholder.
+//
+// Use Query directly, or the "rod" database/sql driver (registered by importing this package) with the path to the
+// Bolt file as the data source name.
+//
+// (Ends)
+package rodsql
@@ -0,0 +1,135 @@
+package rodsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// ErrReadOnly is returned if you try to Exec anything, or begin a transaction, through the driver.
+var ErrReadOnly = errors.New("rodsql: the rod driver is read-only")
+
+func init() {
+	sql.Register("rod", &Driver{})
+}
+
+// Driver is the database/sql driver registered as "rod". The data source name is the path to the Bolt file, which is
+// opened read-only. Bolt holds a lock on the file, so if your program already has it open use OpenDB instead.
+//
+//	db, err := sql.Open("rod", "/var/lib/myapp/data.db")
+//	rows, err := db.Query("SELECT _key, Email FROM users WHERE Logins > ?", 5)
+type Driver struct{}
+
+// Open opens the Bolt file at name read-only.
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	db, err := bolt.Open(name, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &conn{db: db, owned: true}, nil
+}
+
+// OpenDB returns a *sql.DB which queries the already opened Bolt database. Closing the *sql.DB does not close db.
+func OpenDB(db *bolt.DB) *sql.DB {
+	return sql.OpenDB(&connector{db: db})
+}
+
+type connector struct {
+	db *bolt.DB
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &Driver{}
+}
+
+type conn struct {
+	db    *bolt.DB
+	owned bool // whether we opened db and should therefore close it
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	s, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, s: s}, nil
+}
+
+func (c *conn) Close() error {
+	if c.owned {
+		return c.db.Close()
+	}
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, ErrReadOnly
+}
+
+type stmt struct {
+	conn *conn
+	s    *statement
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return s.s.args()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+
+	var res *Result
+	err := s.conn.db.View(func(tx *bolt.Tx) error {
+		var err error
+		res, err = s.s.exec(tx, values)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rows{res: res}, nil
+}
+
+type rows struct {
+	res *Result
+	pos int
+}
+
+func (r *rows) Columns() []string {
+	return r.res.Columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.res.Rows) {
+		return io.EOF
+	}
+	for i, v := range r.res.Rows[r.pos] {
+		dest[i] = v
+	}
+	r.pos++
+	return nil
+}
@@ -0,0 +1,80 @@
+package rodsql
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestDriver(t *testing.T) {
+	db, path, done := openTestDB(t)
+	defer done()
+
+	t.Run("OpenDB", func(t *testing.T) {
+		sqlDB := OpenDB(db)
+		defer sqlDB.Close()
+
+		rows, err := sqlDB.Query("SELECT Username, Logins FROM users WHERE Logins = ? ORDER BY Username DESC", 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		var names []string
+		for rows.Next() {
+			var name string
+			var logins int
+			if err := rows.Scan(&name, &logins); err != nil {
+				t.Fatal(err)
+			}
+			if logins != 12 {
+				t.Fatalf("Expected 12 logins but got %d", logins)
+			}
+			names = append(names, name)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(names) != 2 || names[0] != "dave" || names[1] != "bob" {
+			t.Fatalf("Expected dave and bob but got %v", names)
+		}
+
+		if _, err := sqlDB.Query(`SELECT * FROM users WHERE "a..b" = ?`, 1); err == nil {
+			t.Fatal("A bad column path should be an error")
+		}
+		if _, err := sqlDB.Exec("SELECT * FROM users"); err == nil {
+			t.Fatal("Exec should not be allowed")
+		}
+		if _, err := sqlDB.Begin(); err == nil {
+			t.Fatal("Transactions should not be allowed")
+		}
+
+		// the bolt db should still be open
+		if _, err := Query(db, "SELECT _key FROM users"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("sql.Open", func(t *testing.T) {
+		// we need to let go of the file first
+		db.Close()
+
+		sqlDB, err := sql.Open("rod", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sqlDB.Close()
+
+		var count int
+		rows, err := sqlDB.Query("SELECT _key FROM users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			count++
+		}
+		rows.Close()
+		if count != 4 {
+			t.Fatalf("Expected 4 users but got %d", count)
+		}
+	})
+}
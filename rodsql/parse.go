@@ -0,0 +1,336 @@
+package rodsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chilts/rod"
+)
+
+// statement is a parsed SELECT.
+type statement struct {
+	columns []string // nil means *
+	table   string
+	where   []condition
+	orderBy []ordering
+	limit   int // -1 means no limit
+	offset  int
+}
+
+type condition struct {
+	column string
+	op     string
+	value  interface{}
+	arg    int // the index of the placeholder, or -1 if value is a literal
+}
+
+type ordering struct {
+	column string
+	desc   bool
+}
+
+// tokens
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokSymbol
+	tokPlaceholder
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenise(query string) ([]token, error) {
+	var tokens []token
+	r := []rune(query)
+
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'':
+			// 'string' with '' as an escaped quote
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(r) {
+					return nil, fmt.Errorf("rodsql: unterminated string")
+				}
+				if r[i] == '\'' {
+					if i+1 < len(r) && r[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(r[i])
+				i++
+			}
+			tokens = append(tokens, token{tokString, sb.String()})
+		case c == '"':
+			// "quoted identifier"
+			j := i + 1
+			for j < len(r) && r[j] != '"' {
+				j++
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("rodsql: unterminated quoted identifier")
+			}
+			tokens = append(tokens, token{tokIdent, string(r[i+1 : j])})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(r) && unicode.IsDigit(r[i+1])):
+			j := i + 1
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.' || r[j] == 'e' || r[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, string(r[i:j])})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_' || r[j] == '.' || r[j] == '-') {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, string(r[i:j])})
+			i = j
+		case c == '?':
+			tokens = append(tokens, token{tokPlaceholder, "?"})
+			i++
+		case c == '<' || c == '>' || c == '!':
+			if i+1 < len(r) && (r[i+1] == '=' || (c == '<' && r[i+1] == '>')) {
+				tokens = append(tokens, token{tokSymbol, string(r[i : i+2])})
+				i += 2
+				continue
+			}
+			if c == '!' {
+				return nil, fmt.Errorf("rodsql: unexpected '!'")
+			}
+			tokens = append(tokens, token{tokSymbol, string(c)})
+			i++
+		case c == '=' || c == ',' || c == '*' || c == ';':
+			tokens = append(tokens, token{tokSymbol, string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("rodsql: unexpected character %q", c)
+		}
+	}
+
+	return append(tokens, token{tokEOF, ""}), nil
+}
+
+// parser
+
+type parser struct {
+	tokens []token
+	pos    int
+	args   int
+}
+
+func parse(query string) (*statement, error) {
+	tokens, err := tokenise(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.parseSelect()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword consumes the next token if it is the (case-insensitive) keyword kw.
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) symbol(s string) bool {
+	t := p.peek()
+	if t.kind == tokSymbol && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) ident(what string) (string, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", fmt.Errorf("rodsql: expected %s but got %q", what, t.text)
+	}
+	return t.text, nil
+}
+
+func (p *parser) parseSelect() (*statement, error) {
+	stmt := &statement{limit: -1}
+
+	if !p.keyword("SELECT") {
+		return nil, fmt.Errorf("rodsql: only SELECT is supported")
+	}
+
+	// columns
+	if !p.symbol("*") {
+		for {
+			col, err := p.ident("a column")
+			if err != nil {
+				return nil, err
+			}
+			stmt.columns = append(stmt.columns, col)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+
+	if !p.keyword("FROM") {
+		return nil, fmt.Errorf("rodsql: expected FROM")
+	}
+	table, err := p.ident("a table")
+	if err != nil {
+		return nil, err
+	}
+	stmt.table = table
+
+	if p.keyword("WHERE") {
+		for {
+			cond, err := p.parseCondition()
+			if err != nil {
+				return nil, err
+			}
+			stmt.where = append(stmt.where, cond)
+			if !p.keyword("AND") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("ORDER") {
+		if !p.keyword("BY") {
+			return nil, fmt.Errorf("rodsql: expected BY after ORDER")
+		}
+		for {
+			col, err := p.ident("a column")
+			if err != nil {
+				return nil, err
+			}
+			o := ordering{column: col}
+			if p.keyword("DESC") {
+				o.desc = true
+			} else {
+				p.keyword("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, o)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("LIMIT") {
+		if stmt.limit, err = p.parseInt(); err != nil {
+			return nil, err
+		}
+		if p.keyword("OFFSET") {
+			if stmt.offset, err = p.parseInt(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	p.symbol(";")
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("rodsql: unexpected %q", t.text)
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseCondition() (condition, error) {
+	col, err := p.ident("a column")
+	if err != nil {
+		return condition{}, err
+	}
+	if col != KeyColumn {
+		// rod.Where panics on a bad path, so it must be checked here
+		if err := rod.ValidFieldPath(col); err != nil {
+			return condition{}, fmt.Errorf("rodsql: %w", err)
+		}
+	}
+
+	t := p.next()
+	if t.kind != tokSymbol {
+		return condition{}, fmt.Errorf("rodsql: expected an operator but got %q", t.text)
+	}
+	op := t.text
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=":
+	case "<>":
+		op = "!="
+	default:
+		return condition{}, fmt.Errorf("rodsql: unknown operator %q", op)
+	}
+
+	cond := condition{column: col, op: op, arg: -1}
+	t = p.next()
+	switch t.kind {
+	case tokString:
+		cond.value = t.text
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return condition{}, fmt.Errorf("rodsql: invalid number %q", t.text)
+		}
+		cond.value = f
+	case tokPlaceholder:
+		cond.arg = p.args
+		p.args++
+	case tokIdent:
+		switch strings.ToLower(t.text) {
+		case "true":
+			cond.value = true
+		case "false":
+			cond.value = false
+		case "null":
+			cond.value = nil
+		default:
+			return condition{}, fmt.Errorf("rodsql: expected a value but got %q", t.text)
+		}
+	default:
+		return condition{}, fmt.Errorf("rodsql: expected a value but got %q", t.text)
+	}
+
+	return cond, nil
+}
+
+func (p *parser) parseInt() (int, error) {
+	t := p.next()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		return 0, fmt.Errorf("rodsql: expected a positive integer but got %q", t.text)
+	}
+	return n, nil
+}
@@ -0,0 +1,304 @@
+package rodsql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+// KeyColumn is the name of the special column holding each record's key.
+const KeyColumn = "_key"
+
+// Result holds the columns and rows returned from a query. Each value is one of nil, bool, int64, float64 or string,
+// with nested objects and arrays being given as their JSON encoding.
+type Result struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Query runs the SELECT in query against db inside a read-only transaction. Any ? placeholders in the WHERE clause are
+// filled, in order, from args.
+//
+//	res, err := rodsql.Query(db, "SELECT _key, Email FROM users WHERE Logins > ? ORDER BY Logins DESC LIMIT 10", 5)
+func Query(db *bolt.DB, query string, args ...interface{}) (*Result, error) {
+	var res *Result
+	err := db.View(func(tx *bolt.Tx) error {
+		var err error
+		res, err = QueryTx(tx, query, args...)
+		return err
+	})
+	return res, err
+}
+
+// QueryTx is the same as Query but runs inside a transaction of your own.
+func QueryTx(tx *bolt.Tx, query string, args ...interface{}) (*Result, error) {
+	stmt, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.exec(tx, args)
+}
+
+// exec runs the statement with the given placeholder args.
+func (s *statement) exec(tx *bolt.Tx, args []interface{}) (*Result, error) {
+	if len(args) != s.args() {
+		return nil, fmt.Errorf("rodsql: expected %d arguments but got %d", s.args(), len(args))
+	}
+
+	// build a filter for each condition (other than those on the key)
+	var filters []rod.Filter
+	var keyConds []condition
+	for _, cond := range s.where {
+		value := cond.value
+		if cond.arg >= 0 {
+			value = normaliseArg(args[cond.arg])
+		}
+
+		if cond.column == KeyColumn {
+			cond.value = value
+			keyConds = append(keyConds, cond)
+			continue
+		}
+		filters = append(filters, rod.Where(cond.column, cond.op, value))
+	}
+
+	type record struct {
+		key string
+		doc map[string]interface{}
+	}
+	var records []record
+	seen := make(map[string]bool)
+
+	err := rod.Each(tx, s.table, func(key string, raw []byte) error {
+		for _, cond := range keyConds {
+			if !matchKey(key, cond) {
+				return nil
+			}
+		}
+		for _, filter := range filters {
+			if !filter(raw) {
+				return nil
+			}
+		}
+
+		doc := make(map[string]interface{})
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			// not an object, so it only has a key
+			doc = nil
+		}
+		for name := range doc {
+			seen[name] = true
+		}
+		records = append(records, record{key, doc})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// figure out the columns
+	columns := s.columns
+	if columns == nil {
+		columns = []string{KeyColumn}
+		names := make([]string, 0, len(seen))
+		for name := range seen {
+			if name != KeyColumn {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		columns = append(columns, names...)
+	}
+
+	value := func(r record, column string) interface{} {
+		if column == KeyColumn {
+			return r.key
+		}
+		return convert(lookup(r.doc, column))
+	}
+
+	if len(s.orderBy) > 0 {
+		sort.SliceStable(records, func(i, j int) bool {
+			for _, o := range s.orderBy {
+				cmp := compare(value(records[i], o.column), value(records[j], o.column))
+				if cmp == 0 {
+					continue
+				}
+				if o.desc {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+
+	// apply any offset and limit
+	if s.offset >= len(records) {
+		records = nil
+	} else {
+		records = records[s.offset:]
+	}
+	if s.limit >= 0 && s.limit < len(records) {
+		records = records[:s.limit]
+	}
+
+	res := &Result{Columns: columns, Rows: make([][]interface{}, len(records))}
+	for i, r := range records {
+		row := make([]interface{}, len(columns))
+		for j, column := range columns {
+			row[j] = value(r, column)
+		}
+		res.Rows[i] = row
+	}
+
+	return res, nil
+}
+
+// args counts how many placeholders the statement has.
+func (s *statement) args() int {
+	n := 0
+	for _, cond := range s.where {
+		if cond.arg >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// matchKey compares the key with a condition on the _key column.
+func matchKey(key string, cond condition) bool {
+	want, ok := cond.value.(string)
+	if !ok {
+		return cond.op == "!="
+	}
+	cmp := compare(key, want)
+	switch cond.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+// lookup finds the (possibly dotted) column in doc.
+func lookup(doc map[string]interface{}, column string) interface{} {
+	if v, ok := doc[column]; ok {
+		return v
+	}
+
+	// try reaching into nested objects
+	for i := 0; i < len(column); i++ {
+		if column[i] != '.' {
+			continue
+		}
+		if child, ok := doc[column[:i]].(map[string]interface{}); ok {
+			if v := lookup(child, column[i+1:]); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// convert turns a decoded JSON value into one of the types in a Result.
+func convert(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, string:
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+// normaliseArg converts a placeholder argument into something rod.Where can compare with.
+func normaliseArg(arg interface{}) interface{} {
+	switch a := arg.(type) {
+	case []byte:
+		return string(a)
+	case time.Time:
+		return a.Format(time.RFC3339Nano)
+	}
+	return arg
+}
+
+// compare orders two Result values: nil first, then bools, numbers and finally strings.
+func compare(a, b interface{}) int {
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+
+	switch av := a.(type) {
+	case bool:
+		bv := b.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+		return 1
+	case int64, float64:
+		af, bf := toFloat(a), toFloat(b)
+		if af < bf {
+			return -1
+		}
+		if af > bf {
+			return 1
+		}
+		return 0
+	case string:
+		bv := b.(string)
+		if av < bv {
+			return -1
+		}
+		if av > bv {
+			return 1
+		}
+	}
+	return 0
+}
+
+func rank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int64, float64:
+		return 2
+	}
+	return 3
+}
+
+func toFloat(v interface{}) float64 {
+	if i, ok := v.(int64); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
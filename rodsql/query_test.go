@@ -0,0 +1,165 @@
+package rodsql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+type User struct {
+	Username string
+	Logins   int
+	Admin    bool
+	Address  struct {
+		City string
+	}
+}
+
+// openTestDB opens a Bolt database full of users in a temporary directory, returning it along with its path.
+func openTestDB(t *testing.T) (*bolt.DB, string, func()) {
+	dir, err := ioutil.TempDir("", "rodsql-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "rod.db")
+
+	db, err := bolt.Open(path, 0666, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	users := []User{
+		{Username: "alice", Logins: 3, Admin: true},
+		{Username: "bob", Logins: 12},
+		{Username: "carol", Logins: 20},
+		{Username: "dave", Logins: 12},
+	}
+	users[0].Address.City = "Wellington"
+	users[1].Address.City = "London"
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, u := range users {
+			if err := rod.PutJson(tx, "users", u.Username, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, path, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	db, _, done := openTestDB(t)
+	defer done()
+
+	tests := []struct {
+		query string
+		args  []interface{}
+		rows  [][]interface{}
+	}{
+		{
+			"SELECT _key FROM users",
+			nil,
+			[][]interface{}{{"alice"}, {"bob"}, {"carol"}, {"dave"}},
+		},
+		{
+			"SELECT Username, Logins FROM users WHERE Logins > 10 AND Logins < 20",
+			nil,
+			[][]interface{}{{"bob", int64(12)}, {"dave", int64(12)}},
+		},
+		{
+			"select _key from users where Admin = true",
+			nil,
+			[][]interface{}{{"alice"}},
+		},
+		{
+			"SELECT _key FROM users WHERE Username <> 'bob' AND Logins >= ?",
+			[]interface{}{12},
+			[][]interface{}{{"carol"}, {"dave"}},
+		},
+		{
+			"SELECT _key, Logins FROM users ORDER BY Logins DESC, _key ASC LIMIT 3",
+			nil,
+			[][]interface{}{{"carol", int64(20)}, {"bob", int64(12)}, {"dave", int64(12)}},
+		},
+		{
+			"SELECT _key FROM users ORDER BY _key LIMIT 2 OFFSET 1;",
+			nil,
+			[][]interface{}{{"bob"}, {"carol"}},
+		},
+		{
+			"SELECT _key, Address.City FROM users WHERE Address.City = 'London'",
+			nil,
+			[][]interface{}{{"bob", "London"}},
+		},
+		{
+			"SELECT _key FROM users WHERE _key > 'b' AND _key < 'd'",
+			nil,
+			[][]interface{}{{"bob"}, {"carol"}},
+		},
+		{
+			"SELECT _key FROM \"does-not-exist\"",
+			nil,
+			[][]interface{}{},
+		},
+	}
+
+	for _, test := range tests {
+		res, err := Query(db, test.query, test.args...)
+		if err != nil {
+			t.Fatalf("%s: %v", test.query, err)
+		}
+		if !reflect.DeepEqual(res.Rows, test.rows) {
+			t.Fatalf("%s: expected %v but got %v", test.query, test.rows, res.Rows)
+		}
+	}
+
+	t.Run("SELECT *", func(t *testing.T) {
+		res, err := Query(db, "SELECT * FROM users WHERE _key = 'bob'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"_key", "Address", "Admin", "Logins", "Username"}
+		if !reflect.DeepEqual(res.Columns, want) {
+			t.Fatalf("Expected columns %v but got %v", want, res.Columns)
+		}
+		row := []interface{}{"bob", `{"City":"London"}`, false, int64(12), "bob"}
+		if !reflect.DeepEqual(res.Rows[0], row) {
+			t.Fatalf("Expected row %v but got %v", row, res.Rows[0])
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		bad := []string{
+			"DELETE FROM users",
+			"SELECT FROM users",
+			"SELECT * users",
+			"SELECT * FROM users WHERE Logins ~ 3",
+			"SELECT * FROM users WHERE Logins > ",
+			"SELECT * FROM users WHERE Username = 'unterminated",
+			"SELECT * FROM users LIMIT -1",
+			"SELECT * FROM users WHERE Logins > ?",
+			"SELECT * FROM users extra",
+			`SELECT * FROM users WHERE "a..b" = 1`,
+			"SELECT * FROM users WHERE Address. = 'x'",
+		}
+		for _, query := range bad {
+			if _, err := Query(db, query); err == nil {
+				t.Fatalf("%s: should have failed", query)
+			}
+		}
+	})
+}
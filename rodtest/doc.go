@@ -0,0 +1,22 @@
+// Package rodtest is an in-memory stand-in for rod, for unit tests which don't want to create a temporary Bolt file
+// for every test. Data is kept in maps and thrown away with the DB, so tests are quick and don't need cleaning up.
+//
+//	db := rodtest.New()
+//	err := db.Update(func(tx *rodtest.Tx) error {
+//	    return rodtest.PutJson(tx, "user", "chilts", &user)
+//	})
+//
+// Put, Get, All, Del and friends are simply rod's own functions, since a *rodtest.Tx is a rod.BackendTx. That also
+// means any other rod function can be given a *rodtest.Tx, so code under test which takes a rod.Tx works unchanged.
+//
+// Update runs against a copy of the data which only replaces the original if fn returns nil, so a failed Update is
+// rolled back just as with Bolt. Any number of Views may run at once, but only one Update.
+//
+// Seed loads a directory of JSON fixture files into a DB (or a Bolt database) in one go, so that tests can start from
+// the same data every time. For fuzz tests, Corpus and Gen make awkward locations, keys and values, and RoundTrip
+// checks that rod gives them back as they were put. For testing error handling, a FakeTx can be told to fail
+// particular calls, such as the third Put. And Snapshot compares a location with a golden file, to catch changes to
+// the layout of the data.
+//
+// (Ends)
+package rodtest
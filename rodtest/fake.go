@@ -0,0 +1,189 @@
+package rodtest
+
+import (
+	"errors"
+
+	"github.com/chilts/rod"
+)
+
+// ErrInjected is the error a FakeTx fails with, unless it was told to fail with another.
+var ErrInjected = errors.New("injected failure")
+
+// Op is a kind of call a FakeTx can be told to fail.
+type Op string
+
+// The calls a FakeTx can fail, which are those of a rod.Bucket (or rod.BackendTx) which return an error.
+const (
+	OpPut          Op = "Put"
+	OpDelete       Op = "Delete"
+	OpCreateBucket Op = "CreateBucket"
+	OpNextSequence Op = "NextSequence"
+)
+
+// Call is a single call made through a FakeTx, as given to FailFunc and recorded in Calls.
+type Call struct {
+	Op Op
+
+	// N counts the calls of this Op, starting at 1.
+	N int
+
+	// Location is the bucket the call was made on (which is empty when creating a top-level bucket), and Key is the
+	// key or the name of the bucket being created. Key is empty for NextSequence.
+	Location string
+	Key      string
+}
+
+// FakeTx is a writable rod.BackendTx kept in memory, which can be told to fail particular calls so that the error
+// handling in code using rod can be tested without a database:
+//
+//	tx := rodtest.NewFakeTx().Fail(rodtest.OpPut, 3, nil)
+//	err := importUsers(tx, users) // the third Put fails with ErrInjected
+//
+// Calls are counted at the level of buckets, so a single rod.Put is one OpPut (along with an OpCreateBucket for each
+// bucket in its location). A FakeTx isn't part of a DB and nothing is ever rolled back, so anything put before a
+// failure is still there afterwards for the test to look at.
+type FakeTx struct {
+	tx    *Tx
+	fails []func(c Call) error
+	count map[Op]int
+	calls []Call
+}
+
+// NewFakeTx returns an empty FakeTx which doesn't fail anything yet.
+func NewFakeTx() *FakeTx {
+	root := newBucket()
+	root.writable = true
+	return &FakeTx{tx: &Tx{root: root, writable: true}, count: map[Op]int{}}
+}
+
+// Fail makes the nth call of op fail with err, or every call from then on if n is 0. If err is nil then it fails with
+// ErrInjected. It returns the FakeTx, so calls can be chained.
+func (f *FakeTx) Fail(op Op, n int, err error) *FakeTx {
+	if err == nil {
+		err = ErrInjected
+	}
+	return f.FailFunc(func(c Call) error {
+		if c.Op == op && (n == 0 || c.N == n) {
+			return err
+		}
+		return nil
+	})
+}
+
+// FailFunc calls fn before each call, which then fails with whatever fn returns unless it is nil. It returns the
+// FakeTx, so calls can be chained.
+//
+//	tx.FailFunc(func(c rodtest.Call) error {
+//	    if c.Op == rodtest.OpPut && c.Location == "users" && c.Key == "bob" {
+//	        return bolt.ErrTxClosed
+//	    }
+//	    return nil
+//	})
+func (f *FakeTx) FailFunc(fn func(c Call) error) *FakeTx {
+	f.fails = append(f.fails, fn)
+	return f
+}
+
+// Calls returns every call made through the FakeTx so far, in order, including those which failed.
+func (f *FakeTx) Calls() []Call {
+	return append([]Call(nil), f.calls...)
+}
+
+// call records a call and returns the error it should fail with, if any.
+func (f *FakeTx) call(op Op, location, key string) error {
+	f.count[op]++
+	c := Call{Op: op, N: f.count[op], Location: location, Key: key}
+	f.calls = append(f.calls, c)
+	for _, fn := range f.fails {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writable is always true.
+func (f *FakeTx) Writable() bool {
+	return true
+}
+
+// Bucket returns the top-level bucket with this name, or nil if it doesn't exist.
+func (f *FakeTx) Bucket(name []byte) rod.Bucket {
+	return f.wrap(f.tx.Bucket(name), string(name))
+}
+
+// CreateBucketIfNotExists returns the top-level bucket with this name, creating it first if need be.
+func (f *FakeTx) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	if err := f.call(OpCreateBucket, "", string(name)); err != nil {
+		return nil, err
+	}
+	b, err := f.tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.wrap(b, string(name)), nil
+}
+
+// wrap makes b a fakeBucket at location, keeping a missing bucket nil.
+func (f *FakeTx) wrap(b rod.Bucket, location string) rod.Bucket {
+	if b == nil {
+		return nil
+	}
+	return &fakeBucket{b: b, f: f, location: location}
+}
+
+// fakeBucket passes each call on to the bucket underneath, unless the FakeTx says it should fail.
+type fakeBucket struct {
+	b        rod.Bucket
+	f        *FakeTx
+	location string
+}
+
+func (b *fakeBucket) Get(key []byte) []byte {
+	return b.b.Get(key)
+}
+
+func (b *fakeBucket) Put(key, value []byte) error {
+	if err := b.f.call(OpPut, b.location, string(key)); err != nil {
+		return err
+	}
+	return b.b.Put(key, value)
+}
+
+func (b *fakeBucket) Delete(key []byte) error {
+	if err := b.f.call(OpDelete, b.location, string(key)); err != nil {
+		return err
+	}
+	return b.b.Delete(key)
+}
+
+func (b *fakeBucket) Bucket(name []byte) rod.Bucket {
+	return b.f.wrap(b.b.Bucket(name), b.nested(name))
+}
+
+func (b *fakeBucket) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	if err := b.f.call(OpCreateBucket, b.location, string(name)); err != nil {
+		return nil, err
+	}
+	nested, err := b.b.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return b.f.wrap(nested, b.nested(name)), nil
+}
+
+func (b *fakeBucket) NextSequence() (uint64, error) {
+	if err := b.f.call(OpNextSequence, b.location, ""); err != nil {
+		return 0, err
+	}
+	return b.b.NextSequence()
+}
+
+func (b *fakeBucket) Cursor() rod.Cursor {
+	return b.b.Cursor()
+}
+
+// nested returns the location of the bucket called name within b.
+func (b *fakeBucket) nested(name []byte) string {
+	return b.location + "." + string(name)
+}
@@ -0,0 +1,82 @@
+package rodtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chilts/rod"
+)
+
+func TestFakeTx(t *testing.T) {
+	t.Run("Fail", func(t *testing.T) {
+		tx := NewFakeTx().Fail(OpPut, 3, nil)
+
+		var errs []error
+		for _, key := range []string{"a", "b", "c", "d"} {
+			errs = append(errs, rod.PutString(tx, "users", key, key))
+		}
+		if errs[0] != nil || errs[1] != nil || !errors.Is(errs[2], ErrInjected) || errs[3] != nil {
+			t.Fatalf("Only the third Put should have failed: %v", errs)
+		}
+
+		keys, err := rod.AllKeys(tx, "users")
+		check(t, err)
+		if len(keys) != 3 {
+			t.Fatalf("Expected the other three keys to be there, not %v", keys)
+		}
+	})
+
+	t.Run("Fail - every call", func(t *testing.T) {
+		boom := errors.New("boom")
+		tx := NewFakeTx().Fail(OpDelete, 0, boom)
+		check(t, rod.PutString(tx, "users", "chilts", "x"))
+		for i := 0; i < 2; i++ {
+			if err := rod.Del(tx, "users", "chilts"); !errors.Is(err, boom) {
+				t.Fatalf("Every Del should fail, not %v", err)
+			}
+		}
+	})
+
+	t.Run("FailFunc and Calls", func(t *testing.T) {
+		tx := NewFakeTx().FailFunc(func(c Call) error {
+			if c.Op == OpCreateBucket && c.Location == "users" && c.Key == "bob" {
+				return ErrInjected
+			}
+			return nil
+		})
+
+		check(t, rod.PutString(tx, "users.chilts", "email", "andy@example.com"))
+		if err := rod.PutString(tx, "users.bob", "email", "bob@example.com"); !errors.Is(err, ErrInjected) {
+			t.Fatalf("Creating users.bob should have failed, not %v", err)
+		}
+
+		calls := tx.Calls()
+		want := []Call{
+			{Op: OpCreateBucket, N: 1, Key: "users"},
+			{Op: OpCreateBucket, N: 2, Location: "users", Key: "chilts"},
+			{Op: OpPut, N: 1, Location: "users.chilts", Key: "email"},
+			{Op: OpCreateBucket, N: 3, Key: "users"},
+			{Op: OpCreateBucket, N: 4, Location: "users", Key: "bob"},
+		}
+		if len(calls) != len(want) {
+			t.Fatalf("Unexpected calls %v", calls)
+		}
+		for i := range want {
+			if calls[i] != want[i] {
+				t.Fatalf("Call %d was %#v, not %#v", i, calls[i], want[i])
+			}
+		}
+	})
+
+	t.Run("NextSequence", func(t *testing.T) {
+		tx := NewFakeTx().Fail(OpNextSequence, 2, nil)
+		b, err := tx.CreateBucketIfNotExists([]byte("ids"))
+		check(t, err)
+		if _, err := b.NextSequence(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := b.NextSequence(); !errors.Is(err, ErrInjected) {
+			t.Fatalf("The second NextSequence should have failed, not %v", err)
+		}
+	})
+}
@@ -0,0 +1,224 @@
+package rodtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+
+	"github.com/chilts/rod"
+)
+
+// ErrRoundTrip is returned by RoundTrip when rod doesn't give back what was put.
+var ErrRoundTrip = errors.New("round trip failed")
+
+// Case is one location, key and value to put into rod and read back.
+type Case struct {
+	Location string
+	Key      string
+	Value    []byte
+}
+
+// Corpus returns a set of awkward cases, for seeding a fuzz test: locations with dots at either end or empty segments,
+// empty and oversized keys, keys and values which aren't valid UTF-8, and huge values. Some of them rod should refuse,
+// and the rest it should store exactly.
+//
+//	func FuzzUsers(f *testing.F) {
+//	    for _, c := range rodtest.Corpus() {
+//	        f.Add(c.Location, c.Key, c.Value)
+//	    }
+//	    f.Fuzz(func(t *testing.T, location, key string, value []byte) {
+//	        db := rodtest.New()
+//	        err := db.Update(func(tx *rodtest.Tx) error {
+//	            return rodtest.RoundTrip(tx, rodtest.Case{Location: location, Key: key, Value: value})
+//	        })
+//	        if err != nil {
+//	            t.Fatal(err)
+//	        }
+//	    })
+//	}
+func Corpus() []Case {
+	return []Case{
+		{"users", "chilts", []byte(`{"Username":"chilts"}`)},
+		{"users.chilts.posts", "hello", []byte("Hello, World!")},
+		{"users", "empty", []byte{}},
+		{"users", "nil", nil},
+		{"", "key", []byte("no location")},
+		{".", "key", []byte("only a dot")},
+		{".users", "key", []byte("leading dot")},
+		{"users.", "key", []byte("trailing dot")},
+		{"users..chilts", "key", []byte("empty segment")},
+		{"users", "", []byte("no key")},
+		{"users", "a.b.c", []byte("dots in the key")},
+		{"users", "\xff\xfe", []byte("invalid UTF-8 key")},
+		{"users", "nul\x00key", []byte("NUL in the key")},
+		{"\xff.\xfe", "key", []byte("invalid UTF-8 location")},
+		{"users", "binary", []byte{0xff, 0x00, 0xfe, 0x80}},
+		{"users", "huge", bytes.Repeat([]byte("x"), 1<<20)},
+		{"users", strings.Repeat("k", 40000), []byte("oversized key")},
+		{"users", "日本語", []byte("ユニコード")},
+	}
+}
+
+// Gen makes random locations, keys and values, a good share of which are the awkward sort found in Corpus. A Gen
+// made with the same seed always makes the same things, so a failure can be repeated.
+type Gen struct {
+	r *rand.Rand
+}
+
+// NewGen returns a Gen seeded with seed.
+func NewGen(seed int64) *Gen {
+	return &Gen{r: rand.New(rand.NewSource(seed))}
+}
+
+// GenFrom returns a Gen seeded from data, such as the []byte a fuzz test is given, so that the fuzzer can steer it.
+func GenFrom(data []byte) *Gen {
+	h := fnv.New64a()
+	h.Write(data)
+	return NewGen(int64(h.Sum64()))
+}
+
+// pick returns one of options, at random.
+func (g *Gen) pick(options ...string) string {
+	return options[g.r.Intn(len(options))]
+}
+
+// name returns a random name for a bucket or key, which is usually plain but may be any bytes at all.
+func (g *Gen) name() string {
+	switch g.r.Intn(10) {
+	case 0:
+		return string(g.bytes(1 + g.r.Intn(8)))
+	case 1:
+		return g.pick("日本語", "emoji-\U0001F600", "nul\x00", "\xff", "a b", "'quoted'", `"`, "_")
+	}
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789-_"
+	n := 1 + g.r.Intn(12)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[g.r.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// bytes returns n random bytes.
+func (g *Gen) bytes(n int) []byte {
+	b := make([]byte, n)
+	g.r.Read(b)
+	return b
+}
+
+// Location returns a random location, of one to four buckets. About one in five is invalid, being empty or having a
+// dot at either end or an empty segment.
+func (g *Gen) Location() string {
+	names := make([]string, 1+g.r.Intn(4))
+	for i := range names {
+		names[i] = g.name()
+	}
+	location := strings.Join(names, ".")
+
+	if g.r.Intn(5) == 0 {
+		switch g.r.Intn(4) {
+		case 0:
+			return g.pick("", ".", "..")
+		case 1:
+			return "." + location
+		case 2:
+			return location + "."
+		default:
+			return strings.Replace(location+".x", ".", "..", 1)
+		}
+	}
+	return location
+}
+
+// Key returns a random key. Now and then it is empty, has dots in it, or is too long for Bolt.
+func (g *Gen) Key() string {
+	switch g.r.Intn(20) {
+	case 0:
+		return ""
+	case 1:
+		return g.name() + "." + g.name()
+	case 2:
+		return strings.Repeat("k", 32768+g.r.Intn(1024))
+	}
+	return g.name()
+}
+
+// Value returns a random value. It is usually small JSON, but may be empty, binary, text which isn't valid UTF-8, or
+// (rarely) as much as a megabyte.
+func (g *Gen) Value() []byte {
+	switch g.r.Intn(10) {
+	case 0:
+		return []byte{}
+	case 1:
+		return g.bytes(g.r.Intn(64))
+	case 2:
+		return append([]byte("text \xff\xfe "), g.name()...)
+	case 3:
+		if g.r.Intn(10) == 0 {
+			return bytes.Repeat(g.bytes(1+g.r.Intn(16)), 1<<16)
+		}
+		return g.bytes(g.r.Intn(4096))
+	}
+	return []byte(fmt.Sprintf(`{"name":%q,"n":%d}`, g.name(), g.r.Int63()))
+}
+
+// Case returns a random Case.
+func (g *Gen) Case() Case {
+	return Case{Location: g.Location(), Key: g.Key(), Value: g.Value()}
+}
+
+// RoundTrip puts c.Value at c.Location and c.Key, then checks that Get gives back exactly the same bytes and that
+// AllKeys and AllValues list it. tx must be writable, and the key shouldn't be there yet. If rod refuses the location
+// or key then RoundTrip checks that nothing was stored, and returns nil since refusing bad input is fine too. Anything
+// else wrong is an ErrRoundTrip saying what happened.
+func RoundTrip(tx rod.Tx, c Case) error {
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %q/%q: %s", ErrRoundTrip, c.Location, c.Key, fmt.Sprintf(format, args...))
+	}
+
+	if err := rod.Put(tx, c.Location, c.Key, c.Value); err != nil {
+		value, found, getErr := rod.GetFound(tx, c.Location, c.Key)
+		if getErr == nil && found {
+			return fail("Put failed with %v, but Get found %d bytes", err, len(value))
+		}
+		return nil
+	}
+
+	value, found, err := rod.GetFound(tx, c.Location, c.Key)
+	if err != nil {
+		return fail("Get failed after a Put: %v", err)
+	}
+	if !found {
+		return fail("Get didn't find the key after a Put")
+	}
+	if !bytes.Equal(value, c.Value) {
+		return fail("Get gave back %d different bytes to the %d put", len(value), len(c.Value))
+	}
+
+	keys, err := rod.AllKeys(tx, c.Location)
+	if err != nil {
+		return fail("AllKeys failed: %v", err)
+	}
+	listed := false
+	for _, key := range keys {
+		listed = listed || key == c.Key
+	}
+	if !listed {
+		return fail("AllKeys didn't list the key")
+	}
+
+	// AllValues skips nested buckets, so its values don't line up with the keys, but one of them must be this
+	values, err := rod.AllValues(tx, c.Location)
+	if err != nil {
+		return fail("AllValues failed: %v", err)
+	}
+	for _, v := range values {
+		if bytes.Equal(v, c.Value) {
+			return nil
+		}
+	}
+	return fail("AllValues didn't give back the value")
+}
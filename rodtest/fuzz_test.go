@@ -0,0 +1,72 @@
+package rodtest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestFuzz(t *testing.T) {
+	t.Run("Corpus", func(t *testing.T) {
+		db := New()
+		for _, c := range Corpus() {
+			check(t, db.Update(func(tx *Tx) error {
+				return RoundTrip(tx, c)
+			}))
+		}
+	})
+
+	t.Run("Gen", func(t *testing.T) {
+		a, b := NewGen(42), NewGen(42)
+		for i := 0; i < 100; i++ {
+			if ca, cb := a.Case(), b.Case(); ca.Location != cb.Location || ca.Key != cb.Key || string(ca.Value) != string(cb.Value) {
+				t.Fatal("Two Gens with the same seed should make the same cases")
+			}
+		}
+		if GenFrom([]byte("x")).Key() != GenFrom([]byte("x")).Key() {
+			t.Fatal("Two Gens from the same data should make the same keys")
+		}
+	})
+
+	t.Run("RoundTrip - Bolt", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "rodtest-")
+		check(t, err)
+		defer os.RemoveAll(dir)
+		db, err := bolt.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+		check(t, err)
+		defer db.Close()
+
+		cases := Corpus()
+		g := NewGen(1)
+		for i := 0; i < 200; i++ {
+			cases = append(cases, g.Case())
+		}
+		for _, c := range cases {
+			check(t, db.Update(func(tx *bolt.Tx) error {
+				return RoundTrip(tx, c)
+			}))
+		}
+	})
+
+	t.Run("RoundTrip - failure", func(t *testing.T) {
+		db := New()
+		check(t, db.View(func(tx *Tx) error {
+			// a read-only transaction makes Put fail, but there's nothing there to find so that's fine
+			return RoundTrip(tx, Case{Location: "users", Key: "chilts", Value: []byte("x")})
+		}))
+
+		check(t, db.Update(func(tx *Tx) error {
+			return PutString(tx, "users", "chilts", "before")
+		}))
+		err := db.View(func(tx *Tx) error {
+			return RoundTrip(tx, Case{Location: "users", Key: "chilts", Value: []byte("after")})
+		})
+		if !errors.Is(err, ErrRoundTrip) {
+			t.Fatalf("Expected ErrRoundTrip when Put fails but the key is there, not %v", err)
+		}
+	})
+}
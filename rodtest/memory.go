@@ -0,0 +1,241 @@
+package rodtest
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/chilts/rod"
+)
+
+// ErrTxNotWritable is returned when writing within a View.
+var ErrTxNotWritable = errors.New("tx not writable")
+
+// ErrIncompatibleValue is returned when a key is used as both a value and a bucket.
+var ErrIncompatibleValue = errors.New("incompatible value")
+
+// DB is an in-memory database.
+type DB struct {
+	mu   sync.RWMutex
+	root *Bucket
+}
+
+// New returns a new, empty, in-memory database.
+func New() *DB {
+	return &DB{root: newBucket()}
+}
+
+// Update runs fn inside a read-write transaction. If fn returns an error then none of its changes are kept.
+func (db *DB) Update(fn func(tx *Tx) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := &Tx{root: db.root.clone(), writable: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	tx.root.freeze()
+	db.root = tx.root
+	return nil
+}
+
+// View runs fn inside a read-only transaction.
+func (db *DB) View(fn func(tx *Tx) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return fn(&Tx{root: db.root})
+}
+
+// Tx is a transaction on a DB. It must only be used within the life of the Update or View it was given to. It is a
+// rod.BackendTx, so can be given to any of rod's functions in place of a *bolt.Tx.
+type Tx struct {
+	root     *Bucket
+	writable bool
+}
+
+// Writable tells you whether this transaction came from Update.
+func (tx *Tx) Writable() bool {
+	return tx.writable
+}
+
+// Bucket returns the top-level bucket with this name, or nil if it doesn't exist.
+func (tx *Tx) Bucket(name []byte) rod.Bucket {
+	return tx.root.Bucket(name)
+}
+
+// CreateBucketIfNotExists returns the top-level bucket with this name, creating it first if need be.
+func (tx *Tx) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	if !tx.writable {
+		return nil, ErrTxNotWritable
+	}
+	return tx.root.CreateBucketIfNotExists(name)
+}
+
+// Bucket holds key/values and nested buckets, just like a bolt.Bucket.
+type Bucket struct {
+	writable bool
+	values   map[string][]byte
+	buckets  map[string]*Bucket
+	sequence uint64
+}
+
+func newBucket() *Bucket {
+	return &Bucket{values: make(map[string][]byte), buckets: make(map[string]*Bucket)}
+}
+
+// clone returns a writable deep copy of b. Values are never changed in place so they are shared.
+func (b *Bucket) clone() *Bucket {
+	c := &Bucket{
+		writable: true,
+		values:   make(map[string][]byte, len(b.values)),
+		buckets:  make(map[string]*Bucket, len(b.buckets)),
+		sequence: b.sequence,
+	}
+	for k, v := range b.values {
+		c.values[k] = v
+	}
+	for k, nested := range b.buckets {
+		c.buckets[k] = nested.clone()
+	}
+	return c
+}
+
+// freeze makes b and all of its nested buckets read-only again, once they have been committed.
+func (b *Bucket) freeze() {
+	b.writable = false
+	for _, nested := range b.buckets {
+		nested.freeze()
+	}
+}
+
+// Get returns the value at key, or nil if it doesn't exist (or is a nested bucket).
+func (b *Bucket) Get(key []byte) []byte {
+	return b.values[string(key)]
+}
+
+// Put sets the value at key. The value is copied.
+func (b *Bucket) Put(key, value []byte) error {
+	if !b.writable {
+		return ErrTxNotWritable
+	}
+	if _, ok := b.buckets[string(key)]; ok {
+		return ErrIncompatibleValue
+	}
+	b.values[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+// Delete removes the value at key, if there is one.
+func (b *Bucket) Delete(key []byte) error {
+	if !b.writable {
+		return ErrTxNotWritable
+	}
+	if _, ok := b.buckets[string(key)]; ok {
+		return ErrIncompatibleValue
+	}
+	delete(b.values, string(key))
+	return nil
+}
+
+// Bucket returns the nested bucket with this name, or nil if it doesn't exist.
+func (b *Bucket) Bucket(name []byte) rod.Bucket {
+	nested, ok := b.buckets[string(name)]
+	if !ok {
+		return nil
+	}
+	return nested
+}
+
+// CreateBucketIfNotExists returns the nested bucket with this name, creating it first if need be.
+func (b *Bucket) CreateBucketIfNotExists(name []byte) (rod.Bucket, error) {
+	if !b.writable {
+		return nil, ErrTxNotWritable
+	}
+	if len(name) == 0 {
+		return nil, errors.New("bucket name required")
+	}
+	if nested, ok := b.buckets[string(name)]; ok {
+		return nested, nil
+	}
+	if _, ok := b.values[string(name)]; ok {
+		return nil, ErrIncompatibleValue
+	}
+
+	nested := newBucket()
+	nested.writable = true
+	b.buckets[string(name)] = nested
+	return nested, nil
+}
+
+// NextSequence returns the next number in the bucket's sequence, starting at 1.
+func (b *Bucket) NextSequence() (uint64, error) {
+	if !b.writable {
+		return 0, ErrTxNotWritable
+	}
+	b.sequence++
+	return b.sequence, nil
+}
+
+// Cursor returns a cursor over the bucket's keys, in byte order. As with Bolt, nested buckets are included with a nil
+// value. The cursor sees the keys as they were when it was made.
+func (b *Bucket) Cursor() rod.Cursor {
+	keys := make([]string, 0, len(b.values)+len(b.buckets))
+	for k := range b.values {
+		keys = append(keys, k)
+	}
+	for k := range b.buckets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return &Cursor{bucket: b, keys: keys, pos: -1}
+}
+
+// Cursor moves over the keys of a Bucket.
+type Cursor struct {
+	bucket *Bucket
+	keys   []string
+	pos    int
+}
+
+func (c *Cursor) at(pos int) ([]byte, []byte) {
+	if pos < 0 || pos >= len(c.keys) {
+		c.pos = len(c.keys)
+		return nil, nil
+	}
+	c.pos = pos
+	k := c.keys[pos]
+	return []byte(k), c.bucket.values[k]
+}
+
+// First moves to the first key.
+func (c *Cursor) First() ([]byte, []byte) {
+	return c.at(0)
+}
+
+// Last moves to the last key.
+func (c *Cursor) Last() ([]byte, []byte) {
+	return c.at(len(c.keys) - 1)
+}
+
+// Next moves to the next key.
+func (c *Cursor) Next() ([]byte, []byte) {
+	return c.at(c.pos + 1)
+}
+
+// Prev moves to the previous key.
+func (c *Cursor) Prev() ([]byte, []byte) {
+	if c.pos <= 0 {
+		c.pos = -1
+		return nil, nil
+	}
+	return c.at(c.pos - 1)
+}
+
+// Seek moves to the first key which is the same as or after seek.
+func (c *Cursor) Seek(seek []byte) ([]byte, []byte) {
+	return c.at(sort.Search(len(c.keys), func(i int) bool {
+		return bytes.Compare([]byte(c.keys[i]), seek) >= 0
+	}))
+}
@@ -0,0 +1,50 @@
+package rodtest
+
+import (
+	"github.com/chilts/rod"
+)
+
+// Put is the same as rod.Put.
+func Put(tx *Tx, location, key string, value []byte) error {
+	return rod.Put(tx, location, key, value)
+}
+
+// PutString is the same as rod.PutString.
+func PutString(tx *Tx, location, key, value string) error {
+	return rod.PutString(tx, location, key, value)
+}
+
+// PutJson is the same as rod.PutJson.
+func PutJson(tx *Tx, location, key string, v interface{}) error {
+	return rod.PutJson(tx, location, key, v)
+}
+
+// Get is the same as rod.Get.
+func Get(tx *Tx, location, key string) ([]byte, error) {
+	return rod.Get(tx, location, key)
+}
+
+// GetString is the same as rod.GetString.
+func GetString(tx *Tx, location, key string) (string, error) {
+	return rod.GetString(tx, location, key)
+}
+
+// GetJson is the same as rod.GetJson.
+func GetJson(tx *Tx, location, key string, v interface{}) error {
+	return rod.GetJson(tx, location, key, v)
+}
+
+// Del is the same as rod.Del.
+func Del(tx *Tx, location, key string) error {
+	return rod.Del(tx, location, key)
+}
+
+// AllKeys is the same as rod.AllKeys.
+func AllKeys(tx *Tx, location string) ([]string, error) {
+	return rod.AllKeys(tx, location)
+}
+
+// All is the same as rod.All.
+func All(tx *Tx, location string, to interface{}, opts ...rod.QueryOption) error {
+	return rod.All(tx, location, to, opts...)
+}
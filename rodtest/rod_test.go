@@ -0,0 +1,162 @@
+package rodtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chilts/rod"
+)
+
+type User struct {
+	Username string
+	Logins   int
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRodtest(t *testing.T) {
+	db := New()
+
+	t.Run("Put, Get and Del", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			check(t, PutString(tx, "users.chilts", "email", "andy@example.com"))
+
+			email, err := GetString(tx, "users.chilts", "email")
+			check(t, err)
+			if email != "andy@example.com" {
+				t.Fatalf("Expected the email back but got '%s'", email)
+			}
+
+			check(t, Del(tx, "users.chilts", "email"))
+			value, err := Get(tx, "users.chilts", "email")
+			check(t, err)
+			if value != nil {
+				t.Fatal("Expected the email to have been deleted")
+			}
+
+			// missing buckets are not an error
+			value, err = Get(tx, "does.not.exist", "email")
+			check(t, err)
+			if value != nil {
+				t.Fatal("Expected nothing from a missing bucket")
+			}
+			check(t, Del(tx, "does.not.exist", "email"))
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("PutJson, GetJson, All and AllKeys", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			check(t, PutJson(tx, "user", "bob", User{"bob", 2}))
+			check(t, PutJson(tx, "user", "alice", User{"alice", 1}))
+
+			var bob User
+			check(t, GetJson(tx, "user", "bob", &bob))
+			if bob.Logins != 2 {
+				t.Fatalf("Expected bob to have 2 logins but got %d", bob.Logins)
+			}
+
+			var users []*User
+			check(t, All(tx, "user", &users))
+			if len(users) != 2 || users[0].Username != "alice" {
+				t.Fatalf("Expected alice then bob: %#v", users)
+			}
+
+			// a nested bucket is included by AllKeys, just as with rod
+			check(t, PutString(tx, "user.carol", "email", "carol@example.com"))
+
+			keys, err := AllKeys(tx, "user")
+			check(t, err)
+			if len(keys) != 3 || keys[2] != "carol" {
+				t.Fatalf("Unexpected keys: %v", keys)
+			}
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("Failed updates are rolled back", func(t *testing.T) {
+		failed := errors.New("failed")
+		err := db.Update(func(tx *Tx) error {
+			check(t, PutString(tx, "config", "name", "rod"))
+			return failed
+		})
+		if err != failed {
+			t.Fatalf("Expected the update to fail but got %v", err)
+		}
+
+		err = db.View(func(tx *Tx) error {
+			name, err := GetString(tx, "config", "name")
+			check(t, err)
+			if name != "" {
+				t.Fatalf("Expected the put to have been rolled back but got '%s'", name)
+			}
+
+			err = PutString(tx, "users.chilts", "name", "chilts")
+			if !errors.Is(err, ErrTxNotWritable) {
+				t.Fatalf("Expected ErrTxNotWritable but got %v", err)
+			}
+			return nil
+		})
+		check(t, err)
+	})
+
+	t.Run("Errors are the same as rod's", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			return Put(tx, "", "key", nil)
+		})
+		if !errors.Is(err, rod.ErrLocationMustHaveAtLeastOneBucket) {
+			t.Fatalf("Expected ErrLocationMustHaveAtLeastOneBucket but got %v", err)
+		}
+
+		err = db.Update(func(tx *Tx) error {
+			return Put(tx, "users..chilts", "key", nil)
+		})
+		if !errors.Is(err, rod.ErrInvalidLocationBucket) {
+			t.Fatalf("Expected ErrInvalidLocationBucket but got %v", err)
+		}
+
+		err = db.View(func(tx *Tx) error {
+			var users []User
+			return All(tx, "user", users)
+		})
+		var rodErr *rod.Error
+		if !errors.As(err, &rodErr) || rodErr.Op != "All" || !errors.Is(err, rod.ErrSlicePtrNeeded) {
+			t.Fatalf("Expected ErrSlicePtrNeeded from All but got %v", err)
+		}
+	})
+
+	t.Run("Any rod function can be used", func(t *testing.T) {
+		err := db.Update(func(tx *Tx) error {
+			check(t, PutJson(tx, "scores", "a", User{"a", 3}))
+			check(t, PutJson(tx, "scores", "b", User{"b", 1}))
+			check(t, PutJson(tx, "scores", "c", User{"c", 2}))
+
+			n, err := rod.Count(tx, "scores")
+			check(t, err)
+			if n != 3 {
+				t.Fatalf("Expected 3 scores but got %d", n)
+			}
+
+			var users []User
+			check(t, All(tx, "scores", &users, rod.SortByDesc("Logins")))
+			if len(users) != 3 || users[0].Username != "a" || users[2].Username != "b" {
+				t.Fatalf("Users are not sorted by logins: %#v", users)
+			}
+
+			seq, err := rod.NextSequence(tx, "scores")
+			check(t, err)
+			if seq != 1 {
+				t.Fatalf("Expected the first sequence to be 1 but got %d", seq)
+			}
+			return nil
+		})
+		check(t, err)
+	})
+}
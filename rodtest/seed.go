@@ -0,0 +1,104 @@
+package rodtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+// ErrUnsupportedDB is returned by Seed if it is given something it can't start a transaction on.
+var ErrUnsupportedDB = errors.New("unsupported database type")
+
+// Seed loads every .json and .jsonl fixture file in fixtures into db, all in one transaction, so that each test
+// starts from the same data. The location comes from the file's path without its extension, with each '/' made a '.',
+// so both "users.json" and "users/admins.json" work, going into "users" and "users.admins".
+//
+// A .json file holds one object with a field for each key, and a .jsonl file has one {"key": ..., "value": ...} per
+// line. Each value is put as JSON, except that a string is put as its text (as with rod.ImportTree):
+//
+//	// fixtures/users.json
+//	{"chilts": {"Username": "chilts", "Logins": 1}, "bob": {"Username": "bob"}}
+//
+//	//go:embed fixtures
+//	var fixtures embed.FS
+//
+//	db := rodtest.New()
+//	err := rodtest.Seed(db, fixtures)
+//
+// Other files are skipped. Since the fixtures are usually embedded or in testdata, fs.Sub can be used to take off the
+// directory they are in. The db may be a *rodtest.DB, or a *bolt.DB or *rod.Store for integration tests. If any file
+// can't be loaded then nothing is, and the error says which file it was.
+func Seed(db interface{}, fixtures fs.FS) error {
+	fn := func(tx rod.Tx) error {
+		return fs.WalkDir(fixtures, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			ext := path.Ext(name)
+			if ext != ".json" && ext != ".jsonl" {
+				return nil
+			}
+			if err := seedFile(tx, fixtures, name); err != nil {
+				return fmt.Errorf("rodtest: seeding %s: %w", name, err)
+			}
+			return nil
+		})
+	}
+
+	switch db := db.(type) {
+	case *DB:
+		return db.Update(func(tx *Tx) error { return fn(tx) })
+	case rod.Updater:
+		return db.Update(func(tx *bolt.Tx) error { return fn(tx) })
+	}
+	return ErrUnsupportedDB
+}
+
+// seedFile loads the fixture file called name.
+func seedFile(tx rod.Tx, fixtures fs.FS, name string) error {
+	data, err := fs.ReadFile(fixtures, name)
+	if err != nil {
+		return err
+	}
+	ext := path.Ext(name)
+	location := strings.Replace(strings.TrimSuffix(name, ext), "/", ".", -1)
+
+	if ext == ".jsonl" {
+		// gather the lines into one object, as a .json file would have them
+		records := map[string]json.RawMessage{}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, 64<<20)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var record struct {
+				Key   *string
+				Value json.RawMessage
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if record.Key == nil || record.Value == nil {
+				return fmt.Errorf("line %d: a key and a value are needed", line)
+			}
+			records[*record.Key] = record.Value
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if data, err = json.Marshal(records); err != nil {
+			return err
+		}
+	}
+
+	return rod.ImportTree(tx, location, data, rod.ImportTreeDepth(0))
+}
@@ -0,0 +1,89 @@
+package rodtest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/boltdb/bolt"
+	"github.com/chilts/rod"
+)
+
+func TestSeed(t *testing.T) {
+	fixtures := fstest.MapFS{
+		"users.json":         {Data: []byte(`{"chilts": {"Username": "chilts", "Logins": 1}, "bob": {"Username": "bob"}}`)},
+		"users/admins.jsonl": {Data: []byte("{\"key\": \"chilts\", \"value\": true}\n\n{\"key\": \"motd\", \"value\": \"Hello\"}\n")},
+		"ReadMe.md":          {Data: []byte("# Fixtures")},
+	}
+
+	t.Run("Seed", func(t *testing.T) {
+		db := New()
+		check(t, Seed(db, fixtures))
+
+		check(t, db.View(func(tx *Tx) error {
+			var user User
+			check(t, GetJson(tx, "users", "chilts", &user))
+			if user.Logins != 1 {
+				t.Fatalf("Unexpected user %#v", user)
+			}
+
+			admin, err := GetString(tx, "users.admins", "chilts")
+			check(t, err)
+			motd, err := GetString(tx, "users.admins", "motd")
+			check(t, err)
+			if admin != "true" || motd != "Hello" {
+				t.Fatalf("Unexpected values %q and %q", admin, motd)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Seed - Bolt", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "rodtest-")
+		check(t, err)
+		defer os.RemoveAll(dir)
+		db, err := bolt.Open(filepath.Join(dir, "rod.db"), 0666, nil)
+		check(t, err)
+		defer db.Close()
+
+		check(t, Seed(db, fixtures))
+		check(t, db.View(func(tx *bolt.Tx) error {
+			keys, err := rod.AllKeys(tx, "users")
+			check(t, err)
+			if strings.Join(keys, ",") != "admins,bob,chilts" {
+				t.Fatalf("Unexpected keys %v", keys)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Seed - bad fixture", func(t *testing.T) {
+		db := New()
+		bad := fstest.MapFS{
+			"a.json": {Data: []byte(`{"x": 1}`)},
+			"b.json": {Data: []byte(`[1, 2]`)},
+		}
+		err := Seed(db, bad)
+		if !errors.Is(err, rod.ErrTreeNotObject) || !strings.Contains(err.Error(), "b.json") {
+			t.Fatalf("Expected an error for b.json, not %v", err)
+		}
+
+		// nothing should have been loaded
+		check(t, db.View(func(tx *Tx) error {
+			keys, err := AllKeys(tx, "a")
+			check(t, err)
+			if len(keys) != 0 {
+				t.Fatalf("Expected a to be rolled back, not %v", keys)
+			}
+			return nil
+		}))
+
+		if err := Seed("not a db", fixtures); err != ErrUnsupportedDB {
+			t.Fatalf("Expected ErrUnsupportedDB, not %v", err)
+		}
+	})
+}
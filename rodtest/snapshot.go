@@ -0,0 +1,107 @@
+package rodtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chilts/rod"
+)
+
+// update is the flag which makes Snapshot write its golden files rather than compare against them.
+var update = flag.Bool("rodtest.update", false, "write rodtest.Snapshot golden files instead of comparing against them")
+
+// Snapshot checks that the subtree at location matches the golden file at goldenPath, failing t with the first line
+// which differs if it doesn't. The subtree is written as indented JSON with every object's fields sorted (as
+// rod.ExportTree renders it), so the golden file is readable and shows changes to the layout of the data clearly in
+// a diff:
+//
+//	func TestSignup(t *testing.T) {
+//	    db := rodtest.New()
+//	    // ... sign a user up ...
+//	    db.View(func(tx *rodtest.Tx) error {
+//	        rodtest.Snapshot(t, tx, "users", "testdata/signup.golden.json")
+//	        return nil
+//	    })
+//	}
+//
+// Run the tests with -rodtest.update to write (or rewrite) the golden files, and check them in:
+//
+//	go test ./signup -rodtest.update
+func Snapshot(t testing.TB, tx rod.Tx, location, goldenPath string) {
+	t.Helper()
+
+	raw, err := rod.ExportTree(tx, location)
+	if err != nil {
+		t.Fatalf("rodtest: snapshot of %s: %v", location, err)
+	}
+	got, err := canonicalJson(raw)
+	if err != nil {
+		t.Fatalf("rodtest: snapshot of %s: %v", location, err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Fatalf("rodtest: %v", err)
+		}
+		if err := ioutil.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatalf("rodtest: %v", err)
+		}
+		t.Logf("rodtest: wrote snapshot of %s to %s", location, goldenPath)
+		return
+	}
+
+	want, err := ioutil.ReadFile(goldenPath)
+	if os.IsNotExist(err) {
+		t.Fatalf("rodtest: no golden file %s for %s, run with -rodtest.update to write it", goldenPath, location)
+	}
+	if err != nil {
+		t.Fatalf("rodtest: %v", err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+
+	// find the first line which differs, so the failure says where to look
+	gotLines := strings.Split(string(got), "\n")
+	wantLines := strings.Split(string(want), "\n")
+	for i := 0; ; i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w || i >= len(gotLines) || i >= len(wantLines) {
+			t.Fatalf("rodtest: snapshot of %s differs from %s at line %d:\n got: %s\nwant: %s\n"+
+				"(run with -rodtest.update to accept the change)", location, goldenPath, i+1, g, w)
+			return
+		}
+	}
+}
+
+// canonicalJson re-encodes raw indented, with every object's fields sorted, numbers as they were, and no escaping of
+// HTML characters.
+func canonicalJson(raw []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,103 @@
+package rodtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fatalTB records the failure Snapshot stops with, rather than failing the real test.
+type fatalTB struct {
+	testing.TB
+	failure string
+}
+
+func (tb *fatalTB) Helper() {}
+
+func (tb *fatalTB) Logf(format string, args ...interface{}) {}
+
+func (tb *fatalTB) Fatalf(format string, args ...interface{}) {
+	tb.failure = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// snapshotFailure runs Snapshot and returns how it failed, or "" if it didn't.
+func snapshotFailure(t *testing.T, db *DB, location, goldenPath string) string {
+	tb := &fatalTB{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.View(func(tx *Tx) error {
+			Snapshot(tb, tx, location, goldenPath)
+			return nil
+		})
+	}()
+	<-done
+	return tb.failure
+}
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rodtest-")
+	check(t, err)
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "testdata", "users.golden.json")
+
+	db := New()
+	check(t, db.Update(func(tx *Tx) error {
+		check(t, Put(tx, "users", "chilts", []byte(`{"Username":"chilts","Logins":1,"Admin":true,"Bio":"<b>hi</b>"}`)))
+		check(t, PutString(tx, "users.posts", "hello", "Hello, World!"))
+		return nil
+	}))
+
+	t.Run("Snapshot - update", func(t *testing.T) {
+		*update = true
+		defer func() { *update = false }()
+		check(t, db.View(func(tx *Tx) error {
+			Snapshot(t, tx, "users", golden)
+			return nil
+		}))
+
+		data, err := ioutil.ReadFile(golden)
+		check(t, err)
+		want := `{
+  "chilts": {
+    "Admin": true,
+    "Bio": "<b>hi</b>",
+    "Logins": 1,
+    "Username": "chilts"
+  },
+  "posts": {
+    "hello": "Hello, World!"
+  }
+}
+`
+		if string(data) != want {
+			t.Fatalf("Unexpected golden file:\n%s", data)
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		if failure := snapshotFailure(t, db, "users", golden); failure != "" {
+			t.Fatalf("The snapshot should match: %s", failure)
+		}
+
+		check(t, db.Update(func(tx *Tx) error {
+			return PutString(tx, "users.posts", "hello", "Goodbye")
+		}))
+		failure := snapshotFailure(t, db, "users", golden)
+		if !strings.Contains(failure, "line 9") || !strings.Contains(failure, "Goodbye") {
+			t.Fatalf("Expected the snapshot to differ at line 9, not %q", failure)
+		}
+	})
+
+	t.Run("Snapshot - no golden file", func(t *testing.T) {
+		failure := snapshotFailure(t, db, "users", filepath.Join(dir, "missing.json"))
+		if !strings.Contains(failure, "-rodtest.update") {
+			t.Fatalf("Expected to be told how to write the golden file, not %q", failure)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// rotateChunk is how many keys RotateKeys looks at in each transaction.
+const rotateChunk = 1000
+
+// RotateKeys re-encrypts every encrypted field (see SetEncryptionKey) which was encrypted with oldKey, so that it is
+// encrypted with newKey instead. Every bucket in the database is searched, and the values are rewritten in place
+// without being decoded into your types, so it doesn't need to know them. Only the string values in JSON documents are
+// looked at, so a key or a value which isn't JSON is never mistaken for an encrypted field, and fields encrypted with
+// any other key are left alone. It returns how many values were rewritten.
+//
+// The work is done in chunks of transactions rather than one large one, so the database isn't locked for the whole
+// time. Your application may keep running meanwhile, as long as it can read both keys:
+//
+//	rod.SetEncryptionKey(newKey)
+//	rod.AddDecryptionKey(oldKey)
+//	n, err := rod.RotateKeys(db, oldKey, newKey)
+//
+// If it fails part way through then just call it again, since the values already done no longer use oldKey.
+func RotateKeys(db *bolt.DB, oldKey, newKey []byte) (int, error) {
+	from, err := newEncryptionKey(oldKey)
+	if err != nil {
+		return 0, err
+	}
+	to, err := newEncryptionKey(newKey)
+	if err != nil {
+		return 0, err
+	}
+
+	// find every bucket first, as the list of names down to it
+	var buckets [][][]byte
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			buckets = append(buckets, nestedBuckets([][]byte{copyBytes(name)}, b)...)
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	marker := []byte(`"` + encryptedPrefix + from.id + ":")
+	rewritten := 0
+	for _, path := range buckets {
+		var after []byte
+		for done := false; !done; {
+			err := db.Update(func(tx *bolt.Tx) error {
+				b := tx.Bucket(path[0])
+				for _, name := range path[1:] {
+					if b == nil {
+						break
+					}
+					b = b.Bucket(name)
+				}
+				if b == nil {
+					done = true
+					return nil
+				}
+				location := string(bytes.Join(path, []byte(".")))
+
+				// find the values to change, then change them once we're done with the cursor
+				changes := make(map[string][]byte)
+				c := b.Cursor()
+				k, v := c.First()
+				if after != nil {
+					k, v = c.Seek(after)
+					if bytes.Equal(k, after) {
+						k, v = c.Next()
+					}
+				}
+				for n := 0; n < rotateChunk && k != nil; n++ {
+					// the marker is a quick way to skip values which can't have an encrypted field
+					if v != nil && bytes.Contains(v, marker) && json.Valid(v) {
+						value, err := reencrypt(v, from, to)
+						if err != nil {
+							return wrapErr("RotateKeys", location, string(k), err)
+						}
+						if value != nil {
+							changes[string(k)] = value
+						}
+					}
+					after = copyBytes(k)
+					k, v = c.Next()
+				}
+				if k == nil {
+					done = true
+				}
+
+				for key, value := range changes {
+					if err := putKey(tx, boltBucket{b}, location, []byte(key), value); err != nil {
+						return wrapErr("RotateKeys", location, key, err)
+					}
+				}
+				rewritten += len(changes)
+				return nil
+			})
+			if err != nil {
+				return rewritten, err
+			}
+		}
+	}
+
+	return rewritten, nil
+}
+
+// nestedBuckets returns path along with the path to every bucket nested within b.
+func nestedBuckets(path [][]byte, b *bolt.Bucket) [][][]byte {
+	paths := [][][]byte{path}
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v != nil {
+			continue
+		}
+		if nested := b.Bucket(k); nested != nil {
+			child := append(append([][]byte{}, path...), copyBytes(k))
+			paths = append(paths, nestedBuckets(child, nested)...)
+		}
+	}
+	return paths
+}
+
+// reencrypt rewrites every string value in the JSON document value which was encrypted with from, so it is encrypted
+// with to, leaving the rest of the document byte for byte as it was. Object keys are never rewritten. It returns nil
+// if nothing was encrypted with from.
+func reencrypt(value []byte, from, to *encryptionKey) ([]byte, error) {
+	prefix := encryptedPrefix + from.id + ":"
+	dec := json.NewDecoder(bytes.NewReader(value))
+
+	// one entry for each array or object we're in: an array, an object whose next string is a key, or an object whose
+	// next token is the value for that key
+	const (
+		inArray = iota
+		nextKey
+		nextValue
+	)
+	var within []int
+	var out []byte
+	last := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			within = within[:len(within)-1]
+			continue
+		}
+		if n := len(within); n > 0 {
+			switch within[n-1] {
+			case nextKey:
+				within[n-1] = nextValue
+				continue
+			case nextValue:
+				within[n-1] = nextKey
+			}
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			if t == '{' {
+				within = append(within, nextKey)
+			} else {
+				within = append(within, inArray)
+			}
+		case string:
+			if !strings.HasPrefix(t, prefix) {
+				continue
+			}
+			// encrypted fields never need escaping, so the string is just as it was in value, ending where dec is
+			end := int(dec.InputOffset())
+			start := end - len(t) - 2
+			if start < last || string(value[start+1:end-1]) != t {
+				return nil, ErrDecryptionFailed
+			}
+
+			plain, err := from.decrypt(t[len(prefix):])
+			if err != nil {
+				return nil, err
+			}
+			encrypted, err := to.encrypt(plain)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value[last:start+1]...)
+			out = append(out, encrypted...)
+			last = end - 1
+		}
+	}
+
+	if out == nil {
+		return nil, nil
+	}
+	return append(out, value[last:]...), nil
+}
@@ -0,0 +1,58 @@
+package rod
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// ErrNegativeSample is returned by Sample if it is asked for a negative number of keys.
+var ErrNegativeSample = errors.New("sample size must not be negative")
+
+// Sample returns up to n keys chosen (approximately) uniformly at random from the bucket at location, skipping any
+// nested buckets. Reservoir sampling is used so the whole bucket is walked once with a cursor but only n keys are ever
+// held. If the bucket has n keys or fewer then all of them are returned. The keys are returned in no particular order.
+// A negative n returns ErrNegativeSample.
+func Sample(tx Tx, location string, n int) ([]string, error) {
+	if n < 0 {
+		return nil, wrapErr("Sample", location, "", ErrNegativeSample)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return nil, wrapErr("Sample", location, "", err)
+	}
+	if b == nil || n == 0 {
+		return nil, nil
+	}
+
+	// don't make room for more keys than there are
+	count, err := Count(tx, location)
+	if err != nil {
+		return nil, wrapErr("Sample", location, "", err)
+	}
+	if count < n {
+		n = count
+	}
+
+	sample := make([]string, 0, n)
+	seen := 0
+
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		seen++
+
+		// fill the reservoir first, then replace entries with decreasing probability
+		if len(sample) < n {
+			sample = append(sample, string(k))
+			continue
+		}
+		if i := rand.Intn(seen); i < n {
+			sample[i] = string(k)
+		}
+	}
+
+	return sample, nil
+}
@@ -0,0 +1,63 @@
+package rod
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSample(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		for i := 0; i < 100; i++ {
+			check(PutString(tx, "number", fmt.Sprintf("%03d", i), fmt.Sprintf("%d", i)))
+		}
+		return nil
+	})
+	check(err)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		keys, err := Sample(tx, "number", 10)
+		check(err)
+		if len(keys) != 10 {
+			t.Fatalf("Expected 10 keys but got %d", len(keys))
+		}
+		unique := make(map[string]bool)
+		for _, key := range keys {
+			if unique[key] {
+				t.Fatalf("Key %s was sampled twice", key)
+			}
+			unique[key] = true
+		}
+
+		keys, err = Sample(tx, "number", 1000)
+		check(err)
+		if len(keys) != 100 {
+			t.Fatalf("Asking for more keys than exist should return them all, but got %d", len(keys))
+		}
+
+		keys, err = Sample(tx, "number", math.MaxInt32)
+		check(err)
+		if len(keys) != 100 || cap(keys) != 100 {
+			t.Fatalf("A huge sample should only make room for the keys there are, but got %d of %d", len(keys), cap(keys))
+		}
+
+		if _, err := Sample(tx, "number", -1); !errors.Is(err, ErrNegativeSample) {
+			t.Fatalf("Expected ErrNegativeSample but got %v", err)
+		}
+
+		keys, err = Sample(tx, "does-not-exist", 10)
+		check(err)
+		if len(keys) != 0 {
+			t.Fatalf("Expected no keys from a missing bucket but got %d", len(keys))
+		}
+
+		return nil
+	})
+	check(err)
+}
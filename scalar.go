@@ -0,0 +1,90 @@
+package rod
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrInvalidScalar is returned when a stored value can't be decoded as the scalar asked for, such as GetInt64 on a
+// value which isn't 8 bytes long.
+var ErrInvalidScalar = errors.New("stored value is not a valid encoding of this type")
+
+// PutInt64 stores the value as 8 bytes in big-endian order.
+func PutInt64(tx Tx, location, key string, value int64) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(value))
+	return wrapErr("PutInt64", location, key, Put(tx, location, key, buf))
+}
+
+// GetInt64 fetches a value stored with PutInt64. If the key (or any bucket) doesn't exist it returns 0.
+func GetInt64(tx Tx, location, key string) (int64, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil || raw == nil {
+		return 0, wrapErr("GetInt64", location, key, err)
+	}
+	if len(raw) != 8 {
+		return 0, wrapErr("GetInt64", location, key, ErrInvalidScalar)
+	}
+	return int64(binary.BigEndian.Uint64(raw)), nil
+}
+
+// PutBool stores the value as a single byte, 1 for true and 0 for false.
+func PutBool(tx Tx, location, key string, value bool) error {
+	b := byte(0)
+	if value {
+		b = 1
+	}
+	return wrapErr("PutBool", location, key, Put(tx, location, key, []byte{b}))
+}
+
+// GetBool fetches a value stored with PutBool. If the key (or any bucket) doesn't exist it returns false.
+func GetBool(tx Tx, location, key string) (bool, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil || raw == nil {
+		return false, wrapErr("GetBool", location, key, err)
+	}
+	if len(raw) != 1 || raw[0] > 1 {
+		return false, wrapErr("GetBool", location, key, ErrInvalidScalar)
+	}
+	return raw[0] == 1, nil
+}
+
+// PutFloat64 stores the IEEE 754 bits of the value as 8 bytes in big-endian order.
+func PutFloat64(tx Tx, location, key string, value float64) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, math.Float64bits(value))
+	return wrapErr("PutFloat64", location, key, Put(tx, location, key, buf))
+}
+
+// GetFloat64 fetches a value stored with PutFloat64. If the key (or any bucket) doesn't exist it returns 0.
+func GetFloat64(tx Tx, location, key string) (float64, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil || raw == nil {
+		return 0, wrapErr("GetFloat64", location, key, err)
+	}
+	if len(raw) != 8 {
+		return 0, wrapErr("GetFloat64", location, key, ErrInvalidScalar)
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(raw)), nil
+}
+
+// PutTime stores the value as RFC 3339 text with nanoseconds, keeping its offset from UTC. Any monotonic clock reading
+// is lost, as is the name of the time zone.
+func PutTime(tx Tx, location, key string, value time.Time) error {
+	return wrapErr("PutTime", location, key, PutString(tx, location, key, value.Format(time.RFC3339Nano)))
+}
+
+// GetTime fetches a value stored with PutTime. If the key (or any bucket) doesn't exist it returns the zero time.
+func GetTime(tx Tx, location, key string) (time.Time, error) {
+	raw, err := Get(tx, location, key)
+	if err != nil || raw == nil {
+		return time.Time{}, wrapErr("GetTime", location, key, err)
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return time.Time{}, wrapErr("GetTime", location, key, ErrInvalidScalar)
+	}
+	return t, nil
+}
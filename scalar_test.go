@@ -0,0 +1,113 @@
+package rod
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestScalars(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("Int64", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutInt64(tx, "config", "min", math.MinInt64))
+			check(PutInt64(tx, "config", "answer", 42))
+
+			min, err := GetInt64(tx, "config", "min")
+			check(err)
+			if min != math.MinInt64 {
+				t.Fatalf("Expected %d but got %d", int64(math.MinInt64), min)
+			}
+
+			answer, err := GetInt64(tx, "config", "answer")
+			check(err)
+			if answer != 42 {
+				t.Fatalf("Expected 42 but got %d", answer)
+			}
+
+			missing, err := GetInt64(tx, "config", "missing")
+			check(err)
+			if missing != 0 {
+				t.Fatalf("Expected a missing key to be 0 but got %d", missing)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutBool(tx, "config", "debug", true))
+			check(PutBool(tx, "config", "verbose", false))
+
+			debug, err := GetBool(tx, "config", "debug")
+			check(err)
+			verbose, err := GetBool(tx, "config", "verbose")
+			check(err)
+			if !debug || verbose {
+				t.Fatalf("Expected debug to be true and verbose false but got %t and %t", debug, verbose)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Float64", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutFloat64(tx, "config", "ratio", 0.125))
+
+			ratio, err := GetFloat64(tx, "config", "ratio")
+			check(err)
+			if ratio != 0.125 {
+				t.Fatalf("Expected 0.125 but got %g", ratio)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		now := time.Date(2020, 2, 29, 13, 14, 15, 123456789, time.FixedZone("NZDT", 13*60*60))
+
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutTime(tx, "config", "started", now))
+
+			started, err := GetTime(tx, "config", "started")
+			check(err)
+			if !started.Equal(now) {
+				t.Fatalf("Expected %s but got %s", now, started)
+			}
+
+			missing, err := GetTime(tx, "config", "missing")
+			check(err)
+			if !missing.IsZero() {
+				t.Fatalf("Expected a missing key to be the zero time but got %s", missing)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Invalid encodings", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "config", "name", "rod"))
+
+			if _, err := GetInt64(tx, "config", "name"); !errors.Is(err, ErrInvalidScalar) {
+				t.Fatalf("Expected ErrInvalidScalar but got %v", err)
+			}
+			if _, err := GetBool(tx, "config", "name"); !errors.Is(err, ErrInvalidScalar) {
+				t.Fatalf("Expected ErrInvalidScalar but got %v", err)
+			}
+			if _, err := GetTime(tx, "config", "name"); !errors.Is(err, ErrInvalidScalar) {
+				t.Fatalf("Expected ErrInvalidScalar but got %v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
@@ -0,0 +1,367 @@
+package rod
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedSchemaKeyword is returned by SetSchema if the schema uses a keyword which isn't supported, since
+// quietly not enforcing it would let through documents the schema says are wrong.
+var ErrUnsupportedSchemaKeyword = errors.New("unsupported schema keyword")
+
+// SchemaErrors is returned from PutJson when the document doesn't match the JSON Schema attached to its location with
+// SetSchema. It holds every problem which was found.
+type SchemaErrors []SchemaError
+
+// SchemaError is a single way in which a document doesn't match its schema.
+type SchemaError struct {
+	// Path is a JSON Pointer to the offending part of the document, such as "/addresses/0/city".
+	Path    string
+	Message string
+}
+
+func (e SchemaErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "document does not match schema: " + strings.Join(msgs, "; ")
+}
+
+func (e SchemaError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + e.Message
+}
+
+// schema is a compiled JSON Schema. Only the commonly used keywords are supported: type, enum, const, properties,
+// required, additionalProperties, items, minItems, maxItems, minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// minLength, maxLength and pattern. Annotations such as title, description and format are allowed but not checked, and
+// any other keyword is an error.
+type schema struct {
+	types            []string
+	enum             []interface{}
+	constValue       interface{}
+	hasConst         bool
+	properties       map[string]*schema
+	required         []string
+	noAdditional     bool
+	additional       *schema
+	items            *schema
+	minItems         *int
+	maxItems         *int
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	minLength        *int
+	maxLength        *int
+	pattern          *regexp.Regexp
+}
+
+// schemaKeywords are the keywords compileSchema understands, or (if false) knows it can ignore since they don't
+// constrain the document.
+var schemaKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "properties": true, "required": true, "additionalProperties": true,
+	"items": true, "minItems": true, "maxItems": true, "minimum": true, "maximum": true, "exclusiveMinimum": true,
+	"exclusiveMaximum": true, "minLength": true, "maxLength": true, "pattern": true,
+
+	"$schema": false, "$id": false, "$comment": false, "title": false, "description": false, "default": false,
+	"examples": false, "format": false, "readOnly": false, "writeOnly": false, "deprecated": false,
+}
+
+// SetSchema attaches the JSON Schema to location, so that from then on every document put there within the Store's
+// Update or UpdateAs is validated before it is written, and SchemaErrors is returned if it doesn't match. This goes for
+// every function which writes a JSON document: PutJson, PutManyJson, GetOrPutJson, CompareAndSwapVersion, UpdateJson,
+// MergeJson and PatchJson. Documents are validated before any fields are encrypted. A schema using a keyword which
+// isn't supported (such as $ref, allOf, anyOf or oneOf) is refused with ErrUnsupportedSchemaKeyword. Pass a nil schema
+// to remove it. Call SetSchema before using the Store, since it isn't safe to call alongside transactions.
+//
+//	err := store.SetSchema("user", []byte(`{
+//	    "type": "object",
+//	    "required": ["Username"],
+//	    "properties": {
+//	        "Username": {"type": "string", "minLength": 1},
+//	        "Logins": {"type": "integer", "minimum": 0}
+//	    }
+//	}`))
+func (s *Store) SetSchema(location string, raw []byte) error {
+	if raw == nil {
+		delete(s.schemas, location)
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return wrapErr("SetSchema", location, "", err)
+	}
+	compiled, err := compileSchema(doc)
+	if err != nil {
+		return wrapErr("SetSchema", location, "", err)
+	}
+
+	if s.schemas == nil {
+		s.schemas = make(map[string]*schema)
+	}
+	s.schemas[location] = compiled
+	return nil
+}
+
+// validateJson checks value against any schema attached to location by the Store which started tx.
+func validateJson(tx Tx, location string, value []byte) error {
+	st := stateOf(tx)
+	if st == nil {
+		return nil
+	}
+	_, location, _ = rooted(tx, location)
+	s, ok := st.store.schemas[location]
+	if !ok {
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return err
+	}
+	var errs SchemaErrors
+	s.validate("", doc, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func compileSchema(doc interface{}) (*schema, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		if b, ok := doc.(bool); ok && b {
+			return &schema{}, nil
+		}
+		return nil, fmt.Errorf("schema must be an object")
+	}
+
+	for name := range m {
+		if _, ok := schemaKeywords[name]; !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnsupportedSchemaKeyword, name)
+		}
+	}
+
+	s := &schema{}
+	var err error
+
+	switch t := m["type"].(type) {
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				s.types = append(s.types, name)
+			}
+		}
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok {
+		s.enum = enum
+	}
+	if c, ok := m["const"]; ok {
+		s.constValue, s.hasConst = c, true
+	}
+
+	if props, ok := m["properties"].(map[string]interface{}); ok {
+		s.properties = make(map[string]*schema, len(props))
+		for name, p := range props {
+			if s.properties[name], err = compileSchema(p); err != nil {
+				return nil, fmt.Errorf("properties.%s: %w", name, err)
+			}
+		}
+	}
+	if required, ok := m["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				s.required = append(s.required, name)
+			}
+		}
+	}
+	switch a := m["additionalProperties"].(type) {
+	case bool:
+		s.noAdditional = !a
+	case map[string]interface{}:
+		if s.additional, err = compileSchema(a); err != nil {
+			return nil, fmt.Errorf("additionalProperties: %w", err)
+		}
+	}
+
+	if items, ok := m["items"]; ok {
+		if s.items, err = compileSchema(items); err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+	}
+
+	s.minItems = intKeyword(m, "minItems")
+	s.maxItems = intKeyword(m, "maxItems")
+	s.minLength = intKeyword(m, "minLength")
+	s.maxLength = intKeyword(m, "maxLength")
+	s.minimum = numberKeyword(m, "minimum")
+	s.maximum = numberKeyword(m, "maximum")
+	s.exclusiveMinimum = numberKeyword(m, "exclusiveMinimum")
+	s.exclusiveMaximum = numberKeyword(m, "exclusiveMaximum")
+
+	if pattern, ok := m["pattern"].(string); ok {
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func numberKeyword(m map[string]interface{}, name string) *float64 {
+	if n, ok := m[name].(float64); ok {
+		return &n
+	}
+	return nil
+}
+
+func intKeyword(m map[string]interface{}, name string) *int {
+	if n, ok := m[name].(float64); ok {
+		i := int(n)
+		return &i
+	}
+	return nil
+}
+
+func (s *schema) validate(path string, v interface{}, errs *SchemaErrors) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 {
+		ok := false
+		for _, t := range s.types {
+			if isType(v, t) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("expected %s but got %s", strings.Join(s.types, " or "), typeOf(v))
+			return
+		}
+	}
+
+	if s.enum != nil {
+		ok := false
+		for _, e := range s.enum {
+			if jsonEqual(e, v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("must be one of the enumerated values")
+		}
+	}
+	if s.hasConst && !jsonEqual(s.constValue, v) {
+		fail("must be the constant value")
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := path + "/" + escapePointer(name)
+			if p, ok := s.properties[name]; ok {
+				p.validate(child, v[name], errs)
+			} else if s.noAdditional {
+				*errs = append(*errs, SchemaError{Path: child, Message: "additional property is not allowed"})
+			} else if s.additional != nil {
+				s.additional.validate(child, v[name], errs)
+			}
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			fail("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			fail("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(path+"/"+strconv.Itoa(i), item, errs)
+			}
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			fail("must be at least %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			fail("must be at most %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			fail("must be greater than %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			fail("must be less than %v", *s.exclusiveMaximum)
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			fail("must be at least %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("must be at most %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match the pattern %q", s.pattern.String())
+		}
+	}
+}
+
+func isType(v interface{}, t string) bool {
+	switch t {
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == float64(int64(n))
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return typeOf(v) == t
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
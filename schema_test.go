@@ -0,0 +1,159 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSchema(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	store := NewStore(db)
+	err := store.SetSchema("user", []byte(`{
+		"type": "object",
+		"required": ["Username"],
+		"additionalProperties": false,
+		"properties": {
+			"Username": {"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+			"Logins": {"type": "integer", "minimum": 0}
+		}
+	}`))
+	check(err)
+
+	t.Run("Valid documents are put", func(t *testing.T) {
+		err := store.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "user", "chilts", User{"chilts", 1})
+		})
+		check(err)
+	})
+
+	t.Run("Invalid documents are rejected", func(t *testing.T) {
+		err := store.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "user", "bad", map[string]interface{}{
+				"Username": "Not Valid",
+				"Logins":   -1.5,
+				"Admin":    true,
+			})
+		})
+
+		var errs SchemaErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Expected SchemaErrors but got %v", err)
+		}
+		if len(errs) != 3 {
+			t.Fatalf("Expected three problems but got %d: %v", len(errs), errs)
+		}
+		if errs[0].Path != "/Admin" || errs[1].Path != "/Logins" || errs[2].Path != "/Username" {
+			t.Fatalf("Unexpected problems: %v", errs)
+		}
+
+		err = store.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "user", "empty", map[string]interface{}{})
+		})
+		if !errors.As(err, &errs) || errs[0].Message != `missing required property "Username"` {
+			t.Fatalf("Expected the missing username to be reported but got %v", err)
+		}
+
+		err = store.View(func(tx *bolt.Tx) error {
+			exists, err := Exists(tx, "user", "bad")
+			check(err)
+			if exists {
+				t.Fatal("The invalid document should not have been put")
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Other locations are not validated", func(t *testing.T) {
+		err := store.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "car", "golf", Car{"Volkswagon", "Golf"})
+		})
+		check(err)
+	})
+
+	t.Run("Bad schemas", func(t *testing.T) {
+		if err := store.SetSchema("car", []byte(`{"pattern": "("}`)); err == nil {
+			t.Fatal("Expected a bad pattern to fail")
+		}
+		if err := store.SetSchema("car", []byte(`[]`)); err == nil {
+			t.Fatal("Expected a schema which isn't an object to fail")
+		}
+	})
+
+	t.Run("Unsupported keywords are refused", func(t *testing.T) {
+		for _, raw := range []string{
+			`{"oneOf": [{"type": "string"}, {"type": "number"}]}`,
+			`{"properties": {"Parent": {"$ref": "#"}}}`,
+			`{"items": {"anyOf": [{"type": "string"}]}}`,
+		} {
+			if err := store.SetSchema("car", []byte(raw)); !errors.Is(err, ErrUnsupportedSchemaKeyword) {
+				t.Fatalf("Expected ErrUnsupportedSchemaKeyword for %s but got %v", raw, err)
+			}
+		}
+		check(store.SetSchema("car", []byte(`{"title": "Car", "description": "A car", "type": "object"}`)))
+		check(store.SetSchema("car", nil))
+	})
+
+	t.Run("Every JSON write is validated", func(t *testing.T) {
+		type Note struct {
+			Text   string
+			Secret string `rod:"encrypt"`
+		}
+		check(SetEncryptionKey(bytes.Repeat([]byte("k"), 32)))
+		defer SetEncryptionKey(nil)
+		check(store.SetSchema("note", []byte(`{
+			"type": "object",
+			"required": ["Text"],
+			"properties": {
+				"Text": {"type": "string", "minLength": 1},
+				"Secret": {"type": "string", "maxLength": 10}
+			}
+		}`)))
+
+		isSchemaErr := func(what string, err error) {
+			var errs SchemaErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Expected SchemaErrors from %s but got %v", what, err)
+			}
+		}
+
+		err := store.Update(func(tx *bolt.Tx) error {
+			// the secret is checked before it is encrypted, when it is still short enough
+			check(PutJson(tx, "note", "good", Note{"hello", "shh"}))
+
+			err := PutManyJson(tx, "note", map[string]Note{"bad": {"", "shh"}})
+			var keyErrs KeyErrors
+			if !errors.As(err, &keyErrs) {
+				t.Fatalf("Expected KeyErrors from PutManyJson but got %v", err)
+			}
+			isSchemaErr("PutManyJson", keyErrs["bad"])
+
+			var got Note
+			_, err = GetOrPutJson(tx, "note", "bad", &got, func() interface{} { return Note{} })
+			isSchemaErr("GetOrPutJson", err)
+
+			_, err = CompareAndSwapVersion(tx, "note", "bad", "Version", 0, Note{})
+			isSchemaErr("CompareAndSwapVersion", err)
+
+			isSchemaErr("MergeJson", MergeJson(tx, "note", "good", []byte(`{"Text":""}`)))
+			isSchemaErr("PatchJson", PatchJson(tx, "note", "good", []byte(`[{"op":"remove","path":"/Text"}]`)))
+			isSchemaErr("UpdateJson", UpdateJson(tx, "note", "good", &got, func() error {
+				got.Text = ""
+				return nil
+			}))
+
+			exists, err := Exists(tx, "note", "bad")
+			check(err)
+			if exists {
+				t.Fatal("No invalid document should have been put")
+			}
+			return nil
+		})
+		check(err)
+	})
+}
@@ -0,0 +1,263 @@
+package rod
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+// ShardFunc picks which of n shards key belongs in, returning a number from 0 to n-1. It must always give the same
+// shard for the same key.
+type ShardFunc func(key string, n int) int
+
+// HashShard is the default ShardFunc, which spreads keys evenly across the shards using an FNV-1a hash.
+func HashShard(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+// ShardedStore spreads keys across a number of Stores (usually one Bolt file each), so that writes to different shards
+// don't wait on each other for Bolt's single writer lock. Each key is put in the shard picked by its ShardFunc, which
+// only looks at the key, so the records with the same key in different locations are all in the same shard and can be
+// changed together in one transaction with Update.
+//
+//	store, err := rod.OpenSharded("/var/lib/app", 8, 0600, nil)
+//	err = store.PutJson("user", "chilts", &user)
+//
+//	var users []User
+//	err = store.All("user", &users)
+//
+// All and AllKeys look at every shard at once, and merge the results back into key order. Each shard is read in its
+// own transaction though, so these don't see a single point in time across the shards.
+//
+// Note that the number of shards (and the ShardFunc) can't be changed once there is data in them, since keys would
+// then be looked for in the wrong shards.
+type ShardedStore struct {
+	shards []*Store
+	shard  ShardFunc
+}
+
+// NewShardedStore returns a ShardedStore over these shards. If shard is nil then HashShard is used.
+func NewShardedStore(shards []*Store, shard ShardFunc) *ShardedStore {
+	if shard == nil {
+		shard = HashShard
+	}
+	return &ShardedStore{shards: shards, shard: shard}
+}
+
+// OpenSharded opens (or creates) n Bolt files in dir, named "shard-000.db" onwards, and returns a ShardedStore over
+// them using HashShard. The mode and options are passed straight through to bolt.Open().
+func OpenSharded(dir string, n int, mode os.FileMode, options *bolt.Options) (*ShardedStore, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("rod: need at least one shard, not %d", n)
+	}
+
+	shards := make([]*Store, 0, n)
+	for i := 0; i < n; i++ {
+		store, err := Open(filepath.Join(dir, fmt.Sprintf("shard-%03d.db", i)), mode, options)
+		if err != nil {
+			for _, s := range shards {
+				s.Close()
+			}
+			return nil, err
+		}
+		shards = append(shards, store)
+	}
+
+	return NewShardedStore(shards, nil), nil
+}
+
+// Shards returns every shard, in order.
+func (s *ShardedStore) Shards() []*Store {
+	return s.shards
+}
+
+// Shard returns the shard which key belongs in.
+func (s *ShardedStore) Shard(key string) *Store {
+	return s.shards[s.shard(key, len(s.shards))]
+}
+
+// Close closes every shard, returning the first error.
+func (s *ShardedStore) Close() error {
+	var first error
+	for _, store := range s.shards {
+		if err := store.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Update runs fn inside a read-write transaction on the shard which key belongs in. Only keys which belong in that
+// same shard should be written within fn.
+func (s *ShardedStore) Update(key string, fn func(tx *bolt.Tx) error) error {
+	return s.Shard(key).Update(fn)
+}
+
+// View runs fn inside a read-only transaction on the shard which key belongs in.
+func (s *ShardedStore) View(key string, fn func(tx *bolt.Tx) error) error {
+	return s.Shard(key).View(fn)
+}
+
+// Put is the same as rod.Put, in its own transaction on the key's shard.
+func (s *ShardedStore) Put(location, key string, value []byte) error {
+	return s.Update(key, func(tx *bolt.Tx) error {
+		return Put(tx, location, key, value)
+	})
+}
+
+// Get is the same as rod.Get, in its own transaction on the key's shard. The value is copied out of the transaction.
+func (s *ShardedStore) Get(location, key string) ([]byte, error) {
+	var value []byte
+	err := s.View(key, func(tx *bolt.Tx) error {
+		raw, err := Get(tx, location, key)
+		value = copyBytes(raw)
+		return err
+	})
+	return value, err
+}
+
+// PutJson is the same as rod.PutJson, in its own transaction on the key's shard.
+func (s *ShardedStore) PutJson(location, key string, v interface{}) error {
+	return s.Update(key, func(tx *bolt.Tx) error {
+		return PutJson(tx, location, key, v)
+	})
+}
+
+// GetJson is the same as rod.GetJson, in its own transaction on the key's shard.
+func (s *ShardedStore) GetJson(location, key string, v interface{}) error {
+	return s.View(key, func(tx *bolt.Tx) error {
+		return GetJson(tx, location, key, v)
+	})
+}
+
+// Del is the same as rod.Del, in its own transaction on the key's shard.
+func (s *ShardedStore) Del(location, key string) error {
+	return s.Update(key, func(tx *bolt.Tx) error {
+		return Del(tx, location, key)
+	})
+}
+
+// eachShard calls fn for every shard at once, within a read-only transaction on each, and returns the first error.
+func (s *ShardedStore) eachShard(fn func(i int, tx *bolt.Tx) error) error {
+	errs := make([]error, len(s.shards))
+
+	var wg sync.WaitGroup
+	for i, store := range s.shards {
+		wg.Add(1)
+		go func(i int, store *Store) {
+			defer wg.Done()
+			errs[i] = store.View(func(tx *bolt.Tx) error {
+				return fn(i, tx)
+			})
+		}(i, store)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllKeys is the same as rod.AllKeys, across every shard.
+func (s *ShardedStore) AllKeys(location string) ([]string, error) {
+	parts := make([][]string, len(s.shards))
+	err := s.eachShard(func(i int, tx *bolt.Tx) error {
+		keys, err := AllKeys(tx, location)
+		parts[i] = keys
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, part := range parts {
+		keys = append(keys, part...)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// shardedItem is a decoded element of the results from one shard, with what it needs to be sorted.
+type shardedItem struct {
+	sortable
+	item reflect.Value
+}
+
+// All is the same as rod.All, across every shard. Each value is decoded within its own shard's transaction, so
+// AfterLoad hooks work as usual.
+func (s *ShardedStore) All(location string, to interface{}, opts ...QueryOption) error {
+	results, err := newSliceBuilder(nil, to, opts)
+	if err != nil {
+		return wrapErr("All", location, "", err)
+	}
+
+	var badBefore int
+	if results.query.bad != nil {
+		badBefore = len(*results.query.bad)
+	}
+	parts := make([][]shardedItem, len(s.shards))
+	err = s.eachShard(func(i int, tx *bolt.Tx) error {
+		b, err := BucketAt(tx, location)
+		if err != nil || b == nil {
+			return err
+		}
+
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			item, err := results.newItem(tx, string(k), v)
+			if err != nil {
+				if results.quarantine(string(k), v, err) {
+					continue
+				}
+				return &Error{Key: string(k), Err: err}
+			}
+			si := shardedItem{sortable: sortable{key: string(k)}, item: item}
+			if results.query.sortField != nil {
+				si.sortable = newSortable(string(k), v, results.query.sortField)
+				si.raw = nil // only valid within the tx
+			}
+			parts[i] = append(parts[i], si)
+		}
+		return nil
+	})
+	if err != nil {
+		return wrapErr("All", location, "", err)
+	}
+
+	if results.query.bad != nil {
+		bad := (*results.query.bad)[badBefore:]
+		sort.Slice(bad, func(i, j int) bool {
+			return bad[i].Key < bad[j].Key
+		})
+	}
+
+	var items []shardedItem
+	for _, part := range parts {
+		items = append(items, part...)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].key < items[j].key
+	})
+	if results.query.sortField != nil {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].less(items[j].sortable, results.query.sortDesc)
+		})
+	}
+
+	for _, si := range items {
+		results.add(si.item)
+	}
+	return wrapErr("All", location, "", results.set())
+}
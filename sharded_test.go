@@ -0,0 +1,94 @@
+package rod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestShardedStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	store, err := OpenSharded(dir, 4, 0666, nil)
+	check(err)
+	defer store.Close()
+
+	for i := 0; i < 20; i++ {
+		check(store.PutJson("user", fmt.Sprintf("user-%02d", i), User{fmt.Sprintf("user-%02d", i), i % 5}))
+	}
+
+	t.Run("Keys are spread across the shards", func(t *testing.T) {
+		for i, shard := range store.Shards() {
+			err := shard.View(func(tx *bolt.Tx) error {
+				n, err := Count(tx, "user")
+				check(err)
+				if n == 0 || n == 20 {
+					t.Fatalf("Shard %d should have some but not all of the users, but has %d", i, n)
+				}
+				return nil
+			})
+			check(err)
+		}
+	})
+
+	t.Run("Get, GetJson and Del", func(t *testing.T) {
+		var user User
+		check(store.GetJson("user", "user-07", &user))
+		if user.Logins != 2 {
+			t.Fatalf("Expected user-07 to have 2 logins but got %d", user.Logins)
+		}
+
+		check(store.Del("user", "user-07"))
+		raw, err := store.Get("user", "user-07")
+		check(err)
+		if raw != nil {
+			t.Fatalf("user-07 should have been deleted")
+		}
+		check(store.PutJson("user", "user-07", user))
+	})
+
+	t.Run("AllKeys and All are in key order", func(t *testing.T) {
+		keys, err := store.AllKeys("user")
+		check(err)
+		if len(keys) != 20 || keys[0] != "user-00" || keys[19] != "user-19" {
+			t.Fatalf("Unexpected keys %v", keys)
+		}
+
+		var users []*User
+		check(store.All("user", &users))
+		if len(users) != 20 {
+			t.Fatalf("Expected 20 users but got %d", len(users))
+		}
+		for i, user := range users {
+			if user.Username != keys[i] {
+				t.Fatalf("User %d should be %s but is %s", i, keys[i], user.Username)
+			}
+		}
+	})
+
+	t.Run("All with a sort", func(t *testing.T) {
+		var users []User
+		check(store.All("user", &users, SortByDesc("Logins")))
+		if users[0].Logins != 4 || users[19].Logins != 0 {
+			t.Fatalf("Users should be sorted by logins: %v", users)
+		}
+		// and those with the same logins stay in key order
+		if users[0].Username != "user-04" || users[1].Username != "user-09" {
+			t.Fatalf("Users with the same logins should be in key order: %v", users[:4])
+		}
+	})
+
+	t.Run("A custom ShardFunc", func(t *testing.T) {
+		first := NewShardedStore(store.Shards(), func(key string, n int) int {
+			return 0
+		})
+		if first.Shard("anything") != store.Shards()[0] {
+			t.Fatalf("Every key should be in the first shard")
+		}
+	})
+}
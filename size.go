@@ -0,0 +1,111 @@
+package rod
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// DBSize is how much space a BoltDB is using, and where, as returned by SizeReport.
+type DBSize struct {
+	// FileSize is the size of the file on disk, which Bolt grows ahead of the data, and DataSize is the size of the
+	// data within it.
+	FileSize int64
+	DataSize int64
+	PageSize int
+	// FreeBytes is the space in pages which are free (or will be once open transactions finish), to be used again
+	// before the file grows. FreelistBytes is the space used to keep track of them.
+	FreeBytes     int
+	FreelistBytes int
+	// Buckets holds how much each top-level bucket is using, including the buckets nested within it.
+	Buckets map[string]BucketSize
+}
+
+// BucketSize is how much space a bucket is using.
+type BucketSize struct {
+	// Keys is the number of keys, including those in nested buckets.
+	Keys int
+	// Alloc is the space in the pages the bucket has, and InUse is how much of it is actually used. A small bucket
+	// may be stored within its parent's page, in which case Alloc is 0.
+	Alloc int
+	InUse int
+}
+
+// SizeReport returns how much space db is using, including how much each top-level bucket uses, so you can see what
+// is taking up the disk. Finding each bucket's size reads all of its pages. See Compact to give free space back.
+func SizeReport(db *bolt.DB) (DBSize, error) {
+	info, err := os.Stat(db.Path())
+	if err != nil {
+		return DBSize{}, err
+	}
+	stats := db.Stats()
+
+	report := DBSize{
+		FileSize:      info.Size(),
+		FreeBytes:     stats.FreeAlloc,
+		FreelistBytes: stats.FreelistInuse,
+		Buckets:       make(map[string]BucketSize),
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		// Info reads the mmap, which is only safe while it can't be remapped, such as within a read transaction
+		report.PageSize = db.Info().PageSize
+		report.DataSize = tx.Size()
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			s := b.Stats()
+			size := BucketSize{
+				Keys:  s.KeyN,
+				Alloc: s.BranchAlloc + s.LeafAlloc,
+				InUse: s.BranchInuse + s.LeafInuse,
+			}
+			report.Buckets[string(name)] = size
+			return nil
+		})
+	})
+	if err != nil {
+		return DBSize{}, err
+	}
+	return report, nil
+}
+
+// WatchSize checks the size of db's file every interval, and calls fn with a SizeReport whenever it has grown by more
+// than growth bytes since it was last called (or since WatchSize started), so you can alert before the disk fills. It
+// runs until ctx is done, returning ctx.Err(), or until SizeReport fails.
+//
+//	go rod.WatchSize(ctx, db, time.Minute, 100<<20, func(size rod.DBSize) {
+//	    log.Printf("rod has grown to %d bytes", size.FileSize)
+//	})
+func WatchSize(ctx context.Context, db *bolt.DB, interval time.Duration, growth int64, fn func(size DBSize)) error {
+	info, err := os.Stat(db.Path())
+	if err != nil {
+		return err
+	}
+	baseline := info.Size()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		// only the file size is checked each time, since the full report is much more work
+		info, err := os.Stat(db.Path())
+		if err != nil {
+			return err
+		}
+		if info.Size()-baseline <= growth {
+			continue
+		}
+
+		report, err := SizeReport(db)
+		if err != nil {
+			return err
+		}
+		baseline = report.FileSize
+		fn(report)
+	}
+}
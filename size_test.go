@@ -0,0 +1,98 @@
+package rod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSize(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	pad := strings.Repeat("x", 1000)
+	fill := func(location string, n int) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			for i := 0; i < n; i++ {
+				check(PutString(tx, location, fmt.Sprintf("%06d", i), pad))
+			}
+			return nil
+		}))
+	}
+
+	t.Run("SizeReport", func(t *testing.T) {
+		fill("big", 500)
+		fill("big.nested", 10)
+		fill("small", 1)
+
+		report, err := SizeReport(db)
+		check(err)
+		big, small := report.Buckets["big"], report.Buckets["small"]
+		if big.Keys != 511 || big.Alloc < 500*1000 || big.InUse < 500*1000 || big.InUse > big.Alloc {
+			t.Fatalf("Unexpected size for big %#v", big)
+		}
+		if small.Keys != 1 || small.InUse < 1000 || small.InUse >= big.InUse {
+			t.Fatalf("Unexpected size for small %#v", small)
+		}
+		if report.FileSize < report.DataSize || report.DataSize < int64(big.Alloc) || report.PageSize == 0 {
+			t.Fatalf("Unexpected sizes %#v", report)
+		}
+
+		// deleting leaves the space free rather than shrinking the file
+		check(db.Update(func(tx *bolt.Tx) error {
+			for i := 0; i < 500; i++ {
+				check(Del(tx, "big", fmt.Sprintf("%06d", i)))
+			}
+			return nil
+		}))
+		after, err := SizeReport(db)
+		check(err)
+		if after.FileSize != report.FileSize || after.FreeBytes <= report.FreeBytes {
+			t.Fatalf("Expected the same file with more free space: %#v then %#v", report, after)
+		}
+	})
+
+	t.Run("WatchSize", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		grown := make(chan DBSize, 10)
+		errc := make(chan error, 1)
+		defer func() {
+			cancel()
+			<-errc
+		}()
+		start, err := SizeReport(db)
+		check(err)
+		go func() {
+			errc <- WatchSize(ctx, db, 5*time.Millisecond, 1<<20, func(size DBSize) {
+				grown <- size
+			})
+		}()
+
+		// small changes don't fire
+		fill("watched", 10)
+		time.Sleep(30 * time.Millisecond)
+		if len(grown) != 0 {
+			t.Fatalf("WatchSize should not have fired yet")
+		}
+
+		fill("watched", 5000)
+		select {
+		case size := <-grown:
+			if size.FileSize-start.FileSize <= 1<<20 {
+				t.Fatalf("The file has only grown from %d to %d", start.FileSize, size.FileSize)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("WatchSize should have fired")
+		}
+
+		cancel()
+		if err := <-errc; err != context.Canceled {
+			t.Fatalf("Expected context.Canceled but got %v", err)
+		}
+		errc <- nil
+	})
+}
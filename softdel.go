@@ -0,0 +1,175 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+)
+
+// DeletedBucket is the top-level bucket which soft deleted records are moved into. The record's location is kept
+// beneath it, so a record soft deleted from "users.chilts" is held in "__rod_deleted.users.chilts".
+const DeletedBucket = "__rod_deleted"
+
+// ErrKeyExists is returned from Restore when a live record already has the key being restored, and from SoftDel when a
+// deleted record already has the key being deleted.
+var ErrKeyExists = errors.New("key already exists")
+
+// SoftDel moves the record out of location and into DeletedBucket, rather than deleting it. Since it is no longer in
+// location, Get, All, Each and everything else no longer see it, but it may be brought back with Restore or seen with
+// AllIncludingDeleted. As with Del, a missing key (or bucket) is not an error. If the key was soft deleted before and
+// has since been put again then ErrKeyExists is returned and both are left as they are, so the first deleted record is
+// never lost; Restore or Del one of them first.
+func SoftDel(tx Tx, location, key string) error {
+	if location == "" {
+		return wrapErr("SoftDel", location, key, ErrLocationMustHaveAtLeastOneBucket)
+	}
+	if key == "" {
+		return wrapErr("SoftDel", location, key, ErrKeyNotProvided)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("SoftDel", location, key, err)
+	}
+	if b == nil || !hasKey(b, []byte(key)) {
+		return nil
+	}
+
+	exists, err := Exists(tx, DeletedBucket+"."+location, key)
+	if err != nil {
+		return wrapErr("SoftDel", location, key, err)
+	}
+	if exists {
+		return wrapErr("SoftDel", location, key, ErrKeyExists)
+	}
+
+	return wrapErr("SoftDel", location, key, move(tx, b, location, DeletedBucket+"."+location, key))
+}
+
+// Restore moves a soft deleted record back into location. If there is no such deleted record then nothing happens,
+// and if a record with the same key has since been put into location then ErrKeyExists is returned and both are left
+// as they are.
+func Restore(tx Tx, location, key string) error {
+	if location == "" {
+		return wrapErr("Restore", location, key, ErrLocationMustHaveAtLeastOneBucket)
+	}
+	if key == "" {
+		return wrapErr("Restore", location, key, ErrKeyNotProvided)
+	}
+
+	deleted, err := BucketAt(tx, DeletedBucket+"."+location)
+	if err != nil {
+		return wrapErr("Restore", location, key, err)
+	}
+	if deleted == nil || !hasKey(deleted, []byte(key)) {
+		return nil
+	}
+
+	exists, err := Exists(tx, location, key)
+	if err != nil {
+		return wrapErr("Restore", location, key, err)
+	}
+	if exists {
+		return wrapErr("Restore", location, key, ErrKeyExists)
+	}
+
+	return wrapErr("Restore", location, key, move(tx, deleted, DeletedBucket+"."+location, location, key))
+}
+
+// move puts the value at key in from (which is at fromLocation) into the bucket at location, then deletes it from from.
+func move(tx Tx, from Bucket, fromLocation, location, key string) error {
+	// copy the value, since it is only valid until we change the bucket
+	value := append([]byte{}, from.Get([]byte(key))...)
+
+	to, err := createBucket(tx, location)
+	if err != nil {
+		return err
+	}
+	if err := putKey(tx, to, location, []byte(key), value); err != nil {
+		return err
+	}
+	return delKey(tx, from, fromLocation, []byte(key))
+}
+
+// AllDeleted is the same as All but gives you only the records which have been soft deleted from location.
+func AllDeleted(tx Tx, location string, to interface{}, opts ...QueryOption) error {
+	if location == "" {
+		return wrapErr("AllDeleted", location, "", ErrLocationMustHaveAtLeastOneBucket)
+	}
+	return wrapErr("AllDeleted", location, "", All(tx, DeletedBucket+"."+location, to, opts...))
+}
+
+// AllIncludingDeleted is the same as All but also gives you the records which have been soft deleted from location,
+// mixed in (in key order, unless you sort them) with the live ones. If a key has been put again since it was soft
+// deleted then only the live record is given, though a nested bucket of the same name doesn't hide the deleted one.
+func AllIncludingDeleted(tx Tx, location string, to interface{}, opts ...QueryOption) error {
+	if location == "" {
+		return wrapErr("AllIncludingDeleted", location, "", ErrLocationMustHaveAtLeastOneBucket)
+	}
+
+	results, err := newSliceBuilder(tx, to, opts)
+	if err != nil {
+		return wrapErr("AllIncludingDeleted", location, "", err)
+	}
+
+	live, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("AllIncludingDeleted", location, "", err)
+	}
+	deleted, err := BucketAt(tx, DeletedBucket+"."+location)
+	if err != nil {
+		return wrapErr("AllIncludingDeleted", location, "", err)
+	}
+
+	// merge the two buckets, in key order
+	lk, lv, lc := first(live)
+	dk, dv, dc := first(deleted)
+	for lk != nil || dk != nil {
+		var k, v []byte
+		var b Bucket
+		switch cmp := compareKeys(lk, dk); {
+		case cmp < 0:
+			k, v, b = lk, lv, live
+			lk, lv = lc.Next()
+		case cmp > 0:
+			k, v, b = dk, dv, deleted
+			dk, dv = dc.Next()
+		default:
+			// the live one wins, unless it is a nested bucket
+			k, v, b = lk, lv, live
+			if isBucket(live, lk, lv) {
+				v, b = dv, deleted
+			}
+			lk, lv = lc.Next()
+			dk, dv = dc.Next()
+		}
+		if isBucket(b, k, v) {
+			continue
+		}
+		if err := results.append(string(k), v); err != nil {
+			return wrapErr("AllIncludingDeleted", location, string(k), err)
+		}
+	}
+
+	return wrapErr("AllIncludingDeleted", location, "", results.set())
+}
+
+// first returns the first key/value of b along with its cursor, or nils if there is no bucket.
+func first(b Bucket) ([]byte, []byte, Cursor) {
+	if b == nil {
+		return nil, nil, nil
+	}
+	c := b.Cursor()
+	k, v := c.First()
+	return k, v, c
+}
+
+// compareKeys compares two keys where a nil key (the end of a bucket) comes after everything.
+func compareKeys(a, b []byte) int {
+	switch {
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return bytes.Compare(a, b)
+}
@@ -0,0 +1,128 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSoftDel(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 1}))
+		check(PutJson(tx, "user", "bob", User{"bob", 2}))
+		check(PutJson(tx, "user", "carol", User{"carol", 3}))
+		return nil
+	})
+	check(err)
+
+	t.Run("SoftDel hides the record", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(SoftDel(tx, "user", "bob"))
+			check(SoftDel(tx, "user", "nobody"))
+
+			var bob User
+			check(GetJson(tx, "user", "bob", &bob))
+			if bob.Username != "" {
+				t.Fatalf("A soft deleted record should not be found: %#v", bob)
+			}
+
+			var users []User
+			check(All(tx, "user", &users))
+			if len(users) != 2 {
+				t.Fatalf("Expected two live users but got %d", len(users))
+			}
+
+			check(AllDeleted(tx, "user", &users))
+			if len(users) != 1 || users[0].Username != "bob" {
+				t.Fatalf("Expected bob to be deleted: %#v", users)
+			}
+
+			check(AllIncludingDeleted(tx, "user", &users))
+			if len(users) != 3 || users[1].Username != "bob" {
+				t.Fatalf("Expected all three users in key order: %#v", users)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(Restore(tx, "user", "bob"))
+
+			var bob User
+			check(GetJson(tx, "user", "bob", &bob))
+			if bob.Logins != 2 {
+				t.Fatalf("Expected bob to be restored: %#v", bob)
+			}
+
+			var users []User
+			check(AllDeleted(tx, "user", &users))
+			if len(users) != 0 {
+				t.Fatalf("Expected nobody to be deleted: %#v", users)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Restore over a live record", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(SoftDel(tx, "user", "carol"))
+			check(PutJson(tx, "user", "carol", User{"carol", 30}))
+
+			var users []User
+			check(AllIncludingDeleted(tx, "user", &users))
+			if len(users) != 3 || users[2].Logins != 30 {
+				t.Fatalf("Expected only the live carol: %#v", users)
+			}
+
+			return Restore(tx, "user", "carol")
+		})
+		if !errors.Is(err, ErrKeyExists) {
+			t.Fatalf("Expected ErrKeyExists but got %v", err)
+		}
+	})
+
+	t.Run("SoftDel keeps the first deleted record", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(SoftDel(tx, "user", "carol"))
+			check(PutJson(tx, "user", "carol", User{"carol", 30}))
+			if err := SoftDel(tx, "user", "carol"); !errors.Is(err, ErrKeyExists) {
+				t.Fatalf("Expected ErrKeyExists but got %v", err)
+			}
+
+			var users []User
+			check(AllDeleted(tx, "user", &users))
+			if len(users) != 1 || users[0].Logins != 3 {
+				t.Fatalf("Expected the first carol to still be deleted: %#v", users)
+			}
+			var carol User
+			check(GetJson(tx, "user", "carol", &carol))
+			if carol.Logins != 30 {
+				t.Fatalf("Expected the live carol to be left alone: %#v", carol)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("A nested bucket doesn't hide a deleted record", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(Del(tx, "user", "carol"))
+			check(PutString(tx, "user.carol", "note", "now a bucket"))
+
+			var users []User
+			check(AllIncludingDeleted(tx, "user", &users))
+			if len(users) != 3 || users[2].Logins != 3 {
+				t.Fatalf("Expected the deleted carol: %#v", users)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
@@ -0,0 +1,114 @@
+package rod
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// QueryOption changes how All and Find return their results.
+type QueryOption func(*query)
+
+// query holds the options given to All or Find.
+type query struct {
+	sortField []string
+	sortDesc  bool
+	bad       *[]BadRecord
+}
+
+// SortBy sorts the results in ascending order of the JSON field at path (as per GetJsonField), rather than by key. For
+// a struct this is the field name, or its name in the `json` tag if it has one. Numbers are sorted numerically and
+// strings lexically. Records without the field come first, and records with the same value stay in key order.
+//
+// SortBy panics if the path is invalid, since that is a programming error.
+func SortBy(path string) QueryOption {
+	segments, err := parseFieldPath(path)
+	if err != nil || segments == nil {
+		panic("rod: invalid sort field " + path)
+	}
+	return func(q *query) {
+		q.sortField = segments
+		q.sortDesc = false
+	}
+}
+
+// SortByDesc is the same as SortBy except the results are in descending order, so records without the field come
+// last.
+func SortByDesc(path string) QueryOption {
+	asc := SortBy(path)
+	return func(q *query) {
+		asc(q)
+		q.sortDesc = true
+	}
+}
+
+// sortable is a raw value waiting to be sorted, along with the value of its sort field.
+type sortable struct {
+	key   string
+	raw   []byte
+	field interface{}
+}
+
+func newSortable(key string, raw []byte, segments []string) sortable {
+	item := sortable{key: key, raw: raw}
+	if field, err := jsonField(raw, segments); err == nil && field != nil {
+		item.field, _ = decodeJson(field)
+	}
+	return item
+}
+
+// sortSortables stably sorts items by their field.
+func sortSortables(items []sortable, desc bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].less(items[j], desc)
+	})
+}
+
+// less tells you whether a sorts before b by their field.
+func (a sortable) less(b sortable, desc bool) bool {
+	if desc {
+		return orderJson(b.field, a.field) < 0
+	}
+	return orderJson(a.field, b.field) < 0
+}
+
+// orderJson gives an ordering between any two decoded JSON values: null (or missing), then bools, numbers, strings
+// and finally objects and arrays (which are all equal to each other).
+func orderJson(a, b interface{}) int {
+	ra, rb := jsonRank(a), jsonRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+
+	switch av := a.(type) {
+	case bool:
+		bv := b.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+		return 1
+	case json.Number, string:
+		cmp, _ := compareJson(a, b)
+		return cmp
+	}
+	return 0
+}
+
+func jsonRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case json.Number:
+		return 2
+	case string:
+		return 3
+	}
+	return 4
+}
@@ -0,0 +1,65 @@
+package rod
+
+import (
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSort(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "alice", User{"alice", 12}))
+		check(PutJson(tx, "user", "bob", User{"bob", 3}))
+		check(PutJson(tx, "user", "carol", User{"carol", 20}))
+		check(PutJson(tx, "user", "dave", User{"dave", 3}))
+		check(PutJson(tx, "user", "eve", map[string]string{"Username": "eve"}))
+		return nil
+	})
+	check(err)
+
+	names := func(users []User) []string {
+		var names []string
+		for _, u := range users {
+			names = append(names, u.Username)
+		}
+		return names
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		tests := []struct {
+			opt  QueryOption
+			want []string
+		}{
+			{SortBy("Logins"), []string{"eve", "bob", "dave", "alice", "carol"}},
+			{SortByDesc("Logins"), []string{"carol", "alice", "bob", "dave", "eve"}},
+			{SortByDesc("Username"), []string{"eve", "dave", "carol", "bob", "alice"}},
+		}
+
+		for _, test := range tests {
+			var users []User
+			check(All(tx, "user", &users, test.opt))
+			got := names(users)
+			if len(got) != len(test.want) {
+				t.Fatalf("Expected %v but got %v", test.want, got)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("Expected %v but got %v", test.want, got)
+				}
+			}
+		}
+
+		// and through Find, with pointers
+		var users []*User
+		check(Find(tx, "user", Where("Logins", ">", 1), &users, SortByDesc("Logins")))
+		if len(users) != 4 || users[0].Username != "carol" || users[3].Username != "dave" {
+			t.Fatalf("Find didn't sort correctly")
+		}
+
+		return nil
+	})
+	check(err)
+}
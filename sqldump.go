@@ -0,0 +1,206 @@
+package rod
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sqlKeyColumn is the column ExportSQL puts each record's key in, named as in rodsql.
+const sqlKeyColumn = "_key"
+
+// sqlColumn is a column of an ExportSQL table, with the type worked out from every value seen in it.
+type sqlColumn struct {
+	name string
+	kind string
+}
+
+// see merges a value for the column into its type, which ends up as TEXT if the values don't all agree.
+func (c *sqlColumn) see(raw json.RawMessage) {
+	kind := sqlKind(raw)
+	switch {
+	case kind == "":
+		// a null says nothing about the type
+	case c.kind == "":
+		c.kind = kind
+	case c.kind == kind:
+	case (c.kind == "INTEGER" || c.kind == "NUMERIC") && (kind == "INTEGER" || kind == "NUMERIC"):
+		c.kind = "NUMERIC"
+	default:
+		c.kind = "TEXT"
+	}
+}
+
+// ExportSQL writes the records at location to w as SQL, as a CREATE TABLE statement for table followed by an INSERT
+// for each record, all in one transaction. The SQL works with both SQLite and PostgreSQL, so the data can be loaded
+// into either for ad-hoc analysis:
+//
+//	err := rod.ExportSQL(tx, "users", "users", f)
+//	// $ sqlite3 users.db < users.sql
+//
+// The first column is _key (as in rodsql), the primary key, followed by one column for each top-level field of the
+// JSON objects at location in the order they were first seen. A column's type is INTEGER, NUMERIC, BOOLEAN or TEXT
+// depending on the values in it, and if those are of mixed types it is TEXT. Nested objects and arrays go in as their
+// JSON, a missing field or a null is NULL, and a record which isn't a JSON object only has its _key. A field called
+// _key is left out since that column is taken, and nested buckets are skipped. If table is empty then the location
+// is used, with each '.' replaced by '_'.
+func ExportSQL(tx Tx, location, table string, w io.Writer) error {
+	b, err := getBucket(tx, location)
+	if err != nil {
+		return wrapErr("ExportSQL", location, "", err)
+	}
+	if table == "" {
+		table = strings.Replace(location, ".", "_", -1)
+	}
+
+	// first go through the records to find the columns
+	var columns []*sqlColumn
+	byName := map[string]*sqlColumn{}
+	if b != nil {
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v == nil {
+				continue
+			}
+			err := sqlFields(v, func(name string, raw json.RawMessage) {
+				col, ok := byName[name]
+				if !ok {
+					if name == sqlKeyColumn {
+						return
+					}
+					col = &sqlColumn{name: name}
+					byName[name] = col
+					columns = append(columns, col)
+				}
+				col.see(raw)
+			})
+			if err != nil {
+				return wrapErr("ExportSQL", location, string(k), err)
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	names := []string{sqlIdentifier(sqlKeyColumn)}
+	fmt.Fprintf(bw, "BEGIN;\nCREATE TABLE %s (\n  %s TEXT PRIMARY KEY", sqlIdentifier(table), names[0])
+	for _, col := range columns {
+		kind := col.kind
+		if kind == "" {
+			kind = "TEXT"
+		}
+		fmt.Fprintf(bw, ",\n  %s %s", sqlIdentifier(col.name), kind)
+		names = append(names, sqlIdentifier(col.name))
+	}
+	bw.WriteString("\n);\n")
+
+	// and then again to write them out
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", sqlIdentifier(table), strings.Join(names, ", "))
+	if b != nil {
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if v == nil {
+				continue
+			}
+			values := map[string]json.RawMessage{}
+			err := sqlFields(v, func(name string, raw json.RawMessage) {
+				values[name] = raw
+			})
+			if err != nil {
+				return wrapErr("ExportSQL", location, string(k), err)
+			}
+
+			bw.WriteString(insert)
+			bw.WriteString(sqlString(string(k)))
+			for _, col := range columns {
+				bw.WriteString(", ")
+				bw.WriteString(sqlValue(col, values[col.name]))
+			}
+			bw.WriteString(");\n")
+		}
+	}
+	bw.WriteString("COMMIT;\n")
+
+	return wrapErr("ExportSQL", location, "", bw.Flush())
+}
+
+// sqlFields calls fn with each top-level field of v in order, if v is a JSON object. Anything else has no fields.
+func sqlFields(v []byte, fn func(name string, raw json.RawMessage)) error {
+	if !isJsonObject(v) || !json.Valid(v) {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(v))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		fn(t.(string), raw)
+	}
+	return nil
+}
+
+// sqlKind returns the column type for a single JSON value, or "" for a null.
+func sqlKind(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	switch raw[0] {
+	case 'n':
+		return ""
+	case 't', 'f':
+		return "BOOLEAN"
+	case '"', '{', '[':
+		return "TEXT"
+	}
+	if _, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+		return "INTEGER"
+	}
+	return "NUMERIC"
+}
+
+// sqlValue returns the SQL literal for raw in col.
+func sqlValue(col *sqlColumn, raw json.RawMessage) string {
+	kind := sqlKind(raw)
+	switch {
+	case kind == "":
+		return "NULL"
+	case col.kind == "TEXT" && raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return sqlString(s)
+		}
+	case col.kind == "BOOLEAN":
+		return strings.ToUpper(string(raw))
+	case col.kind == "INTEGER" || col.kind == "NUMERIC":
+		return string(raw)
+	}
+
+	// everything else goes in as its JSON
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return sqlString(string(raw))
+	}
+	return sqlString(buf.String())
+}
+
+// sqlString quotes s as an SQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// sqlIdentifier quotes name as an SQL identifier, so that any name can be a table or column.
+func sqlIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
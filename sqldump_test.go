@@ -0,0 +1,61 @@
+package rod
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestExportSQL(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		check(Put(tx, "users", "chilts", []byte(`{"Username":"chilts","Logins":1,"Admin":true,"Tags":["a", "b"]}`)))
+		check(Put(tx, "users", "o'brien", []byte(`{"Username":"o'brien","Logins":2.5,"Score":null,"Admin":false}`)))
+		check(Put(tx, "users", "zed", []byte(`{"Username":7,"_key":"ignored"}`)))
+		check(PutString(tx, "users", "plain", "not json"))
+		check(PutString(tx, "users.nested", "skipped", "yes"))
+		return nil
+	}))
+
+	t.Run("ExportSQL", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			var buf bytes.Buffer
+			check(ExportSQL(tx, "users", "", &buf))
+
+			want := `BEGIN;
+CREATE TABLE "users" (
+  "_key" TEXT PRIMARY KEY,
+  "Username" TEXT,
+  "Logins" NUMERIC,
+  "Admin" BOOLEAN,
+  "Tags" TEXT,
+  "Score" TEXT
+);
+INSERT INTO "users" ("_key", "Username", "Logins", "Admin", "Tags", "Score") VALUES ('chilts', 'chilts', 1, TRUE, '["a","b"]', NULL);
+INSERT INTO "users" ("_key", "Username", "Logins", "Admin", "Tags", "Score") VALUES ('o''brien', 'o''brien', 2.5, FALSE, NULL, NULL);
+INSERT INTO "users" ("_key", "Username", "Logins", "Admin", "Tags", "Score") VALUES ('plain', NULL, NULL, NULL, NULL, NULL);
+INSERT INTO "users" ("_key", "Username", "Logins", "Admin", "Tags", "Score") VALUES ('zed', '7', NULL, NULL, NULL, NULL);
+COMMIT;
+`
+			if buf.String() != want {
+				t.Fatalf("Unexpected SQL:\n%s", buf.String())
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ExportSQL - missing location", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			var buf bytes.Buffer
+			check(ExportSQL(tx, "users.nobody", "", &buf))
+			want := "BEGIN;\nCREATE TABLE \"users_nobody\" (\n  \"_key\" TEXT PRIMARY KEY\n);\nCOMMIT;\n"
+			if buf.String() != want {
+				t.Fatalf("Unexpected SQL:\n%s", buf.String())
+			}
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,116 @@
+package rod
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// StatsCacheTime is how long Stats keeps the key counts of each location before counting them again, since counting
+// has to read every page of the bucket when it has nested buckets.
+var StatsCacheTime = time.Minute
+
+// DBStats is a snapshot of a BoltDB's statistics, as returned by Stats.
+type DBStats struct {
+	// Bolt holds Bolt's own statistics, including the number of read transactions and the freelist's page counts.
+	Bolt bolt.Stats
+	// PageSize is the size of each page, and Size the size of the whole database, in bytes.
+	PageSize int
+	Size     int64
+	// Locations holds the number of keys in each top-level bucket (not including nested buckets), as counted at
+	// CountedAt.
+	Locations map[string]int
+	CountedAt time.Time
+}
+
+// statsCounts are the location counts last made for a database.
+type statsCounts struct {
+	sync.Mutex
+	locations map[string]int
+	at        time.Time
+}
+
+// statsCache holds the statsCounts for each open database given to Stats.
+var statsCache struct {
+	sync.Mutex
+	counts map[*bolt.DB]*statsCounts
+}
+
+// forgetStats drops the cached counts for db once it has been closed.
+func forgetStats(db *bolt.DB) {
+	statsCache.Lock()
+	defer statsCache.Unlock()
+	delete(statsCache.counts, db)
+}
+
+// Stats returns a snapshot of the statistics for db, for lightweight monitoring. The key counts of each location are
+// cached for StatsCacheTime, so Stats is cheap enough to call often. See PublishStats to publish them with expvar.
+// The cache for a Store's database is dropped when the Store is closed, and for any other database the first time
+// Stats is called on it once it has been closed.
+func Stats(db *bolt.DB) (DBStats, error) {
+	var stats DBStats
+
+	statsCache.Lock()
+	if statsCache.counts == nil {
+		statsCache.counts = make(map[*bolt.DB]*statsCounts)
+	}
+	counts, ok := statsCache.counts[db]
+	if !ok {
+		counts = &statsCounts{}
+		statsCache.counts[db] = counts
+	}
+	statsCache.Unlock()
+
+	counts.Lock()
+	defer counts.Unlock()
+	err := db.View(func(tx *bolt.Tx) error {
+		// Info reads the mmap, which is only safe while it can't be remapped, such as within a read transaction
+		stats.PageSize = db.Info().PageSize
+		stats.Size = tx.Size()
+		if counts.locations != nil && now().Sub(counts.at) < StatsCacheTime {
+			return nil
+		}
+
+		locations := make(map[string]int)
+		err := tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			n, err := Count(tx, string(name))
+			locations[string(name)] = n
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		counts.locations, counts.at = locations, now()
+		return nil
+	})
+	if err == bolt.ErrDatabaseNotOpen {
+		forgetStats(db)
+	}
+	if err != nil {
+		return DBStats{}, err
+	}
+
+	stats.Bolt = db.Stats()
+	stats.Locations = make(map[string]int, len(counts.locations))
+	for name, n := range counts.locations {
+		stats.Locations[name] = n
+	}
+	stats.CountedAt = counts.at
+	return stats, nil
+}
+
+// PublishStats publishes the Stats for db with expvar under name, so they show up at /debug/vars. As with
+// expvar.Publish, it panics if name has already been used.
+//
+//	rod.PublishStats("rod", s.DB())
+func PublishStats(name string, db *bolt.DB) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		stats, err := Stats(db)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		return stats
+	}))
+}
@@ -0,0 +1,99 @@
+package rod
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestStats(t *testing.T) {
+	db, cleanup := openTestDB(t)
+	defer cleanup()
+
+	clock := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		check(PutString(tx, "notes", "shopping", "milk"))
+		check(PutString(tx, "notes", "work", "report"))
+		check(PutString(tx, "user.chilts", "email", "andychilton@gmail.com"))
+		return PutString(tx, "user", "count", "1")
+	}))
+
+	t.Run("Snapshot", func(t *testing.T) {
+		stats, err := Stats(db)
+		check(err)
+		if stats.Locations["notes"] != 2 || stats.Locations["user"] != 1 {
+			t.Fatalf("Unexpected counts %v", stats.Locations)
+		}
+		if stats.PageSize == 0 || stats.Size < int64(stats.PageSize) {
+			t.Fatalf("Unexpected sizes %d and %d", stats.PageSize, stats.Size)
+		}
+		if stats.Bolt.TxN == 0 || !stats.CountedAt.Equal(clock) {
+			t.Fatalf("Unexpected stats %#v", stats)
+		}
+	})
+
+	t.Run("Counts are cached", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "notes", "holiday", "tickets")
+		}))
+
+		stats, err := Stats(db)
+		check(err)
+		if stats.Locations["notes"] != 2 {
+			t.Fatalf("The count should have been cached, but is %d", stats.Locations["notes"])
+		}
+
+		clock = clock.Add(StatsCacheTime)
+		stats, err = Stats(db)
+		check(err)
+		if stats.Locations["notes"] != 3 || !stats.CountedAt.Equal(clock) {
+			t.Fatalf("The count should have been refreshed, but is %d", stats.Locations["notes"])
+		}
+	})
+
+	t.Run("PublishStats", func(t *testing.T) {
+		PublishStats("rod-test", db)
+
+		var stats DBStats
+		check(json.Unmarshal([]byte(expvar.Get("rod-test").String()), &stats))
+		if stats.Locations["notes"] != 3 {
+			t.Fatalf("Unexpected published counts %v", stats.Locations)
+		}
+	})
+
+	t.Run("Closed databases are forgotten", func(t *testing.T) {
+		cached := func(db *bolt.DB) bool {
+			statsCache.Lock()
+			defer statsCache.Unlock()
+			_, ok := statsCache.counts[db]
+			return ok
+		}
+
+		other, done := openTestDB(t)
+		_, err := Stats(other)
+		check(err)
+		done()
+		if _, err := Stats(other); err != bolt.ErrDatabaseNotOpen {
+			t.Fatalf("Expected ErrDatabaseNotOpen but got %v", err)
+		}
+		if cached(other) {
+			t.Fatal("The counts for a closed database should have been dropped")
+		}
+
+		storeDB, done := openTestDB(t)
+		defer done()
+		s := NewStore(storeDB)
+		_, err = Stats(s.DB())
+		check(err)
+		check(s.Close())
+		if cached(storeDB) {
+			t.Fatal("The counts for a closed Store should have been dropped")
+		}
+	})
+}
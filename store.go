@@ -0,0 +1,135 @@
+package rod
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// Store wraps a *bolt.DB so that rod can offer helpers which need to manage their own transactions, rather than
+// working inside one of yours.
+type Store struct {
+	db *bolt.DB
+	// options are those db was opened with by Open, for CompactInPlace to reopen it with.
+	options *bolt.Options
+	// swapping is held by CompactInPlace while it replaces db, and held for reading by every transaction.
+	swapping  sync.RWMutex
+	hooks     []hook
+	views     []MaterializedView
+	schemas   map[string]*schema
+	replica   bool
+	readOnly  bool
+	changelog bool
+	observers []func(op Operation)
+	watchers  []watcher
+	cache     *readCache
+	// writeBehind is the queue for PutAsync, once EnableWriteBehind has been called.
+	writeBehind *writeBehind
+}
+
+// NewStore returns a Store wrapping the already opened BoltDB.
+func NewStore(db *bolt.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open opens (or creates) the BoltDB at path and returns a Store wrapping it. The mode and options are passed straight
+// through to bolt.Open().
+func Open(path string, mode os.FileMode, options *bolt.Options) (*Store, error) {
+	db, err := bolt.Open(path, mode, options)
+	if err != nil {
+		return nil, err
+	}
+	s := NewStore(db)
+	s.options = options
+	return s, nil
+}
+
+// DB returns the underlying *bolt.DB.
+func (s *Store) DB() *bolt.DB {
+	s.swapping.RLock()
+	defer s.swapping.RUnlock()
+	return s.db
+}
+
+// Close closes the underlying BoltDB, first writing anything still queued with PutAsync.
+func (s *Store) Close() error {
+	if s.writeBehind != nil {
+		s.writeBehind.close()
+	}
+
+	s.swapping.RLock()
+	defer s.swapping.RUnlock()
+	forgetStats(s.db)
+	return s.db.Close()
+}
+
+// Update runs fn inside a read-write transaction, exactly as bolt.DB.Update() does. Any features enabled on the Store
+// (such as EnableAudit) see the writes made with rod inside fn.
+func (s *Store) Update(fn func(tx *bolt.Tx) error) error {
+	return s.UpdateAs("", fn)
+}
+
+// UpdateAs is the same as Update except the writes are attributed to actor, such as in the audit log.
+func (s *Store) UpdateAs(actor string, fn func(tx *bolt.Tx) error) error {
+	if s.replica {
+		return ErrReadOnlyReplica
+	}
+	return s.update(actor, fn)
+}
+
+// update does the work for UpdateAs, even for a replica. Once the transaction has finished (and the Store is no longer
+// locked) the cache forgets the keys written to, and then any watchers are told about the changes.
+func (s *Store) update(actor string, fn func(tx *bolt.Tx) error) error {
+	if s.readOnly {
+		return ErrReadOnlyStore
+	}
+
+	st := &txState{store: s, actor: actor}
+	err := s.updateTx(st, fn)
+	if s.cache != nil && len(st.uncached) > 0 {
+		s.cache.invalidate(st.uncached)
+	}
+	if err == nil {
+		s.notifyWatchers(st.watched)
+	}
+	return err
+}
+
+func (s *Store) updateTx(st *txState, fn func(tx *bolt.Tx) error) error {
+	s.swapping.RLock()
+	defer s.swapping.RUnlock()
+
+	if len(s.hooks) == 0 && len(s.schemas) == 0 && len(s.observers) == 0 {
+		return s.db.Update(fn)
+	}
+
+	start := time.Now()
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		txStates.Store(tx, st)
+		defer txStates.Delete(tx)
+		return fn(tx)
+	})
+	s.observe(Operation{Name: "update", Start: start, Duration: time.Since(start), Err: err})
+	return err
+}
+
+// View runs fn inside a read-only transaction, exactly as bolt.DB.View() does.
+func (s *Store) View(fn func(tx *bolt.Tx) error) error {
+	s.swapping.RLock()
+	defer s.swapping.RUnlock()
+
+	if len(s.observers) == 0 && !s.readOnly {
+		return s.db.View(fn)
+	}
+
+	start := time.Now()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		txStates.Store(tx, &txState{store: s})
+		defer txStates.Delete(tx)
+		return fn(tx)
+	})
+	s.observe(Operation{Name: "view", Start: start, Duration: time.Since(start), Err: err})
+	return err
+}
@@ -0,0 +1,43 @@
+package rod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	store, err := Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(err)
+	defer store.Close()
+
+	t.Run("Update and View", func(t *testing.T) {
+		err := store.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "config", "name", "rod")
+		})
+		check(err)
+
+		err = store.View(func(tx *bolt.Tx) error {
+			name, err := GetString(tx, "config", "name")
+			check(err)
+			if name != "rod" {
+				t.Fatalf("Expected name to be 'rod' but was '%s'", name)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("DB", func(t *testing.T) {
+		if store.DB() == nil {
+			t.Fatal("DB() should return the underlying database")
+		}
+	})
+}
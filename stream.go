@@ -0,0 +1,54 @@
+package rod
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/boltdb/bolt"
+)
+
+// Item is a single key and its raw JSON value, as sent by StreamJson.
+type Item struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// StreamJson scans the bucket at location in a read-only transaction on its own goroutine, sending every key/value to
+// out in key order. Since out is yours, the amount of buffering (and therefore backpressure) is up to you. Each value is
+// copied out of the transaction so it can be used long after the scan has finished.
+//
+// The returned channel receives exactly one value once the scan has finished and out has been closed: nil if
+// everything was sent, ctx.Err() if the context was cancelled, or whatever error stopped the scan.
+//
+//	out := make(chan rod.Item, 100)
+//	errc := store.StreamJson(ctx, "events", out)
+//	for item := range out {
+//	    var ev Event
+//	    json.Unmarshal(item.Value, &ev)
+//	}
+//	if err := <-errc; err != nil {
+//	    ...
+//	}
+func (s *Store) StreamJson(ctx context.Context, location string, out chan<- Item) <-chan error {
+	errc := make(chan error, 1)
+
+	go func() {
+		err := s.View(func(tx *bolt.Tx) error {
+			return Each(tx, location, func(key string, value []byte) error {
+				raw := make(json.RawMessage, len(value))
+				copy(raw, value)
+
+				select {
+				case out <- Item{Key: key, Value: raw}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		})
+		close(out)
+		errc <- err
+	}()
+
+	return errc
+}
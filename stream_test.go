@@ -0,0 +1,60 @@
+package rod
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestStreamJson(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	store := NewStore(db)
+
+	err := store.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "car", "golf", Car{"Volkswagon", "Golf"}))
+		check(PutJson(tx, "car", "hilux", Car{"Toyota", "Hilux"}))
+		check(PutJson(tx, "car", "leaf", Car{"Nissan", "Leaf"}))
+		return nil
+	})
+	check(err)
+
+	t.Run("Stream everything", func(t *testing.T) {
+		out := make(chan Item)
+		errc := store.StreamJson(context.Background(), "car", out)
+
+		var cars []Car
+		for item := range out {
+			var car Car
+			check(json.Unmarshal(item.Value, &car))
+			cars = append(cars, car)
+		}
+		check(<-errc)
+
+		if len(cars) != 3 {
+			t.Fatalf("Three cars should have been streamed, but instead %d were", len(cars))
+		}
+		if cars[2].Model != "Leaf" {
+			t.Fatalf("Third car should have been a Leaf, not a %s", cars[2].Model)
+		}
+	})
+
+	t.Run("Cancel the stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := make(chan Item)
+		errc := store.StreamJson(ctx, "car", out)
+
+		// read one, then cancel without reading any more
+		<-out
+		cancel()
+
+		if err := <-errc; err != context.Canceled {
+			t.Fatalf("Expected context.Canceled, but got %v", err)
+		}
+		if _, ok := <-out; ok {
+			t.Fatal("The out channel should have been closed")
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package rod
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sort"
+
+	"github.com/boltdb/bolt"
+)
+
+// SyncBucket is the top-level bucket which a Store keeps its ID, and what it has seen from each peer, in.
+const SyncBucket = "__rod_sync"
+
+var (
+	// ErrChangelogNotEnabled is returned by Sync if either Store hasn't had EnableChangelog called.
+	ErrChangelogNotEnabled = errors.New("changelog not enabled")
+
+	// ErrSyncWithSelf is returned by Sync if both Stores are the same one.
+	ErrSyncWithSelf = errors.New("cannot sync a store with itself")
+)
+
+// Conflict is a key which was changed in both Stores since they last synced.
+type Conflict struct {
+	Location string
+	Key      string
+	// A and B are the latest change to the key in each Store.
+	A Change
+	B Change
+}
+
+// ConflictResolver decides what a key changed in both Stores should end up as, in both of them. It usually returns
+// either c.A or c.B, but may return a new change altogether, such as a merge of the two values.
+type ConflictResolver func(c Conflict) (Change, error)
+
+// LastWriteWins is a ConflictResolver keeping whichever change was made last, or A if they were made at the same
+// time. It relies on the clocks of the machines making the changes being close enough.
+func LastWriteWins(c Conflict) (Change, error) {
+	if c.B.Time.After(c.A.Time) {
+		return c.B, nil
+	}
+	return c.A, nil
+}
+
+// ResolveByLocation returns a ConflictResolver using the resolver for the conflict's location, or fallback if there
+// isn't one.
+//
+//	resolver := rod.ResolveByLocation(map[string]rod.ConflictResolver{
+//	    "settings": keepServer,
+//	}, rod.LastWriteWins)
+func ResolveByLocation(resolvers map[string]ConflictResolver, fallback ConflictResolver) ConflictResolver {
+	return func(c Conflict) (Change, error) {
+		if resolver, ok := resolvers[c.Location]; ok {
+			return resolver(c)
+		}
+		return fallback(c)
+	}
+}
+
+// StoreID returns the Store's ID, which is made when first asked for and then kept in SyncBucket. It tells the Stores
+// taking part in Sync apart.
+func StoreID(s *Store) (string, error) {
+	var id string
+	err := s.View(func(tx *bolt.Tx) error {
+		var err error
+		id, err = GetString(tx, SyncBucket, "id")
+		return err
+	})
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	err = s.update("", func(tx *bolt.Tx) error {
+		id, err = replicaID(tx)
+		return err
+	})
+	return id, err
+}
+
+// replicaID returns the ID of the Store tx belongs to, making it first if it doesn't have one yet (which needs tx to be
+// writable).
+func replicaID(tx Tx) (string, error) {
+	id, err := GetString(tx, SyncBucket, "id")
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	b, err := createBucket(tx, SyncBucket)
+	if err != nil {
+		return "", err
+	}
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	id = hex.EncodeToString(raw)
+	// this isn't done with putKey, since the ID belongs to this Store alone
+	return id, b.Put([]byte("id"), []byte(id))
+}
+
+// Sync exchanges the changes made in a and b since they last synced, such as between a laptop and a server, so that
+// they both end up the same. Both must have their changelog enabled. Keys which were only changed on one side are
+// copied to the other, and keys changed on both are given to resolver (LastWriteWins if it is nil) to decide between.
+//
+//	err := rod.Sync(laptop, server, rod.LastWriteWins)
+//
+// Both Stores are written to in a single transaction each, which are held at once, so changes made during a Sync are
+// simply picked up by the next one. Changes a Store received from a peer are never sent back to that peer, so Stores
+// can be synced in any pattern which forms a tree (such as many laptops each syncing with one server) but not in a
+// loop. The Store of a FollowerStore may only be changed by replication, so if either is one then ErrReadOnlyReplica
+// is returned.
+func Sync(a, b *Store, resolver ConflictResolver) error {
+	if a.replica || b.replica {
+		return ErrReadOnlyReplica
+	}
+	if !a.changelog || !b.changelog {
+		return ErrChangelogNotEnabled
+	}
+	if resolver == nil {
+		resolver = LastWriteWins
+	}
+
+	aID, err := StoreID(a)
+	if err != nil {
+		return err
+	}
+	bID, err := StoreID(b)
+	if err != nil {
+		return err
+	}
+	if aID == bID {
+		return ErrSyncWithSelf
+	}
+
+	// always take the writers in the same order, so that two Syncs can't wait on each other
+	first, second := a, b
+	if bID < aID {
+		first, second = b, a
+	}
+	return first.update("", func(ftx *bolt.Tx) error {
+		return second.update("", func(stx *bolt.Tx) error {
+			atx, btx := ftx, stx
+			if first != a {
+				atx, btx = stx, ftx
+			}
+			return syncTxs(&syncSide{tx: atx, id: aID}, &syncSide{tx: btx, id: bID}, resolver)
+		})
+	})
+}
+
+// syncSide is one of the Stores in a Sync.
+type syncSide struct {
+	tx Tx
+	id string
+	// changes are those made since the other side last saw, which didn't come from it
+	changes []Change
+	// last is the Seq of the last change read, including those which did come from the other side
+	last   uint64
+	latest map[string]Change
+}
+
+// read finds the changes made to s since peer last synced with it.
+func (s *syncSide) read(peer *syncSide) error {
+	since, err := seen(peer.tx, s.id)
+	if err != nil {
+		return err
+	}
+	changes, err := ReadChanges(s.tx, since, 0)
+	if err != nil {
+		return err
+	}
+
+	s.last = since
+	s.latest = make(map[string]Change)
+	for _, change := range changes {
+		s.last = change.Seq
+		if change.Origin == peer.id {
+			continue
+		}
+		s.changes = append(s.changes, change)
+		s.latest[syncKey(change)] = change
+	}
+	return nil
+}
+
+// apply makes the change to s, as having come from peer.
+func (s *syncSide) apply(change Change, peer *syncSide) error {
+	change.Origin = peer.id
+	return applyChange(s.tx, change)
+}
+
+func syncKey(c Change) string {
+	return c.Location + "\x00" + c.Key
+}
+
+// sameChange tells you whether a and b leave a key the same.
+func sameChange(a, b Change) bool {
+	return a.Op == b.Op && (a.Op == "del" || bytes.Equal(a.Value, b.Value))
+}
+
+// seen returns the Seq of the last change from peer which tx's Store has seen.
+func seen(tx Tx, peer string) (uint64, error) {
+	raw, err := Get(tx, SyncBucket, "seen."+peer)
+	if err != nil || len(raw) != 8 {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(raw), nil
+}
+
+// setSeen records that tx's Store has seen every change from peer up to seq.
+func setSeen(tx Tx, peer string, seq uint64) error {
+	// this isn't done with putKey, since what has been seen belongs to this Store alone
+	b, err := createBucket(tx, SyncBucket)
+	if err != nil {
+		return err
+	}
+	raw := make([]byte, 8)
+	binary.BigEndian.PutUint64(raw, seq)
+	return b.Put([]byte("seen."+peer), raw)
+}
+
+// syncTxs does the work for Sync, once both transactions are open.
+func syncTxs(a, b *syncSide, resolver ConflictResolver) error {
+	if err := a.read(b); err != nil {
+		return err
+	}
+	if err := b.read(a); err != nil {
+		return err
+	}
+
+	// the keys changed on both sides
+	var conflicts []string
+	for key := range a.latest {
+		if _, ok := b.latest[key]; ok {
+			conflicts = append(conflicts, key)
+		}
+	}
+	sort.Strings(conflicts)
+	conflicted := make(map[string]bool, len(conflicts))
+	for _, key := range conflicts {
+		conflicted[key] = true
+	}
+
+	// everything else is simply copied across, in order
+	for _, change := range a.changes {
+		if !conflicted[syncKey(change)] {
+			if err := b.apply(change, a); err != nil {
+				return err
+			}
+		}
+	}
+	for _, change := range b.changes {
+		if !conflicted[syncKey(change)] {
+			if err := a.apply(change, b); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range conflicts {
+		ac, bc := a.latest[key], b.latest[key]
+		resolved, err := resolver(Conflict{Location: ac.Location, Key: ac.Key, A: ac, B: bc})
+		if err != nil {
+			return err
+		}
+		resolved.Location, resolved.Key = ac.Location, ac.Key
+		if !sameChange(resolved, ac) {
+			if err := a.apply(resolved, b); err != nil {
+				return err
+			}
+		}
+		if !sameChange(resolved, bc) {
+			if err := b.apply(resolved, a); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := setSeen(a.tx, b.id, b.last); err != nil {
+		return err
+	}
+	return setSeen(b.tx, a.id, a.last)
+}
@@ -0,0 +1,192 @@
+package rod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	laptop, err := Open(filepath.Join(dir, "laptop.db"), 0666, nil)
+	check(err)
+	defer laptop.Close()
+	laptop.EnableChangelog()
+
+	server, err := Open(filepath.Join(dir, "server.db"), 0666, nil)
+	check(err)
+	defer server.Close()
+	server.EnableChangelog()
+
+	clock := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	getString := func(s *Store, location, key string) string {
+		var value string
+		check(s.View(func(tx *bolt.Tx) error {
+			var err error
+			value, err = GetString(tx, location, key)
+			return err
+		}))
+		return value
+	}
+
+	putString := func(s *Store, location, key, value string) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, location, key, value)
+		}))
+	}
+
+	changes := func(s *Store) uint64 {
+		var seq uint64
+		check(s.View(func(tx *bolt.Tx) error {
+			all, err := ReadChanges(tx, 0, 0)
+			seq = uint64(len(all))
+			return err
+		}))
+		return seq
+	}
+
+	t.Run("Requires the changelog", func(t *testing.T) {
+		plain, err := Open(filepath.Join(dir, "plain.db"), 0666, nil)
+		check(err)
+		defer plain.Close()
+
+		if err := Sync(laptop, plain, nil); err != ErrChangelogNotEnabled {
+			t.Fatalf("Expected ErrChangelogNotEnabled but got %v", err)
+		}
+		if err := Sync(laptop, laptop, nil); err != ErrSyncWithSelf {
+			t.Fatalf("Expected ErrSyncWithSelf but got %v", err)
+		}
+	})
+
+	t.Run("Replicas can't be synced", func(t *testing.T) {
+		replica, err := Open(filepath.Join(dir, "replica.db"), 0666, nil)
+		check(err)
+		defer replica.Close()
+		replica.EnableChangelog()
+		NewFollowerStore(server, replica)
+
+		for _, pair := range [][2]*Store{{laptop, replica}, {replica, laptop}} {
+			if err := Sync(pair[0], pair[1], nil); err != ErrReadOnlyReplica {
+				t.Fatalf("Expected ErrReadOnlyReplica but got %v", err)
+			}
+		}
+	})
+
+	t.Run("StoreID is kept", func(t *testing.T) {
+		id1, err := StoreID(laptop)
+		check(err)
+		id2, err := StoreID(laptop)
+		check(err)
+		other, err := StoreID(server)
+		check(err)
+		if id1 == "" || id1 != id2 || id1 == other {
+			t.Fatalf("Unexpected IDs %q, %q and %q", id1, id2, other)
+		}
+	})
+
+	t.Run("Changes go both ways", func(t *testing.T) {
+		putString(laptop, "notes", "shopping", "milk")
+		putString(server, "notes", "work", "report")
+		check(server.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "notes", "old", "gone soon"))
+			return Del(tx, "notes", "old")
+		}))
+
+		check(Sync(laptop, server, nil))
+
+		for _, s := range []*Store{laptop, server} {
+			if got := getString(s, "notes", "shopping"); got != "milk" {
+				t.Fatalf("shopping should be 'milk' but is %q", got)
+			}
+			if got := getString(s, "notes", "work"); got != "report" {
+				t.Fatalf("work should be 'report' but is %q", got)
+			}
+			if got := getString(s, "notes", "old"); got != "" {
+				t.Fatalf("old should have been deleted but is %q", got)
+			}
+		}
+	})
+
+	t.Run("Nothing is sent back", func(t *testing.T) {
+		laptopSeq, serverSeq := changes(laptop), changes(server)
+		check(Sync(laptop, server, nil))
+		check(Sync(server, laptop, nil))
+		if changes(laptop) != laptopSeq || changes(server) != serverSeq {
+			t.Fatalf("Syncing again should not have changed anything")
+		}
+	})
+
+	t.Run("Last write wins", func(t *testing.T) {
+		putString(server, "notes", "shopping", "eggs")
+		clock = clock.Add(time.Minute)
+		putString(laptop, "notes", "shopping", "bread")
+		clock = clock.Add(time.Minute)
+		putString(server, "notes", "work", "slides")
+		putString(laptop, "notes", "work", "email")
+		putString(laptop, "notes", "work", "slides")
+
+		check(Sync(server, laptop, LastWriteWins))
+
+		for _, s := range []*Store{laptop, server} {
+			if got := getString(s, "notes", "shopping"); got != "bread" {
+				t.Fatalf("shopping should be 'bread' but is %q", got)
+			}
+			if got := getString(s, "notes", "work"); got != "slides" {
+				t.Fatalf("work should be 'slides' but is %q", got)
+			}
+		}
+
+		laptopSeq, serverSeq := changes(laptop), changes(server)
+		check(Sync(laptop, server, nil))
+		if changes(laptop) != laptopSeq || changes(server) != serverSeq {
+			t.Fatalf("Syncing again should not have changed anything")
+		}
+	})
+
+	t.Run("Resolve by location", func(t *testing.T) {
+		clock = clock.Add(time.Minute)
+		putString(laptop, "settings", "theme", "dark")
+		putString(laptop, "notes", "shopping", "jam")
+		clock = clock.Add(time.Minute)
+		putString(server, "settings", "theme", "light")
+		putString(server, "notes", "shopping", "tea")
+
+		keepLaptop := func(c Conflict) (Change, error) {
+			return c.A, nil
+		}
+		merge := func(c Conflict) (Change, error) {
+			c.A.Value = append(append(c.A.Value, '+'), c.B.Value...)
+			return c.A, nil
+		}
+		resolver := ResolveByLocation(map[string]ConflictResolver{
+			"settings": keepLaptop,
+			"notes":    merge,
+		}, LastWriteWins)
+		check(Sync(laptop, server, resolver))
+
+		for _, s := range []*Store{laptop, server} {
+			if got := getString(s, "settings", "theme"); got != "dark" {
+				t.Fatalf("theme should be 'dark' but is %q", got)
+			}
+			if got := getString(s, "notes", "shopping"); got != "jam+tea" {
+				t.Fatalf("shopping should be 'jam+tea' but is %q", got)
+			}
+		}
+
+		laptopSeq, serverSeq := changes(laptop), changes(server)
+		check(Sync(server, laptop, nil))
+		if changes(laptop) != laptopSeq || changes(server) != serverSeq {
+			t.Fatalf("Syncing again should not have changed anything")
+		}
+	})
+}
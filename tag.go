@@ -0,0 +1,195 @@
+package rod
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrKeyFieldNeeded is returned from PutJsonAuto when the value isn't a struct with a field tagged `rod:"key"`.
+var ErrKeyFieldNeeded = errors.New("provided value must be a struct with a `rod:\"key\"` field")
+
+// taggedField finds the field in struct type t (including those promoted from embedded structs) whose `rod` tag
+// contains name, returning its index for FieldByIndex, or nil if there isn't one.
+func taggedField(t reflect.Type, name string) []int {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, f := range reflect.VisibleFields(t) {
+		for _, opt := range strings.Split(f.Tag.Get("rod"), ",") {
+			if opt == name {
+				return f.Index
+			}
+		}
+	}
+	return nil
+}
+
+// taggedFields is like taggedField but returns every field whose `rod` tag contains name.
+func taggedFields(t reflect.Type, name string) [][]int {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields [][]int
+	for _, f := range reflect.VisibleFields(t) {
+		for _, opt := range strings.Split(f.Tag.Get("rod"), ",") {
+			if opt == name {
+				fields = append(fields, f.Index)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// structTags holds the index of each of the tagged fields in a struct type, with nil for those it doesn't have.
+type structTags struct {
+	created []int
+	updated []int
+	version []int
+	encrypt [][]int
+}
+
+// tagCache holds the *structTags for each struct type seen, so VisibleFields is only walked once.
+var tagCache sync.Map
+
+func tagsFor(t reflect.Type) *structTags {
+	if tags, ok := tagCache.Load(t); ok {
+		return tags.(*structTags)
+	}
+	tags := &structTags{
+		created: taggedField(t, "created"),
+		updated: taggedField(t, "updated"),
+		version: taggedField(t, "version"),
+		encrypt: encryptedFields(t),
+	}
+	tagCache.Store(t, tags)
+	return tags
+}
+
+// prepareJson deals with any tagged fields in v before PutJson marshals it, returning what should be marshalled in
+// place of v. This checks and increments the `rod:"version"` field, then stamps the `rod:"created"` and
+// `rod:"updated"` fields, using the record already stored at key. It works on a copy so that v is left as it was if
+// the put fails, and returns done to be called once it has succeeded, which sets the fields on what v points to (if it
+// is a pointer) so the caller sees them.
+func prepareJson(tx Tx, location, key string, v interface{}) (interface{}, func(), error) {
+	ref := reflect.ValueOf(v)
+	if !ref.IsValid() || (ref.Kind() == reflect.Ptr && ref.IsNil()) {
+		return v, func() {}, nil
+	}
+	t := reflect.Indirect(ref).Type()
+	if t.Kind() != reflect.Struct {
+		return v, func() {}, nil
+	}
+	tags := tagsFor(t)
+	if tags.created == nil && tags.updated == nil && tags.version == nil {
+		return v, func() {}, nil
+	}
+
+	// get hold of a copy we can set
+	item := reflect.New(t).Elem()
+	item.Set(reflect.Indirect(ref))
+
+	// decode the stored record, if there is one
+	var existing reflect.Value
+	raw, err := Get(tx, location, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if raw != nil {
+		existing = reflect.New(t)
+		if err := json.Unmarshal(raw, existing.Interface()); err != nil {
+			return nil, nil, err
+		}
+		existing = existing.Elem()
+	}
+
+	if tags.version != nil {
+		if err := bumpVersion(item, existing, tags.version); err != nil {
+			return nil, nil, err
+		}
+	}
+	stampTimes(item, existing, tags, now())
+
+	if ref.Kind() != reflect.Ptr {
+		return item.Interface(), func() {}, nil
+	}
+	done := func() {
+		for _, index := range [][]int{tags.version, tags.created, tags.updated} {
+			if index == nil {
+				continue
+			}
+			if field, err := ref.Elem().FieldByIndexErr(index); err == nil && field.CanSet() {
+				field.Set(item.FieldByIndex(index))
+			}
+		}
+	}
+	return item.Addr().Interface(), done, nil
+}
+
+// PutJsonAuto is the same as PutJson except the key is taken from the field in v tagged `rod:"key"`, which must be a
+// string or an integer. This saves passing both the record and its ID everywhere.
+//
+//	type User struct {
+//	    Username string `json:"-" rod:"key"`
+//	    Email    string
+//	}
+//
+//	err := rod.PutJsonAuto(tx, "user", &user)
+//
+// All and Find fill the tagged field back in with the record's key, so it may be left out of the JSON as above.
+func PutJsonAuto(tx Tx, location string, v interface{}) error {
+	ref := reflect.Indirect(reflect.ValueOf(v))
+	if !ref.IsValid() {
+		return wrapErr("PutJsonAuto", location, "", ErrKeyFieldNeeded)
+	}
+
+	index := taggedField(ref.Type(), "key")
+	if index == nil {
+		return wrapErr("PutJsonAuto", location, "", ErrKeyFieldNeeded)
+	}
+
+	field, err := ref.FieldByIndexErr(index)
+	if err != nil {
+		return wrapErr("PutJsonAuto", location, "", ErrKeyFieldNeeded)
+	}
+	key, ok := keyFromField(field)
+	if !ok {
+		return wrapErr("PutJsonAuto", location, "", ErrKeyFieldNeeded)
+	}
+
+	return wrapErr("PutJsonAuto", location, key, PutJson(tx, location, key, v))
+}
+
+// keyFromField formats the key field as a string, if it is of a suitable kind.
+func keyFromField(field reflect.Value) (string, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), true
+	}
+	return "", false
+}
+
+// setKeyField puts key into the key field, the reverse of keyFromField. Keys which don't parse as the field's kind are
+// left out.
+func setKeyField(field reflect.Value, key string) {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(key, 10, field.Type().Bits()); err == nil {
+			field.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(key, 10, field.Type().Bits()); err == nil {
+			field.SetUint(n)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type Account struct {
+	Username string `json:"-" rod:"key"`
+	Email    string
+}
+
+type Order struct {
+	ID    int64 `rod:"key"`
+	Total int
+}
+
+func TestPutJsonAuto(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("String keys", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutJsonAuto(tx, "account", &Account{"chilts", "andy@example.com"}))
+			check(PutJsonAuto(tx, "account", Account{"bob", "bob@example.com"}))
+
+			// the key isn't in the JSON
+			raw, err := GetString(tx, "account", "chilts")
+			check(err)
+			if raw != `{"Email":"andy@example.com"}` {
+				t.Fatalf("Unexpected JSON: %s", raw)
+			}
+
+			// but All fills it back in
+			var accounts []Account
+			check(All(tx, "account", &accounts))
+			if len(accounts) != 2 || accounts[0].Username != "bob" || accounts[1].Username != "chilts" {
+				t.Fatalf("Expected the keys to be filled in: %#v", accounts)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Integer keys", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutJsonAuto(tx, "order", &Order{ID: 7, Total: 100}))
+
+			var orders []*Order
+			check(Find(tx, "order", func(raw []byte) bool { return true }, &orders))
+			if len(orders) != 1 || orders[0].ID != 7 {
+				t.Fatalf("Expected order 7: %#v", orders)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Without a key field", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return PutJsonAuto(tx, "user", &User{"chilts", 1})
+		})
+		if !errors.Is(err, ErrKeyFieldNeeded) {
+			t.Fatalf("Expected ErrKeyFieldNeeded but got %v", err)
+		}
+
+		err = db.Update(func(tx *bolt.Tx) error {
+			return PutJsonAuto(tx, "account", &Account{})
+		})
+		if !errors.Is(err, ErrKeyNotProvided) {
+			t.Fatalf("Expected ErrKeyNotProvided but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package rod
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// TierBucket is the top-level bucket in which a Tiered keeps when each record was last touched, and whether it has been
+// moved to the cold store, beneath a bucket for its location.
+const TierBucket = "__rod_tier"
+
+// ColdStore is where a Tiered moves the records which haven't been touched for a while, such as another BoltDB (see
+// BoltColdStore) or an object store. Get returns nil for a record which isn't there.
+type ColdStore interface {
+	Get(location, key string) ([]byte, error)
+	Put(location, key string, value []byte) error
+	Delete(location, key string) error
+}
+
+// BoltColdStore returns a ColdStore which keeps the records in db, at the same locations as in the primary.
+func BoltColdStore(db *bolt.DB) ColdStore {
+	return boltCold{db}
+}
+
+type boltCold struct {
+	db *bolt.DB
+}
+
+func (c boltCold) Get(location, key string) ([]byte, error) {
+	var value []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		raw, err := Get(tx, location, key)
+		value = copyBytes(raw)
+		return err
+	})
+	return value, err
+}
+
+func (c boltCold) Put(location, key string, value []byte) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return Put(tx, location, key, value)
+	})
+}
+
+func (c boltCold) Delete(location, key string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return Del(tx, location, key)
+	})
+}
+
+// tierRecord is what a Tiered keeps for each record of its locations.
+type tierRecord struct {
+	Touched time.Time
+	Cold    bool
+}
+
+// Tiered keeps the records of some locations of a Store small by moving those which haven't been touched for a while
+// into a ColdStore, fetching them back when they are asked for. Records are written with rod as usual, inside the
+// Store's Update, and read with the Tiered's Get or GetJson, which look in the cold store for a record which has been
+// moved there and move it back.
+//
+//	cold, err := bolt.Open("archive.db", 0666, nil)
+//	tiered := rod.NewTiered(store, rod.BoltColdStore(cold), 30*24*time.Hour, "orders")
+//	n, err := tiered.Demote("orders") // every night, say
+//	err = tiered.GetJson("orders", id, &order)
+//
+// A record is touched when it is put, or got through the Tiered. Moving records between the stores doesn't count as a
+// change, so it isn't seen by the changelog, watchers or views. Reads made with rod directly (such as Get or All) only
+// see the records which haven't been moved.
+type Tiered struct {
+	store     *Store
+	cold      ColdStore
+	after     time.Duration
+	locations map[string]bool
+
+	mu      sync.Mutex
+	touched map[cacheKey]time.Time
+}
+
+// NewTiered returns a Tiered which moves records of the locations to cold once they haven't been touched for after.
+// Since it adds a hook to the Store to see which records are put, call NewTiered before using the Store, as with
+// AddView.
+func NewTiered(s *Store, cold ColdStore, after time.Duration, locations ...string) *Tiered {
+	t := &Tiered{store: s, cold: cold, after: after, locations: map[string]bool{}, touched: map[cacheKey]time.Time{}}
+	for _, location := range locations {
+		t.locations[location] = true
+	}
+	s.hooks = append(s.hooks, t.hook)
+	return t
+}
+
+func tierLocation(location string) string {
+	return TierBucket + "." + location
+}
+
+// putTier puts the tier record for location/key. This isn't done with putKey, since the tier records are about this
+// Store and mustn't be seen by the changelog, watchers or views (nor by the Tiered's own hook).
+func putTier(tx Tx, location, key string, rec tierRecord) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b, err := createBucket(tx, tierLocation(location))
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), raw)
+}
+
+// delTier deletes the tier record for location/key, without delKey for the same reason as putTier.
+func delTier(tx Tx, location, key string) error {
+	b, err := BucketAt(tx, tierLocation(location))
+	if err != nil || b == nil {
+		return err
+	}
+	return b.Delete([]byte(key))
+}
+
+// hook keeps the tier record of each record put or deleted. If the record was in the cold store then that copy is
+// out of date, so it is deleted once the transaction has committed.
+func (t *Tiered) hook(tx Tx, st *txState, m *Mutation) error {
+	if !t.locations[m.Location] {
+		return nil
+	}
+
+	var rec tierRecord
+	if _, err := GetJsonFound(tx, tierLocation(m.Location), m.Key, &rec); err != nil {
+		return err
+	}
+	if rec.Cold {
+		if btx, ok := tx.(*bolt.Tx); ok {
+			btx.OnCommit(func() {
+				t.cold.Delete(m.Location, m.Key)
+			})
+		}
+	}
+
+	if m.Op == "del" {
+		return delTier(tx, m.Location, m.Key)
+	}
+	return putTier(tx, m.Location, m.Key, tierRecord{Touched: now()})
+}
+
+// Get returns the record at location/key, from the cold store if it has been moved there, in which case it is moved
+// back. As with Get it returns nil if there is no such record, but unlike Get the value is a copy.
+func (t *Tiered) Get(location, key string) ([]byte, error) {
+	var value []byte
+	var cold bool
+	err := t.store.View(func(tx *bolt.Tx) error {
+		raw, err := Get(tx, location, key)
+		if err != nil || raw != nil {
+			value = copyBytes(raw)
+			return err
+		}
+		var rec tierRecord
+		_, err = GetJsonFound(tx, tierLocation(location), key, &rec)
+		cold = rec.Cold
+		return err
+	})
+	if err != nil {
+		return nil, wrapErr("Get", location, key, err)
+	}
+	if value != nil {
+		t.mu.Lock()
+		t.touched[cacheKey{location, key}] = now()
+		t.mu.Unlock()
+		return value, nil
+	}
+	if !cold {
+		return nil, nil
+	}
+
+	value, err = t.cold.Get(location, key)
+	if err != nil || value == nil {
+		return nil, wrapErr("Get", location, key, err)
+	}
+	return value, wrapErr("Get", location, key, t.promote(location, key, value))
+}
+
+// GetJson is like GetJson for a record got with Get.
+func (t *Tiered) GetJson(location, key string, v interface{}) error {
+	raw, err := t.Get(location, key)
+	if err != nil || raw == nil {
+		return err
+	}
+	err = t.store.View(func(tx *bolt.Tx) error {
+		return loadJson(tx, raw, v)
+	})
+	return wrapErr("GetJson", location, key, err)
+}
+
+// Del deletes the record at location/key from both stores. Use it rather than Del, which can't delete a record which
+// has been moved to the cold store.
+func (t *Tiered) Del(location, key string) error {
+	err := t.store.Update(func(tx *bolt.Tx) error {
+		if err := Del(tx, location, key); err != nil {
+			return err
+		}
+		return delTier(tx, location, key)
+	})
+	if err != nil {
+		return wrapErr("Del", location, key, err)
+	}
+	return wrapErr("Del", location, key, t.cold.Delete(location, key))
+}
+
+// promote moves the record got from the cold store back into the Store, unless it has been written to since.
+func (t *Tiered) promote(location, key string, value []byte) error {
+	var promoted bool
+	err := t.store.Update(func(tx *bolt.Tx) error {
+		var rec tierRecord
+		if _, err := GetJsonFound(tx, tierLocation(location), key, &rec); err != nil || !rec.Cold {
+			return err
+		}
+		b, err := createBucket(tx, location)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(key), value); err != nil {
+			return err
+		}
+		promoted = true
+		return putTier(tx, location, key, tierRecord{Touched: now()})
+	})
+	if err != nil || !promoted {
+		return err
+	}
+	return t.cold.Delete(location, key)
+}
+
+// Demote moves every record in location which hasn't been touched for the Tiered's period into the cold store,
+// returning how many were moved. A record which was there before the Tiered first saw it counts as being touched the
+// first time Demote sees it.
+func (t *Tiered) Demote(location string) (int, error) {
+	t.mu.Lock()
+	touched := t.touched
+	t.touched = map[cacheKey]time.Time{}
+	t.mu.Unlock()
+
+	cutoff := now().Add(-t.after)
+	moved := 0
+	err := t.store.Update(func(tx *bolt.Tx) error {
+		b, err := BucketAt(tx, location)
+		if err != nil || b == nil {
+			return err
+		}
+
+		var demote []KV
+		var seen []string
+		err = Each(tx, location, func(key string, value []byte) error {
+			var rec tierRecord
+			if _, err := GetJsonFound(tx, tierLocation(location), key, &rec); err != nil {
+				return err
+			}
+			if at, ok := touched[cacheKey{location, key}]; ok && at.After(rec.Touched) {
+				rec.Touched = at
+			}
+			switch {
+			case rec.Touched.IsZero():
+				seen = append(seen, key)
+			case rec.Touched.Before(cutoff):
+				demote = append(demote, KV{Key: key, Value: copyBytes(value)})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range seen {
+			if err := putTier(tx, location, key, tierRecord{Touched: now()}); err != nil {
+				return err
+			}
+		}
+		for _, item := range demote {
+			if err := t.cold.Put(location, item.Key, item.Value); err != nil {
+				return err
+			}
+			// deleted directly, since moving the record isn't a change to it
+			if err := b.Delete([]byte(item.Key)); err != nil {
+				return err
+			}
+			if err := putTier(tx, location, item.Key, tierRecord{Cold: true}); err != nil {
+				return err
+			}
+		}
+		moved = len(demote)
+		return nil
+	})
+	return moved, wrapErr("Demote", location, "", err)
+}
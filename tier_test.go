@@ -0,0 +1,120 @@
+package rod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestTiered(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	coldDB, coldDone := openTestDB(t)
+	defer coldDone()
+
+	clock := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	// a record from before the Tiered was added
+	check(db.Update(func(tx *bolt.Tx) error {
+		return PutString(tx, "orders", "old", "from before")
+	}))
+
+	s := NewStore(db)
+	var changes int
+	s.Watch("orders", func(m Mutation) { changes++ })
+	var tierChanges int
+	s.Watch(tierLocation("orders"), func(m Mutation) { tierChanges++ })
+	tiered := NewTiered(s, BoltColdStore(coldDB), 24*time.Hour, "orders")
+
+	check(s.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "orders", "1", User{"chilts", 1}))
+		return PutJson(tx, "orders", "2", User{"bob", 2})
+	}))
+	changes = 0
+
+	hot := func(key string) bool {
+		var found bool
+		check(db.View(func(tx *bolt.Tx) error {
+			var err error
+			found, err = Exists(tx, "orders", key)
+			return err
+		}))
+		return found
+	}
+
+	t.Run("Demote", func(t *testing.T) {
+		n, err := tiered.Demote("orders")
+		check(err)
+		if n != 0 {
+			t.Fatalf("Nothing should be old enough to demote yet, but %d were", n)
+		}
+
+		// touch order 2 half way through
+		clock = clock.Add(12 * time.Hour)
+		_, err = tiered.Get("orders", "2")
+		check(err)
+
+		clock = clock.Add(13 * time.Hour)
+		n, err = tiered.Demote("orders")
+		check(err)
+		if n != 2 || hot("1") || hot("old") || !hot("2") {
+			t.Fatalf("Orders 1 and old should have been demoted, not %d", n)
+		}
+		if changes != 0 {
+			t.Fatal("Moving records isn't a change to them")
+		}
+
+		v, err := BoltColdStore(coldDB).Get("orders", "1")
+		check(err)
+		if v == nil {
+			t.Fatal("The order should be in the cold store")
+		}
+	})
+
+	t.Run("Read-through", func(t *testing.T) {
+		var user User
+		check(tiered.GetJson("orders", "1", &user))
+		if user.Username != "chilts" || !hot("1") {
+			t.Fatalf("The order should have been fetched and moved back: %#v", user)
+		}
+		v, err := BoltColdStore(coldDB).Get("orders", "1")
+		check(err)
+		if v != nil {
+			t.Fatal("The order should have left the cold store")
+		}
+
+		missing, err := tiered.Get("orders", "nope")
+		check(err)
+		if missing != nil {
+			t.Fatal("A missing record should be nil")
+		}
+	})
+
+	t.Run("Writes and deletes", func(t *testing.T) {
+		// a write replaces the cold copy
+		check(s.Update(func(tx *bolt.Tx) error {
+			return PutString(tx, "orders", "old", "new value")
+		}))
+		v, err := BoltColdStore(coldDB).Get("orders", "old")
+		check(err)
+		if v != nil {
+			t.Fatal("A write should delete the out-of-date cold copy")
+		}
+
+		clock = clock.Add(48 * time.Hour)
+		_, err = tiered.Demote("orders")
+		check(err)
+		check(tiered.Del("orders", "old"))
+		got, err := tiered.Get("orders", "old")
+		check(err)
+		if got != nil || hot("old") {
+			t.Fatal("Del should delete the record from both stores")
+		}
+		if tierChanges != 0 {
+			t.Fatalf("The tier records aren't changes either, but %d were seen", tierChanges)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+// ErrTimeOutOfRange is returned when a time can't be used as a key since its year (in UTC) is outside 0 to 9999.
+var ErrTimeOutOfRange = errors.New("time must be between the years 0 and 9999 to be used as a key")
+
+// ErrInvalidTimeKey is returned when a key can't be decoded by ParseTimeKey.
+var ErrInvalidTimeKey = errors.New("key is not a time key")
+
+// timeKeyLayout is fixed width (RFC3339Nano trims trailing zeros) and always in UTC, so keys sort in time order.
+const timeKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// TimeKey encodes t as a key which sorts in time order, in the form "2006-01-02T15:04:05.000000000Z". The time is
+// converted to UTC first, so the original zone is lost.
+func TimeKey(t time.Time) (string, error) {
+	t = t.UTC()
+	if t.Year() < 0 || t.Year() > 9999 {
+		return "", ErrTimeOutOfRange
+	}
+	return t.Format(timeKeyLayout), nil
+}
+
+// ParseTimeKey decodes a key made by TimeKey back into its time, in UTC.
+func ParseTimeKey(key string) (time.Time, error) {
+	t, err := time.Parse(timeKeyLayout, key)
+	if err != nil {
+		return time.Time{}, ErrInvalidTimeKey
+	}
+	return t, nil
+}
+
+// PutAtTime puts the value into the bucket at location using TimeKey(t) as the key. Two values put at exactly the same
+// time share a key, so the second overwrites the first. If that matters to you then include something unique in the
+// key yourself.
+func PutAtTime(tx Tx, location string, t time.Time, value []byte) error {
+	key, err := TimeKey(t)
+	if err != nil {
+		return wrapErr("PutAtTime", location, "", err)
+	}
+	return wrapErr("PutAtTime", location, key, Put(tx, location, key, value))
+}
+
+// RangeTime calls fn, in time order, for every value put with PutAtTime at or after from and before to. As with Each,
+// fn may return Stop to halt early, any other error is returned to you, and the value is only valid for the life of
+// the transaction. Keys which aren't time keys are skipped.
+//
+//	err := rod.RangeTime(tx, "metrics.cpu", start, start.Add(time.Hour), func(t time.Time, value []byte) error {
+//	    ...
+//	})
+func RangeTime(tx Tx, location string, from, to time.Time, fn func(t time.Time, value []byte) error) error {
+	start, err := TimeKey(from)
+	if err != nil {
+		return wrapErr("RangeTime", location, "", err)
+	}
+	end, err := TimeKey(to)
+	if err != nil {
+		return wrapErr("RangeTime", location, "", err)
+	}
+
+	b, err := BucketAt(tx, location)
+	if err != nil {
+		return wrapErr("RangeTime", location, "", err)
+	}
+	if b == nil {
+		return nil
+	}
+
+	c := b.Cursor()
+	for k, v := c.Seek([]byte(start)); k != nil && bytes.Compare(k, []byte(end)) < 0; k, v = c.Next() {
+		if isBucket(b, k, v) {
+			continue
+		}
+		t, err := ParseTimeKey(string(k))
+		if err != nil {
+			continue
+		}
+		if err := fn(t, v); err != nil {
+			if err == Stop {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestTimeKey(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	base := time.Date(2019, 12, 31, 23, 59, 0, 0, time.UTC)
+
+	t.Run("PutAtTime and RangeTime", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			// put them out of order, and with fractions that RFC3339Nano would trim differently
+			check(PutAtTime(tx, "metrics", base.Add(90*time.Second), []byte("c")))
+			check(PutAtTime(tx, "metrics", base.Add(500*time.Millisecond), []byte("b")))
+			check(PutAtTime(tx, "metrics", base, []byte("a")))
+			check(PutAtTime(tx, "metrics", base.Add(time.Hour), []byte("d")))
+
+			// a time in another zone still sorts correctly
+			nz := time.FixedZone("NZDT", 13*60*60)
+			check(PutAtTime(tx, "metrics", base.Add(-time.Minute).In(nz), []byte("before")))
+
+			var got string
+			var times []time.Time
+			err := RangeTime(tx, "metrics", base, base.Add(time.Hour), func(t time.Time, value []byte) error {
+				got += string(value)
+				times = append(times, t)
+				return nil
+			})
+			check(err)
+			if got != "abc" {
+				t.Fatalf("Expected 'abc' but got '%s'", got)
+			}
+			if !times[1].Equal(base.Add(500 * time.Millisecond)) {
+				t.Fatalf("Expected the second time to be %s but got %s", base.Add(500*time.Millisecond), times[1])
+			}
+
+			// and stopping early
+			got = ""
+			err = RangeTime(tx, "metrics", base.Add(-time.Hour), base.Add(2*time.Hour), func(t time.Time, value []byte) error {
+				got += string(value)
+				if len(got) > 0 {
+					return Stop
+				}
+				return nil
+			})
+			check(err)
+			if got != "before" {
+				t.Fatalf("Expected 'before' but got '%s'", got)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("TimeKey out of range", func(t *testing.T) {
+		_, err := TimeKey(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC))
+		if !errors.Is(err, ErrTimeOutOfRange) {
+			t.Fatalf("Expected ErrTimeOutOfRange but got %v", err)
+		}
+		if _, err := ParseTimeKey("chilts"); !errors.Is(err, ErrInvalidTimeKey) {
+			t.Fatalf("Expected ErrInvalidTimeKey but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package rod
+
+import (
+	"reflect"
+	"time"
+)
+
+// now is used for the created and updated timestamps, and may be replaced in tests.
+var now = time.Now
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// stampTimes fills in any time.Time fields within item tagged `rod:"created"` or `rod:"updated"`. The updated field is
+// always set to when. The created field is kept from the existing record (so overwriting doesn't lose it), or else is
+// set to when unless it already has a value.
+func stampTimes(item, existing reflect.Value, tags *structTags, when time.Time) {
+	if field := timeField(item, tags.created); field.IsValid() {
+		// keep the original if there is one
+		if existing.IsValid() {
+			if original := timeField(existing, tags.created); original.IsValid() && !original.Interface().(time.Time).IsZero() {
+				field.Set(original)
+			}
+		}
+		if field.Interface().(time.Time).IsZero() {
+			field.Set(reflect.ValueOf(when))
+		}
+	}
+
+	if field := timeField(item, tags.updated); field.IsValid() {
+		field.Set(reflect.ValueOf(when))
+	}
+}
+
+// timeField returns the settable time.Time field at index within item, or an invalid Value if there isn't one.
+func timeField(item reflect.Value, index []int) reflect.Value {
+	if index == nil {
+		return reflect.Value{}
+	}
+	field, err := item.FieldByIndexErr(index)
+	if err != nil || field.Type() != timeType || !field.CanSet() {
+		return reflect.Value{}
+	}
+	return field
+}
@@ -0,0 +1,82 @@
+package rod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+type Note struct {
+	Text    string
+	Created time.Time `rod:"created"`
+	Updated time.Time `rod:"updated"`
+}
+
+func TestTimestamps(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	clock := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	t.Run("Created is kept and updated changes", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			note := Note{Text: "first"}
+			check(PutJson(tx, "note", "1", &note))
+			if !note.Created.Equal(clock) || !note.Updated.Equal(clock) {
+				t.Fatalf("Expected both times to be stamped: %#v", note)
+			}
+
+			// overwrite with a brand new value an hour later
+			clock = clock.Add(time.Hour)
+			check(PutJson(tx, "note", "1", Note{Text: "second"}))
+
+			var stored Note
+			check(GetJson(tx, "note", "1", &stored))
+			if stored.Text != "second" {
+				t.Fatalf("Expected the note to be overwritten: %#v", stored)
+			}
+			if !stored.Created.Equal(clock.Add(-time.Hour)) {
+				t.Fatalf("Expected the original created time to be kept but got %s", stored.Created)
+			}
+			if !stored.Updated.Equal(clock) {
+				t.Fatalf("Expected the updated time to be %s but got %s", clock, stored.Updated)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Structs without tags are left alone", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			check(PutJson(tx, "user", "chilts", User{"chilts", 1}))
+			raw, err := GetString(tx, "user", "chilts")
+			check(err)
+			if raw != `{"Username":"chilts","Logins":1}` {
+				t.Fatalf("Unexpected JSON: %s", raw)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("PutManyJson stamps the times", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			notes := map[string]*Note{"a": {Text: "a"}}
+			check(PutManyJson(tx, "notes", notes))
+			if !notes["a"].Created.Equal(clock) || !notes["a"].Updated.Equal(clock) {
+				t.Fatalf("Expected both times to be stamped: %#v", notes["a"])
+			}
+
+			var stored Note
+			check(GetJson(tx, "notes", "a", &stored))
+			if !stored.Created.Equal(clock) || !stored.Updated.Equal(clock) {
+				t.Fatalf("Expected both times to be stored: %#v", stored)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
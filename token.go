@@ -0,0 +1,203 @@
+package rod
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTokenInvalid is returned by TokenStore.Verify for a token it didn't issue, or whose secret is wrong.
+	ErrTokenInvalid = errors.New("token is invalid")
+
+	// ErrTokenExpired is returned by TokenStore.Verify for a token which has expired.
+	ErrTokenExpired = errors.New("token has expired")
+
+	// ErrTokenRevoked is returned by TokenStore.Verify for a token which has been revoked.
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+// Token is what a TokenStore knows about a token it has issued, without the token itself.
+type Token struct {
+	ID      string
+	Name    string
+	Created time.Time
+	Expires time.Time // zero if it never expires
+}
+
+// tokenRecord is a Token as it is stored, with the salted hash of its secret.
+type tokenRecord struct {
+	Token
+	Salt []byte
+	Hash []byte
+}
+
+// TokenStore issues and verifies tokens (such as API keys), keeping only a salted hash of each one so that they can't
+// be recovered from the database. Tokens are kept in the bucket "<location>.tokens", keyed by their ID, and the IDs of
+// revoked tokens in "<location>.revoked" along with when they were revoked.
+//
+//	keys := rod.NewTokenStore("apikeys")
+//	token, info, err := keys.Issue(tx, "ci", 90*24*time.Hour)
+//	// ... give token to the user, it can't be shown again
+//	info, err = keys.Verify(tx, token)
+type TokenStore struct {
+	location string
+}
+
+// NewTokenStore returns the TokenStore at location.
+func NewTokenStore(location string) *TokenStore {
+	return &TokenStore{location: location}
+}
+
+func (s *TokenStore) tokens() string {
+	return s.location + ".tokens"
+}
+
+func (s *TokenStore) revoked() string {
+	return s.location + ".revoked"
+}
+
+// Issue creates a new token called name, which expires after ttl (or never, if ttl is 0). The token is returned as
+// "<id>.<secret>" and only its hash is kept, so this is the only time it is available.
+func (s *TokenStore) Issue(tx Tx, name string, ttl time.Duration) (string, *Token, error) {
+	id, err := randomBytes(12)
+	if err != nil {
+		return "", nil, wrapErr("Issue", s.tokens(), "", err)
+	}
+	secret, err := randomBytes(32)
+	if err != nil {
+		return "", nil, wrapErr("Issue", s.tokens(), "", err)
+	}
+	salt, err := randomBytes(16)
+	if err != nil {
+		return "", nil, wrapErr("Issue", s.tokens(), "", err)
+	}
+
+	rec := tokenRecord{
+		Token: Token{ID: hex.EncodeToString(id), Name: name, Created: now().UTC()},
+		Salt:  salt,
+		Hash:  hashToken(salt, secret),
+	}
+	if ttl > 0 {
+		rec.Expires = rec.Created.Add(ttl)
+	}
+	if err := PutJson(tx, s.tokens(), rec.ID, rec); err != nil {
+		return "", nil, wrapErr("Issue", s.tokens(), rec.ID, err)
+	}
+
+	token := rec.ID + "." + base64.RawURLEncoding.EncodeToString(secret)
+	return token, &rec.Token, nil
+}
+
+// Verify checks a token presented to you, returning what is known about it if it is valid. Otherwise the error is
+// ErrTokenInvalid, ErrTokenExpired or ErrTokenRevoked.
+func (s *TokenStore) Verify(tx Tx, token string) (*Token, error) {
+	id, encoded, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return nil, wrapErr("Verify", s.tokens(), "", ErrTokenInvalid)
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, wrapErr("Verify", s.tokens(), id, ErrTokenInvalid)
+	}
+
+	var rec tokenRecord
+	found, err := GetJsonFound(tx, s.tokens(), id, &rec)
+	if err != nil {
+		return nil, wrapErr("Verify", s.tokens(), id, err)
+	}
+	if !found || subtle.ConstantTimeCompare(hashToken(rec.Salt, secret), rec.Hash) != 1 {
+		return nil, wrapErr("Verify", s.tokens(), id, ErrTokenInvalid)
+	}
+
+	revoked, err := Exists(tx, s.revoked(), id)
+	if err != nil {
+		return nil, wrapErr("Verify", s.revoked(), id, err)
+	}
+	if revoked {
+		return nil, wrapErr("Verify", s.tokens(), id, ErrTokenRevoked)
+	}
+	if !rec.Expires.IsZero() && now().After(rec.Expires) {
+		return nil, wrapErr("Verify", s.tokens(), id, ErrTokenExpired)
+	}
+	return &rec.Token, nil
+}
+
+// Revoke adds the token with this ID to the revocation list, so it no longer verifies. It returns ErrKeyNotFound if
+// there is no such token.
+func (s *TokenStore) Revoke(tx Tx, id string) error {
+	found, err := Exists(tx, s.tokens(), id)
+	if err != nil {
+		return wrapErr("Revoke", s.tokens(), id, err)
+	}
+	if !found {
+		return wrapErr("Revoke", s.tokens(), id, ErrKeyNotFound)
+	}
+	return wrapErr("Revoke", s.revoked(), id, PutTime(tx, s.revoked(), id, now().UTC()))
+}
+
+// Revoked returns the revocation list, as the ID of each revoked token and when it was revoked.
+func (s *TokenStore) Revoked(tx Tx) (map[string]time.Time, error) {
+	revoked := map[string]time.Time{}
+	err := Each(tx, s.revoked(), func(id string, _ []byte) error {
+		at, err := GetTime(tx, s.revoked(), id)
+		revoked[id] = at
+		return err
+	})
+	if err != nil {
+		return nil, wrapErr("Revoked", s.revoked(), "", err)
+	}
+	return revoked, nil
+}
+
+// List returns every token which has been issued (including those which have expired or been revoked), in ID order.
+func (s *TokenStore) List(tx Tx) ([]Token, error) {
+	var tokens []Token
+	err := EachJson(tx, s.tokens(), func(id string, rec tokenRecord) error {
+		tokens = append(tokens, rec.Token)
+		return nil
+	})
+	return tokens, wrapErr("List", s.tokens(), "", err)
+}
+
+// Prune deletes every token which has expired, along with any revocation of it (since it wouldn't verify anyway),
+// returning how many there were.
+func (s *TokenStore) Prune(tx Tx) (int, error) {
+	at := now()
+	var expired []string
+	err := EachJson(tx, s.tokens(), func(id string, rec tokenRecord) error {
+		if !rec.Expires.IsZero() && at.After(rec.Expires) {
+			expired = append(expired, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, wrapErr("Prune", s.tokens(), "", err)
+	}
+
+	for _, id := range expired {
+		if err := Del(tx, s.tokens(), id); err != nil {
+			return 0, wrapErr("Prune", s.tokens(), id, err)
+		}
+		if err := Del(tx, s.revoked(), id); err != nil {
+			return 0, wrapErr("Prune", s.revoked(), id, err)
+		}
+	}
+	return len(expired), nil
+}
+
+func hashToken(salt, secret []byte) []byte {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), secret...))
+	return sum[:]
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	return b, err
+}
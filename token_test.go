@@ -0,0 +1,122 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestTokenStore(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	keys := NewTokenStore("apikeys")
+	var token, expired string
+
+	t.Run("Issue and Verify", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			var info *Token
+			var err error
+			token, info, err = keys.Issue(tx, "ci", 0)
+			check(err)
+			if info.Name != "ci" || !info.Expires.IsZero() || !strings.HasPrefix(token, info.ID+".") {
+				t.Fatalf("Unexpected token %q: %#v", token, info)
+			}
+
+			got, err := keys.Verify(tx, token)
+			check(err)
+			if got.ID != info.ID {
+				t.Fatalf("Verify should return the token's info: %#v", got)
+			}
+
+			// the secret itself is never stored
+			secret := token[len(info.ID)+1:]
+			raw, err := Get(tx, "apikeys.tokens", info.ID)
+			check(err)
+			if bytes.Contains(raw, []byte(secret)) {
+				t.Fatal("The token's secret should not be stored")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Invalid tokens", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			for _, bad := range []string{"", "nodot", token + "x", "0000." + token[strings.Index(token, ".")+1:]} {
+				_, err := keys.Verify(tx, bad)
+				if !errors.Is(err, ErrTokenInvalid) {
+					t.Fatalf("Expected ErrTokenInvalid for %q, got %v", bad, err)
+				}
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Expiry and Prune", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			clock := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			now = func() time.Time { return clock }
+			defer func() { now = time.Now }()
+
+			var err error
+			expired, _, err = keys.Issue(tx, "short", time.Hour)
+			check(err)
+
+			// still valid right up until it expires
+			clock = clock.Add(time.Hour)
+			_, err = keys.Verify(tx, expired)
+			check(err)
+			n, err := keys.Prune(tx)
+			check(err)
+			if n != 0 {
+				t.Fatalf("Nothing should have been pruned yet, not %d", n)
+			}
+
+			clock = clock.Add(time.Nanosecond)
+			_, err = keys.Verify(tx, expired)
+			if !errors.Is(err, ErrTokenExpired) {
+				t.Fatalf("Expected ErrTokenExpired, got %v", err)
+			}
+
+			n, err = keys.Prune(tx)
+			check(err)
+			if n != 1 {
+				t.Fatalf("One token should have been pruned, not %d", n)
+			}
+			tokens, err := keys.List(tx)
+			check(err)
+			if len(tokens) != 1 || tokens[0].Name != "ci" {
+				t.Fatalf("Only the ci token should be left: %#v", tokens)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			id := token[:strings.Index(token, ".")]
+			check(keys.Revoke(tx, id))
+
+			_, err := keys.Verify(tx, token)
+			if !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("Expected ErrTokenRevoked, got %v", err)
+			}
+
+			revoked, err := keys.Revoked(tx)
+			check(err)
+			if _, ok := revoked[id]; !ok || len(revoked) != 1 {
+				t.Fatalf("The token should be on the revocation list: %v", revoked)
+			}
+
+			err = keys.Revoke(tx, "missing")
+			if !errors.Is(err, ErrKeyNotFound) {
+				t.Fatalf("Expected ErrKeyNotFound, got %v", err)
+			}
+			return nil
+		}))
+	})
+}
@@ -0,0 +1,178 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrTreeNotObject is returned by ImportTree if the data it is given isn't a JSON object.
+var ErrTreeNotObject = errors.New("tree must be a JSON object")
+
+// TreeOption configures ImportTree.
+type TreeOption func(*treeImport)
+
+// ImportTreeDepth makes only the first n levels of nested objects into buckets, with any deeper put as JSON values
+// instead. By default every nested object becomes a bucket, which means a record stored as a JSON object can't be
+// imported as one. With ImportTreeDepth(0) every field of the data is a key at location, so that
+//
+//	rod.ImportTree(tx, "users", []byte(`{"chilts":{"Username":"chilts","Logins":1}}`), rod.ImportTreeDepth(0))
+//
+// puts one record for "chilts" in "users", as PutJson would.
+func ImportTreeDepth(n int) TreeOption {
+	return func(ti *treeImport) {
+		ti.depth = n
+	}
+}
+
+type treeImport struct {
+	depth int
+}
+
+// ExportTree renders the bucket at location as one JSON object, with each key as a field and each nested bucket as a
+// nested object, all in key order. Values which are JSON go in as they are (compacted), and any other value goes in as
+// a string. A location which doesn't exist renders as an empty object. It is meant for a readable snapshot of part of
+// a store, such as a user's records for a support ticket or a test fixture, rather than a backup, since a value which
+// isn't valid UTF-8 doesn't survive being made into a string. For that use DumpJsonLines.
+//
+//	raw, err := rod.ExportTree(tx, "users.chilts")
+//	// {"posts":{"hello":"Hello, World!"},"profile":{"Username":"chilts","Logins":1}}
+func ExportTree(tx Tx, location string) (json.RawMessage, error) {
+	b, err := getBucket(tx, location)
+	if err != nil {
+		return nil, wrapErr("ExportTree", location, "", err)
+	}
+
+	var buf bytes.Buffer
+	if err := exportBucket(&buf, b); err != nil {
+		return nil, wrapErr("ExportTree", location, "", err)
+	}
+	return json.RawMessage(buf.Bytes()), nil
+}
+
+// exportBucket writes b to buf as a JSON object. A nil b is written as an empty one.
+func exportBucket(buf *bytes.Buffer, b Bucket) error {
+	buf.WriteByte('{')
+	if b != nil {
+		c := b.Cursor()
+		first := true
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+
+			name, err := json.Marshal(string(k))
+			if err != nil {
+				return err
+			}
+			buf.Write(name)
+			buf.WriteByte(':')
+
+			if v == nil {
+				if nested := b.Bucket(k); nested != nil {
+					if err := exportBucket(buf, nested); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if json.Valid(v) {
+				if err := json.Compact(buf, v); err != nil {
+					return err
+				}
+				continue
+			}
+			s, err := json.Marshal(string(v))
+			if err != nil {
+				return err
+			}
+			buf.Write(s)
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// ImportTree is the inverse of ExportTree. It puts each field of the JSON object in data as a key at location, except
+// that a field holding an object becomes a nested bucket with its fields in turn (see ImportTreeDepth). A string is put
+// as its text, as PutString would, and any other value (a number, an array, true, false or null) as its JSON. Keys
+// already at location are overwritten, and everything else there is left alone. Since it goes through Put, hooks such
+// as indexes and audit logs see every key, and it all happens in tx so either the whole tree goes in or none of it.
+// It is handy for fixture files in tests and for seeding an environment from a JSON document.
+//
+//	err := rod.ImportTree(tx, "users.chilts", []byte(`{"posts":{"hello":"Hello, World!"}}`))
+//
+// Since bucket names can't have a '.' in them, an object whose field name has one is an error, as is one with an empty
+// field name. Note that ExportTree followed by ImportTree gives back a different tree if any value was a JSON object
+// or string, since those export the same as a bucket or plain text.
+func ImportTree(tx Tx, location string, data []byte, opts ...TreeOption) error {
+	ti := treeImport{depth: -1}
+	for _, opt := range opts {
+		opt(&ti)
+	}
+	return wrapErr("ImportTree", location, "", importTree(tx, location, data, 0, &ti))
+}
+
+// importTree puts the object in data at location, which is depth levels below where the import started.
+func importTree(tx Tx, location string, data []byte, depth int, ti *treeImport) error {
+	if !isJsonObject(data) {
+		return ErrTreeNotObject
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	// make sure the bucket is there even if the object is empty
+	if _, err := createBucket(tx, location); err != nil {
+		return err
+	}
+
+	// go through the fields in order, so that an import always happens the same way
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		raw := fields[name]
+		if isJsonObject(raw) && (ti.depth < 0 || depth < ti.depth) {
+			if name == "" || strings.Contains(name, ".") {
+				return wrapErr("ImportTree", location, name, ErrInvalidLocationBucket)
+			}
+			if err := importTree(tx, location+"."+name, raw, depth+1, ti); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var value []byte
+		if len(raw) > 0 && raw[0] == '"' {
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return err
+			}
+			value = []byte(s)
+		} else {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, raw); err != nil {
+				return err
+			}
+			value = buf.Bytes()
+		}
+		if err := Put(tx, location, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isJsonObject says whether data, ignoring any whitespace before it, is a JSON object.
+func isJsonObject(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	return len(data) > 0 && data[0] == '{'
+}
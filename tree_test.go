@@ -0,0 +1,107 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestTree(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	check(db.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "users.chilts", "profile", User{"chilts", 1}))
+		check(PutString(tx, "users.chilts.posts", "hello", "Hello, \"World\"!"))
+		check(Put(tx, "users.chilts", "spaced", []byte(`{ "a": [1, 2] }`)))
+		check(PutString(tx, "users.other", "profile", "not me"))
+		_, err := createBucket(tx, "users.chilts.empty")
+		return err
+	}))
+
+	t.Run("ExportTree", func(t *testing.T) {
+		check(db.View(func(tx *bolt.Tx) error {
+			raw, err := ExportTree(tx, "users.chilts")
+			check(err)
+			want := `{"empty":{},"posts":{"hello":"Hello, \"World\"!"},"profile":{"Username":"chilts","Logins":1},"spaced":{"a":[1,2]}}`
+			if string(raw) != want {
+				t.Fatalf("Unexpected export:\n%s\n%s", raw, want)
+			}
+
+			raw, err = ExportTree(tx, "users.nobody")
+			check(err)
+			if string(raw) != "{}" {
+				t.Fatalf("A missing location should export as an empty object, not %s", raw)
+			}
+
+			if _, err := ExportTree(tx, ""); err == nil {
+				t.Fatal("An empty location should be an error")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ImportTree", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			raw, err := ExportTree(tx, "users.chilts")
+			check(err)
+			check(ImportTree(tx, "copy", raw))
+
+			again, err := ExportTree(tx, "copy")
+			check(err)
+			want := `{"empty":{},"posts":{"hello":"Hello, \"World\"!"},"profile":{"Logins":1,"Username":"chilts"},"spaced":{"a":[1,2]}}`
+			if string(again) != want {
+				t.Fatalf("Unexpected export of the import:\n%s\n%s", again, want)
+			}
+
+			// the record became a bucket, and the string went in as text
+			username, err := GetString(tx, "copy.profile", "Username")
+			check(err)
+			hello, err := GetString(tx, "copy.posts", "hello")
+			check(err)
+			if username != "chilts" || hello != `Hello, "World"!` {
+				t.Fatalf("Unexpected values %q and %q", username, hello)
+			}
+			b, err := GetBucket(tx, "copy.empty")
+			check(err)
+			if b == nil {
+				t.Fatal("The empty object should have become an empty bucket")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ImportTreeDepth", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			data := []byte(`{"chilts":{"Username":"chilts","Logins":3}}`)
+			check(ImportTree(tx, "people", data, ImportTreeDepth(0)))
+
+			var user User
+			check(GetJson(tx, "people", "chilts", &user))
+			if user.Logins != 3 {
+				t.Fatalf("The record should have gone in as JSON: %#v", user)
+			}
+			// one level down, "admins" is a bucket but its fields are records
+			data = []byte(`{"admins":{"andy":{"Username":"andy"}}}`)
+			check(ImportTree(tx, "people", data, ImportTreeDepth(1)))
+			check(GetJson(tx, "people.admins", "andy", &user))
+			if user.Username != "andy" {
+				t.Fatalf("Unexpected user %#v", user)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("ImportTree - bad data", func(t *testing.T) {
+		check(db.Update(func(tx *bolt.Tx) error {
+			if err := ImportTree(tx, "bad", []byte(`[1, 2]`)); !errors.Is(err, ErrTreeNotObject) {
+				t.Fatalf("Expected ErrTreeNotObject, not %v", err)
+			}
+			if err := ImportTree(tx, "bad", []byte(`{"a.b":{}}`)); !errors.Is(err, ErrInvalidLocationBucket) {
+				t.Fatalf("Expected ErrInvalidLocationBucket, not %v", err)
+			}
+			return nil
+		}))
+	})
+}
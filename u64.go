@@ -0,0 +1,61 @@
+package rod
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// ErrInvalidU64Key is returned when a key can't be decoded by ParseU64Key, since it isn't 8 bytes long.
+var ErrInvalidU64Key = errors.New("key is not an 8 byte uint64 key")
+
+// U64Key encodes n as 8 bytes in big-endian order, so that numeric keys sort (and therefore iterate) in numeric order
+// rather than lexically. The result is a regular key and may be used with any of the other functions.
+//
+//	id, _ := rod.NextSequence(tx, "post")
+//	rod.PutJson(tx, "post", rod.U64Key(id), post)
+func U64Key(n uint64) string {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, n)
+	return string(buf)
+}
+
+// ParseU64Key decodes a key made by U64Key back into its number, such as when iterating with Each or Keys.
+func ParseU64Key(key string) (uint64, error) {
+	if len(key) != 8 {
+		return 0, ErrInvalidU64Key
+	}
+	return binary.BigEndian.Uint64([]byte(key)), nil
+}
+
+// PutU64Key is the same as Put except the key is a number, encoded with U64Key.
+func PutU64Key(tx Tx, location string, key uint64, value []byte) error {
+	return wrapU64Err("PutU64Key", location, key, Put(tx, location, U64Key(key), value))
+}
+
+// GetU64Key is the same as Get except the key is a number, encoded with U64Key.
+func GetU64Key(tx Tx, location string, key uint64) ([]byte, error) {
+	v, err := Get(tx, location, U64Key(key))
+	return v, wrapU64Err("GetU64Key", location, key, err)
+}
+
+// wrapU64Err is like wrapErr except the key in the error is the number itself, rather than the 8 bytes U64Key encodes
+// it as which are no use to anyone reading the error.
+func wrapU64Err(op, location string, key uint64, err error) error {
+	if e, ok := err.(*Error); ok {
+		err = e.Err
+	}
+	return wrapErr(op, location, strconv.FormatUint(key, 10), err)
+}
+
+// NextSequence returns the next number in the bucket's sequence, creating the bucket hierarchy first just like Put.
+// The first number returned is 1. It is ideal for use with U64Key or PutU64Key.
+func NextSequence(tx Tx, location string) (uint64, error) {
+	b, err := createBucket(tx, location)
+	if err != nil {
+		return 0, wrapErr("NextSequence", location, "", err)
+	}
+
+	n, err := b.NextSequence()
+	return n, wrapErr("NextSequence", location, "", err)
+}
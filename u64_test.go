@@ -0,0 +1,79 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestU64Key(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("Keys iterate in numeric order", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			for _, n := range []uint64{256, 2, 10, 1} {
+				check(PutU64Key(tx, "numbers", n, []byte("x")))
+			}
+
+			var got []uint64
+			for key := range Keys(tx, "numbers") {
+				n, err := ParseU64Key(key)
+				check(err)
+				got = append(got, n)
+			}
+
+			want := []uint64{1, 2, 10, 256}
+			if len(got) != len(want) {
+				t.Fatalf("Expected %v but got %v", want, got)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("Expected %v but got %v", want, got)
+				}
+			}
+
+			v, err := GetU64Key(tx, "numbers", 10)
+			check(err)
+			if string(v) != "x" {
+				t.Fatalf("Expected 'x' but got '%s'", v)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("NextSequence", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			for want := uint64(1); want <= 3; want++ {
+				id, err := NextSequence(tx, "posts")
+				check(err)
+				if id != want {
+					t.Fatalf("Expected %d but got %d", want, id)
+				}
+				check(PutString(tx, "posts", U64Key(id), "post"))
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Errors show the number as the key", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			err := PutU64Key(tx, "", 42, []byte("post"))
+			var e *Error
+			if !errors.As(err, &e) || e.Op != "PutU64Key" || e.Key != "42" {
+				t.Fatalf("Expected a PutU64Key error for key 42 but got %#v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("ParseU64Key with a bad key", func(t *testing.T) {
+		if _, err := ParseU64Key("chilts"); !errors.Is(err, ErrInvalidU64Key) {
+			t.Fatalf("Expected ErrInvalidU64Key but got %v", err)
+		}
+	})
+}
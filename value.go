@@ -0,0 +1,59 @@
+package rod
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// PutValue puts v at key in the most compact form it has. If v implements encoding.BinaryMarshaler then that is used,
+// or failing that encoding.TextMarshaler, and otherwise v is put as JSON with PutJson. This lets types which have an
+// encoding of their own, such as UUIDs, decimals, or time.Time, be stored as that rather than as a JSON string:
+//
+//	rod.PutValue(tx, "sessions", token, sessionID) // sessionID's 16 bytes, via MarshalBinary
+//	rod.PutValue(tx, "prices", "widget", price)     // "12.50", via MarshalText
+//
+// Read the value back with GetValue, which looks for the same interfaces, so a type should implement both halves of
+// whichever pair it has. A nil pointer is put as JSON, ie. null.
+func PutValue(tx Tx, location, key string, v interface{}) error {
+	if ref := reflect.ValueOf(v); ref.Kind() == reflect.Ptr && ref.IsNil() {
+		return wrapErr("PutValue", location, key, PutJson(tx, location, key, v))
+	}
+
+	switch m := v.(type) {
+	case encoding.BinaryMarshaler:
+		value, err := m.MarshalBinary()
+		if err != nil {
+			return wrapErr("PutValue", location, key, err)
+		}
+		return wrapErr("PutValue", location, key, Put(tx, location, key, value))
+	case encoding.TextMarshaler:
+		value, err := m.MarshalText()
+		if err != nil {
+			return wrapErr("PutValue", location, key, err)
+		}
+		return wrapErr("PutValue", location, key, Put(tx, location, key, value))
+	}
+	return wrapErr("PutValue", location, key, PutJson(tx, location, key, v))
+}
+
+// GetValue reads the value at key into v, which must be a pointer, as PutValue put it: with UnmarshalBinary if v
+// implements encoding.BinaryUnmarshaler, or failing that UnmarshalText if it implements encoding.TextUnmarshaler, and
+// otherwise with GetJson. If any bucket or the key doesn't exist then nothing is placed into v, and no error is
+// returned.
+func GetValue(tx Tx, location, key string, v interface{}) error {
+	switch u := v.(type) {
+	case encoding.BinaryUnmarshaler:
+		value, err := Get(tx, location, key)
+		if err != nil || value == nil {
+			return wrapErr("GetValue", location, key, err)
+		}
+		return wrapErr("GetValue", location, key, u.UnmarshalBinary(value))
+	case encoding.TextUnmarshaler:
+		value, err := Get(tx, location, key)
+		if err != nil || value == nil {
+			return wrapErr("GetValue", location, key, err)
+		}
+		return wrapErr("GetValue", location, key, u.UnmarshalText(value))
+	}
+	return wrapErr("GetValue", location, key, GetJson(tx, location, key, v))
+}
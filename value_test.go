@@ -0,0 +1,138 @@
+package rod
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// testID is stored as its four bytes.
+type testID [4]byte
+
+func (id testID) MarshalBinary() ([]byte, error) {
+	return id[:], nil
+}
+
+func (id *testID) UnmarshalBinary(data []byte) error {
+	if len(data) != len(id) {
+		return errors.New("wrong length")
+	}
+	copy(id[:], data)
+	return nil
+}
+
+// testPrice is a number of cents, stored as text such as "12.50".
+type testPrice int64
+
+func (p testPrice) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", p/100, p%100)), nil
+}
+
+func (p *testPrice) UnmarshalText(text []byte) error {
+	var dollars, cents int64
+	if _, err := fmt.Sscanf(string(text), "%d.%d", &dollars, &cents); err != nil {
+		return err
+	}
+	*p = testPrice(dollars*100 + cents)
+	return nil
+}
+
+func TestValue(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("Values are put with their own encoding", func(t *testing.T) {
+		when := time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC)
+		whenBinary, err := when.MarshalBinary()
+		check(err)
+
+		err = db.Update(func(tx *bolt.Tx) error {
+			check(PutValue(tx, "values", "id", testID{0xde, 0xad, 0xbe, 0xef}))
+			check(PutValue(tx, "values", "price", testPrice(1250)))
+			check(PutValue(tx, "values", "when", when))
+			check(PutValue(tx, "values", "user", User{Username: "chilts", Logins: 2}))
+			check(PutValue(tx, "values", "nil", (*testID)(nil)))
+
+			for key, want := range map[string]string{
+				"id":    "\xde\xad\xbe\xef",
+				"price": "12.50",
+				"when":  string(whenBinary),
+				"user":  `{"Username":"chilts","Logins":2}`,
+				"nil":   "null",
+			} {
+				got, err := GetString(tx, "values", key)
+				check(err)
+				if got != want {
+					t.Fatalf("Expected %q at %s but got %q", want, key, got)
+				}
+			}
+			return nil
+		})
+		check(err)
+
+		err = db.View(func(tx *bolt.Tx) error {
+			var id testID
+			check(GetValue(tx, "values", "id", &id))
+			if id != (testID{0xde, 0xad, 0xbe, 0xef}) {
+				t.Fatalf("Unexpected id %x", id)
+			}
+
+			var price testPrice
+			check(GetValue(tx, "values", "price", &price))
+			if price != 1250 {
+				t.Fatalf("Expected 1250 but got %d", price)
+			}
+
+			var got time.Time
+			check(GetValue(tx, "values", "when", &got))
+			if !got.Equal(when) {
+				t.Fatalf("Expected %v but got %v", when, got)
+			}
+
+			var u User
+			check(GetValue(tx, "values", "user", &u))
+			if u.Username != "chilts" || u.Logins != 2 {
+				t.Fatalf("Unexpected user %v", u)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Missing values are left alone", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			id := testID{1, 2, 3, 4}
+			check(GetValue(tx, "values", "missing", &id))
+			check(GetValue(tx, "nowhere", "id", &id))
+			if id != (testID{1, 2, 3, 4}) {
+				t.Fatalf("Expected id to be left alone but got %x", id)
+			}
+
+			price := testPrice(99)
+			check(GetValue(tx, "values", "missing", &price))
+			if price != 99 {
+				t.Fatalf("Expected price to be left alone but got %d", price)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("Decoding errors are returned", func(t *testing.T) {
+		err := db.View(func(tx *bolt.Tx) error {
+			var id testID
+			if err := GetValue(tx, "values", "price", &id); err == nil {
+				t.Fatal("Expected an error decoding a price as an id")
+			}
+			var e *Error
+			if err := GetValue(tx, "values", "user", &id); !errors.As(err, &e) || e.Op != "GetValue" {
+				t.Fatalf("Expected a GetValue error but got %v", err)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
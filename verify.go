@@ -0,0 +1,197 @@
+package rod
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/boltdb/bolt"
+)
+
+// VerifyReport is what Verify found.
+type VerifyReport struct {
+	// Buckets and Keys are how many of each were checked.
+	Buckets int
+	Keys    int
+	// Problems holds every value which failed a check, in the order they were found.
+	Problems []VerifyProblem
+}
+
+// OK tells you whether no problems were found.
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// VerifyProblem is a single value which failed one of Verify's checks.
+type VerifyProblem struct {
+	Location string
+	Key      string
+	Err      error
+}
+
+// VerifyOption configures Verify.
+type VerifyOption func(*verification)
+
+// VerifyType checks that every value at location decodes into the type of v (such as User{}), as GetJson would.
+// Nested locations need their own VerifyType.
+func VerifyType(location string, v interface{}) VerifyOption {
+	return func(vf *verification) {
+		t := reflect.TypeOf(v)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		vf.types[location] = t
+	}
+}
+
+type verification struct {
+	types   map[string]reflect.Type
+	schemas map[string]*schema
+	report  *VerifyReport
+}
+
+// Verify walks every bucket at and below location (or the whole database if location is empty) and checks each value
+// it finds, returning a report of those which are corrupt or undecodable rather than stopping at the first. It checks
+// that:
+//
+//   - values which look like JSON (starting with '{' or '[') are valid JSON
+//   - values at a location given to VerifyType decode into that type
+//   - encrypted fields (see SetEncryptionKey) decrypt, which also checks their authentication tag has not changed
+//
+// Values have no checksum of their own besides that of encrypted fields, so a raw value which isn't JSON can't be
+// checked. Use Store.Verify to also check values against the schemas attached to the Store.
+//
+//	report, err := rod.Verify(db, "", rod.VerifyType("user", User{}))
+//	for _, p := range report.Problems {
+//	    log.Printf("%s/%s: %v", p.Location, p.Key, p.Err)
+//	}
+func Verify(db *bolt.DB, location string, opts ...VerifyOption) (*VerifyReport, error) {
+	return verify(db, location, nil, opts)
+}
+
+// Verify is the same as rod.Verify for the Store's database, except that values are also checked against any schema
+// attached to their location with SetSchema.
+func (s *Store) Verify(location string, opts ...VerifyOption) (*VerifyReport, error) {
+	return verify(s.DB(), location, s.schemas, opts)
+}
+
+func verify(db *bolt.DB, location string, schemas map[string]*schema, opts []VerifyOption) (*VerifyReport, error) {
+	vf := &verification{types: make(map[string]reflect.Type), schemas: schemas, report: &VerifyReport{}}
+	for _, opt := range opts {
+		opt(vf)
+	}
+
+	err := db.View(func(tx *bolt.Tx) error {
+		if location != "" {
+			b, err := BucketAt(tx, location)
+			if err != nil || b == nil {
+				return err
+			}
+			return vf.walk(location, b)
+		}
+
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return vf.walk(string(name), boltBucket{b})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vf.report, nil
+}
+
+// walk checks each value in b (which is at location), and then each bucket nested within it.
+func (vf *verification) walk(location string, b Bucket) error {
+	vf.report.Buckets++
+
+	var nested []string
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil {
+			nested = append(nested, string(k))
+			continue
+		}
+		vf.report.Keys++
+		if err := vf.check(location, v); err != nil {
+			vf.report.Problems = append(vf.report.Problems, VerifyProblem{Location: location, Key: string(k), Err: err})
+		}
+	}
+
+	for _, name := range nested {
+		if child := b.Bucket([]byte(name)); child != nil {
+			if err := vf.walk(location+"."+name, child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// check runs each of the checks on value, which is at location.
+func (vf *verification) check(location string, value []byte) error {
+	t, typed := vf.types[location]
+	s, hasSchema := vf.schemas[location]
+	trimmed := bytes.TrimSpace(value)
+	looksJson := len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+	if !typed && !hasSchema && !looksJson {
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return err
+	}
+	if err := checkEncrypted(doc); err != nil {
+		return err
+	}
+	if hasSchema {
+		var errs SchemaErrors
+		s.validate("", doc, &errs)
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+	if typed {
+		item := reflect.New(t)
+		if err := json.Unmarshal(value, item.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkEncrypted decrypts every encrypted string within doc, returning the first error.
+func checkEncrypted(doc interface{}) error {
+	switch d := doc.(type) {
+	case string:
+		if !strings.HasPrefix(d, encryptedPrefix) {
+			return nil
+		}
+		id, ciphertext, ok := splitEncrypted(d)
+		if !ok {
+			return ErrDecryptionFailed
+		}
+		encryption.RLock()
+		key, ok := encryption.keys[id]
+		encryption.RUnlock()
+		if !ok {
+			return ErrUnknownEncryptionKey
+		}
+		_, err := key.decrypt(ciphertext)
+		return err
+	case []interface{}:
+		for _, item := range d {
+			if err := checkEncrypted(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range d {
+			if err := checkEncrypted(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package rod
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+
+	s, err := Open(filepath.Join(dir, "rod.db"), 0666, nil)
+	check(err)
+	defer s.Close()
+
+	check(SetEncryptionKey(bytes.Repeat([]byte("k"), 32)))
+	defer SetEncryptionKey(nil)
+
+	check(s.Update(func(tx *bolt.Tx) error {
+		check(PutJson(tx, "user", "chilts", User{"chilts", 1}))
+		check(Put(tx, "user", "broken", []byte(`{"Username":`)))
+		check(Put(tx, "user", "wrong", []byte(`{"Logins":"lots"}`)))
+		check(PutString(tx, "config", "motd", "not json, and that's fine"))
+		check(PutJson(tx, "integration", "github", &Integration{"github", "secret-token"}))
+		check(PutJson(tx, "integration", "gitlab", &Integration{"gitlab", "other-token"}))
+		return PutJson(tx, "user.extra", "profile", map[string]string{"Bio": "hi"})
+	}))
+
+	// tamper with one of the encrypted fields
+	check(s.Update(func(tx *bolt.Tx) error {
+		raw, err := GetString(tx, "integration", "gitlab")
+		check(err)
+		if !strings.Contains(raw, encryptedPrefix) {
+			t.Fatalf("The token should have been encrypted")
+		}
+		tampered := []byte(raw)
+		tampered[len(tampered)-4] ^= 1
+		return Put(tx, "integration", "gitlab", tampered)
+	}))
+
+	problems := func(r *VerifyReport) []string {
+		var keys []string
+		for _, p := range r.Problems {
+			keys = append(keys, p.Location+"/"+p.Key)
+		}
+		return keys
+	}
+
+	t.Run("Whole database", func(t *testing.T) {
+		report, err := Verify(s.DB(), "")
+		check(err)
+		if report.Buckets != 4 || report.Keys != 7 {
+			t.Fatalf("Unexpected counts %d and %d", report.Buckets, report.Keys)
+		}
+		if got := strings.Join(problems(report), ","); got != "integration/gitlab,user/broken" {
+			t.Fatalf("Unexpected problems %s", got)
+		}
+		if !errors.Is(report.Problems[0].Err, ErrDecryptionFailed) {
+			t.Fatalf("Expected ErrDecryptionFailed but got %v", report.Problems[0].Err)
+		}
+	})
+
+	t.Run("VerifyType", func(t *testing.T) {
+		report, err := Verify(s.DB(), "user", VerifyType("user", User{}))
+		check(err)
+		if report.Buckets != 2 || report.Keys != 4 {
+			t.Fatalf("Unexpected counts %d and %d", report.Buckets, report.Keys)
+		}
+		if got := strings.Join(problems(report), ","); got != "user/broken,user/wrong" {
+			t.Fatalf("Unexpected problems %s", got)
+		}
+	})
+
+	t.Run("Store.Verify uses schemas", func(t *testing.T) {
+		check(s.SetSchema("user.extra", []byte(`{"type": "object", "required": ["Name"]}`)))
+		defer s.SetSchema("user.extra", nil)
+
+		report, err := s.Verify("user.extra")
+		check(err)
+		if len(report.Problems) != 1 || report.OK() {
+			t.Fatalf("Unexpected problems %v", problems(report))
+		}
+		if _, ok := report.Problems[0].Err.(SchemaErrors); !ok {
+			t.Fatalf("Expected SchemaErrors but got %v", report.Problems[0].Err)
+		}
+	})
+
+	t.Run("Missing location", func(t *testing.T) {
+		report, err := Verify(s.DB(), "does-not-exist")
+		check(err)
+		if !report.OK() || report.Buckets != 0 {
+			t.Fatalf("Nothing should have been checked")
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package rod
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrVersionConflict is returned from PutJson when the record has a field tagged `rod:"version"` and the version being
+// written isn't the version currently stored, meaning someone else has changed the record since it was read.
+var ErrVersionConflict = errors.New("stored version differs from the version being written")
+
+// bumpVersion checks the integer field tagged `rod:"version"` in item against the same field in the existing record
+// (with no record being version 0) and, if they match, increments it in item. Otherwise ErrVersionConflict is returned
+// and item is left alone.
+func bumpVersion(item, existing reflect.Value, index []int) error {
+	field, err := item.FieldByIndexErr(index)
+	if err != nil || !field.CanSet() {
+		return nil
+	}
+
+	var stored int64
+	if existing.IsValid() {
+		if f, err := existing.FieldByIndexErr(index); err == nil {
+			stored, _ = versionOf(f)
+		}
+	}
+
+	current, ok := versionOf(field)
+	if !ok {
+		return nil
+	}
+	if current != stored {
+		return ErrVersionConflict
+	}
+
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(field.Uint() + 1)
+	default:
+		field.SetInt(field.Int() + 1)
+	}
+	return nil
+}
+
+// versionOf reads an integer field as an int64, telling you if it wasn't an integer at all.
+func versionOf(field reflect.Value) (int64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(field.Uint()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,139 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+type Document struct {
+	Body    string
+	Version int `rod:"version"`
+}
+
+func TestVersion(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	t.Run("Versions increment on each put", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			doc := Document{Body: "draft"}
+			check(PutJson(tx, "doc", "readme", &doc))
+			if doc.Version != 1 {
+				t.Fatalf("Expected version 1 but got %d", doc.Version)
+			}
+
+			doc.Body = "final"
+			check(PutJson(tx, "doc", "readme", &doc))
+
+			var stored Document
+			check(GetJson(tx, "doc", "readme", &stored))
+			if stored.Version != 2 || stored.Body != "final" {
+				t.Fatalf("Expected version 2 of the final body: %#v", stored)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("A stale version conflicts", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			// two handlers read the same version
+			var first, second Document
+			check(GetJson(tx, "doc", "readme", &first))
+			check(GetJson(tx, "doc", "readme", &second))
+
+			first.Body = "first"
+			check(PutJson(tx, "doc", "readme", &first))
+
+			second.Body = "second"
+			err := PutJson(tx, "doc", "readme", &second)
+			if !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("Expected ErrVersionConflict but got %v", err)
+			}
+			if second.Version != 2 {
+				t.Fatalf("The conflicting value should be left alone but has version %d", second.Version)
+			}
+
+			var stored Document
+			check(GetJson(tx, "doc", "readme", &stored))
+			if stored.Body != "first" {
+				t.Fatalf("The first write should have won: %#v", stored)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("A new record must start at version 0", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			return PutJson(tx, "doc", "new", Document{Body: "new", Version: 3})
+		})
+		if !errors.Is(err, ErrVersionConflict) {
+			t.Fatalf("Expected ErrVersionConflict but got %v", err)
+		}
+	})
+
+	t.Run("A failed put leaves the version alone", func(t *testing.T) {
+		store := NewStore(db)
+		check(store.SetSchema("strict", []byte(`{"properties": {"Body": {"type": "string", "minLength": 1}}}`)))
+
+		err := store.Update(func(tx *bolt.Tx) error {
+			doc := Document{}
+			if err := PutJson(tx, "strict", "doc", &doc); err == nil {
+				t.Fatal("Expected the empty body to fail the schema")
+			}
+			if doc.Version != 0 {
+				t.Fatalf("Expected the version to be left at 0 but got %d", doc.Version)
+			}
+
+			// so the retry isn't a conflict
+			doc.Body = "fixed"
+			check(PutJson(tx, "strict", "doc", &doc))
+			if doc.Version != 1 {
+				t.Fatalf("Expected version 1 but got %d", doc.Version)
+			}
+
+			// the put itself failing, onto a nested bucket
+			check(PutString(tx, "strict.nested", "key", "value"))
+			other := Document{Body: "clash"}
+			if err := PutJson(tx, "strict", "nested", &other); err == nil {
+				t.Fatal("Expected putting onto a bucket to fail")
+			}
+			if other.Version != 0 {
+				t.Fatalf("Expected the version to be left at 0 but got %d", other.Version)
+			}
+			return nil
+		})
+		check(err)
+	})
+
+	t.Run("PutManyJson checks and increments versions", func(t *testing.T) {
+		err := db.Update(func(tx *bolt.Tx) error {
+			docs := map[string]*Document{"a": {Body: "a"}, "b": {Body: "b"}}
+			check(PutManyJson(tx, "many", docs))
+			if docs["a"].Version != 1 || docs["b"].Version != 1 {
+				t.Fatalf("Expected both to be version 1: %#v %#v", docs["a"], docs["b"])
+			}
+
+			var stored Document
+			check(GetJson(tx, "many", "a", &stored))
+			if stored.Version != 1 {
+				t.Fatalf("Expected version 1 to be stored but got %d", stored.Version)
+			}
+
+			err := PutManyJson(tx, "many", map[string]Document{"a": {Body: "stale"}, "b": {Body: "b2", Version: 1}})
+			var errs KeyErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs["a"], ErrVersionConflict) {
+				t.Fatalf("Expected only a to conflict but got %v", err)
+			}
+			check(GetJson(tx, "many", "b", &stored))
+			if stored.Version != 2 || stored.Body != "b2" {
+				t.Fatalf("Expected version 2 of b: %#v", stored)
+			}
+			return nil
+		})
+		check(err)
+	})
+}
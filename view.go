@@ -0,0 +1,121 @@
+package rod
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+// MaterializedView keeps the bucket at Target derived from the bucket at Source. Every record in Source is given to
+// Transform, which returns the key and value to keep in Target, or false if the record should be left out.
+//
+//	store.AddView(rod.MaterializedView{
+//	    Source: "user",
+//	    Target: "user-by-email",
+//	    Transform: func(key string, value []byte) (string, []byte, bool) {
+//	        var user User
+//	        if err := json.Unmarshal(value, &user); err != nil || user.Email == "" {
+//	            return "", nil, false
+//	        }
+//	        return user.Email, []byte(key), true
+//	    },
+//	})
+//
+// Each record in Source should give a different key in Target, otherwise they overwrite each other.
+type MaterializedView struct {
+	Source    string
+	Target    string
+	Transform func(key string, value []byte) (string, []byte, bool)
+}
+
+// AddView registers the view with the Store. From then on, every Put or Del to the view's Source made with rod inside
+// the Store's Update or UpdateAs also updates its Target, within the same transaction, so they can't drift apart. Any
+// records already in Source aren't added to Target until RebuildViews is called. Call AddView before using the Store,
+// since it isn't safe to call alongside transactions.
+func (s *Store) AddView(v MaterializedView) {
+	s.views = append(s.views, v)
+	if len(s.views) == 1 {
+		s.hooks = append(s.hooks, maintainViews)
+	}
+}
+
+// maintainViews is the hook which applies each mutation to any views of its location. The old target record (from
+// the value before) is removed and the new one (from the value after) is put.
+func maintainViews(tx Tx, st *txState, m *Mutation) error {
+	for _, v := range st.store.views {
+		// a view also applies within each Namespace, keeping its target in the same one
+		if v.Source != m.Location {
+			if m.namespace == "" || m.namespace+"."+v.Source != m.Location {
+				continue
+			}
+			v.Target = m.namespace + "." + v.Target
+		}
+
+		oldKey, _, hadOld := transform(v, m.Key, m.Before)
+		newKey, newValue, hasNew := transform(v, m.Key, m.After)
+
+		if hadOld && (!hasNew || oldKey != newKey) {
+			if err := Del(tx, v.Target, oldKey); err != nil {
+				return err
+			}
+		}
+		if hasNew {
+			if err := Put(tx, v.Target, newKey, newValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func transform(v MaterializedView, key string, value []byte) (string, []byte, bool) {
+	if value == nil {
+		return "", nil, false
+	}
+	targetKey, targetValue, ok := v.Transform(key, value)
+	return targetKey, targetValue, ok && targetKey != ""
+}
+
+// RebuildViews empties the Target of every view and builds it again from its Source, in one transaction. Use this
+// after adding a view to existing data, or if the source was changed outside the Store.
+func (s *Store) RebuildViews() error {
+	return s.Update(func(tx *bolt.Tx) error {
+		for _, v := range s.views {
+			if err := clearBucket(tx, v.Target); err != nil {
+				return err
+			}
+			err := Each(tx, v.Source, func(key string, value []byte) error {
+				if targetKey, targetValue, ok := transform(v, key, value); ok {
+					return Put(tx, v.Target, targetKey, targetValue)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// clearBucket deletes every key from the bucket at location, leaving any nested buckets alone.
+func clearBucket(tx Tx, location string) error {
+	b, err := BucketAt(tx, location)
+	if err != nil || b == nil {
+		return err
+	}
+
+	var keys []string
+	err = Each(tx, location, func(key string, value []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := delKey(tx, b, location, []byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
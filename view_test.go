@@ -0,0 +1,86 @@
+package rod
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestMaterializedView(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+
+	store := NewStore(db)
+
+	// users with any logins, indexed by their login count
+	byLogins := MaterializedView{
+		Source: "user",
+		Target: "user-by-logins",
+		Transform: func(key string, value []byte) (string, []byte, bool) {
+			var user User
+			if err := json.Unmarshal(value, &user); err != nil || user.Logins == 0 {
+				return "", nil, false
+			}
+			return Key(user.Logins, key), []byte(key), true
+		},
+	}
+
+	// some data from before the view existed
+	err := store.Update(func(tx *bolt.Tx) error {
+		return PutJson(tx, "user", "alice", User{"alice", 3})
+	})
+	check(err)
+
+	store.AddView(byLogins)
+
+	targets := func() []string {
+		var users []string
+		err := store.View(func(tx *bolt.Tx) error {
+			return Each(tx, "user-by-logins", func(key string, value []byte) error {
+				users = append(users, string(value))
+				return nil
+			})
+		})
+		check(err)
+		return users
+	}
+
+	t.Run("Writes update the view", func(t *testing.T) {
+		err := store.Update(func(tx *bolt.Tx) error {
+			check(PutJson(tx, "user", "bob", User{"bob", 5}))
+			check(PutJson(tx, "user", "carol", User{"carol", 1}))
+			check(PutJson(tx, "user", "dave", User{"dave", 0}))
+			return nil
+		})
+		check(err)
+
+		users := targets()
+		if len(users) != 2 || users[0] != "carol" || users[1] != "bob" {
+			t.Fatalf("Expected carol then bob but got %v", users)
+		}
+	})
+
+	t.Run("Changing and deleting a source moves its target", func(t *testing.T) {
+		err := store.Update(func(tx *bolt.Tx) error {
+			check(PutJson(tx, "user", "carol", User{"carol", 10}))
+			check(Del(tx, "user", "bob"))
+			return nil
+		})
+		check(err)
+
+		users := targets()
+		if len(users) != 1 || users[0] != "carol" {
+			t.Fatalf("Expected only carol but got %v", users)
+		}
+	})
+
+	t.Run("RebuildViews", func(t *testing.T) {
+		check(store.RebuildViews())
+
+		users := targets()
+		if len(users) != 2 || users[0] != "alice" || users[1] != "carol" {
+			t.Fatalf("Expected alice then carol but got %v", users)
+		}
+	})
+}
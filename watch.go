@@ -0,0 +1,51 @@
+package rod
+
+import "strings"
+
+// watcher is a function given to Store.Watch, and the location it is watching.
+type watcher struct {
+	location string
+	fn       func(m Mutation)
+}
+
+// Watch calls fn with each change made with rod to location, or to any location beneath it (such as "user.chilts" for
+// "user"), within the Store's Update or UpdateAs. Changes are only passed on once their transaction has committed, so
+// fn never sees a change which was rolled back. It is called on the goroutine which called Update, just before Update
+// returns, so it may start transactions of its own but holds up the caller until it returns. As with AddView, call
+// Watch before using the Store, since it isn't safe to call alongside transactions.
+//
+//	store.Watch("config", func(m rod.Mutation) {
+//	    log.Printf("%s %s/%s", m.Op, m.Location, m.Key)
+//	})
+func (s *Store) Watch(location string, fn func(m Mutation)) {
+	s.watchers = append(s.watchers, watcher{location: location, fn: fn})
+	if len(s.watchers) == 1 {
+		s.hooks = append(s.hooks, collectWatched)
+	}
+}
+
+// collectWatched is the hook which keeps each mutation which a watcher is interested in, until the commit.
+func collectWatched(tx Tx, st *txState, m *Mutation) error {
+	for _, w := range st.store.watchers {
+		if w.watches(m.Location) {
+			st.watched = append(st.watched, *m)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (w watcher) watches(location string) bool {
+	return location == w.location || strings.HasPrefix(location, w.location+".")
+}
+
+// notifyWatchers tells each watcher about the mutations it is interested in, in the order they were made.
+func (s *Store) notifyWatchers(watched []Mutation) {
+	for _, m := range watched {
+		for _, w := range s.watchers {
+			if w.watches(m.Location) {
+				w.fn(m)
+			}
+		}
+	}
+}
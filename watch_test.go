@@ -0,0 +1,52 @@
+package rod
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestWatch(t *testing.T) {
+	db, done := openTestDB(t)
+	defer done()
+	s := NewStore(db)
+
+	var seen []string
+	s.Watch("user", func(m Mutation) {
+		seen = append(seen, m.Op+" "+m.Location+"/"+m.Key)
+
+		// watchers may start transactions of their own
+		check(s.View(func(tx *bolt.Tx) error {
+			_, err := Get(tx, m.Location, m.Key)
+			return err
+		}))
+	})
+
+	t.Run("Committed changes", func(t *testing.T) {
+		check(s.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "user", "andy", "Andrew"))
+			check(PutString(tx, "user.chilts", "email", "andy@example.com"))
+			check(PutString(tx, "users", "other", "not watched"))
+			if len(seen) != 0 {
+				t.Fatal("Watchers should not be told about changes before the commit")
+			}
+			return Del(tx, "user", "andy")
+		}))
+
+		if len(seen) != 3 || seen[0] != "put user/andy" || seen[1] != "put user.chilts/email" || seen[2] != "del user/andy" {
+			t.Fatalf("Unexpected changes: %v", seen)
+		}
+	})
+
+	t.Run("Rolled back changes", func(t *testing.T) {
+		seen = nil
+		err := s.Update(func(tx *bolt.Tx) error {
+			check(PutString(tx, "user", "rolled", "back"))
+			return errors.New("roll back")
+		})
+		if err == nil || len(seen) != 0 {
+			t.Fatalf("Watchers should not see changes which were rolled back: %v", seen)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package rod
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// The defaults for EnableWriteBehind.
+const (
+	DefaultWriteBehindQueue    = 10000
+	DefaultWriteBehindBatch    = 1000
+	DefaultWriteBehindInterval = 100 * time.Millisecond
+)
+
+var (
+	// ErrWriteBehindNotEnabled is returned by PutAsync if EnableWriteBehind hasn't been called.
+	ErrWriteBehindNotEnabled = errors.New("write-behind not enabled")
+
+	// ErrWriteQueueFull is returned by PutAsync when the queue is full, rather than waiting for room.
+	ErrWriteQueueFull = errors.New("write queue is full")
+
+	// ErrWriteBehindClosed is returned by PutAsync once the Store has been closed.
+	ErrWriteBehindClosed = errors.New("write-behind closed")
+
+	// ErrInvalidWriteBehind is returned by EnableWriteBehind if the batch or interval isn't positive, or the queue size
+	// is negative.
+	ErrInvalidWriteBehind = errors.New("write-behind batch and interval must be positive")
+)
+
+// AsyncWrite is a write queued with PutAsync.
+type AsyncWrite struct {
+	Location string
+	Key      string
+	Value    []byte
+}
+
+// WriteBehindOption configures EnableWriteBehind.
+type WriteBehindOption func(*writeBehind)
+
+// WriteBehindQueue sets how many writes may be waiting to be written before PutAsync returns ErrWriteQueueFull,
+// rather than DefaultWriteBehindQueue.
+func WriteBehindQueue(n int) WriteBehindOption {
+	return func(w *writeBehind) {
+		w.queueSize = n
+	}
+}
+
+// WriteBehindBatch sets the most writes put in each transaction, rather than DefaultWriteBehindBatch. A batch is
+// written as soon as it is full, without waiting for the interval.
+func WriteBehindBatch(n int) WriteBehindOption {
+	return func(w *writeBehind) {
+		w.batch = n
+	}
+}
+
+// WriteBehindInterval sets how often the queued writes are written, rather than DefaultWriteBehindInterval.
+func WriteBehindInterval(d time.Duration) WriteBehindOption {
+	return func(w *writeBehind) {
+		w.interval = d
+	}
+}
+
+// OnWriteBehindError calls fn whenever a batch of writes fails, with the error and the writes which were lost. Without
+// it, failed writes are silently dropped.
+func OnWriteBehindError(fn func(err error, writes []AsyncWrite)) WriteBehindOption {
+	return func(w *writeBehind) {
+		w.onError = fn
+	}
+}
+
+// writeBehind is the queue of writes made with PutAsync, and the goroutine which writes them.
+type writeBehind struct {
+	queueSize int
+	batch     int
+	interval  time.Duration
+	onError   func(err error, writes []AsyncWrite)
+
+	// mu is held to read while queueing and to write while closing, so nothing can be queued once the goroutine has
+	// been told to stop and is draining the queue for the last time.
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan AsyncWrite
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// EnableWriteBehind starts a goroutine which writes the values given to PutAsync in batches, each in a transaction of
+// its own. This trades durability for latency: PutAsync returns as soon as the write is queued, and a write which is
+// still queued is lost if the process dies, so it suits high-rate data such as telemetry where losing the last moments
+// is acceptable. Close writes whatever is still queued before closing the database. Call EnableWriteBehind before
+// using the Store, since it isn't safe to call alongside PutAsync. It returns ErrInvalidWriteBehind, and starts
+// nothing, if the batch or interval isn't positive or the queue size is negative.
+func (s *Store) EnableWriteBehind(opts ...WriteBehindOption) error {
+	w := &writeBehind{
+		queueSize: DefaultWriteBehindQueue,
+		batch:     DefaultWriteBehindBatch,
+		interval:  DefaultWriteBehindInterval,
+		flushes:   make(chan chan struct{}),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.batch <= 0 || w.interval <= 0 || w.queueSize < 0 {
+		return ErrInvalidWriteBehind
+	}
+	w.queue = make(chan AsyncWrite, w.queueSize)
+	s.writeBehind = w
+	go w.run(s)
+	return nil
+}
+
+// PutAsync queues value to be put at location/key by the write-behind goroutine, returning straight away. The value is
+// copied, so may be reused once PutAsync returns. It returns ErrWriteQueueFull if the queue is full, in which case
+// nothing is queued.
+func (s *Store) PutAsync(location, key string, value []byte) error {
+	w := s.writeBehind
+	if w == nil {
+		return wrapErr("PutAsync", location, key, ErrWriteBehindNotEnabled)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return wrapErr("PutAsync", location, key, ErrWriteBehindClosed)
+	}
+
+	select {
+	case w.queue <- AsyncWrite{Location: location, Key: key, Value: copyBytes(value)}:
+		return nil
+	default:
+		return wrapErr("PutAsync", location, key, ErrWriteQueueFull)
+	}
+}
+
+// Flush waits until every write queued with PutAsync before it was called has been written (or failed).
+func (s *Store) Flush() {
+	w := s.writeBehind
+	if w == nil {
+		return
+	}
+	reply := make(chan struct{})
+	select {
+	case w.flushes <- reply:
+		<-reply
+	case <-w.done:
+	}
+}
+
+// close writes whatever is still queued and stops the goroutine.
+func (w *writeBehind) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
+
+func (w *writeBehind) run(s *Store) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var pending []AsyncWrite
+	for {
+		select {
+		case write := <-w.queue:
+			pending = append(pending, write)
+			if len(pending) >= w.batch {
+				pending = w.write(s, pending)
+			}
+		case <-ticker.C:
+			pending = w.write(s, pending)
+		case reply := <-w.flushes:
+			pending = w.write(s, w.drain(pending))
+			close(reply)
+		case <-w.stop:
+			w.write(s, w.drain(pending))
+			return
+		}
+	}
+}
+
+// drain adds everything already in the queue to pending.
+func (w *writeBehind) drain(pending []AsyncWrite) []AsyncWrite {
+	for {
+		select {
+		case write := <-w.queue:
+			pending = append(pending, write)
+		default:
+			return pending
+		}
+	}
+}
+
+// write puts the pending writes in transactions of up to w.batch writes, returning pending emptied for reuse.
+func (w *writeBehind) write(s *Store, pending []AsyncWrite) []AsyncWrite {
+	for start := 0; start < len(pending); start += w.batch {
+		end := start + w.batch
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+
+		err := s.Update(func(tx *bolt.Tx) error {
+			for _, write := range batch {
+				if err := Put(tx, write.Location, write.Key, write.Value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil && w.onError != nil {
+			w.onError(err, append([]AsyncWrite(nil), batch...))
+		}
+	}
+	return pending[:0]
+}
@@ -0,0 +1,180 @@
+package rod
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestWriteBehind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rod-")
+	check(err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "rod.db")
+
+	t.Run("Not enabled", func(t *testing.T) {
+		s, err := Open(filename, 0666, nil)
+		check(err)
+		defer s.Close()
+
+		err = s.PutAsync("metrics", "a", []byte("1"))
+		if !errors.Is(err, ErrWriteBehindNotEnabled) {
+			t.Fatalf("Expected ErrWriteBehindNotEnabled, got %v", err)
+		}
+	})
+
+	t.Run("PutAsync and Flush", func(t *testing.T) {
+		s, err := Open(filename, 0666, nil)
+		check(err)
+		defer s.Close()
+
+		var mu sync.Mutex
+		updates := 0
+		s.Observe(func(op Operation) {
+			if op.Name == "update" {
+				mu.Lock()
+				updates++
+				mu.Unlock()
+			}
+		})
+		check(s.EnableWriteBehind(WriteBehindBatch(10), WriteBehindInterval(time.Hour)))
+
+		value := []byte("0")
+		for i := 0; i < 25; i++ {
+			check(s.PutAsync("metrics", fmt.Sprintf("%02d", i), value))
+		}
+		value[0] = 'x' // the queued values are copies
+		s.Flush()
+
+		check(s.View(func(tx *bolt.Tx) error {
+			keys, err := AllKeys(tx, "metrics")
+			check(err)
+			if len(keys) != 25 {
+				t.Fatalf("All 25 writes should have been flushed, not %d", len(keys))
+			}
+			v, err := GetString(tx, "metrics", "00")
+			check(err)
+			if v != "0" {
+				t.Fatalf("Unexpected value %q", v)
+			}
+			return nil
+		}))
+
+		mu.Lock()
+		defer mu.Unlock()
+		if updates != 3 {
+			t.Fatalf("25 writes in batches of 10 should take 3 transactions, not %d", updates)
+		}
+	})
+
+	t.Run("Bounded queue, errors and Close", func(t *testing.T) {
+		s, err := Open(filename, 0666, nil)
+		check(err)
+
+		var failed []AsyncWrite
+		stuck := make(chan struct{})
+		check(s.EnableWriteBehind(WriteBehindQueue(2), WriteBehindBatch(1), OnWriteBehindError(func(err error, writes []AsyncWrite) {
+			failed = append(failed, writes...)
+			<-stuck
+		})))
+
+		// a write which fails keeps the goroutine stuck in OnWriteBehindError, so the queue fills up
+		check(s.PutAsync("", "no-location", []byte("v")))
+		full := false
+		for i := 0; i < 10 && !full; i++ {
+			err := s.PutAsync("queue", fmt.Sprintf("%d", i), []byte("v"))
+			full = errors.Is(err, ErrWriteQueueFull)
+			time.Sleep(time.Millisecond)
+		}
+		if !full {
+			t.Fatal("PutAsync should return ErrWriteQueueFull once the queue is full")
+		}
+		close(stuck)
+
+		check(s.Close())
+		if err := s.PutAsync("queue", "late", []byte("v")); !errors.Is(err, ErrWriteBehindClosed) {
+			t.Fatalf("Expected ErrWriteBehindClosed, got %v", err)
+		}
+		if len(failed) != 1 || failed[0].Key != "no-location" {
+			t.Fatalf("The failed batch should have been given to OnWriteBehindError: %v", failed)
+		}
+
+		db, err := bolt.Open(filename, 0666, nil)
+		check(err)
+		defer db.Close()
+		check(db.View(func(tx *bolt.Tx) error {
+			v, err := GetString(tx, "queue", "0")
+			check(err)
+			if v != "v" {
+				t.Fatal("Close should have written the queued writes")
+			}
+			return nil
+		}))
+	})
+
+	t.Run("Invalid options are refused", func(t *testing.T) {
+		s, err := Open(filename, 0666, nil)
+		check(err)
+		defer s.Close()
+
+		for _, opt := range []WriteBehindOption{WriteBehindBatch(0), WriteBehindInterval(0), WriteBehindInterval(-time.Second), WriteBehindQueue(-1)} {
+			if err := s.EnableWriteBehind(opt); !errors.Is(err, ErrInvalidWriteBehind) {
+				t.Fatalf("Expected ErrInvalidWriteBehind, got %v", err)
+			}
+		}
+		if err := s.PutAsync("metrics", "a", []byte("1")); !errors.Is(err, ErrWriteBehindNotEnabled) {
+			t.Fatalf("Nothing should have been enabled, got %v", err)
+		}
+	})
+
+	t.Run("Writes racing Close are either written or refused", func(t *testing.T) {
+		s, err := Open(filename, 0666, nil)
+		check(err)
+		check(s.EnableWriteBehind(WriteBehindInterval(time.Hour)))
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var queued []string
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					key := fmt.Sprintf("%d-%03d", i, j)
+					err := s.PutAsync("race", key, []byte("v"))
+					if errors.Is(err, ErrWriteBehindClosed) {
+						return
+					}
+					check(err)
+					mu.Lock()
+					queued = append(queued, key)
+					mu.Unlock()
+				}
+			}(i)
+		}
+		time.Sleep(time.Millisecond)
+		check(s.Close())
+		wg.Wait()
+
+		db, err := bolt.Open(filename, 0666, nil)
+		check(err)
+		defer db.Close()
+		check(db.View(func(tx *bolt.Tx) error {
+			for _, key := range queued {
+				exists, err := Exists(tx, "race", key)
+				check(err)
+				if !exists {
+					t.Fatalf("%s was queued but never written", key)
+				}
+			}
+			return nil
+		}))
+	})
+}